@@ -0,0 +1,11 @@
+package dependency
+
+import (
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/audit"
+	firebaseAuditStore "github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/audit"
+)
+
+func NewAuditStore(firestoreClient *firestore.Client) audit.Store {
+	return firebaseAuditStore.NewStore(firestoreClient)
+}
@@ -1,67 +1,253 @@
 package dependency
 
 import (
+	"context"
+	"expvar"
+	"time"
+
 	"cloud.google.com/go/firestore"
+	gcpubsub "cloud.google.com/go/pubsub"
 	"github.com/dwaynelavon/es-loyalty-program/config"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/audit"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
-	"github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/readmodel"
-	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	firebaseCheckpointStore "github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/checkpoint"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/pubsub"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
 	userCommand "github.com/dwaynelavon/es-loyalty-program/internal/app/user/command"
 	userEvent "github.com/dwaynelavon/es-loyalty-program/internal/app/user/event"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	walletCommand "github.com/dwaynelavon/es-loyalty-program/internal/app/wallet/command"
+	walletEvent "github.com/dwaynelavon/es-loyalty-program/internal/app/wallet/event"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/webhook"
+	"github.com/pkg/errors"
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
 func RegisterDispatchHandlers(
 	logger *zap.Logger,
+	configReader *config.Reader,
 	userEventStore user.EventStore,
+	walletEventStore wallet.EventStore,
 	eventBus eventsource.EventBus,
 	dispatcher eventsource.CommandDispatcher,
 ) error {
+	policyConfig, errPolicy := configReader.ReferralPolicyConfig()
+	if errPolicy != nil {
+		return errPolicy
+	}
+
 	userRepository := newUserRepository(logger, userEventStore)
-	dispatcher.RegisterHandler(
+	errUser := dispatcher.RegisterHandler(
 		userCommand.NewUserCommandHandler(
 			userCommand.CommandHandlerParams{
 				Repo:     userRepository,
 				Logger:   logger,
 				EventBus: eventBus,
+				ReferralPolicy: user.ReferralPolicy{
+					DailyLimit:     policyConfig.DailyLimit,
+					LifetimeLimit:  policyConfig.LifetimeLimit,
+					BlockedDomains: policyConfig.BlockedDomains,
+				},
+			},
+		),
+	)
+	if errUser != nil {
+		return errUser
+	}
+
+	walletRepository := newWalletRepository(logger, walletEventStore)
+	return dispatcher.RegisterHandler(
+		walletCommand.NewWalletCommandHandler(
+			walletCommand.CommandHandlerParams{
+				Repo:     walletRepository,
+				Logger:   logger,
+				EventBus: eventBus,
 			},
 		),
 	)
-	return nil
 }
 
 func RegisterEventHandlers(
 	logger *zap.Logger,
-	firestoreClient *firestore.Client,
 	eventBus eventsource.EventBus,
+	userProjector eventsource.Projector,
+	webhookHandler webhook.EventHandler,
+	userSaga userEvent.Saga,
+	referralSaga userEvent.ReferralSaga,
+	userEventStore user.EventStore,
+	walletProjector wallet.Projector,
 	dispatcher eventsource.CommandDispatcher,
-	userRepo user.ReadRepo,
-	pointsMappingService loyalty.PointsMappingService,
-	eventStore user.EventStore,
 ) error {
-	eventBus.RegisterHandler(
-		userEvent.NewEventHandler(
-			logger,
-			readmodel.NewUserStore(firestoreClient),
-			eventStore,
-		),
-	)
-	eventBus.RegisterHandler(userEvent.NewSaga(logger, dispatcher, userRepo, pointsMappingService))
+	eventBus.RegisterHandler(userProjector)
+	eventBus.RegisterHandler(walletProjector)
+	eventBus.RegisterHandler(walletEvent.NewOpener(dispatcher))
+	eventBus.RegisterHandler(walletEvent.NewLedger(dispatcher, userEventStore))
+	eventBus.RegisterHandler(userSaga)
+	eventBus.RegisterHandler(referralSaga)
+	eventBus.RegisterHandler(webhookHandler)
 	return nil
 }
 
-func NewDispatcher(
+// CatchUpProjections brings the read models up to date with the event
+// store before live events are consumed
+func CatchUpProjections(
+	userProjector eventsource.Projector,
+	walletProjector wallet.Projector,
+) error {
+	errUser := userProjector.CatchUp(context.Background())
+	if errUser != nil {
+		return errUser
+	}
+	return walletProjector.CatchUp(context.Background())
+}
+
+func NewUserProjector(
 	logger *zap.Logger,
+	userRepo user.ReadRepo,
+	eventStore user.EventStore,
+	checkpointStore eventsource.CheckpointStore,
+) eventsource.Projector {
+	return eventsource.NewProjector(eventsource.ProjectorParams{
+		Projection:  userEvent.NewEventHandler(logger, userRepo),
+		Store:       eventStore,
+		Checkpoints: checkpointStore,
+		Logger:      logger,
+	})
+}
+
+func NewCheckpointStore(
 	firestoreClient *firestore.Client,
-) eventsource.CommandDispatcher {
-	return eventsource.NewDispatcher(logger)
+) eventsource.CheckpointStore {
+	return firebaseCheckpointStore.NewStore(firestoreClient)
+}
+
+func NewDispatcher(
+	lc fx.Lifecycle,
+	logger *zap.Logger,
+	configReader *config.Reader,
+	middlewares []eventsource.Middleware,
+) (eventsource.CommandDispatcher, error) {
+	dispatcher := eventsource.NewDispatcher(logger)
+	dispatcher.Use(middlewares...)
+
+	dispatchConfig, err := configReader.CommandDispatchConfig()
+	if err != nil {
+		return nil, err
+	}
+	if dispatchConfig.Mode != config.CommandDispatchQueued {
+		return dispatcher, nil
+	}
+
+	queue := eventsource.NewQueuedDispatcher(eventsource.QueuedDispatcherParams{
+		Dispatcher:     dispatcher,
+		Logger:         logger,
+		MaxConcurrency: dispatchConfig.MaxConcurrency,
+		MaxDepth:       dispatchConfig.MaxQueueDepth,
+	})
+
+	// Served to admins at /debug/vars
+	expvar.Publish("commandQueue", expvar.Func(func() interface{} {
+		return queue.Stats()
+	}))
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return queue.Close(ctx)
+		},
+	})
+	return queue, nil
+}
+
+// conflictRetryAttempts is how many times a command is handled when its
+// aggregate keeps changing concurrently
+var conflictRetryAttempts = 3
+
+// NewDispatcherMiddlewares returns the dispatch pipeline in the order it
+// runs, the first middleware being the outermost. Auditing wraps recovery
+// so that panicking commands are recorded as failed. Conflicts are retried
+// outside the idempotency check so that concurrent duplicates of a
+// command return the result of the first
+func NewDispatcherMiddlewares(
+	logger *zap.Logger,
+	userEventStore user.EventStore,
+	auditStore audit.Store,
+) []eventsource.Middleware {
+	return []eventsource.Middleware{
+		audit.Middleware(auditStore, logger),
+		eventsource.RecoveryMiddleware(logger),
+		eventsource.LoggingMiddleware(logger),
+		eventsource.TimingMiddleware(
+			func(command string, elapsed time.Duration, err error) {
+				logger.Debug("command timing",
+					zap.String("command", command),
+					zap.Duration("elapsed", elapsed),
+					zap.Bool("failed", err != nil),
+				)
+			},
+		),
+		eventsource.ValidationMiddleware(),
+		eventsource.ConflictRetryMiddleware(conflictRetryAttempts),
+		eventsource.IdempotencyMiddleware(userEventStore),
+	}
 }
 
 func NewEventBus(
 	logger *zap.Logger,
-	firestoreClient *firestore.Client,
 	configReader *config.Reader,
+	transport eventsource.Transport,
 ) eventsource.EventBus {
-	return eventsource.NewEventBus(logger, configReader)
+	return eventsource.NewEventBusWithTransport(logger, configReader, transport)
+}
+
+func NewEventTransport(
+	logger *zap.Logger,
+	configReader *config.Reader,
+) (eventsource.Transport, error) {
+	transportConfig, err := configReader.EventBusTransportConfig()
+	if err != nil {
+		return nil, err
+	}
+	if transportConfig.Transport != config.EventBusTransportPubSub {
+		return eventsource.NewInProcessTransport(), nil
+	}
+
+	client, errClient := gcpubsub.NewClient(
+		context.Background(),
+		transportConfig.ProjectID,
+	)
+	if errClient != nil {
+		return nil, errors.Wrap(errClient, "unable to instantiate pubsub client")
+	}
+
+	return pubsub.NewTransport(pubsub.TransportParams{
+		Client:         client,
+		TopicID:        transportConfig.TopicID,
+		SubscriptionID: transportConfig.SubscriptionID,
+		Logger:         logger,
+	}), nil
+}
+
+// StartEventTransport begins receiving events from external transports
+// once the event handlers have been registered
+func StartEventTransport(
+	lc fx.Lifecycle,
+	transport eventsource.Transport,
+) error {
+	t, ok := transport.(pubsub.Transport)
+	if !ok {
+		return nil
+	}
+
+	errStart := t.Start(context.Background())
+	if errStart != nil {
+		return errStart
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return t.Stop()
+		},
+	})
+	return nil
 }
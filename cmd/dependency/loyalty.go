@@ -1,7 +1,73 @@
 package dependency
 
-import "github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+import (
+	"context"
+	"io/ioutil"
+	"path"
 
-func NewPointsMappingService() loyalty.PointsMappingService {
-	return loyalty.NewPointsActionMappingService()
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/config"
+	firebasePointsRulesStore "github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/pointsrules"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/pkg/errors"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+func NewPointsRulesStore(firestoreClient *firestore.Client) loyalty.PointsRulesStore {
+	return firebasePointsRulesStore.NewStore(firestoreClient)
+}
+
+// NewPointsRulesService loads the points rules while the app is built so
+// that sagas recovered on startup map points with the stored rules. The
+// app fails to start when the award rules are invalid
+func NewPointsRulesService(
+	lc fx.Lifecycle,
+	logger *zap.Logger,
+	configReader *config.Reader,
+	store loyalty.PointsRulesStore,
+) (loyalty.PointsRulesService, error) {
+	awardRules, errAwardRules := readAwardRules(configReader)
+	if errAwardRules != nil {
+		return nil, errAwardRules
+	}
+
+	service, err := loyalty.NewPointsRulesService(
+		context.Background(),
+		loyalty.PointsRulesParams{
+			Store:      store,
+			AwardRules: awardRules,
+			Logger:     logger,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			service.Stop()
+			return nil
+		},
+	})
+	return service, nil
+}
+
+func NewPointsMappingService(
+	rules loyalty.PointsRulesService,
+) loyalty.PointsMappingService {
+	return rules
+}
+
+func readAwardRules(configReader *config.Reader) ([]loyalty.AwardRule, error) {
+	file, exists := configReader.PointsAwardRulesFile()
+	if !exists {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path.Join("../config", file))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read award rules file")
+	}
+	return loyalty.ParseAwardRules(data)
 }
@@ -3,10 +3,13 @@ package dependency
 import (
 	"context"
 	"errors"
+	"expvar"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/99designs/gqlgen/graphql"
@@ -15,19 +18,41 @@ import (
 	"github.com/dwaynelavon/es-loyalty-program/config"
 	"github.com/dwaynelavon/es-loyalty-program/graph"
 	"github.com/dwaynelavon/es-loyalty-program/graph/generated"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/audit"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/auth"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/webhook"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 	"go.uber.org/zap"
 )
 
-var defaultPort = "8080"
+var (
+	defaultPort = "8080"
+
+	// actorHeader used to identify the caller. Clients can set it to any
+	// actor so it is dropped from every request
+	actorHeader    = "X-Actor-ID"
+	anonymousActor = "anonymous"
+	bearerPrefix   = "Bearer "
+)
 
 func RegisterRoutes(
 	logger *zap.Logger,
 	firestoreClient *firestore.Client,
 	dispatcher eventsource.CommandDispatcher,
 	userReadModel user.ReadModel,
+	userReadModelRebuilder user.ReadModelRebuilder,
+	webhookService webhook.Service,
+	auditStore audit.Store,
+	sagaRunner saga.Runner,
+	userEventStore user.EventStore,
+	walletReadRepo wallet.ReadRepo,
+	configReader *config.Reader,
+	pointsRulesService loyalty.PointsRulesService,
 ) {
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -36,8 +61,16 @@ func RegisterRoutes(
 
 	// Build server
 	graphResolver := &graph.Resolver{
-		UserReadModel: userReadModel,
-		Dispatcher:    dispatcher,
+		AdminActorIDs:          configReader.AdminActorIDs(),
+		AuditStore:             auditStore,
+		PointsRulesService:     pointsRulesService,
+		UserReadModel:          userReadModel,
+		UserEventStore:         userEventStore,
+		UserReadModelRebuilder: userReadModelRebuilder,
+		WalletReadRepo:         walletReadRepo,
+		WebhookService:         webhookService,
+		Dispatcher:             dispatcher,
+		SagaRunner:             sagaRunner,
 	}
 	generatedConfig := generated.Config{
 		Resolvers: graphResolver,
@@ -46,12 +79,29 @@ func RegisterRoutes(
 	srv := handler.NewDefaultServer(schema)
 	srv.SetErrorPresenter(errorPresenterWithLogger(logger))
 
-	// Handlers
-	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
+	var tokens *auth.Tokens
+	if secret, exists := configReader.ActorTokenSecret(); exists {
+		var errTokens error
+		tokens, errTokens = auth.NewTokens(secret)
+		if errTokens != nil {
+			log.Fatal(errTokens)
+		}
+	} else {
+		logger.Warn("ACTOR_TOKEN_SECRET is not set, every request is anonymous")
+	}
+
+	// Handlers. The default mux is not served, so that the variables
+	// expvar registers there are only served to admins
+	mux := http.NewServeMux()
+	mux.Handle("/", playground.Handler("GraphQL playground", "/query"))
+	mux.Handle("/query", withActor(tokens, srv))
+	mux.Handle("/debug/vars", withActor(
+		tokens,
+		withAdmin(configReader.AdminActorIDs(), expvar.Handler()),
+	))
 
 	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	log.Fatal(http.ListenAndServe(":"+port, mux))
 }
 
 func LoadEnv() error {
@@ -62,6 +112,48 @@ func LoadEnv() error {
 	return nil
 }
 
+// withActor attributes the commands dispatched while serving a request to
+// the actor of its bearer token. Requests without a token are anonymous
+// and requests with an invalid one are rejected. The actor header is
+// never trusted
+func withActor(tokens *auth.Tokens, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(actorHeader)
+
+		actor := anonymousActor
+		authorization := r.Header.Get("Authorization")
+		if tokens != nil && strings.HasPrefix(authorization, bearerPrefix) {
+			verified, err := tokens.Verify(
+				strings.TrimPrefix(authorization, bearerPrefix),
+				time.Now(),
+			)
+			if err != nil {
+				http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+				return
+			}
+			actor = verified
+		}
+
+		ctx := eventsource.WithActor(r.Context(), actor)
+		ctx = eventsource.WithCommandSource(ctx, eventsource.CommandSourceGraphQL)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withAdmin rejects the requests of actors that are not admins
+func withAdmin(adminActorIDs []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := eventsource.ActorFromContext(r.Context())
+		for _, v := range adminActorIDs {
+			if v == actor {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		http.Error(w, "admin required", http.StatusForbidden)
+	})
+}
+
 func errorPresenterWithLogger(
 	logger *zap.Logger,
 ) func(ctx context.Context, err error) *gqlerror.Error {
@@ -73,6 +165,35 @@ func errorPresenterWithLogger(
 				"Request timeout. Check network connection")
 		}
 
+		if validationErr, ok := eventsource.AsValidationError(err); ok {
+			return validationErrorPresenter(ctx, validationErr)
+		}
+
 		return graphql.DefaultErrorPresenter(ctx, err)
 	}
 }
+
+// validationErrorPresenter reports every invalid field in the error
+// extensions. Field names match the mutation arguments
+func validationErrorPresenter(
+	ctx context.Context,
+	err *eventsource.ValidationError,
+) *gqlerror.Error {
+	presented := gqlerror.ErrorPathf(
+		graphql.GetFieldContext(ctx).Path(),
+		"%v",
+		err.Error(),
+	)
+	arguments := make(map[string]interface{}, len(err.Fields))
+	for _, v := range err.Fields {
+		arguments[v.Field] = map[string]interface{}{
+			"rule":    v.Rule,
+			"message": v.Message,
+		}
+	}
+	presented.Extensions = map[string]interface{}{
+		"code":      "VALIDATION_FAILED",
+		"arguments": arguments,
+	}
+	return presented
+}
@@ -0,0 +1,150 @@
+package dependency
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/smtp"
+	"path"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/config"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	firebaseSagaStore "github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/saga"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/notify"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/scheduler"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	userEvent "github.com/dwaynelavon/es-loyalty-program/internal/app/user/event"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+func NewSagaStore(firestoreClient *firestore.Client) saga.Store {
+	return firebaseSagaStore.NewStore(firestoreClient)
+}
+
+func NewSagaRunner(logger *zap.Logger, store saga.Store) saga.Runner {
+	return saga.NewRunner(saga.RunnerParams{
+		Store:  store,
+		Logger: logger,
+	})
+}
+
+func NewSagaTimeouts(s scheduler.Scheduler) saga.Timeouts {
+	return saga.NewTimeouts(s)
+}
+
+// NewNotifier creates the configured Notifier. The templates are parsed
+// in either case so that an invalid templates file fails at startup
+func NewNotifier(
+	logger *zap.Logger,
+	configReader *config.Reader,
+) (notify.Notifier, error) {
+	notifierConfig, err := configReader.NotifierConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	templates, errTemplates := readNotificationTemplates(notifierConfig.TemplatesFile)
+	if errTemplates != nil {
+		return nil, errTemplates
+	}
+
+	if notifierConfig.Notifier != config.NotifierSMTP {
+		return notify.NewLogNotifier(logger), nil
+	}
+
+	var auth smtp.Auth
+	if notifierConfig.SMTPUsername != "" {
+		host, _, errHost := net.SplitHostPort(notifierConfig.SMTPAddr)
+		if errHost != nil {
+			return nil, errors.Wrap(errHost, "invalid smtp address")
+		}
+		auth = smtp.PlainAuth(
+			"",
+			notifierConfig.SMTPUsername,
+			notifierConfig.SMTPPassword,
+			host,
+		)
+	}
+
+	return notify.NewSMTPNotifier(notify.SMTPParams{
+		Addr:      notifierConfig.SMTPAddr,
+		From:      notifierConfig.SMTPFrom,
+		Auth:      auth,
+		Templates: templates,
+		Logger:    logger,
+	}), nil
+}
+
+func readNotificationTemplates(file string) (*notify.Templates, error) {
+	if file == "" {
+		return notify.NewTemplates(nil)
+	}
+
+	data, err := ioutil.ReadFile(path.Join("../config", file))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read notification templates file")
+	}
+	return notify.ParseTemplates(data)
+}
+
+func NewUserSaga(
+	logger *zap.Logger,
+	dispatcher eventsource.CommandDispatcher,
+	runner saga.Runner,
+	userEventStore user.EventStore,
+	userRepo user.ReadRepo,
+	pointsMappingService loyalty.PointsMappingService,
+) userEvent.Saga {
+	return userEvent.NewSaga(userEvent.SagaParams{
+		Dispatcher:    dispatcher,
+		Runner:        runner,
+		Store:         userEventStore,
+		Repo:          userRepo,
+		PointsMapping: pointsMappingService,
+		Logger:        logger,
+	})
+}
+
+func NewReferralSaga(
+	logger *zap.Logger,
+	dispatcher eventsource.CommandDispatcher,
+	runner saga.Runner,
+	timeouts saga.Timeouts,
+	notifier notify.Notifier,
+	userEventStore user.EventStore,
+) userEvent.ReferralSaga {
+	return userEvent.NewReferralSaga(userEvent.ReferralSagaParams{
+		Timeouts:   timeouts,
+		Notifier:   notifier,
+		Dispatcher: dispatcher,
+		Runner:     runner,
+		Store:      userEventStore,
+		Logger:     logger,
+	})
+}
+
+// sagaRecoveryTimeout bounds the startup time spent recovering sagas.
+// Instances left over are recovered on the next start or when their event
+// is redelivered
+var sagaRecoveryTimeout = 2 * time.Minute
+
+// RecoverSagas finishes interrupted sagas once the read models they rely
+// on have caught up
+func RecoverSagas(
+	userSaga userEvent.Saga,
+	referralSaga userEvent.ReferralSaga,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sagaRecoveryTimeout)
+	defer cancel()
+
+	errUser := userSaga.Recover(ctx)
+	if errUser != nil {
+		return errUser
+	}
+	return referralSaga.Recover(ctx)
+}
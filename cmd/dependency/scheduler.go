@@ -0,0 +1,82 @@
+package dependency
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	firebaseScheduleStore "github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/schedule"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/scheduler"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// NewCommandRegistry registers the commands that can be scheduled
+func NewCommandRegistry() eventsource.CommandRegistry {
+	registry := eventsource.NewCommandRegistry()
+	registry.Register(
+		&loyalty.CreateUser{},
+		&loyalty.DeleteUser{},
+		&loyalty.CreateReferral{},
+		&loyalty.CompleteReferral{},
+		&loyalty.EarnPoints{},
+		&loyalty.ExpireReferral{},
+		&loyalty.MarkReferralSent{},
+		&loyalty.CancelReferral{},
+		&loyalty.RedeemPoints{},
+		&loyalty.RevokePoints{},
+		&loyalty.UpdateProfile{},
+		&loyalty.OpenWallet{},
+		&loyalty.EarnWalletPoints{},
+		&loyalty.RedeemWalletPoints{},
+		&loyalty.AdjustWalletPoints{},
+		&loyalty.ExpireWalletPoints{},
+		&loyalty.TransferWalletPoints{},
+	)
+	return registry
+}
+
+func NewScheduleStore(firestoreClient *firestore.Client) scheduler.Store {
+	return firebaseScheduleStore.NewStore(firestoreClient)
+}
+
+func NewClock() scheduler.Clock {
+	return scheduler.NewClock()
+}
+
+func NewScheduler(
+	logger *zap.Logger,
+	store scheduler.Store,
+	registry eventsource.CommandRegistry,
+	dispatcher eventsource.CommandDispatcher,
+	clock scheduler.Clock,
+) scheduler.Scheduler {
+	return scheduler.New(scheduler.Params{
+		Store:      store,
+		Registry:   registry,
+		Dispatcher: dispatcher,
+		Clock:      clock,
+		Logger:     logger,
+	})
+}
+
+// StartScheduler begins dispatching due commands once the command
+// handlers have been registered
+func StartScheduler(
+	lc fx.Lifecycle,
+	s scheduler.Scheduler,
+) error {
+	errStart := s.Start(context.Background())
+	if errStart != nil {
+		return errStart
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			s.Stop()
+			return nil
+		},
+	})
+	return nil
+}
@@ -1,17 +1,25 @@
 package dependency
 
 import (
+	"context"
+
 	"cloud.google.com/go/firestore"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
 	firebaseEventStore "github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/event"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/readmodel"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	userEvent "github.com/dwaynelavon/es-loyalty-program/internal/app/user/event"
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
-func NewUserReadRepo(firestoreClient *firestore.Client) user.ReadRepo {
-	return newUserReadRepo(firestoreClient)
+func NewUserStore(firestoreClient *firestore.Client) user.SwitchableReadRepo {
+	return readmodel.NewUserStore(firestoreClient)
+}
+
+func NewUserReadRepo(userStore user.SwitchableReadRepo) user.ReadRepo {
+	return userStore
 }
 
 func NewUserReadModel(logger *zap.Logger, readRepo user.ReadRepo) user.ReadModel {
@@ -21,10 +29,6 @@ func NewUserReadModel(logger *zap.Logger, readRepo user.ReadRepo) user.ReadModel
 	})
 }
 
-func newUserReadRepo(firestoreClient *firestore.Client) user.ReadRepo {
-	return readmodel.NewUserStore(firestoreClient)
-}
-
 func newUserRepository(logger *zap.Logger, eventStore user.EventStore) eventsource.EventRepo {
 	params := loyalty.RepositoryParams{
 		Store:  eventStore,
@@ -36,6 +40,46 @@ func newUserRepository(logger *zap.Logger, eventStore user.EventStore) eventsour
 	return loyalty.NewRepository(params)
 }
 
+// NewUserReadModelRebuilder creates the rebuilder and keeps the user
+// projector on the collection switched to by other instances
+func NewUserReadModelRebuilder(
+	lc fx.Lifecycle,
+	logger *zap.Logger,
+	userStore user.SwitchableReadRepo,
+	eventStore user.EventStore,
+	checkpointStore eventsource.CheckpointStore,
+	userProjector eventsource.Projector,
+) user.ReadModelRebuilder {
+	rebuilder := userEvent.NewReadModelRebuilder(userEvent.RebuilderParams{
+		ReadRepo:    userStore,
+		Store:       eventStore,
+		Checkpoints: checkpointStore,
+		Projector:   userProjector,
+		Logger:      logger,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				errFollow := rebuilder.Follow(ctx)
+				if errFollow != nil {
+					logger.Error(
+						"stopped following read model switches",
+						zap.Error(errFollow),
+					)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return rebuilder
+}
+
 func NewUserEventStore(firestoreClient *firestore.Client) user.EventStore {
 	return firebaseEventStore.NewStore(firestoreClient)
 }
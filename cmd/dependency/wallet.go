@@ -0,0 +1,47 @@
+package dependency
+
+import (
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	firebaseEventStore "github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/event"
+	firebaseWalletStore "github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/wallet"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	walletEvent "github.com/dwaynelavon/es-loyalty-program/internal/app/wallet/event"
+	"go.uber.org/zap"
+)
+
+// NewWalletEventStore shares the event collection with users; wallet
+// streams are kept apart by wallet.ID
+func NewWalletEventStore(firestoreClient *firestore.Client) wallet.EventStore {
+	return firebaseEventStore.NewStore(firestoreClient)
+}
+
+func NewWalletReadRepo(firestoreClient *firestore.Client) wallet.ReadRepo {
+	return firebaseWalletStore.NewStore(firestoreClient)
+}
+
+func NewWalletProjector(
+	logger *zap.Logger,
+	readRepo wallet.ReadRepo,
+	eventStore wallet.EventStore,
+	checkpointStore eventsource.CheckpointStore,
+) wallet.Projector {
+	return eventsource.NewProjector(eventsource.ProjectorParams{
+		Projection:  walletEvent.NewEventHandler(logger, readRepo),
+		Store:       eventStore,
+		Checkpoints: checkpointStore,
+		Logger:      logger,
+	})
+}
+
+func newWalletRepository(logger *zap.Logger, eventStore wallet.EventStore) eventsource.EventRepo {
+	params := loyalty.RepositoryParams{
+		Store:  eventStore,
+		Logger: logger,
+		NewAggregate: func(id string) eventsource.Aggregate {
+			return wallet.NewWallet(id)
+		},
+	}
+	return loyalty.NewRepository(params)
+}
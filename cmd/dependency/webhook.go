@@ -0,0 +1,43 @@
+package dependency
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	firebaseWebhookStore "github.com/dwaynelavon/es-loyalty-program/internal/app/firebasestore/webhook"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/webhook"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+func NewWebhookStore(firestoreClient *firestore.Client) webhook.Store {
+	return firebaseWebhookStore.NewStore(firestoreClient)
+}
+
+func NewWebhookService(logger *zap.Logger, store webhook.Store) webhook.Service {
+	return webhook.NewService(store, logger)
+}
+
+// webhookDrainTimeout bounds how long shutdown waits for in-flight webhook
+// deliveries. Deliveries still running are cut off and not recorded
+var webhookDrainTimeout = 10 * time.Second
+
+func NewWebhookEventHandler(
+	lc fx.Lifecycle,
+	logger *zap.Logger,
+	store webhook.Store,
+) webhook.EventHandler {
+	handler := webhook.NewEventHandler(webhook.EventHandlerParams{
+		Store:  store,
+		Logger: logger,
+	})
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, webhookDrainTimeout)
+			defer cancel()
+			return handler.Wait(ctx)
+		},
+	})
+	return handler
+}
@@ -12,11 +12,36 @@ func main() {
 		dependency.NewFirebaseApp,
 		dependency.NewFirebaseClient,
 		dependency.NewUserEventStore,
+		dependency.NewUserStore,
 		dependency.NewUserReadRepo,
 		dependency.NewUserReadModel,
+		dependency.NewAuditStore,
+		dependency.NewDispatcherMiddlewares,
 		dependency.NewDispatcher,
+		dependency.NewEventTransport,
 		dependency.NewEventBus,
+		dependency.NewPointsRulesStore,
+		dependency.NewPointsRulesService,
 		dependency.NewPointsMappingService,
+		dependency.NewCheckpointStore,
+		dependency.NewUserProjector,
+		dependency.NewUserReadModelRebuilder,
+		dependency.NewWalletEventStore,
+		dependency.NewWalletReadRepo,
+		dependency.NewWalletProjector,
+		dependency.NewWebhookStore,
+		dependency.NewWebhookService,
+		dependency.NewWebhookEventHandler,
+		dependency.NewSagaStore,
+		dependency.NewSagaRunner,
+		dependency.NewUserSaga,
+		dependency.NewReferralSaga,
+		dependency.NewSagaTimeouts,
+		dependency.NewNotifier,
+		dependency.NewCommandRegistry,
+		dependency.NewScheduleStore,
+		dependency.NewClock,
+		dependency.NewScheduler,
 	)
 
 	modules := fx.Options()
@@ -25,6 +50,10 @@ func main() {
 		dependency.LoadEnv,
 		dependency.RegisterEventHandlers,
 		dependency.RegisterDispatchHandlers,
+		dependency.CatchUpProjections,
+		dependency.RecoverSagas,
+		dependency.StartEventTransport,
+		dependency.StartScheduler,
 		dependency.RegisterRoutes,
 	)
 
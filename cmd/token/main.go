@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/config"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/auth"
+)
+
+// Issues a bearer token for an actor, signed with ACTOR_TOKEN_SECRET, e.g.
+//
+//	go run ./token -actor admin -ttl 1h
+func main() {
+	actor := flag.String("actor", "", "actor the token identifies")
+	ttl := flag.Duration("ttl", time.Hour, "how long the token is valid")
+	flag.Parse()
+
+	// The environment may already hold the secret
+	_ = config.LoadEnvWithPath("../config/.env")
+	secret, exists := config.NewReader().ActorTokenSecret()
+	if !exists {
+		log.Fatal("ACTOR_TOKEN_SECRET is not set")
+	}
+
+	tokens, err := auth.NewTokens(secret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	token, errIssue := tokens.Issue(*actor, time.Now().Add(*ttl))
+	if errIssue != nil {
+		log.Fatal(errIssue)
+	}
+	fmt.Println(token)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -55,6 +56,101 @@ func (r *Reader) EventBusBackoffConfig() (*EventBusBackoffConfig, error) {
 	}, nil
 }
 
+// EventBusHandlerTimeout reads the deadline given to each event handler invocation
+func (r *Reader) EventBusHandlerTimeout() (time.Duration, error) {
+	timeoutStr, timeoutExists := os.LookupEnv("EVENT_BUS_HANDLER_TIMEOUT")
+	if !timeoutExists {
+		return 0, errors.New("missing event bus handler timeout")
+	}
+
+	timeout, errParseTimeout := strconv.ParseFloat(timeoutStr, 32)
+	if errParseTimeout != nil {
+		return 0, errors.New("unable to parse event bus handler timeout")
+	}
+
+	return time.Duration(timeout) * time.Millisecond, nil
+}
+
+// Supported event bus transports
+const (
+	EventBusTransportInProcess = "inprocess"
+	EventBusTransportPubSub    = "pubsub"
+)
+
+type EventBusTransportConfig struct {
+	Transport      string
+	ProjectID      string
+	TopicID        string
+	SubscriptionID string
+}
+
+// EventBusTransportConfig reads the transport used to deliver events. The
+// Pub/Sub client connects to the emulator when PUBSUB_EMULATOR_HOST is set.
+// PUBSUB_SUBSCRIPTION is shared by every replica
+func (r *Reader) EventBusTransportConfig() (*EventBusTransportConfig, error) {
+	transport, transportExists := os.LookupEnv("EVENT_BUS_TRANSPORT")
+	if !transportExists || transport == EventBusTransportInProcess {
+		return &EventBusTransportConfig{
+			Transport: EventBusTransportInProcess,
+		}, nil
+	}
+	if transport != EventBusTransportPubSub {
+		return nil, errors.New("unsupported event bus transport")
+	}
+
+	projectID, projectExists := os.LookupEnv("PUBSUB_PROJECT_ID")
+	topicID, topicExists := os.LookupEnv("PUBSUB_TOPIC")
+	subscriptionID, subscriptionExists := os.LookupEnv("PUBSUB_SUBSCRIPTION")
+	if !projectExists || !topicExists || !subscriptionExists {
+		return nil, errors.New("missing pubsub transport config values")
+	}
+
+	return &EventBusTransportConfig{
+		Transport:      transport,
+		ProjectID:      projectID,
+		TopicID:        topicID,
+		SubscriptionID: subscriptionID,
+	}, nil
+}
+
+// Supported command dispatch modes
+const (
+	CommandDispatchInline = "inline"
+	CommandDispatchQueued = "queued"
+)
+
+type CommandDispatchConfig struct {
+	Mode           string
+	MaxConcurrency int
+	MaxQueueDepth  int
+}
+
+// CommandDispatchConfig reads how commands are dispatched. Queued
+// dispatch limits are optional
+func (r *Reader) CommandDispatchConfig() (*CommandDispatchConfig, error) {
+	mode, modeExists := os.LookupEnv("COMMAND_DISPATCH_MODE")
+	if !modeExists || mode == CommandDispatchInline {
+		return &CommandDispatchConfig{
+			Mode: CommandDispatchInline,
+		}, nil
+	}
+	if mode != CommandDispatchQueued {
+		return nil, errors.New("unsupported command dispatch mode")
+	}
+
+	maxConcurrency, errConcurrency := lookupOptionalInt("COMMAND_QUEUE_MAX_CONCURRENCY")
+	maxQueueDepth, errDepth := lookupOptionalInt("COMMAND_QUEUE_MAX_DEPTH")
+	if errConcurrency != nil || errDepth != nil {
+		return nil, errors.New("unable to parse command queue values")
+	}
+
+	return &CommandDispatchConfig{
+		Mode:           mode,
+		MaxConcurrency: maxConcurrency,
+		MaxQueueDepth:  maxQueueDepth,
+	}, nil
+}
+
 // LoadEnvWithPath create a funtion that can be used to
 // load env variables from the filesystem when invoked
 func LoadEnvWithPath(configPath string) error {
@@ -63,3 +159,119 @@ func LoadEnvWithPath(configPath string) error {
 	}
 	return nil
 }
+
+func lookupOptionalInt(key string) (int, error) {
+	str, exists := os.LookupEnv(key)
+	if !exists {
+		return 0, nil
+	}
+	return strconv.Atoi(str)
+}
+
+// AdminActorIDs reads the actors allowed to change the loyalty program
+// configuration, e.g. points rules. No actor is an admin when unset
+func (r *Reader) AdminActorIDs() []string {
+	value, exists := os.LookupEnv("ADMIN_ACTOR_IDS")
+	if !exists {
+		return []string{}
+	}
+
+	actors := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if actor := strings.TrimSpace(v); actor != "" {
+			actors = append(actors, actor)
+		}
+	}
+	return actors
+}
+
+// ActorTokenSecret reads the secret signing the bearer tokens that
+// identify actors. Every request is anonymous when unset
+func (r *Reader) ActorTokenSecret() (string, bool) {
+	secret, exists := os.LookupEnv("ACTOR_TOKEN_SECRET")
+	return secret, exists && secret != ""
+}
+
+// PointsAwardRulesFile reads the name of the JSON file in the config
+// directory declaring the award rules. No award rules apply when unset
+func (r *Reader) PointsAwardRulesFile() (string, bool) {
+	return os.LookupEnv("POINTS_AWARD_RULES_FILE")
+}
+
+// Supported notifiers
+const (
+	NotifierLog  = "log"
+	NotifierSMTP = "smtp"
+)
+
+type NotifierConfig struct {
+	Notifier      string
+	SMTPAddr      string
+	SMTPFrom      string
+	SMTPUsername  string
+	SMTPPassword  string
+	TemplatesFile string
+}
+
+// NotifierConfig reads how notifications are delivered. Notifications are
+// logged unless NOTIFIER is smtp. The SMTP credentials and the templates
+// file in the config directory are optional
+func (r *Reader) NotifierConfig() (*NotifierConfig, error) {
+	templatesFile, _ := os.LookupEnv("NOTIFICATION_TEMPLATES_FILE")
+	notifier, notifierExists := os.LookupEnv("NOTIFIER")
+	if !notifierExists || notifier == NotifierLog {
+		return &NotifierConfig{
+			Notifier:      NotifierLog,
+			TemplatesFile: templatesFile,
+		}, nil
+	}
+	if notifier != NotifierSMTP {
+		return nil, errors.New("unsupported notifier")
+	}
+
+	addr, addrExists := os.LookupEnv("SMTP_ADDR")
+	from, fromExists := os.LookupEnv("SMTP_FROM")
+	if !addrExists || !fromExists {
+		return nil, errors.New("missing smtp notifier config values")
+	}
+
+	return &NotifierConfig{
+		Notifier:      notifier,
+		SMTPAddr:      addr,
+		SMTPFrom:      from,
+		SMTPUsername:  os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:  os.Getenv("SMTP_PASSWORD"),
+		TemplatesFile: templatesFile,
+	}, nil
+}
+
+type ReferralPolicyConfig struct {
+	DailyLimit     int
+	LifetimeLimit  int
+	BlockedDomains []string
+}
+
+// ReferralPolicyConfig reads the anti-abuse limits of referrals. Limits
+// are off when unset and no domain is blocked
+func (r *Reader) ReferralPolicyConfig() (*ReferralPolicyConfig, error) {
+	dailyLimit, errDaily := lookupOptionalInt("REFERRAL_DAILY_LIMIT")
+	lifetimeLimit, errLifetime := lookupOptionalInt("REFERRAL_LIFETIME_LIMIT")
+	if errDaily != nil || errLifetime != nil {
+		return nil, errors.New("unable to parse referral limits")
+	}
+
+	domains := []string{}
+	if value, exists := os.LookupEnv("REFERRAL_BLOCKED_DOMAINS"); exists {
+		for _, v := range strings.Split(value, ",") {
+			if domain := strings.TrimSpace(v); domain != "" {
+				domains = append(domains, domain)
+			}
+		}
+	}
+
+	return &ReferralPolicyConfig{
+		DailyLimit:     dailyLimit,
+		LifetimeLimit:  lifetimeLimit,
+		BlockedDomains: domains,
+	}, nil
+}
@@ -4,6 +4,7 @@ go 1.14
 
 require (
 	cloud.google.com/go/firestore v1.2.0
+	cloud.google.com/go/pubsub v1.3.1
 	firebase.google.com/go v3.13.0+incompatible
 	github.com/99designs/gqlgen v0.11.3
 	github.com/cenkalti/backoff/v4 v4.0.2
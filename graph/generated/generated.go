@@ -14,7 +14,13 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/introspection"
 	"github.com/dwaynelavon/es-loyalty-program/graph/model"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/audit"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/webhook"
 	gqlparser "github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
 )
@@ -38,22 +44,100 @@ type Config struct {
 
 type ResolverRoot interface {
 	Mutation() MutationResolver
+	PointsRule() PointsRuleResolver
 	Query() QueryResolver
 	User() UserResolver
+	Wallet() WalletResolver
 }
 
 type DirectiveRoot struct {
 }
 
 type ComplexityRoot struct {
+	CommandAuditEntry struct {
+		Actor       func(childComplexity int) int
+		AggregateID func(childComplexity int) int
+		CommandID   func(childComplexity int) int
+		CommandType func(childComplexity int) int
+		DurationMs  func(childComplexity int) int
+		Error       func(childComplexity int) int
+		ID          func(childComplexity int) int
+		IssuedAt    func(childComplexity int) int
+		Outcome     func(childComplexity int) int
+		Payload     func(childComplexity int) int
+		Source      func(childComplexity int) int
+	}
+
+	CommandRegistration struct {
+		CommandType func(childComplexity int) int
+		Handler     func(childComplexity int) int
+	}
+
+	CommandResult struct {
+		AggregateID func(childComplexity int) int
+		EventTypes  func(childComplexity int) int
+		Version     func(childComplexity int) int
+	}
+
 	Mutation struct {
-		UserCreate         func(childComplexity int, username string, email string, referredByCode *string) int
-		UserDelete         func(childComplexity int, userID string) int
-		UserReferralCreate func(childComplexity int, userID string, referredUserEmail string) int
+		PointsRedeem              func(childComplexity int, userID string, points int, reason *string, idempotencyKey *string) int
+		PointsRuleUpdate          func(childComplexity int, action loyalty.PointsAction, points int, expectedVersion *int) int
+		ProfileUpdate             func(childComplexity int, userID string, name *string, birthday *string, phone *string, idempotencyKey *string) int
+		UserCreate                func(childComplexity int, username string, email string, referredByCode *string, idempotencyKey *string) int
+		UserDelete                func(childComplexity int, userID string) int
+		UserReadModelRebuild      func(childComplexity int) int
+		UserReadModelRollback     func(childComplexity int) int
+		UserReferralCancel        func(childComplexity int, userID string, referralID string, reason *string, idempotencyKey *string) int
+		UserReferralCreate        func(childComplexity int, userID string, referredUserEmail string, idempotencyKey *string) int
+		WebhookSubscriptionCreate func(childComplexity int, url string, eventTypes []string, secret *string) int
+		WebhookSubscriptionDelete func(childComplexity int, subscriptionID string) int
+	}
+
+	PointsRedeemResponse struct {
+		Balance        func(childComplexity int) int
+		PointsRedeemed func(childComplexity int) int
+		Result         func(childComplexity int) int
+		UserID         func(childComplexity int) int
+	}
+
+	PointsRule struct {
+		Action    func(childComplexity int) int
+		Points    func(childComplexity int) int
+		UpdatedAt func(childComplexity int) int
+		UpdatedBy func(childComplexity int) int
+		Version   func(childComplexity int) int
+	}
+
+	Profile struct {
+		Birthday func(childComplexity int) int
+		Name     func(childComplexity int) int
+		Phone    func(childComplexity int) int
+	}
+
+	ProfileUpdateResponse struct {
+		Result func(childComplexity int) int
+		UserID func(childComplexity int) int
 	}
 
 	Query struct {
-		Users func(childComplexity int) int
+		CommandAuditLog            func(childComplexity int, aggregateID *string, actor *string, limit *int) int
+		PointsRules                func(childComplexity int) int
+		RegisteredCommands         func(childComplexity int) int
+		Sagas                      func(childComplexity int, statuses []saga.Status) int
+		UserReadModelRebuildStatus func(childComplexity int) int
+		Users                      func(childComplexity int) int
+		Wallet                     func(childComplexity int, userID string) int
+		WebhookDeliveries          func(childComplexity int, subscriptionID string) int
+		WebhookSubscriptions       func(childComplexity int) int
+	}
+
+	ReadModelRebuild struct {
+		Collection         func(childComplexity int) int
+		EventsProcessed    func(childComplexity int) int
+		InProgress         func(childComplexity int) int
+		LastError          func(childComplexity int) int
+		PreviousCollection func(childComplexity int) int
+		TotalEvents        func(childComplexity int) int
 	}
 
 	Referral struct {
@@ -65,47 +149,135 @@ type ComplexityRoot struct {
 		UpdatedAt         func(childComplexity int) int
 	}
 
+	SagaInstance struct {
+		Attempts         func(childComplexity int) int
+		CompensatedSteps func(childComplexity int) int
+		CompletedSteps   func(childComplexity int) int
+		CorrelationID    func(childComplexity int) int
+		ID               func(childComplexity int) int
+		LastError        func(childComplexity int) int
+		StartedAt        func(childComplexity int) int
+		Status           func(childComplexity int) int
+		Type             func(childComplexity int) int
+		UpdatedAt        func(childComplexity int) int
+	}
+
 	User struct {
-		CreatedAt    func(childComplexity int) int
-		Email        func(childComplexity int) int
-		Points       func(childComplexity int) int
-		ReferralCode func(childComplexity int) int
-		Referrals    func(childComplexity int) int
-		UpdatedAt    func(childComplexity int) int
-		UserID       func(childComplexity int) int
-		Username     func(childComplexity int) int
-		Version      func(childComplexity int) int
+		CreatedAt          func(childComplexity int) int
+		Email              func(childComplexity int) int
+		Points             func(childComplexity int) int
+		Profile            func(childComplexity int) int
+		ProfileCompletedAt func(childComplexity int) int
+		ReferralCode       func(childComplexity int) int
+		Referrals          func(childComplexity int) int
+		UpdatedAt          func(childComplexity int) int
+		UserID             func(childComplexity int) int
+		Username           func(childComplexity int) int
+		Version            func(childComplexity int) int
+		Wallet             func(childComplexity int) int
 	}
 
 	UserCreateResponse struct {
 		Email    func(childComplexity int) int
+		Result   func(childComplexity int) int
 		UserID   func(childComplexity int) int
 		Username func(childComplexity int) int
 	}
 
 	UserDeleteResponse struct {
+		Result func(childComplexity int) int
 		UserID func(childComplexity int) int
 	}
 
+	UserReferralCancelResponse struct {
+		ReferralID func(childComplexity int) int
+		Result     func(childComplexity int) int
+		UserID     func(childComplexity int) int
+	}
+
 	UserReferralCreatedResponse struct {
+		FlagReason        func(childComplexity int) int
+		Flagged           func(childComplexity int) int
 		ReferredUserEmail func(childComplexity int) int
+		Result            func(childComplexity int) int
 		UserID            func(childComplexity int) int
 	}
+
+	Wallet struct {
+		Balance   func(childComplexity int) int
+		OpenedAt  func(childComplexity int) int
+		UpdatedAt func(childComplexity int) int
+		UserID    func(childComplexity int) int
+		Version   func(childComplexity int) int
+		WalletID  func(childComplexity int) int
+	}
+
+	WebhookDelivery struct {
+		AggregateID    func(childComplexity int) int
+		Attempts       func(childComplexity int) int
+		DeliveredAt    func(childComplexity int) int
+		Error          func(childComplexity int) int
+		EventType      func(childComplexity int) int
+		EventVersion   func(childComplexity int) int
+		ID             func(childComplexity int) int
+		StatusCode     func(childComplexity int) int
+		SubscriptionID func(childComplexity int) int
+		Succeeded      func(childComplexity int) int
+	}
+
+	WebhookSubscription struct {
+		CreatedAt  func(childComplexity int) int
+		EventTypes func(childComplexity int) int
+		ID         func(childComplexity int) int
+		URL        func(childComplexity int) int
+	}
+
+	WebhookSubscriptionCreateResponse struct {
+		Secret       func(childComplexity int) int
+		Subscription func(childComplexity int) int
+	}
+
+	WebhookSubscriptionDeleteResponse struct {
+		SubscriptionID func(childComplexity int) int
+	}
 }
 
 type MutationResolver interface {
-	UserCreate(ctx context.Context, username string, email string, referredByCode *string) (*model.UserCreateResponse, error)
+	UserCreate(ctx context.Context, username string, email string, referredByCode *string, idempotencyKey *string) (*model.UserCreateResponse, error)
 	UserDelete(ctx context.Context, userID string) (*model.UserDeleteResponse, error)
-	UserReferralCreate(ctx context.Context, userID string, referredUserEmail string) (*model.UserReferralCreatedResponse, error)
+	UserReferralCreate(ctx context.Context, userID string, referredUserEmail string, idempotencyKey *string) (*model.UserReferralCreatedResponse, error)
+	UserReferralCancel(ctx context.Context, userID string, referralID string, reason *string, idempotencyKey *string) (*model.UserReferralCancelResponse, error)
+	ProfileUpdate(ctx context.Context, userID string, name *string, birthday *string, phone *string, idempotencyKey *string) (*model.ProfileUpdateResponse, error)
+	PointsRedeem(ctx context.Context, userID string, points int, reason *string, idempotencyKey *string) (*model.PointsRedeemResponse, error)
+	PointsRuleUpdate(ctx context.Context, action loyalty.PointsAction, points int, expectedVersion *int) (*loyalty.PointsRule, error)
+	UserReadModelRebuild(ctx context.Context) (*user.RebuildStatus, error)
+	UserReadModelRollback(ctx context.Context) (*user.RebuildStatus, error)
+	WebhookSubscriptionCreate(ctx context.Context, url string, eventTypes []string, secret *string) (*model.WebhookSubscriptionCreateResponse, error)
+	WebhookSubscriptionDelete(ctx context.Context, subscriptionID string) (*model.WebhookSubscriptionDeleteResponse, error)
+}
+type PointsRuleResolver interface {
+	Points(ctx context.Context, obj *loyalty.PointsRule) (int, error)
 }
 type QueryResolver interface {
 	Users(ctx context.Context) ([]user.DTO, error)
+	Wallet(ctx context.Context, userID string) (*wallet.DTO, error)
+	UserReadModelRebuildStatus(ctx context.Context) (*user.RebuildStatus, error)
+	WebhookSubscriptions(ctx context.Context) ([]webhook.Subscription, error)
+	WebhookDeliveries(ctx context.Context, subscriptionID string) ([]webhook.Delivery, error)
+	CommandAuditLog(ctx context.Context, aggregateID *string, actor *string, limit *int) ([]audit.Entry, error)
+	RegisteredCommands(ctx context.Context) ([]eventsource.CommandRegistration, error)
+	Sagas(ctx context.Context, statuses []saga.Status) ([]saga.Instance, error)
+	PointsRules(ctx context.Context) ([]loyalty.PointsRule, error)
 }
 type UserResolver interface {
 	Points(ctx context.Context, obj *user.DTO) (int, error)
+	Wallet(ctx context.Context, obj *user.DTO) (*wallet.DTO, error)
 
 	Referrals(ctx context.Context, obj *user.DTO) ([]user.Referral, error)
 }
+type WalletResolver interface {
+	Balance(ctx context.Context, obj *wallet.DTO) (int, error)
+}
 
 type executableSchema struct {
 	resolvers  ResolverRoot
@@ -122,6 +294,154 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 	_ = ec
 	switch typeName + "." + field {
 
+	case "CommandAuditEntry.actor":
+		if e.complexity.CommandAuditEntry.Actor == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.Actor(childComplexity), true
+
+	case "CommandAuditEntry.aggregateId":
+		if e.complexity.CommandAuditEntry.AggregateID == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.AggregateID(childComplexity), true
+
+	case "CommandAuditEntry.commandId":
+		if e.complexity.CommandAuditEntry.CommandID == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.CommandID(childComplexity), true
+
+	case "CommandAuditEntry.commandType":
+		if e.complexity.CommandAuditEntry.CommandType == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.CommandType(childComplexity), true
+
+	case "CommandAuditEntry.durationMs":
+		if e.complexity.CommandAuditEntry.DurationMs == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.DurationMs(childComplexity), true
+
+	case "CommandAuditEntry.error":
+		if e.complexity.CommandAuditEntry.Error == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.Error(childComplexity), true
+
+	case "CommandAuditEntry.id":
+		if e.complexity.CommandAuditEntry.ID == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.ID(childComplexity), true
+
+	case "CommandAuditEntry.issuedAt":
+		if e.complexity.CommandAuditEntry.IssuedAt == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.IssuedAt(childComplexity), true
+
+	case "CommandAuditEntry.outcome":
+		if e.complexity.CommandAuditEntry.Outcome == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.Outcome(childComplexity), true
+
+	case "CommandAuditEntry.payload":
+		if e.complexity.CommandAuditEntry.Payload == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.Payload(childComplexity), true
+
+	case "CommandAuditEntry.source":
+		if e.complexity.CommandAuditEntry.Source == nil {
+			break
+		}
+
+		return e.complexity.CommandAuditEntry.Source(childComplexity), true
+
+	case "CommandRegistration.commandType":
+		if e.complexity.CommandRegistration.CommandType == nil {
+			break
+		}
+
+		return e.complexity.CommandRegistration.CommandType(childComplexity), true
+
+	case "CommandRegistration.handler":
+		if e.complexity.CommandRegistration.Handler == nil {
+			break
+		}
+
+		return e.complexity.CommandRegistration.Handler(childComplexity), true
+
+	case "CommandResult.aggregateId":
+		if e.complexity.CommandResult.AggregateID == nil {
+			break
+		}
+
+		return e.complexity.CommandResult.AggregateID(childComplexity), true
+
+	case "CommandResult.eventTypes":
+		if e.complexity.CommandResult.EventTypes == nil {
+			break
+		}
+
+		return e.complexity.CommandResult.EventTypes(childComplexity), true
+
+	case "CommandResult.version":
+		if e.complexity.CommandResult.Version == nil {
+			break
+		}
+
+		return e.complexity.CommandResult.Version(childComplexity), true
+
+	case "Mutation.pointsRedeem":
+		if e.complexity.Mutation.PointsRedeem == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_pointsRedeem_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.PointsRedeem(childComplexity, args["userId"].(string), args["points"].(int), args["reason"].(*string), args["idempotencyKey"].(*string)), true
+
+	case "Mutation.pointsRuleUpdate":
+		if e.complexity.Mutation.PointsRuleUpdate == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_pointsRuleUpdate_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.PointsRuleUpdate(childComplexity, args["action"].(loyalty.PointsAction), args["points"].(int), args["expectedVersion"].(*int)), true
+
+	case "Mutation.profileUpdate":
+		if e.complexity.Mutation.ProfileUpdate == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_profileUpdate_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.ProfileUpdate(childComplexity, args["userId"].(string), args["name"].(*string), args["birthday"].(*string), args["phone"].(*string), args["idempotencyKey"].(*string)), true
+
 	case "Mutation.userCreate":
 		if e.complexity.Mutation.UserCreate == nil {
 			break
@@ -132,7 +452,7 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 			return 0, false
 		}
 
-		return e.complexity.Mutation.UserCreate(childComplexity, args["username"].(string), args["email"].(string), args["referredByCode"].(*string)), true
+		return e.complexity.Mutation.UserCreate(childComplexity, args["username"].(string), args["email"].(string), args["referredByCode"].(*string), args["idempotencyKey"].(*string)), true
 
 	case "Mutation.userDelete":
 		if e.complexity.Mutation.UserDelete == nil {
@@ -146,6 +466,32 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Mutation.UserDelete(childComplexity, args["userId"].(string)), true
 
+	case "Mutation.userReadModelRebuild":
+		if e.complexity.Mutation.UserReadModelRebuild == nil {
+			break
+		}
+
+		return e.complexity.Mutation.UserReadModelRebuild(childComplexity), true
+
+	case "Mutation.userReadModelRollback":
+		if e.complexity.Mutation.UserReadModelRollback == nil {
+			break
+		}
+
+		return e.complexity.Mutation.UserReadModelRollback(childComplexity), true
+
+	case "Mutation.userReferralCancel":
+		if e.complexity.Mutation.UserReferralCancel == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_userReferralCancel_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.UserReferralCancel(childComplexity, args["userId"].(string), args["referralId"].(string), args["reason"].(*string), args["idempotencyKey"].(*string)), true
+
 	case "Mutation.userReferralCreate":
 		if e.complexity.Mutation.UserReferralCreate == nil {
 			break
@@ -156,443 +502,4725 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 			return 0, false
 		}
 
-		return e.complexity.Mutation.UserReferralCreate(childComplexity, args["userId"].(string), args["referredUserEmail"].(string)), true
+		return e.complexity.Mutation.UserReferralCreate(childComplexity, args["userId"].(string), args["referredUserEmail"].(string), args["idempotencyKey"].(*string)), true
 
-	case "Query.users":
-		if e.complexity.Query.Users == nil {
+	case "Mutation.webhookSubscriptionCreate":
+		if e.complexity.Mutation.WebhookSubscriptionCreate == nil {
 			break
 		}
 
-		return e.complexity.Query.Users(childComplexity), true
+		args, err := ec.field_Mutation_webhookSubscriptionCreate_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
 
-	case "Referral.createdAt":
-		if e.complexity.Referral.CreatedAt == nil {
+		return e.complexity.Mutation.WebhookSubscriptionCreate(childComplexity, args["url"].(string), args["eventTypes"].([]string), args["secret"].(*string)), true
+
+	case "Mutation.webhookSubscriptionDelete":
+		if e.complexity.Mutation.WebhookSubscriptionDelete == nil {
 			break
 		}
 
-		return e.complexity.Referral.CreatedAt(childComplexity), true
+		args, err := ec.field_Mutation_webhookSubscriptionDelete_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
 
-	case "Referral.id":
-		if e.complexity.Referral.ID == nil {
+		return e.complexity.Mutation.WebhookSubscriptionDelete(childComplexity, args["subscriptionId"].(string)), true
+
+	case "PointsRedeemResponse.balance":
+		if e.complexity.PointsRedeemResponse.Balance == nil {
 			break
 		}
 
-		return e.complexity.Referral.ID(childComplexity), true
+		return e.complexity.PointsRedeemResponse.Balance(childComplexity), true
 
-	case "Referral.referralCode":
-		if e.complexity.Referral.ReferralCode == nil {
+	case "PointsRedeemResponse.pointsRedeemed":
+		if e.complexity.PointsRedeemResponse.PointsRedeemed == nil {
 			break
 		}
 
-		return e.complexity.Referral.ReferralCode(childComplexity), true
+		return e.complexity.PointsRedeemResponse.PointsRedeemed(childComplexity), true
 
-	case "Referral.referredUserEmail":
-		if e.complexity.Referral.ReferredUserEmail == nil {
+	case "PointsRedeemResponse.result":
+		if e.complexity.PointsRedeemResponse.Result == nil {
 			break
 		}
 
-		return e.complexity.Referral.ReferredUserEmail(childComplexity), true
+		return e.complexity.PointsRedeemResponse.Result(childComplexity), true
 
-	case "Referral.status":
-		if e.complexity.Referral.Status == nil {
+	case "PointsRedeemResponse.userId":
+		if e.complexity.PointsRedeemResponse.UserID == nil {
 			break
 		}
 
-		return e.complexity.Referral.Status(childComplexity), true
+		return e.complexity.PointsRedeemResponse.UserID(childComplexity), true
 
-	case "Referral.updatedAt":
-		if e.complexity.Referral.UpdatedAt == nil {
+	case "PointsRule.action":
+		if e.complexity.PointsRule.Action == nil {
 			break
 		}
 
-		return e.complexity.Referral.UpdatedAt(childComplexity), true
+		return e.complexity.PointsRule.Action(childComplexity), true
 
-	case "User.createdAt":
-		if e.complexity.User.CreatedAt == nil {
+	case "PointsRule.points":
+		if e.complexity.PointsRule.Points == nil {
 			break
 		}
 
-		return e.complexity.User.CreatedAt(childComplexity), true
+		return e.complexity.PointsRule.Points(childComplexity), true
 
-	case "User.email":
-		if e.complexity.User.Email == nil {
+	case "PointsRule.updatedAt":
+		if e.complexity.PointsRule.UpdatedAt == nil {
 			break
 		}
 
-		return e.complexity.User.Email(childComplexity), true
+		return e.complexity.PointsRule.UpdatedAt(childComplexity), true
 
-	case "User.points":
-		if e.complexity.User.Points == nil {
+	case "PointsRule.updatedBy":
+		if e.complexity.PointsRule.UpdatedBy == nil {
 			break
 		}
 
-		return e.complexity.User.Points(childComplexity), true
+		return e.complexity.PointsRule.UpdatedBy(childComplexity), true
 
-	case "User.referralCode":
-		if e.complexity.User.ReferralCode == nil {
+	case "PointsRule.version":
+		if e.complexity.PointsRule.Version == nil {
 			break
 		}
 
-		return e.complexity.User.ReferralCode(childComplexity), true
+		return e.complexity.PointsRule.Version(childComplexity), true
 
-	case "User.referrals":
-		if e.complexity.User.Referrals == nil {
+	case "Profile.birthday":
+		if e.complexity.Profile.Birthday == nil {
 			break
 		}
 
-		return e.complexity.User.Referrals(childComplexity), true
+		return e.complexity.Profile.Birthday(childComplexity), true
 
-	case "User.updatedAt":
-		if e.complexity.User.UpdatedAt == nil {
+	case "Profile.name":
+		if e.complexity.Profile.Name == nil {
 			break
 		}
 
-		return e.complexity.User.UpdatedAt(childComplexity), true
+		return e.complexity.Profile.Name(childComplexity), true
 
-	case "User.userId":
-		if e.complexity.User.UserID == nil {
+	case "Profile.phone":
+		if e.complexity.Profile.Phone == nil {
 			break
 		}
 
-		return e.complexity.User.UserID(childComplexity), true
+		return e.complexity.Profile.Phone(childComplexity), true
 
-	case "User.username":
-		if e.complexity.User.Username == nil {
+	case "ProfileUpdateResponse.result":
+		if e.complexity.ProfileUpdateResponse.Result == nil {
 			break
 		}
 
-		return e.complexity.User.Username(childComplexity), true
+		return e.complexity.ProfileUpdateResponse.Result(childComplexity), true
 
-	case "User.version":
-		if e.complexity.User.Version == nil {
+	case "ProfileUpdateResponse.userId":
+		if e.complexity.ProfileUpdateResponse.UserID == nil {
 			break
 		}
 
-		return e.complexity.User.Version(childComplexity), true
+		return e.complexity.ProfileUpdateResponse.UserID(childComplexity), true
 
-	case "UserCreateResponse.email":
-		if e.complexity.UserCreateResponse.Email == nil {
+	case "Query.commandAuditLog":
+		if e.complexity.Query.CommandAuditLog == nil {
 			break
 		}
 
-		return e.complexity.UserCreateResponse.Email(childComplexity), true
+		args, err := ec.field_Query_commandAuditLog_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
 
-	case "UserCreateResponse.userId":
-		if e.complexity.UserCreateResponse.UserID == nil {
+		return e.complexity.Query.CommandAuditLog(childComplexity, args["aggregateId"].(*string), args["actor"].(*string), args["limit"].(*int)), true
+
+	case "Query.pointsRules":
+		if e.complexity.Query.PointsRules == nil {
 			break
 		}
 
-		return e.complexity.UserCreateResponse.UserID(childComplexity), true
+		return e.complexity.Query.PointsRules(childComplexity), true
 
-	case "UserCreateResponse.username":
-		if e.complexity.UserCreateResponse.Username == nil {
+	case "Query.registeredCommands":
+		if e.complexity.Query.RegisteredCommands == nil {
 			break
 		}
 
-		return e.complexity.UserCreateResponse.Username(childComplexity), true
+		return e.complexity.Query.RegisteredCommands(childComplexity), true
 
-	case "UserDeleteResponse.userId":
-		if e.complexity.UserDeleteResponse.UserID == nil {
+	case "Query.sagas":
+		if e.complexity.Query.Sagas == nil {
 			break
 		}
 
-		return e.complexity.UserDeleteResponse.UserID(childComplexity), true
+		args, err := ec.field_Query_sagas_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
 
-	case "UserReferralCreatedResponse.referredUserEmail":
-		if e.complexity.UserReferralCreatedResponse.ReferredUserEmail == nil {
+		return e.complexity.Query.Sagas(childComplexity, args["statuses"].([]saga.Status)), true
+
+	case "Query.userReadModelRebuildStatus":
+		if e.complexity.Query.UserReadModelRebuildStatus == nil {
 			break
 		}
 
-		return e.complexity.UserReferralCreatedResponse.ReferredUserEmail(childComplexity), true
+		return e.complexity.Query.UserReadModelRebuildStatus(childComplexity), true
 
-	case "UserReferralCreatedResponse.userId":
-		if e.complexity.UserReferralCreatedResponse.UserID == nil {
+	case "Query.users":
+		if e.complexity.Query.Users == nil {
 			break
 		}
 
-		return e.complexity.UserReferralCreatedResponse.UserID(childComplexity), true
+		return e.complexity.Query.Users(childComplexity), true
 
-	}
-	return 0, false
-}
+	case "Query.wallet":
+		if e.complexity.Query.Wallet == nil {
+			break
+		}
 
-func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
-	rc := graphql.GetOperationContext(ctx)
-	ec := executionContext{rc, e}
-	first := true
+		args, err := ec.field_Query_wallet_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
 
-	switch rc.Operation.Operation {
-	case ast.Query:
-		return func(ctx context.Context) *graphql.Response {
-			if !first {
-				return nil
-			}
-			first = false
-			data := ec._Query(ctx, rc.Operation.SelectionSet)
-			var buf bytes.Buffer
-			data.MarshalGQL(&buf)
+		return e.complexity.Query.Wallet(childComplexity, args["userId"].(string)), true
 
-			return &graphql.Response{
-				Data: buf.Bytes(),
-			}
+	case "Query.webhookDeliveries":
+		if e.complexity.Query.WebhookDeliveries == nil {
+			break
 		}
-	case ast.Mutation:
-		return func(ctx context.Context) *graphql.Response {
-			if !first {
-				return nil
-			}
-			first = false
-			data := ec._Mutation(ctx, rc.Operation.SelectionSet)
-			var buf bytes.Buffer
-			data.MarshalGQL(&buf)
 
-			return &graphql.Response{
-				Data: buf.Bytes(),
-			}
+		args, err := ec.field_Query_webhookDeliveries_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
 		}
 
-	default:
-		return graphql.OneShot(graphql.ErrorResponse(ctx, "unsupported GraphQL operation"))
-	}
-}
+		return e.complexity.Query.WebhookDeliveries(childComplexity, args["subscriptionId"].(string)), true
 
-type executionContext struct {
-	*graphql.OperationContext
-	*executableSchema
-}
+	case "Query.webhookSubscriptions":
+		if e.complexity.Query.WebhookSubscriptions == nil {
+			break
+		}
 
-func (ec *executionContext) introspectSchema() (*introspection.Schema, error) {
-	if ec.DisableIntrospection {
-		return nil, errors.New("introspection disabled")
-	}
-	return introspection.WrapSchema(parsedSchema), nil
-}
+		return e.complexity.Query.WebhookSubscriptions(childComplexity), true
 
-func (ec *executionContext) introspectType(name string) (*introspection.Type, error) {
-	if ec.DisableIntrospection {
-		return nil, errors.New("introspection disabled")
-	}
-	return introspection.WrapTypeFromDef(parsedSchema, parsedSchema.Types[name]), nil
-}
+	case "ReadModelRebuild.collection":
+		if e.complexity.ReadModelRebuild.Collection == nil {
+			break
+		}
 
-var sources = []*ast.Source{
-	&ast.Source{Name: "graph/schema.graphqls", Input: `# GraphQL schema example
-#
-# https://gqlgen.com/getting-started/
+		return e.complexity.ReadModelRebuild.Collection(childComplexity), true
 
-scalar Time
+	case "ReadModelRebuild.eventsProcessed":
+		if e.complexity.ReadModelRebuild.EventsProcessed == nil {
+			break
+		}
 
-enum ReferralStatus {
-    Created
-    Sent
-    Completed
-}
+		return e.complexity.ReadModelRebuild.EventsProcessed(childComplexity), true
 
-type Referral {
-    id: String!
-    status: ReferralStatus!
-    referredUserEmail: String!
-    referralCode: String!
-    createdAt: Time!
-    updatedAt: Time!
-}
+	case "ReadModelRebuild.inProgress":
+		if e.complexity.ReadModelRebuild.InProgress == nil {
+			break
+		}
 
-type User {
-    userId: String
-    createdAt: Time!
-    updatedAt: Time!
-    username: String!
-    email: String!
-    points: Int!
-    referralCode: String!
-    referrals: [Referral!]!
-    version: Int!
-}
+		return e.complexity.ReadModelRebuild.InProgress(childComplexity), true
 
-type Query {
-    users: [User!]!
-}
+	case "ReadModelRebuild.lastError":
+		if e.complexity.ReadModelRebuild.LastError == nil {
+			break
+		}
 
-input NewUser {
-    username: String!
-}
+		return e.complexity.ReadModelRebuild.LastError(childComplexity), true
 
-type UserCreateResponse {
-    userId: String
-    username: String
-    email: String
-}
+	case "ReadModelRebuild.previousCollection":
+		if e.complexity.ReadModelRebuild.PreviousCollection == nil {
+			break
+		}
 
-type UserDeleteResponse {
-    userId: String
-}
+		return e.complexity.ReadModelRebuild.PreviousCollection(childComplexity), true
 
-type UserReferralCreatedResponse {
-    userId: String
-    referredUserEmail: String
-}
+	case "ReadModelRebuild.totalEvents":
+		if e.complexity.ReadModelRebuild.TotalEvents == nil {
+			break
+		}
 
-type Mutation {
-    userCreate(
-        username: String!
-        email: String!
-        referredByCode: String
-    ): UserCreateResponse!
-    userDelete(userId: String!): UserDeleteResponse!
-    userReferralCreate(
-        userId: String!
-        referredUserEmail: String!
-    ): UserReferralCreatedResponse
-}
-`, BuiltIn: false},
-}
-var parsedSchema = gqlparser.MustLoadSchema(sources...)
+		return e.complexity.ReadModelRebuild.TotalEvents(childComplexity), true
 
-// endregion ************************** generated!.gotpl **************************
+	case "Referral.createdAt":
+		if e.complexity.Referral.CreatedAt == nil {
+			break
+		}
 
-// region    ***************************** args.gotpl *****************************
+		return e.complexity.Referral.CreatedAt(childComplexity), true
 
-func (ec *executionContext) field_Mutation_userCreate_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
-	var err error
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["username"]; ok {
-		arg0, err = ec.unmarshalNString2string(ctx, tmp)
-		if err != nil {
-			return nil, err
+	case "Referral.id":
+		if e.complexity.Referral.ID == nil {
+			break
 		}
-	}
-	args["username"] = arg0
-	var arg1 string
-	if tmp, ok := rawArgs["email"]; ok {
-		arg1, err = ec.unmarshalNString2string(ctx, tmp)
-		if err != nil {
-			return nil, err
+
+		return e.complexity.Referral.ID(childComplexity), true
+
+	case "Referral.referralCode":
+		if e.complexity.Referral.ReferralCode == nil {
+			break
 		}
-	}
-	args["email"] = arg1
-	var arg2 *string
-	if tmp, ok := rawArgs["referredByCode"]; ok {
-		arg2, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
-		if err != nil {
-			return nil, err
+
+		return e.complexity.Referral.ReferralCode(childComplexity), true
+
+	case "Referral.referredUserEmail":
+		if e.complexity.Referral.ReferredUserEmail == nil {
+			break
 		}
-	}
-	args["referredByCode"] = arg2
-	return args, nil
-}
 
-func (ec *executionContext) field_Mutation_userDelete_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
-	var err error
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["userId"]; ok {
-		arg0, err = ec.unmarshalNString2string(ctx, tmp)
-		if err != nil {
-			return nil, err
+		return e.complexity.Referral.ReferredUserEmail(childComplexity), true
+
+	case "Referral.status":
+		if e.complexity.Referral.Status == nil {
+			break
 		}
-	}
-	args["userId"] = arg0
-	return args, nil
-}
 
-func (ec *executionContext) field_Mutation_userReferralCreate_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
-	var err error
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["userId"]; ok {
-		arg0, err = ec.unmarshalNString2string(ctx, tmp)
-		if err != nil {
-			return nil, err
+		return e.complexity.Referral.Status(childComplexity), true
+
+	case "Referral.updatedAt":
+		if e.complexity.Referral.UpdatedAt == nil {
+			break
 		}
-	}
-	args["userId"] = arg0
-	var arg1 string
-	if tmp, ok := rawArgs["referredUserEmail"]; ok {
-		arg1, err = ec.unmarshalNString2string(ctx, tmp)
-		if err != nil {
-			return nil, err
+
+		return e.complexity.Referral.UpdatedAt(childComplexity), true
+
+	case "SagaInstance.attempts":
+		if e.complexity.SagaInstance.Attempts == nil {
+			break
 		}
-	}
-	args["referredUserEmail"] = arg1
-	return args, nil
-}
 
-func (ec *executionContext) field_Query___type_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
-	var err error
-	args := map[string]interface{}{}
-	var arg0 string
-	if tmp, ok := rawArgs["name"]; ok {
-		arg0, err = ec.unmarshalNString2string(ctx, tmp)
-		if err != nil {
-			return nil, err
+		return e.complexity.SagaInstance.Attempts(childComplexity), true
+
+	case "SagaInstance.compensatedSteps":
+		if e.complexity.SagaInstance.CompensatedSteps == nil {
+			break
 		}
-	}
-	args["name"] = arg0
-	return args, nil
-}
 
-func (ec *executionContext) field___Type_enumValues_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
-	var err error
-	args := map[string]interface{}{}
-	var arg0 bool
-	if tmp, ok := rawArgs["includeDeprecated"]; ok {
-		arg0, err = ec.unmarshalOBoolean2bool(ctx, tmp)
-		if err != nil {
-			return nil, err
+		return e.complexity.SagaInstance.CompensatedSteps(childComplexity), true
+
+	case "SagaInstance.completedSteps":
+		if e.complexity.SagaInstance.CompletedSteps == nil {
+			break
 		}
-	}
-	args["includeDeprecated"] = arg0
-	return args, nil
-}
 
-func (ec *executionContext) field___Type_fields_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
-	var err error
-	args := map[string]interface{}{}
-	var arg0 bool
-	if tmp, ok := rawArgs["includeDeprecated"]; ok {
-		arg0, err = ec.unmarshalOBoolean2bool(ctx, tmp)
-		if err != nil {
-			return nil, err
+		return e.complexity.SagaInstance.CompletedSteps(childComplexity), true
+
+	case "SagaInstance.correlationId":
+		if e.complexity.SagaInstance.CorrelationID == nil {
+			break
 		}
-	}
-	args["includeDeprecated"] = arg0
-	return args, nil
-}
 
-// endregion ***************************** args.gotpl *****************************
+		return e.complexity.SagaInstance.CorrelationID(childComplexity), true
 
-// region    ************************** directives.gotpl **************************
+	case "SagaInstance.id":
+		if e.complexity.SagaInstance.ID == nil {
+			break
+		}
 
-// endregion ************************** directives.gotpl **************************
+		return e.complexity.SagaInstance.ID(childComplexity), true
 
-// region    **************************** field.gotpl *****************************
+	case "SagaInstance.lastError":
+		if e.complexity.SagaInstance.LastError == nil {
+			break
+		}
 
-func (ec *executionContext) _Mutation_userCreate(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	defer func() {
-		if r := recover(); r != nil {
-			ec.Error(ctx, ec.Recover(ctx, r))
-			ret = graphql.Null
+		return e.complexity.SagaInstance.LastError(childComplexity), true
+
+	case "SagaInstance.startedAt":
+		if e.complexity.SagaInstance.StartedAt == nil {
+			break
 		}
-	}()
-	fc := &graphql.FieldContext{
-		Object:   "Mutation",
-		Field:    field,
-		Args:     nil,
-		IsMethod: true,
-	}
 
-	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field_Mutation_userCreate_args(ctx, rawArgs)
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	fc.Args = args
+		return e.complexity.SagaInstance.StartedAt(childComplexity), true
+
+	case "SagaInstance.status":
+		if e.complexity.SagaInstance.Status == nil {
+			break
+		}
+
+		return e.complexity.SagaInstance.Status(childComplexity), true
+
+	case "SagaInstance.type":
+		if e.complexity.SagaInstance.Type == nil {
+			break
+		}
+
+		return e.complexity.SagaInstance.Type(childComplexity), true
+
+	case "SagaInstance.updatedAt":
+		if e.complexity.SagaInstance.UpdatedAt == nil {
+			break
+		}
+
+		return e.complexity.SagaInstance.UpdatedAt(childComplexity), true
+
+	case "User.createdAt":
+		if e.complexity.User.CreatedAt == nil {
+			break
+		}
+
+		return e.complexity.User.CreatedAt(childComplexity), true
+
+	case "User.email":
+		if e.complexity.User.Email == nil {
+			break
+		}
+
+		return e.complexity.User.Email(childComplexity), true
+
+	case "User.points":
+		if e.complexity.User.Points == nil {
+			break
+		}
+
+		return e.complexity.User.Points(childComplexity), true
+
+	case "User.profile":
+		if e.complexity.User.Profile == nil {
+			break
+		}
+
+		return e.complexity.User.Profile(childComplexity), true
+
+	case "User.profileCompletedAt":
+		if e.complexity.User.ProfileCompletedAt == nil {
+			break
+		}
+
+		return e.complexity.User.ProfileCompletedAt(childComplexity), true
+
+	case "User.referralCode":
+		if e.complexity.User.ReferralCode == nil {
+			break
+		}
+
+		return e.complexity.User.ReferralCode(childComplexity), true
+
+	case "User.referrals":
+		if e.complexity.User.Referrals == nil {
+			break
+		}
+
+		return e.complexity.User.Referrals(childComplexity), true
+
+	case "User.updatedAt":
+		if e.complexity.User.UpdatedAt == nil {
+			break
+		}
+
+		return e.complexity.User.UpdatedAt(childComplexity), true
+
+	case "User.userId":
+		if e.complexity.User.UserID == nil {
+			break
+		}
+
+		return e.complexity.User.UserID(childComplexity), true
+
+	case "User.username":
+		if e.complexity.User.Username == nil {
+			break
+		}
+
+		return e.complexity.User.Username(childComplexity), true
+
+	case "User.version":
+		if e.complexity.User.Version == nil {
+			break
+		}
+
+		return e.complexity.User.Version(childComplexity), true
+
+	case "User.wallet":
+		if e.complexity.User.Wallet == nil {
+			break
+		}
+
+		return e.complexity.User.Wallet(childComplexity), true
+
+	case "UserCreateResponse.email":
+		if e.complexity.UserCreateResponse.Email == nil {
+			break
+		}
+
+		return e.complexity.UserCreateResponse.Email(childComplexity), true
+
+	case "UserCreateResponse.result":
+		if e.complexity.UserCreateResponse.Result == nil {
+			break
+		}
+
+		return e.complexity.UserCreateResponse.Result(childComplexity), true
+
+	case "UserCreateResponse.userId":
+		if e.complexity.UserCreateResponse.UserID == nil {
+			break
+		}
+
+		return e.complexity.UserCreateResponse.UserID(childComplexity), true
+
+	case "UserCreateResponse.username":
+		if e.complexity.UserCreateResponse.Username == nil {
+			break
+		}
+
+		return e.complexity.UserCreateResponse.Username(childComplexity), true
+
+	case "UserDeleteResponse.result":
+		if e.complexity.UserDeleteResponse.Result == nil {
+			break
+		}
+
+		return e.complexity.UserDeleteResponse.Result(childComplexity), true
+
+	case "UserDeleteResponse.userId":
+		if e.complexity.UserDeleteResponse.UserID == nil {
+			break
+		}
+
+		return e.complexity.UserDeleteResponse.UserID(childComplexity), true
+
+	case "UserReferralCancelResponse.referralId":
+		if e.complexity.UserReferralCancelResponse.ReferralID == nil {
+			break
+		}
+
+		return e.complexity.UserReferralCancelResponse.ReferralID(childComplexity), true
+
+	case "UserReferralCancelResponse.result":
+		if e.complexity.UserReferralCancelResponse.Result == nil {
+			break
+		}
+
+		return e.complexity.UserReferralCancelResponse.Result(childComplexity), true
+
+	case "UserReferralCancelResponse.userId":
+		if e.complexity.UserReferralCancelResponse.UserID == nil {
+			break
+		}
+
+		return e.complexity.UserReferralCancelResponse.UserID(childComplexity), true
+
+	case "UserReferralCreatedResponse.flagReason":
+		if e.complexity.UserReferralCreatedResponse.FlagReason == nil {
+			break
+		}
+
+		return e.complexity.UserReferralCreatedResponse.FlagReason(childComplexity), true
+
+	case "UserReferralCreatedResponse.flagged":
+		if e.complexity.UserReferralCreatedResponse.Flagged == nil {
+			break
+		}
+
+		return e.complexity.UserReferralCreatedResponse.Flagged(childComplexity), true
+
+	case "UserReferralCreatedResponse.referredUserEmail":
+		if e.complexity.UserReferralCreatedResponse.ReferredUserEmail == nil {
+			break
+		}
+
+		return e.complexity.UserReferralCreatedResponse.ReferredUserEmail(childComplexity), true
+
+	case "UserReferralCreatedResponse.result":
+		if e.complexity.UserReferralCreatedResponse.Result == nil {
+			break
+		}
+
+		return e.complexity.UserReferralCreatedResponse.Result(childComplexity), true
+
+	case "UserReferralCreatedResponse.userId":
+		if e.complexity.UserReferralCreatedResponse.UserID == nil {
+			break
+		}
+
+		return e.complexity.UserReferralCreatedResponse.UserID(childComplexity), true
+
+	case "Wallet.balance":
+		if e.complexity.Wallet.Balance == nil {
+			break
+		}
+
+		return e.complexity.Wallet.Balance(childComplexity), true
+
+	case "Wallet.openedAt":
+		if e.complexity.Wallet.OpenedAt == nil {
+			break
+		}
+
+		return e.complexity.Wallet.OpenedAt(childComplexity), true
+
+	case "Wallet.updatedAt":
+		if e.complexity.Wallet.UpdatedAt == nil {
+			break
+		}
+
+		return e.complexity.Wallet.UpdatedAt(childComplexity), true
+
+	case "Wallet.userId":
+		if e.complexity.Wallet.UserID == nil {
+			break
+		}
+
+		return e.complexity.Wallet.UserID(childComplexity), true
+
+	case "Wallet.version":
+		if e.complexity.Wallet.Version == nil {
+			break
+		}
+
+		return e.complexity.Wallet.Version(childComplexity), true
+
+	case "Wallet.walletId":
+		if e.complexity.Wallet.WalletID == nil {
+			break
+		}
+
+		return e.complexity.Wallet.WalletID(childComplexity), true
+
+	case "WebhookDelivery.aggregateId":
+		if e.complexity.WebhookDelivery.AggregateID == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.AggregateID(childComplexity), true
+
+	case "WebhookDelivery.attempts":
+		if e.complexity.WebhookDelivery.Attempts == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.Attempts(childComplexity), true
+
+	case "WebhookDelivery.deliveredAt":
+		if e.complexity.WebhookDelivery.DeliveredAt == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.DeliveredAt(childComplexity), true
+
+	case "WebhookDelivery.error":
+		if e.complexity.WebhookDelivery.Error == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.Error(childComplexity), true
+
+	case "WebhookDelivery.eventType":
+		if e.complexity.WebhookDelivery.EventType == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.EventType(childComplexity), true
+
+	case "WebhookDelivery.eventVersion":
+		if e.complexity.WebhookDelivery.EventVersion == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.EventVersion(childComplexity), true
+
+	case "WebhookDelivery.id":
+		if e.complexity.WebhookDelivery.ID == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.ID(childComplexity), true
+
+	case "WebhookDelivery.statusCode":
+		if e.complexity.WebhookDelivery.StatusCode == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.StatusCode(childComplexity), true
+
+	case "WebhookDelivery.subscriptionId":
+		if e.complexity.WebhookDelivery.SubscriptionID == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.SubscriptionID(childComplexity), true
+
+	case "WebhookDelivery.succeeded":
+		if e.complexity.WebhookDelivery.Succeeded == nil {
+			break
+		}
+
+		return e.complexity.WebhookDelivery.Succeeded(childComplexity), true
+
+	case "WebhookSubscription.createdAt":
+		if e.complexity.WebhookSubscription.CreatedAt == nil {
+			break
+		}
+
+		return e.complexity.WebhookSubscription.CreatedAt(childComplexity), true
+
+	case "WebhookSubscription.eventTypes":
+		if e.complexity.WebhookSubscription.EventTypes == nil {
+			break
+		}
+
+		return e.complexity.WebhookSubscription.EventTypes(childComplexity), true
+
+	case "WebhookSubscription.id":
+		if e.complexity.WebhookSubscription.ID == nil {
+			break
+		}
+
+		return e.complexity.WebhookSubscription.ID(childComplexity), true
+
+	case "WebhookSubscription.url":
+		if e.complexity.WebhookSubscription.URL == nil {
+			break
+		}
+
+		return e.complexity.WebhookSubscription.URL(childComplexity), true
+
+	case "WebhookSubscriptionCreateResponse.secret":
+		if e.complexity.WebhookSubscriptionCreateResponse.Secret == nil {
+			break
+		}
+
+		return e.complexity.WebhookSubscriptionCreateResponse.Secret(childComplexity), true
+
+	case "WebhookSubscriptionCreateResponse.subscription":
+		if e.complexity.WebhookSubscriptionCreateResponse.Subscription == nil {
+			break
+		}
+
+		return e.complexity.WebhookSubscriptionCreateResponse.Subscription(childComplexity), true
+
+	case "WebhookSubscriptionDeleteResponse.subscriptionId":
+		if e.complexity.WebhookSubscriptionDeleteResponse.SubscriptionID == nil {
+			break
+		}
+
+		return e.complexity.WebhookSubscriptionDeleteResponse.SubscriptionID(childComplexity), true
+
+	}
+	return 0, false
+}
+
+func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
+	rc := graphql.GetOperationContext(ctx)
+	ec := executionContext{rc, e}
+	first := true
+
+	switch rc.Operation.Operation {
+	case ast.Query:
+		return func(ctx context.Context) *graphql.Response {
+			if !first {
+				return nil
+			}
+			first = false
+			data := ec._Query(ctx, rc.Operation.SelectionSet)
+			var buf bytes.Buffer
+			data.MarshalGQL(&buf)
+
+			return &graphql.Response{
+				Data: buf.Bytes(),
+			}
+		}
+	case ast.Mutation:
+		return func(ctx context.Context) *graphql.Response {
+			if !first {
+				return nil
+			}
+			first = false
+			data := ec._Mutation(ctx, rc.Operation.SelectionSet)
+			var buf bytes.Buffer
+			data.MarshalGQL(&buf)
+
+			return &graphql.Response{
+				Data: buf.Bytes(),
+			}
+		}
+
+	default:
+		return graphql.OneShot(graphql.ErrorResponse(ctx, "unsupported GraphQL operation"))
+	}
+}
+
+type executionContext struct {
+	*graphql.OperationContext
+	*executableSchema
+}
+
+func (ec *executionContext) introspectSchema() (*introspection.Schema, error) {
+	if ec.DisableIntrospection {
+		return nil, errors.New("introspection disabled")
+	}
+	return introspection.WrapSchema(parsedSchema), nil
+}
+
+func (ec *executionContext) introspectType(name string) (*introspection.Type, error) {
+	if ec.DisableIntrospection {
+		return nil, errors.New("introspection disabled")
+	}
+	return introspection.WrapTypeFromDef(parsedSchema, parsedSchema.Types[name]), nil
+}
+
+var sources = []*ast.Source{
+	&ast.Source{Name: "graph/schema.graphqls", Input: `# GraphQL schema example
+#
+# https://gqlgen.com/getting-started/
+
+scalar Time
+
+enum ReferralStatus {
+    Created
+    Sent
+    Completed
+    Expired
+    Cancelled
+}
+
+type Referral {
+    id: String!
+    status: ReferralStatus!
+    referredUserEmail: String!
+    referralCode: String!
+    createdAt: Time!
+    updatedAt: Time!
+}
+
+type Profile {
+    name: String!
+    # Formatted as 2006-01-02
+    birthday: String!
+    phone: String!
+}
+
+type User {
+    userId: String
+    createdAt: Time!
+    updatedAt: Time!
+    username: String!
+    email: String!
+    # Points the user can spend. Their wallet mirrors this balance
+    points: Int!
+    # Null until the wallet of the user is opened
+    wallet: Wallet
+    referralCode: String!
+    referrals: [Referral!]!
+    profile: Profile!
+    # When the profile was first completed, earning the CompleteProfile points
+    profileCompletedAt: Time
+    version: Int!
+}
+
+type Wallet {
+    walletId: String!
+    userId: String!
+    balance: Int!
+    openedAt: Time!
+    updatedAt: Time!
+    version: Int!
+}
+
+type ReadModelRebuild {
+    collection: String!
+    previousCollection: String!
+    eventsProcessed: Int!
+    totalEvents: Int!
+    inProgress: Boolean!
+    # Why the last rebuild failed, if it did
+    lastError: String
+}
+
+type WebhookSubscription {
+    id: String!
+    url: String!
+    eventTypes: [String!]!
+    createdAt: Time!
+}
+
+type WebhookDelivery {
+    id: String!
+    subscriptionId: String!
+    eventType: String!
+    aggregateId: String!
+    eventVersion: Int!
+    attempts: Int!
+    statusCode: Int!
+    succeeded: Boolean!
+    error: String!
+    deliveredAt: Time!
+}
+
+enum CommandSource {
+    GraphQL
+    Saga
+    Scheduler
+    System
+}
+
+enum CommandOutcome {
+    Succeeded
+    Rejected
+    Failed
+}
+
+type CommandAuditEntry {
+    id: String!
+    commandType: String!
+    aggregateId: String!
+    commandId: String!
+    payload: String!
+    actor: String!
+    source: CommandSource!
+    outcome: CommandOutcome!
+    error: String!
+    durationMs: Int!
+    issuedAt: Time!
+}
+
+type CommandRegistration {
+    commandType: String!
+    handler: String!
+}
+
+enum SagaStatus {
+    Running
+    Completed
+    Compensated
+    Failed
+}
+
+type SagaInstance {
+    id: String!
+    type: String!
+    correlationId: String!
+    status: SagaStatus!
+    completedSteps: [String!]!
+    compensatedSteps: [String!]!
+    attempts: Int!
+    lastError: String!
+    startedAt: Time!
+    updatedAt: Time!
+}
+
+enum PointsAction {
+    ReferUser
+    SignUpWithReferral
+    SignUpWithoutReferral
+    CompleteProfile
+}
+
+type PointsRule {
+    action: PointsAction!
+    points: Int!
+    version: Int!
+    updatedAt: Time!
+    updatedBy: String!
+}
+
+type Query {
+    users: [User!]!
+    # Null until the wallet of the user is opened
+    wallet(userId: String!): Wallet
+    # Restricted to admins
+    userReadModelRebuildStatus: ReadModelRebuild!
+    # Restricted to admins
+    webhookSubscriptions: [WebhookSubscription!]!
+    # Restricted to admins
+    webhookDeliveries(subscriptionId: String!): [WebhookDelivery!]!
+    # Restricted to admins. Returns the most recent commands first, with
+    # personal data redacted. At least one of aggregateId and actor is
+    # required
+    commandAuditLog(
+        aggregateId: String
+        actor: String
+        limit: Int
+    ): [CommandAuditEntry!]!
+    # Restricted to admins
+    registeredCommands: [CommandRegistration!]!
+    # Restricted to admins. Returns the least recently updated instances
+    # first. Defaults to the stuck (Running) and Failed instances
+    sagas(statuses: [SagaStatus!]): [SagaInstance!]!
+    pointsRules: [PointsRule!]!
+}
+
+input NewUser {
+    username: String!
+}
+
+type CommandResult {
+    aggregateId: String!
+    version: Int!
+    eventTypes: [String!]!
+}
+
+type UserCreateResponse {
+    userId: String
+    username: String
+    email: String
+    result: CommandResult!
+}
+
+type UserDeleteResponse {
+    userId: String
+    result: CommandResult!
+}
+
+type UserReferralCreatedResponse {
+    userId: String
+    referredUserEmail: String
+    # Flagged referrals were rejected by the referral policy, e.g. past the
+    # daily limit, and are kept for review instead of being sent
+    flagged: Boolean!
+    flagReason: String
+    result: CommandResult!
+}
+
+type UserReferralCancelResponse {
+    userId: String!
+    referralId: String!
+    result: CommandResult!
+}
+
+type ProfileUpdateResponse {
+    userId: String!
+    result: CommandResult!
+}
+
+type PointsRedeemResponse {
+    userId: String!
+    pointsRedeemed: Int!
+    balance: Int!
+    result: CommandResult!
+}
+
+type WebhookSubscriptionCreateResponse {
+    subscription: WebhookSubscription!
+    secret: String!
+}
+
+type WebhookSubscriptionDeleteResponse {
+    subscriptionId: String
+}
+
+type Mutation {
+    # Retrying with the same idempotencyKey returns the original user.
+    # Keys are scoped to the caller, e.g. a client generated uuid
+    userCreate(
+        username: String!
+        email: String!
+        referredByCode: String
+        idempotencyKey: String
+    ): UserCreateResponse!
+    userDelete(userId: String!): UserDeleteResponse!
+    # Restricted to the user themselves and admins
+    userReferralCreate(
+        userId: String!
+        referredUserEmail: String!
+        idempotencyKey: String
+    ): UserReferralCreatedResponse
+    # Only referrals that are neither completed nor expired can be
+    # cancelled. Restricted to the user themselves and admins
+    userReferralCancel(
+        userId: String!
+        referralId: String!
+        reason: String
+        idempotencyKey: String
+    ): UserReferralCancelResponse!
+    # Fields left out are not changed and empty fields are cleared.
+    # Restricted to the user themselves and admins
+    profileUpdate(
+        userId: String!
+        name: String
+        birthday: String
+        phone: String
+        idempotencyKey: String
+    ): ProfileUpdateResponse!
+    # Restricted to the user themselves and admins
+    pointsRedeem(
+        userId: String!
+        points: Int!
+        reason: String
+        idempotencyKey: String
+    ): PointsRedeemResponse!
+    # Restricted to the actors listed in ADMIN_ACTOR_IDS. When
+    # expectedVersion is set, the update fails if the rule has changed since
+    pointsRuleUpdate(
+        action: PointsAction!
+        points: Int!
+        expectedVersion: Int
+    ): PointsRule!
+    # Restricted to admins
+    userReadModelRebuild: ReadModelRebuild!
+    # Restricted to admins
+    userReadModelRollback: ReadModelRebuild!
+    # Restricted to admins
+    webhookSubscriptionCreate(
+        url: String!
+        eventTypes: [String!]!
+        secret: String
+    ): WebhookSubscriptionCreateResponse!
+    # Restricted to admins
+    webhookSubscriptionDelete(
+        subscriptionId: String!
+    ): WebhookSubscriptionDeleteResponse!
+}
+`, BuiltIn: false},
+}
+var parsedSchema = gqlparser.MustLoadSchema(sources...)
+
+// endregion ************************** generated!.gotpl **************************
+
+// region    ***************************** args.gotpl *****************************
+
+func (ec *executionContext) field_Mutation_pointsRedeem_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["userId"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["userId"] = arg0
+	var arg1 int
+	if tmp, ok := rawArgs["points"]; ok {
+		arg1, err = ec.unmarshalNInt2int(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["points"] = arg1
+	var arg2 *string
+	if tmp, ok := rawArgs["reason"]; ok {
+		arg2, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["reason"] = arg2
+	var arg3 *string
+	if tmp, ok := rawArgs["idempotencyKey"]; ok {
+		arg3, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["idempotencyKey"] = arg3
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_pointsRuleUpdate_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 loyalty.PointsAction
+	if tmp, ok := rawArgs["action"]; ok {
+		arg0, err = ec.unmarshalNPointsAction2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsAction(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["action"] = arg0
+	var arg1 int
+	if tmp, ok := rawArgs["points"]; ok {
+		arg1, err = ec.unmarshalNInt2int(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["points"] = arg1
+	var arg2 *int
+	if tmp, ok := rawArgs["expectedVersion"]; ok {
+		arg2, err = ec.unmarshalOInt2ᚖint(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["expectedVersion"] = arg2
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_profileUpdate_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["userId"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["userId"] = arg0
+	var arg1 *string
+	if tmp, ok := rawArgs["name"]; ok {
+		arg1, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["name"] = arg1
+	var arg2 *string
+	if tmp, ok := rawArgs["birthday"]; ok {
+		arg2, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["birthday"] = arg2
+	var arg3 *string
+	if tmp, ok := rawArgs["phone"]; ok {
+		arg3, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["phone"] = arg3
+	var arg4 *string
+	if tmp, ok := rawArgs["idempotencyKey"]; ok {
+		arg4, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["idempotencyKey"] = arg4
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_userCreate_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["username"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["username"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["email"]; ok {
+		arg1, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["email"] = arg1
+	var arg2 *string
+	if tmp, ok := rawArgs["referredByCode"]; ok {
+		arg2, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["referredByCode"] = arg2
+	var arg3 *string
+	if tmp, ok := rawArgs["idempotencyKey"]; ok {
+		arg3, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["idempotencyKey"] = arg3
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_userDelete_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["userId"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["userId"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_userReferralCancel_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["userId"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["userId"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["referralId"]; ok {
+		arg1, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["referralId"] = arg1
+	var arg2 *string
+	if tmp, ok := rawArgs["reason"]; ok {
+		arg2, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["reason"] = arg2
+	var arg3 *string
+	if tmp, ok := rawArgs["idempotencyKey"]; ok {
+		arg3, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["idempotencyKey"] = arg3
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_userReferralCreate_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["userId"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["userId"] = arg0
+	var arg1 string
+	if tmp, ok := rawArgs["referredUserEmail"]; ok {
+		arg1, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["referredUserEmail"] = arg1
+	var arg2 *string
+	if tmp, ok := rawArgs["idempotencyKey"]; ok {
+		arg2, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["idempotencyKey"] = arg2
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_webhookSubscriptionCreate_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["url"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["url"] = arg0
+	var arg1 []string
+	if tmp, ok := rawArgs["eventTypes"]; ok {
+		arg1, err = ec.unmarshalNString2ᚕstringᚄ(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["eventTypes"] = arg1
+	var arg2 *string
+	if tmp, ok := rawArgs["secret"]; ok {
+		arg2, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["secret"] = arg2
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_webhookSubscriptionDelete_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["subscriptionId"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["subscriptionId"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Query___type_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["name"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["name"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Query_commandAuditLog_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 *string
+	if tmp, ok := rawArgs["aggregateId"]; ok {
+		arg0, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["aggregateId"] = arg0
+	var arg1 *string
+	if tmp, ok := rawArgs["actor"]; ok {
+		arg1, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["actor"] = arg1
+	var arg2 *int
+	if tmp, ok := rawArgs["limit"]; ok {
+		arg2, err = ec.unmarshalOInt2ᚖint(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["limit"] = arg2
+	return args, nil
+}
+
+func (ec *executionContext) field_Query_sagas_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 []saga.Status
+	if tmp, ok := rawArgs["statuses"]; ok {
+		arg0, err = ec.unmarshalOSagaStatus2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐStatusᚄ(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["statuses"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Query_wallet_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["userId"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["userId"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Query_webhookDeliveries_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["subscriptionId"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["subscriptionId"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field___Type_enumValues_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 bool
+	if tmp, ok := rawArgs["includeDeprecated"]; ok {
+		arg0, err = ec.unmarshalOBoolean2bool(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["includeDeprecated"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field___Type_fields_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 bool
+	if tmp, ok := rawArgs["includeDeprecated"]; ok {
+		arg0, err = ec.unmarshalOBoolean2bool(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["includeDeprecated"] = arg0
+	return args, nil
+}
+
+// endregion ***************************** args.gotpl *****************************
+
+// region    ************************** directives.gotpl **************************
+
+// endregion ************************** directives.gotpl **************************
+
+// region    **************************** field.gotpl *****************************
+
+func (ec *executionContext) _CommandAuditEntry_id(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_commandType(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CommandType, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_aggregateId(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.AggregateID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_commandId(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CommandID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_payload(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Payload, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_actor(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Actor, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_source(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Source, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(eventsource.CommandSource)
+	fc.Result = res
+	return ec.marshalNCommandSource2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandSource(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_outcome(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Outcome, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(audit.Outcome)
+	fc.Result = res
+	return ec.marshalNCommandOutcome2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋauditᚐOutcome(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_error(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Error, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_durationMs(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.DurationMs, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int64)
+	fc.Result = res
+	return ec.marshalNInt2int64(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandAuditEntry_issuedAt(ctx context.Context, field graphql.CollectedField, obj *audit.Entry) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandAuditEntry",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.IssuedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandRegistration_commandType(ctx context.Context, field graphql.CollectedField, obj *eventsource.CommandRegistration) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandRegistration",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CommandType, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandRegistration_handler(ctx context.Context, field graphql.CollectedField, obj *eventsource.CommandRegistration) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandRegistration",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Handler, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandResult_aggregateId(ctx context.Context, field graphql.CollectedField, obj *eventsource.CommandResult) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandResult",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.AggregateID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandResult_version(ctx context.Context, field graphql.CollectedField, obj *eventsource.CommandResult) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandResult",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Version, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _CommandResult_eventTypes(ctx context.Context, field graphql.CollectedField, obj *eventsource.CommandResult) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "CommandResult",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.EventTypes, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]string)
+	fc.Result = res
+	return ec.marshalNString2ᚕstringᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_userCreate(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_userCreate_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().UserCreate(rctx, args["username"].(string), args["email"].(string), args["referredByCode"].(*string), args["idempotencyKey"].(*string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.UserCreateResponse)
+	fc.Result = res
+	return ec.marshalNUserCreateResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserCreateResponse(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_userDelete(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_userDelete_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().UserDelete(rctx, args["userId"].(string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.UserDeleteResponse)
+	fc.Result = res
+	return ec.marshalNUserDeleteResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserDeleteResponse(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_userReferralCreate(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_userReferralCreate_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().UserReferralCreate(rctx, args["userId"].(string), args["referredUserEmail"].(string), args["idempotencyKey"].(*string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*model.UserReferralCreatedResponse)
+	fc.Result = res
+	return ec.marshalOUserReferralCreatedResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserReferralCreatedResponse(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_userReferralCancel(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_userReferralCancel_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().UserReferralCancel(rctx, args["userId"].(string), args["referralId"].(string), args["reason"].(*string), args["idempotencyKey"].(*string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.UserReferralCancelResponse)
+	fc.Result = res
+	return ec.marshalNUserReferralCancelResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserReferralCancelResponse(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_profileUpdate(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_profileUpdate_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().ProfileUpdate(rctx, args["userId"].(string), args["name"].(*string), args["birthday"].(*string), args["phone"].(*string), args["idempotencyKey"].(*string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.ProfileUpdateResponse)
+	fc.Result = res
+	return ec.marshalNProfileUpdateResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐProfileUpdateResponse(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_pointsRedeem(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_pointsRedeem_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().PointsRedeem(rctx, args["userId"].(string), args["points"].(int), args["reason"].(*string), args["idempotencyKey"].(*string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.PointsRedeemResponse)
+	fc.Result = res
+	return ec.marshalNPointsRedeemResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐPointsRedeemResponse(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_pointsRuleUpdate(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_pointsRuleUpdate_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().PointsRuleUpdate(rctx, args["action"].(loyalty.PointsAction), args["points"].(int), args["expectedVersion"].(*int))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*loyalty.PointsRule)
+	fc.Result = res
+	return ec.marshalNPointsRule2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsRule(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_userReadModelRebuild(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().UserReadModelRebuild(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*user.RebuildStatus)
+	fc.Result = res
+	return ec.marshalNReadModelRebuild2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐRebuildStatus(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_userReadModelRollback(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().UserReadModelRollback(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*user.RebuildStatus)
+	fc.Result = res
+	return ec.marshalNReadModelRebuild2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐRebuildStatus(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_webhookSubscriptionCreate(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_webhookSubscriptionCreate_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().WebhookSubscriptionCreate(rctx, args["url"].(string), args["eventTypes"].([]string), args["secret"].(*string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.WebhookSubscriptionCreateResponse)
+	fc.Result = res
+	return ec.marshalNWebhookSubscriptionCreateResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐWebhookSubscriptionCreateResponse(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_webhookSubscriptionDelete(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_webhookSubscriptionDelete_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().WebhookSubscriptionDelete(rctx, args["subscriptionId"].(string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.WebhookSubscriptionDeleteResponse)
+	fc.Result = res
+	return ec.marshalNWebhookSubscriptionDeleteResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐWebhookSubscriptionDeleteResponse(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PointsRedeemResponse_userId(ctx context.Context, field graphql.CollectedField, obj *model.PointsRedeemResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PointsRedeemResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UserID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PointsRedeemResponse_pointsRedeemed(ctx context.Context, field graphql.CollectedField, obj *model.PointsRedeemResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PointsRedeemResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.PointsRedeemed, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PointsRedeemResponse_balance(ctx context.Context, field graphql.CollectedField, obj *model.PointsRedeemResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PointsRedeemResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Balance, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PointsRedeemResponse_result(ctx context.Context, field graphql.CollectedField, obj *model.PointsRedeemResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PointsRedeemResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Result, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*eventsource.CommandResult)
+	fc.Result = res
+	return ec.marshalNCommandResult2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandResult(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PointsRule_action(ctx context.Context, field graphql.CollectedField, obj *loyalty.PointsRule) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PointsRule",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Action, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(loyalty.PointsAction)
+	fc.Result = res
+	return ec.marshalNPointsAction2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsAction(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PointsRule_points(ctx context.Context, field graphql.CollectedField, obj *loyalty.PointsRule) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PointsRule",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.PointsRule().Points(rctx, obj)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PointsRule_version(ctx context.Context, field graphql.CollectedField, obj *loyalty.PointsRule) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PointsRule",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Version, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PointsRule_updatedAt(ctx context.Context, field graphql.CollectedField, obj *loyalty.PointsRule) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PointsRule",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UpdatedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PointsRule_updatedBy(ctx context.Context, field graphql.CollectedField, obj *loyalty.PointsRule) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PointsRule",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UpdatedBy, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Profile_name(ctx context.Context, field graphql.CollectedField, obj *user.Profile) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Profile",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Name, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Profile_birthday(ctx context.Context, field graphql.CollectedField, obj *user.Profile) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Profile",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Birthday, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Profile_phone(ctx context.Context, field graphql.CollectedField, obj *user.Profile) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Profile",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Phone, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _ProfileUpdateResponse_userId(ctx context.Context, field graphql.CollectedField, obj *model.ProfileUpdateResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "ProfileUpdateResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UserID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _ProfileUpdateResponse_result(ctx context.Context, field graphql.CollectedField, obj *model.ProfileUpdateResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "ProfileUpdateResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Result, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*eventsource.CommandResult)
+	fc.Result = res
+	return ec.marshalNCommandResult2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandResult(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_users(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().Users(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]user.DTO)
+	fc.Result = res
+	return ec.marshalNUser2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐDTOᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_wallet(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Query_wallet_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().Wallet(rctx, args["userId"].(string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*wallet.DTO)
+	fc.Result = res
+	return ec.marshalOWallet2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwalletᚐDTO(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_userReadModelRebuildStatus(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().UserReadModelRebuildStatus(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*user.RebuildStatus)
+	fc.Result = res
+	return ec.marshalNReadModelRebuild2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐRebuildStatus(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_webhookSubscriptions(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().WebhookSubscriptions(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]webhook.Subscription)
+	fc.Result = res
+	return ec.marshalNWebhookSubscription2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐSubscriptionᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_webhookDeliveries(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Query_webhookDeliveries_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().WebhookDeliveries(rctx, args["subscriptionId"].(string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]webhook.Delivery)
+	fc.Result = res
+	return ec.marshalNWebhookDelivery2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐDeliveryᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_commandAuditLog(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Query_commandAuditLog_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().CommandAuditLog(rctx, args["aggregateId"].(*string), args["actor"].(*string), args["limit"].(*int))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]audit.Entry)
+	fc.Result = res
+	return ec.marshalNCommandAuditEntry2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋauditᚐEntryᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_registeredCommands(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().RegisteredCommands(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]eventsource.CommandRegistration)
+	fc.Result = res
+	return ec.marshalNCommandRegistration2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandRegistrationᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_sagas(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Query_sagas_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().Sagas(rctx, args["statuses"].([]saga.Status))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]saga.Instance)
+	fc.Result = res
+	return ec.marshalNSagaInstance2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐInstanceᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_pointsRules(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().PointsRules(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]loyalty.PointsRule)
+	fc.Result = res
+	return ec.marshalNPointsRule2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsRuleᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query___type(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Query___type_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.introspectType(args["name"].(string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*introspection.Type)
+	fc.Result = res
+	return ec.marshalO__Type2ᚖgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐType(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query___schema(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.introspectSchema()
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*introspection.Schema)
+	fc.Result = res
+	return ec.marshalO__Schema2ᚖgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐSchema(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _ReadModelRebuild_collection(ctx context.Context, field graphql.CollectedField, obj *user.RebuildStatus) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "ReadModelRebuild",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Collection, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _ReadModelRebuild_previousCollection(ctx context.Context, field graphql.CollectedField, obj *user.RebuildStatus) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "ReadModelRebuild",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.PreviousCollection, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _ReadModelRebuild_eventsProcessed(ctx context.Context, field graphql.CollectedField, obj *user.RebuildStatus) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "ReadModelRebuild",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.EventsProcessed, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _ReadModelRebuild_totalEvents(ctx context.Context, field graphql.CollectedField, obj *user.RebuildStatus) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "ReadModelRebuild",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.TotalEvents, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _ReadModelRebuild_inProgress(ctx context.Context, field graphql.CollectedField, obj *user.RebuildStatus) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "ReadModelRebuild",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.InProgress, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _ReadModelRebuild_lastError(ctx context.Context, field graphql.CollectedField, obj *user.RebuildStatus) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "ReadModelRebuild",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.LastError, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Referral_id(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Referral",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Referral_status(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Referral",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Status, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(user.ReferralStatus)
+	fc.Result = res
+	return ec.marshalNReferralStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralStatus(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Referral_referredUserEmail(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Referral",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ReferredUserEmail, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Referral_referralCode(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Referral",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ReferralCode, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Referral_createdAt(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Referral",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CreatedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Referral_updatedAt(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Referral",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UpdatedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_id(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_type(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Type, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_correlationId(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CorrelationID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_status(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Status, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(saga.Status)
+	fc.Result = res
+	return ec.marshalNSagaStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐStatus(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_completedSteps(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CompletedSteps, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]string)
+	fc.Result = res
+	return ec.marshalNString2ᚕstringᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_compensatedSteps(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CompensatedSteps, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]string)
+	fc.Result = res
+	return ec.marshalNString2ᚕstringᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_attempts(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Attempts, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_lastError(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.LastError, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_startedAt(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.StartedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SagaInstance_updatedAt(ctx context.Context, field graphql.CollectedField, obj *saga.Instance) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SagaInstance",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UpdatedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_userId(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UserID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalOString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_createdAt(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.CreatedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_updatedAt(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UpdatedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_username(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Username, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_email(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Email, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_points(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.User().Points(rctx, obj)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_wallet(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.User().Wallet(rctx, obj)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*wallet.DTO)
+	fc.Result = res
+	return ec.marshalOWallet2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwalletᚐDTO(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_referralCode(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ReferralCode, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_referrals(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.User().Referrals(rctx, obj)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]user.Referral)
+	fc.Result = res
+	return ec.marshalNReferral2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_profile(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Profile, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(user.Profile)
+	fc.Result = res
+	return ec.marshalNProfile2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐProfile(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_profileCompletedAt(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ProfileCompletedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*time.Time)
+	fc.Result = res
+	return ec.marshalOTime2ᚖtimeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _User_version(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "User",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Version, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _UserCreateResponse_userId(ctx context.Context, field graphql.CollectedField, obj *model.UserCreateResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "UserCreateResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UserID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _UserCreateResponse_username(ctx context.Context, field graphql.CollectedField, obj *model.UserCreateResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "UserCreateResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Username, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _UserCreateResponse_email(ctx context.Context, field graphql.CollectedField, obj *model.UserCreateResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "UserCreateResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Email, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _UserCreateResponse_result(ctx context.Context, field graphql.CollectedField, obj *model.UserCreateResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "UserCreateResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Result, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*eventsource.CommandResult)
+	fc.Result = res
+	return ec.marshalNCommandResult2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandResult(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _UserDeleteResponse_userId(ctx context.Context, field graphql.CollectedField, obj *model.UserDeleteResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "UserDeleteResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UserID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _UserDeleteResponse_result(ctx context.Context, field graphql.CollectedField, obj *model.UserDeleteResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "UserDeleteResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Result, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*eventsource.CommandResult)
+	fc.Result = res
+	return ec.marshalNCommandResult2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandResult(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _UserReferralCancelResponse_userId(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCancelResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "UserReferralCancelResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UserID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _UserReferralCancelResponse_referralId(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCancelResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "UserReferralCancelResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().UserCreate(rctx, args["username"].(string), args["email"].(string), args["referredByCode"].(*string))
+		return obj.ReferralID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -604,12 +5232,12 @@ func (ec *executionContext) _Mutation_userCreate(ctx context.Context, field grap
 		}
 		return graphql.Null
 	}
-	res := resTmp.(*model.UserCreateResponse)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNUserCreateResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserCreateResponse(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Mutation_userDelete(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _UserReferralCancelResponse_result(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCancelResponse) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -617,23 +5245,16 @@ func (ec *executionContext) _Mutation_userDelete(ctx context.Context, field grap
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Mutation",
+		Object:   "UserReferralCancelResponse",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field_Mutation_userDelete_args(ctx, rawArgs)
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().UserDelete(rctx, args["userId"].(string))
+		return obj.Result, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -645,12 +5266,12 @@ func (ec *executionContext) _Mutation_userDelete(ctx context.Context, field grap
 		}
 		return graphql.Null
 	}
-	res := resTmp.(*model.UserDeleteResponse)
+	res := resTmp.(*eventsource.CommandResult)
 	fc.Result = res
-	return ec.marshalNUserDeleteResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserDeleteResponse(ctx, field.Selections, res)
+	return ec.marshalNCommandResult2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandResult(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Mutation_userReferralCreate(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _UserReferralCreatedResponse_userId(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCreatedResponse) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -658,23 +5279,47 @@ func (ec *executionContext) _Mutation_userReferralCreate(ctx context.Context, fi
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Mutation",
+		Object:   "UserReferralCreatedResponse",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field_Mutation_userReferralCreate_args(ctx, rawArgs)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.UserID, nil
+	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
-	fc.Args = args
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _UserReferralCreatedResponse_referredUserEmail(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCreatedResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "UserReferralCreatedResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().UserReferralCreate(rctx, args["userId"].(string), args["referredUserEmail"].(string))
+		return obj.ReferredUserEmail, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -683,12 +5328,12 @@ func (ec *executionContext) _Mutation_userReferralCreate(ctx context.Context, fi
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*model.UserReferralCreatedResponse)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalOUserReferralCreatedResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserReferralCreatedResponse(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Query_users(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _UserReferralCreatedResponse_flagged(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCreatedResponse) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -696,16 +5341,16 @@ func (ec *executionContext) _Query_users(ctx context.Context, field graphql.Coll
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Query",
+		Object:   "UserReferralCreatedResponse",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Query().Users(rctx)
+		return obj.Flagged, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -717,12 +5362,12 @@ func (ec *executionContext) _Query_users(ctx context.Context, field graphql.Coll
 		}
 		return graphql.Null
 	}
-	res := resTmp.([]user.DTO)
+	res := resTmp.(bool)
 	fc.Result = res
-	return ec.marshalNUser2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐDTOᚄ(ctx, field.Selections, res)
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Query___type(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _UserReferralCreatedResponse_flagReason(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCreatedResponse) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -730,23 +5375,16 @@ func (ec *executionContext) _Query___type(ctx context.Context, field graphql.Col
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Query",
+		Object:   "UserReferralCreatedResponse",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field_Query___type_args(ctx, rawArgs)
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.introspectType(args["name"].(string))
+		return obj.FlagReason, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -755,12 +5393,12 @@ func (ec *executionContext) _Query___type(ctx context.Context, field graphql.Col
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*introspection.Type)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalO__Type2ᚖgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐType(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Query___schema(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _UserReferralCreatedResponse_result(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCreatedResponse) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -768,30 +5406,33 @@ func (ec *executionContext) _Query___schema(ctx context.Context, field graphql.C
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Query",
+		Object:   "UserReferralCreatedResponse",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.introspectSchema()
+		return obj.Result, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*introspection.Schema)
+	res := resTmp.(*eventsource.CommandResult)
 	fc.Result = res
-	return ec.marshalO__Schema2ᚖgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐSchema(ctx, field.Selections, res)
+	return ec.marshalNCommandResult2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandResult(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Referral_id(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+func (ec *executionContext) _Wallet_walletId(ctx context.Context, field graphql.CollectedField, obj *wallet.DTO) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -799,7 +5440,7 @@ func (ec *executionContext) _Referral_id(ctx context.Context, field graphql.Coll
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Referral",
+		Object:   "Wallet",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -808,7 +5449,7 @@ func (ec *executionContext) _Referral_id(ctx context.Context, field graphql.Coll
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ID, nil
+		return obj.WalletID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -825,7 +5466,7 @@ func (ec *executionContext) _Referral_id(ctx context.Context, field graphql.Coll
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Referral_status(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+func (ec *executionContext) _Wallet_userId(ctx context.Context, field graphql.CollectedField, obj *wallet.DTO) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -833,7 +5474,7 @@ func (ec *executionContext) _Referral_status(ctx context.Context, field graphql.
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Referral",
+		Object:   "Wallet",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -842,7 +5483,7 @@ func (ec *executionContext) _Referral_status(ctx context.Context, field graphql.
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Status, nil
+		return obj.UserID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -854,12 +5495,12 @@ func (ec *executionContext) _Referral_status(ctx context.Context, field graphql.
 		}
 		return graphql.Null
 	}
-	res := resTmp.(user.ReferralStatus)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNReferralStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralStatus(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Referral_referredUserEmail(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+func (ec *executionContext) _Wallet_balance(ctx context.Context, field graphql.CollectedField, obj *wallet.DTO) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -867,16 +5508,16 @@ func (ec *executionContext) _Referral_referredUserEmail(ctx context.Context, fie
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Referral",
+		Object:   "Wallet",
 		Field:    field,
 		Args:     nil,
-		IsMethod: false,
+		IsMethod: true,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ReferredUserEmail, nil
+		return ec.resolvers.Wallet().Balance(rctx, obj)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -888,12 +5529,12 @@ func (ec *executionContext) _Referral_referredUserEmail(ctx context.Context, fie
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Referral_referralCode(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+func (ec *executionContext) _Wallet_openedAt(ctx context.Context, field graphql.CollectedField, obj *wallet.DTO) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -901,7 +5542,7 @@ func (ec *executionContext) _Referral_referralCode(ctx context.Context, field gr
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Referral",
+		Object:   "Wallet",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -910,7 +5551,7 @@ func (ec *executionContext) _Referral_referralCode(ctx context.Context, field gr
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ReferralCode, nil
+		return obj.OpenedAt, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -922,12 +5563,12 @@ func (ec *executionContext) _Referral_referralCode(ctx context.Context, field gr
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(time.Time)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Referral_createdAt(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+func (ec *executionContext) _Wallet_updatedAt(ctx context.Context, field graphql.CollectedField, obj *wallet.DTO) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -935,7 +5576,7 @@ func (ec *executionContext) _Referral_createdAt(ctx context.Context, field graph
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Referral",
+		Object:   "Wallet",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -944,7 +5585,7 @@ func (ec *executionContext) _Referral_createdAt(ctx context.Context, field graph
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.CreatedAt, nil
+		return obj.UpdatedAt, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -961,7 +5602,7 @@ func (ec *executionContext) _Referral_createdAt(ctx context.Context, field graph
 	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Referral_updatedAt(ctx context.Context, field graphql.CollectedField, obj *user.Referral) (ret graphql.Marshaler) {
+func (ec *executionContext) _Wallet_version(ctx context.Context, field graphql.CollectedField, obj *wallet.DTO) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -969,7 +5610,7 @@ func (ec *executionContext) _Referral_updatedAt(ctx context.Context, field graph
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Referral",
+		Object:   "Wallet",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -978,7 +5619,7 @@ func (ec *executionContext) _Referral_updatedAt(ctx context.Context, field graph
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.UpdatedAt, nil
+		return obj.Version, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -990,12 +5631,12 @@ func (ec *executionContext) _Referral_updatedAt(ctx context.Context, field graph
 		}
 		return graphql.Null
 	}
-	res := resTmp.(time.Time)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_userId(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookDelivery_id(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1003,7 +5644,7 @@ func (ec *executionContext) _User_userId(ctx context.Context, field graphql.Coll
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "User",
+		Object:   "WebhookDelivery",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1012,21 +5653,24 @@ func (ec *executionContext) _User_userId(ctx context.Context, field graphql.Coll
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.UserID, nil
+		return obj.ID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
 	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOString2string(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_createdAt(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookDelivery_subscriptionId(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1034,7 +5678,7 @@ func (ec *executionContext) _User_createdAt(ctx context.Context, field graphql.C
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "User",
+		Object:   "WebhookDelivery",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1043,7 +5687,7 @@ func (ec *executionContext) _User_createdAt(ctx context.Context, field graphql.C
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.CreatedAt, nil
+		return obj.SubscriptionID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1055,12 +5699,12 @@ func (ec *executionContext) _User_createdAt(ctx context.Context, field graphql.C
 		}
 		return graphql.Null
 	}
-	res := resTmp.(time.Time)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_updatedAt(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookDelivery_eventType(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1068,7 +5712,7 @@ func (ec *executionContext) _User_updatedAt(ctx context.Context, field graphql.C
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "User",
+		Object:   "WebhookDelivery",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1077,7 +5721,7 @@ func (ec *executionContext) _User_updatedAt(ctx context.Context, field graphql.C
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.UpdatedAt, nil
+		return obj.EventType, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1089,12 +5733,12 @@ func (ec *executionContext) _User_updatedAt(ctx context.Context, field graphql.C
 		}
 		return graphql.Null
 	}
-	res := resTmp.(time.Time)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_username(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookDelivery_aggregateId(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1102,7 +5746,7 @@ func (ec *executionContext) _User_username(ctx context.Context, field graphql.Co
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "User",
+		Object:   "WebhookDelivery",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1111,7 +5755,7 @@ func (ec *executionContext) _User_username(ctx context.Context, field graphql.Co
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Username, nil
+		return obj.AggregateID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1128,7 +5772,7 @@ func (ec *executionContext) _User_username(ctx context.Context, field graphql.Co
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_email(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookDelivery_eventVersion(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1136,7 +5780,7 @@ func (ec *executionContext) _User_email(ctx context.Context, field graphql.Colle
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "User",
+		Object:   "WebhookDelivery",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1145,7 +5789,7 @@ func (ec *executionContext) _User_email(ctx context.Context, field graphql.Colle
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Email, nil
+		return obj.EventVersion, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1157,12 +5801,12 @@ func (ec *executionContext) _User_email(ctx context.Context, field graphql.Colle
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_points(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookDelivery_attempts(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1170,16 +5814,16 @@ func (ec *executionContext) _User_points(ctx context.Context, field graphql.Coll
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "User",
+		Object:   "WebhookDelivery",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.User().Points(rctx, obj)
+		return obj.Attempts, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1196,7 +5840,7 @@ func (ec *executionContext) _User_points(ctx context.Context, field graphql.Coll
 	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_referralCode(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookDelivery_statusCode(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1204,7 +5848,7 @@ func (ec *executionContext) _User_referralCode(ctx context.Context, field graphq
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "User",
+		Object:   "WebhookDelivery",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1213,7 +5857,75 @@ func (ec *executionContext) _User_referralCode(ctx context.Context, field graphq
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ReferralCode, nil
+		return obj.StatusCode, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _WebhookDelivery_succeeded(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "WebhookDelivery",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Succeeded, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _WebhookDelivery_error(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "WebhookDelivery",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Error, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1230,7 +5942,7 @@ func (ec *executionContext) _User_referralCode(ctx context.Context, field graphq
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_referrals(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookDelivery_deliveredAt(ctx context.Context, field graphql.CollectedField, obj *webhook.Delivery) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1238,16 +5950,16 @@ func (ec *executionContext) _User_referrals(ctx context.Context, field graphql.C
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "User",
+		Object:   "WebhookDelivery",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.User().Referrals(rctx, obj)
+		return obj.DeliveredAt, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1259,12 +5971,12 @@ func (ec *executionContext) _User_referrals(ctx context.Context, field graphql.C
 		}
 		return graphql.Null
 	}
-	res := resTmp.([]user.Referral)
+	res := resTmp.(time.Time)
 	fc.Result = res
-	return ec.marshalNReferral2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralᚄ(ctx, field.Selections, res)
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_version(ctx context.Context, field graphql.CollectedField, obj *user.DTO) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookSubscription_id(ctx context.Context, field graphql.CollectedField, obj *webhook.Subscription) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1272,7 +5984,7 @@ func (ec *executionContext) _User_version(ctx context.Context, field graphql.Col
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "User",
+		Object:   "WebhookSubscription",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1281,7 +5993,7 @@ func (ec *executionContext) _User_version(ctx context.Context, field graphql.Col
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Version, nil
+		return obj.ID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1293,12 +6005,12 @@ func (ec *executionContext) _User_version(ctx context.Context, field graphql.Col
 		}
 		return graphql.Null
 	}
-	res := resTmp.(int)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNInt2int(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _UserCreateResponse_userId(ctx context.Context, field graphql.CollectedField, obj *model.UserCreateResponse) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookSubscription_url(ctx context.Context, field graphql.CollectedField, obj *webhook.Subscription) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1306,7 +6018,7 @@ func (ec *executionContext) _UserCreateResponse_userId(ctx context.Context, fiel
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "UserCreateResponse",
+		Object:   "WebhookSubscription",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1315,21 +6027,24 @@ func (ec *executionContext) _UserCreateResponse_userId(ctx context.Context, fiel
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.UserID, nil
+		return obj.URL, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _UserCreateResponse_username(ctx context.Context, field graphql.CollectedField, obj *model.UserCreateResponse) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookSubscription_eventTypes(ctx context.Context, field graphql.CollectedField, obj *webhook.Subscription) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1337,7 +6052,7 @@ func (ec *executionContext) _UserCreateResponse_username(ctx context.Context, fi
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "UserCreateResponse",
+		Object:   "WebhookSubscription",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1346,21 +6061,24 @@ func (ec *executionContext) _UserCreateResponse_username(ctx context.Context, fi
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Username, nil
+		return obj.EventTypes, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.([]string)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNString2ᚕstringᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _UserCreateResponse_email(ctx context.Context, field graphql.CollectedField, obj *model.UserCreateResponse) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookSubscription_createdAt(ctx context.Context, field graphql.CollectedField, obj *webhook.Subscription) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1368,7 +6086,7 @@ func (ec *executionContext) _UserCreateResponse_email(ctx context.Context, field
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "UserCreateResponse",
+		Object:   "WebhookSubscription",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1377,21 +6095,24 @@ func (ec *executionContext) _UserCreateResponse_email(ctx context.Context, field
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Email, nil
+		return obj.CreatedAt, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(time.Time)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _UserDeleteResponse_userId(ctx context.Context, field graphql.CollectedField, obj *model.UserDeleteResponse) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookSubscriptionCreateResponse_subscription(ctx context.Context, field graphql.CollectedField, obj *model.WebhookSubscriptionCreateResponse) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1399,7 +6120,7 @@ func (ec *executionContext) _UserDeleteResponse_userId(ctx context.Context, fiel
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "UserDeleteResponse",
+		Object:   "WebhookSubscriptionCreateResponse",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1408,21 +6129,24 @@ func (ec *executionContext) _UserDeleteResponse_userId(ctx context.Context, fiel
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.UserID, nil
+		return obj.Subscription, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(*webhook.Subscription)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNWebhookSubscription2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐSubscription(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _UserReferralCreatedResponse_userId(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCreatedResponse) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookSubscriptionCreateResponse_secret(ctx context.Context, field graphql.CollectedField, obj *model.WebhookSubscriptionCreateResponse) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1430,7 +6154,7 @@ func (ec *executionContext) _UserReferralCreatedResponse_userId(ctx context.Cont
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "UserReferralCreatedResponse",
+		Object:   "WebhookSubscriptionCreateResponse",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1439,21 +6163,24 @@ func (ec *executionContext) _UserReferralCreatedResponse_userId(ctx context.Cont
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.UserID, nil
+		return obj.Secret, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _UserReferralCreatedResponse_referredUserEmail(ctx context.Context, field graphql.CollectedField, obj *model.UserReferralCreatedResponse) (ret graphql.Marshaler) {
+func (ec *executionContext) _WebhookSubscriptionDeleteResponse_subscriptionId(ctx context.Context, field graphql.CollectedField, obj *model.WebhookSubscriptionDeleteResponse) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1461,7 +6188,7 @@ func (ec *executionContext) _UserReferralCreatedResponse_referredUserEmail(ctx c
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "UserReferralCreatedResponse",
+		Object:   "WebhookSubscriptionDeleteResponse",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -1470,7 +6197,7 @@ func (ec *executionContext) _UserReferralCreatedResponse_referredUserEmail(ctx c
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ReferredUserEmail, nil
+		return obj.SubscriptionID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2396,202 +7123,555 @@ func (ec *executionContext) ___Type_interfaces(ctx context.Context, field graphq
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
-	if resTmp == nil {
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]introspection.Type)
+	fc.Result = res
+	return ec.marshalO__Type2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐTypeᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) ___Type_possibleTypes(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "__Type",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.PossibleTypes(), nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]introspection.Type)
+	fc.Result = res
+	return ec.marshalO__Type2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐTypeᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) ___Type_enumValues(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "__Type",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field___Type_enumValues_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.EnumValues(args["includeDeprecated"].(bool)), nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]introspection.EnumValue)
+	fc.Result = res
+	return ec.marshalO__EnumValue2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐEnumValueᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) ___Type_inputFields(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "__Type",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.InputFields(), nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]introspection.InputValue)
+	fc.Result = res
+	return ec.marshalO__InputValue2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐInputValueᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) ___Type_ofType(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "__Type",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.OfType(), nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*introspection.Type)
+	fc.Result = res
+	return ec.marshalO__Type2ᚖgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐType(ctx, field.Selections, res)
+}
+
+// endregion **************************** field.gotpl *****************************
+
+// region    **************************** input.gotpl *****************************
+
+func (ec *executionContext) unmarshalInputNewUser(ctx context.Context, obj interface{}) (model.NewUser, error) {
+	var it model.NewUser
+	var asMap = obj.(map[string]interface{})
+
+	for k, v := range asMap {
+		switch k {
+		case "username":
+			var err error
+			it.Username, err = ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		}
+	}
+
+	return it, nil
+}
+
+// endregion **************************** input.gotpl *****************************
+
+// region    ************************** interface.gotpl ***************************
+
+// endregion ************************** interface.gotpl ***************************
+
+// region    **************************** object.gotpl ****************************
+
+var commandAuditEntryImplementors = []string{"CommandAuditEntry"}
+
+func (ec *executionContext) _CommandAuditEntry(ctx context.Context, sel ast.SelectionSet, obj *audit.Entry) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, commandAuditEntryImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("CommandAuditEntry")
+		case "id":
+			out.Values[i] = ec._CommandAuditEntry_id(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "commandType":
+			out.Values[i] = ec._CommandAuditEntry_commandType(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "aggregateId":
+			out.Values[i] = ec._CommandAuditEntry_aggregateId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "commandId":
+			out.Values[i] = ec._CommandAuditEntry_commandId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "payload":
+			out.Values[i] = ec._CommandAuditEntry_payload(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "actor":
+			out.Values[i] = ec._CommandAuditEntry_actor(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "source":
+			out.Values[i] = ec._CommandAuditEntry_source(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "outcome":
+			out.Values[i] = ec._CommandAuditEntry_outcome(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "error":
+			out.Values[i] = ec._CommandAuditEntry_error(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "durationMs":
+			out.Values[i] = ec._CommandAuditEntry_durationMs(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "issuedAt":
+			out.Values[i] = ec._CommandAuditEntry_issuedAt(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
 		return graphql.Null
 	}
-	res := resTmp.([]introspection.Type)
-	fc.Result = res
-	return ec.marshalO__Type2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐTypeᚄ(ctx, field.Selections, res)
+	return out
 }
 
-func (ec *executionContext) ___Type_possibleTypes(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
-	defer func() {
-		if r := recover(); r != nil {
-			ec.Error(ctx, ec.Recover(ctx, r))
-			ret = graphql.Null
-		}
-	}()
-	fc := &graphql.FieldContext{
-		Object:   "__Type",
-		Field:    field,
-		Args:     nil,
-		IsMethod: true,
-	}
+var commandRegistrationImplementors = []string{"CommandRegistration"}
 
-	ctx = graphql.WithFieldContext(ctx, fc)
-	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return obj.PossibleTypes(), nil
-	})
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
+func (ec *executionContext) _CommandRegistration(ctx context.Context, sel ast.SelectionSet, obj *eventsource.CommandRegistration) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, commandRegistrationImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("CommandRegistration")
+		case "commandType":
+			out.Values[i] = ec._CommandRegistration_commandType(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "handler":
+			out.Values[i] = ec._CommandRegistration_handler(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
 	}
-	if resTmp == nil {
+	out.Dispatch()
+	if invalids > 0 {
 		return graphql.Null
 	}
-	res := resTmp.([]introspection.Type)
-	fc.Result = res
-	return ec.marshalO__Type2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐTypeᚄ(ctx, field.Selections, res)
+	return out
 }
 
-func (ec *executionContext) ___Type_enumValues(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
-	defer func() {
-		if r := recover(); r != nil {
-			ec.Error(ctx, ec.Recover(ctx, r))
-			ret = graphql.Null
+var commandResultImplementors = []string{"CommandResult"}
+
+func (ec *executionContext) _CommandResult(ctx context.Context, sel ast.SelectionSet, obj *eventsource.CommandResult) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, commandResultImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("CommandResult")
+		case "aggregateId":
+			out.Values[i] = ec._CommandResult_aggregateId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "version":
+			out.Values[i] = ec._CommandResult_version(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "eventTypes":
+			out.Values[i] = ec._CommandResult_eventTypes(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
 		}
-	}()
-	fc := &graphql.FieldContext{
-		Object:   "__Type",
-		Field:    field,
-		Args:     nil,
-		IsMethod: true,
 	}
-
-	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field___Type_enumValues_args(ctx, rawArgs)
-	if err != nil {
-		ec.Error(ctx, err)
+	out.Dispatch()
+	if invalids > 0 {
 		return graphql.Null
 	}
-	fc.Args = args
-	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return obj.EnumValues(args["includeDeprecated"].(bool)), nil
+	return out
+}
+
+var mutationImplementors = []string{"Mutation"}
+
+func (ec *executionContext) _Mutation(ctx context.Context, sel ast.SelectionSet) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, mutationImplementors)
+
+	ctx = graphql.WithFieldContext(ctx, &graphql.FieldContext{
+		Object: "Mutation",
 	})
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Mutation")
+		case "userCreate":
+			out.Values[i] = ec._Mutation_userCreate(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "userDelete":
+			out.Values[i] = ec._Mutation_userDelete(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "userReferralCreate":
+			out.Values[i] = ec._Mutation_userReferralCreate(ctx, field)
+		case "userReferralCancel":
+			out.Values[i] = ec._Mutation_userReferralCancel(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "profileUpdate":
+			out.Values[i] = ec._Mutation_profileUpdate(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "pointsRedeem":
+			out.Values[i] = ec._Mutation_pointsRedeem(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "pointsRuleUpdate":
+			out.Values[i] = ec._Mutation_pointsRuleUpdate(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "userReadModelRebuild":
+			out.Values[i] = ec._Mutation_userReadModelRebuild(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "userReadModelRollback":
+			out.Values[i] = ec._Mutation_userReadModelRollback(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "webhookSubscriptionCreate":
+			out.Values[i] = ec._Mutation_webhookSubscriptionCreate(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "webhookSubscriptionDelete":
+			out.Values[i] = ec._Mutation_webhookSubscriptionDelete(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
 	}
-	if resTmp == nil {
+	out.Dispatch()
+	if invalids > 0 {
 		return graphql.Null
 	}
-	res := resTmp.([]introspection.EnumValue)
-	fc.Result = res
-	return ec.marshalO__EnumValue2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐEnumValueᚄ(ctx, field.Selections, res)
+	return out
 }
 
-func (ec *executionContext) ___Type_inputFields(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
-	defer func() {
-		if r := recover(); r != nil {
-			ec.Error(ctx, ec.Recover(ctx, r))
-			ret = graphql.Null
-		}
-	}()
-	fc := &graphql.FieldContext{
-		Object:   "__Type",
-		Field:    field,
-		Args:     nil,
-		IsMethod: true,
-	}
+var pointsRedeemResponseImplementors = []string{"PointsRedeemResponse"}
 
-	ctx = graphql.WithFieldContext(ctx, fc)
-	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return obj.InputFields(), nil
-	})
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
+func (ec *executionContext) _PointsRedeemResponse(ctx context.Context, sel ast.SelectionSet, obj *model.PointsRedeemResponse) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, pointsRedeemResponseImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("PointsRedeemResponse")
+		case "userId":
+			out.Values[i] = ec._PointsRedeemResponse_userId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "pointsRedeemed":
+			out.Values[i] = ec._PointsRedeemResponse_pointsRedeemed(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "balance":
+			out.Values[i] = ec._PointsRedeemResponse_balance(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "result":
+			out.Values[i] = ec._PointsRedeemResponse_result(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
 	}
-	if resTmp == nil {
+	out.Dispatch()
+	if invalids > 0 {
 		return graphql.Null
 	}
-	res := resTmp.([]introspection.InputValue)
-	fc.Result = res
-	return ec.marshalO__InputValue2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐInputValueᚄ(ctx, field.Selections, res)
+	return out
 }
 
-func (ec *executionContext) ___Type_ofType(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
-	defer func() {
-		if r := recover(); r != nil {
-			ec.Error(ctx, ec.Recover(ctx, r))
-			ret = graphql.Null
+var pointsRuleImplementors = []string{"PointsRule"}
+
+func (ec *executionContext) _PointsRule(ctx context.Context, sel ast.SelectionSet, obj *loyalty.PointsRule) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, pointsRuleImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("PointsRule")
+		case "action":
+			out.Values[i] = ec._PointsRule_action(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		case "points":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._PointsRule_points(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "version":
+			out.Values[i] = ec._PointsRule_version(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		case "updatedAt":
+			out.Values[i] = ec._PointsRule_updatedAt(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		case "updatedBy":
+			out.Values[i] = ec._PointsRule_updatedBy(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
 		}
-	}()
-	fc := &graphql.FieldContext{
-		Object:   "__Type",
-		Field:    field,
-		Args:     nil,
-		IsMethod: true,
-	}
-
-	ctx = graphql.WithFieldContext(ctx, fc)
-	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return obj.OfType(), nil
-	})
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
 	}
-	if resTmp == nil {
+	out.Dispatch()
+	if invalids > 0 {
 		return graphql.Null
 	}
-	res := resTmp.(*introspection.Type)
-	fc.Result = res
-	return ec.marshalO__Type2ᚖgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐType(ctx, field.Selections, res)
+	return out
 }
 
-// endregion **************************** field.gotpl *****************************
-
-// region    **************************** input.gotpl *****************************
+var profileImplementors = []string{"Profile"}
 
-func (ec *executionContext) unmarshalInputNewUser(ctx context.Context, obj interface{}) (model.NewUser, error) {
-	var it model.NewUser
-	var asMap = obj.(map[string]interface{})
+func (ec *executionContext) _Profile(ctx context.Context, sel ast.SelectionSet, obj *user.Profile) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, profileImplementors)
 
-	for k, v := range asMap {
-		switch k {
-		case "username":
-			var err error
-			it.Username, err = ec.unmarshalNString2string(ctx, v)
-			if err != nil {
-				return it, err
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Profile")
+		case "name":
+			out.Values[i] = ec._Profile_name(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
 			}
+		case "birthday":
+			out.Values[i] = ec._Profile_birthday(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "phone":
+			out.Values[i] = ec._Profile_phone(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
 		}
 	}
-
-	return it, nil
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
 }
 
-// endregion **************************** input.gotpl *****************************
-
-// region    ************************** interface.gotpl ***************************
-
-// endregion ************************** interface.gotpl ***************************
-
-// region    **************************** object.gotpl ****************************
-
-var mutationImplementors = []string{"Mutation"}
-
-func (ec *executionContext) _Mutation(ctx context.Context, sel ast.SelectionSet) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, mutationImplementors)
+var profileUpdateResponseImplementors = []string{"ProfileUpdateResponse"}
 
-	ctx = graphql.WithFieldContext(ctx, &graphql.FieldContext{
-		Object: "Mutation",
-	})
+func (ec *executionContext) _ProfileUpdateResponse(ctx context.Context, sel ast.SelectionSet, obj *model.ProfileUpdateResponse) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, profileUpdateResponseImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	var invalids uint32
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("Mutation")
-		case "userCreate":
-			out.Values[i] = ec._Mutation_userCreate(ctx, field)
+			out.Values[i] = graphql.MarshalString("ProfileUpdateResponse")
+		case "userId":
+			out.Values[i] = ec._ProfileUpdateResponse_userId(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
-		case "userDelete":
-			out.Values[i] = ec._Mutation_userDelete(ctx, field)
+		case "result":
+			out.Values[i] = ec._ProfileUpdateResponse_result(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
-		case "userReferralCreate":
-			out.Values[i] = ec._Mutation_userReferralCreate(ctx, field)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -2632,6 +7712,115 @@ func (ec *executionContext) _Query(ctx context.Context, sel ast.SelectionSet) gr
 				}
 				return res
 			})
+		case "wallet":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_wallet(ctx, field)
+				return res
+			})
+		case "userReadModelRebuildStatus":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_userReadModelRebuildStatus(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "webhookSubscriptions":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_webhookSubscriptions(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "webhookDeliveries":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_webhookDeliveries(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "commandAuditLog":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_commandAuditLog(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "registeredCommands":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_registeredCommands(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "sagas":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_sagas(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "pointsRules":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_pointsRules(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
 		case "__type":
 			out.Values[i] = ec._Query___type(ctx, field)
 		case "__schema":
@@ -2647,44 +7836,165 @@ func (ec *executionContext) _Query(ctx context.Context, sel ast.SelectionSet) gr
 	return out
 }
 
-var referralImplementors = []string{"Referral"}
+var readModelRebuildImplementors = []string{"ReadModelRebuild"}
+
+func (ec *executionContext) _ReadModelRebuild(ctx context.Context, sel ast.SelectionSet, obj *user.RebuildStatus) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, readModelRebuildImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("ReadModelRebuild")
+		case "collection":
+			out.Values[i] = ec._ReadModelRebuild_collection(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "previousCollection":
+			out.Values[i] = ec._ReadModelRebuild_previousCollection(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "eventsProcessed":
+			out.Values[i] = ec._ReadModelRebuild_eventsProcessed(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "totalEvents":
+			out.Values[i] = ec._ReadModelRebuild_totalEvents(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "inProgress":
+			out.Values[i] = ec._ReadModelRebuild_inProgress(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "lastError":
+			out.Values[i] = ec._ReadModelRebuild_lastError(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var referralImplementors = []string{"Referral"}
+
+func (ec *executionContext) _Referral(ctx context.Context, sel ast.SelectionSet, obj *user.Referral) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, referralImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Referral")
+		case "id":
+			out.Values[i] = ec._Referral_id(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "status":
+			out.Values[i] = ec._Referral_status(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "referredUserEmail":
+			out.Values[i] = ec._Referral_referredUserEmail(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "referralCode":
+			out.Values[i] = ec._Referral_referralCode(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "createdAt":
+			out.Values[i] = ec._Referral_createdAt(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "updatedAt":
+			out.Values[i] = ec._Referral_updatedAt(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var sagaInstanceImplementors = []string{"SagaInstance"}
 
-func (ec *executionContext) _Referral(ctx context.Context, sel ast.SelectionSet, obj *user.Referral) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, referralImplementors)
+func (ec *executionContext) _SagaInstance(ctx context.Context, sel ast.SelectionSet, obj *saga.Instance) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, sagaInstanceImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	var invalids uint32
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("Referral")
+			out.Values[i] = graphql.MarshalString("SagaInstance")
 		case "id":
-			out.Values[i] = ec._Referral_id(ctx, field, obj)
+			out.Values[i] = ec._SagaInstance_id(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "type":
+			out.Values[i] = ec._SagaInstance_type(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "correlationId":
+			out.Values[i] = ec._SagaInstance_correlationId(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
 		case "status":
-			out.Values[i] = ec._Referral_status(ctx, field, obj)
+			out.Values[i] = ec._SagaInstance_status(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
-		case "referredUserEmail":
-			out.Values[i] = ec._Referral_referredUserEmail(ctx, field, obj)
+		case "completedSteps":
+			out.Values[i] = ec._SagaInstance_completedSteps(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
-		case "referralCode":
-			out.Values[i] = ec._Referral_referralCode(ctx, field, obj)
+		case "compensatedSteps":
+			out.Values[i] = ec._SagaInstance_compensatedSteps(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
-		case "createdAt":
-			out.Values[i] = ec._Referral_createdAt(ctx, field, obj)
+		case "attempts":
+			out.Values[i] = ec._SagaInstance_attempts(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "lastError":
+			out.Values[i] = ec._SagaInstance_lastError(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "startedAt":
+			out.Values[i] = ec._SagaInstance_startedAt(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
 		case "updatedAt":
-			out.Values[i] = ec._Referral_updatedAt(ctx, field, obj)
+			out.Values[i] = ec._SagaInstance_updatedAt(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
@@ -2746,6 +8056,17 @@ func (ec *executionContext) _User(ctx context.Context, sel ast.SelectionSet, obj
 				}
 				return res
 			})
+		case "wallet":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._User_wallet(ctx, field, obj)
+				return res
+			})
 		case "referralCode":
 			out.Values[i] = ec._User_referralCode(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
@@ -2765,6 +8086,13 @@ func (ec *executionContext) _User(ctx context.Context, sel ast.SelectionSet, obj
 				}
 				return res
 			})
+		case "profile":
+			out.Values[i] = ec._User_profile(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		case "profileCompletedAt":
+			out.Values[i] = ec._User_profileCompletedAt(ctx, field, obj)
 		case "version":
 			out.Values[i] = ec._User_version(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
@@ -2798,6 +8126,11 @@ func (ec *executionContext) _UserCreateResponse(ctx context.Context, sel ast.Sel
 			out.Values[i] = ec._UserCreateResponse_username(ctx, field, obj)
 		case "email":
 			out.Values[i] = ec._UserCreateResponse_email(ctx, field, obj)
+		case "result":
+			out.Values[i] = ec._UserCreateResponse_result(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -2822,6 +8155,48 @@ func (ec *executionContext) _UserDeleteResponse(ctx context.Context, sel ast.Sel
 			out.Values[i] = graphql.MarshalString("UserDeleteResponse")
 		case "userId":
 			out.Values[i] = ec._UserDeleteResponse_userId(ctx, field, obj)
+		case "result":
+			out.Values[i] = ec._UserDeleteResponse_result(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var userReferralCancelResponseImplementors = []string{"UserReferralCancelResponse"}
+
+func (ec *executionContext) _UserReferralCancelResponse(ctx context.Context, sel ast.SelectionSet, obj *model.UserReferralCancelResponse) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, userReferralCancelResponseImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("UserReferralCancelResponse")
+		case "userId":
+			out.Values[i] = ec._UserReferralCancelResponse_userId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "referralId":
+			out.Values[i] = ec._UserReferralCancelResponse_referralId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "result":
+			out.Values[i] = ec._UserReferralCancelResponse_result(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -2848,6 +8223,249 @@ func (ec *executionContext) _UserReferralCreatedResponse(ctx context.Context, se
 			out.Values[i] = ec._UserReferralCreatedResponse_userId(ctx, field, obj)
 		case "referredUserEmail":
 			out.Values[i] = ec._UserReferralCreatedResponse_referredUserEmail(ctx, field, obj)
+		case "flagged":
+			out.Values[i] = ec._UserReferralCreatedResponse_flagged(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "flagReason":
+			out.Values[i] = ec._UserReferralCreatedResponse_flagReason(ctx, field, obj)
+		case "result":
+			out.Values[i] = ec._UserReferralCreatedResponse_result(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var walletImplementors = []string{"Wallet"}
+
+func (ec *executionContext) _Wallet(ctx context.Context, sel ast.SelectionSet, obj *wallet.DTO) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, walletImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Wallet")
+		case "walletId":
+			out.Values[i] = ec._Wallet_walletId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		case "userId":
+			out.Values[i] = ec._Wallet_userId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		case "balance":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Wallet_balance(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "openedAt":
+			out.Values[i] = ec._Wallet_openedAt(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		case "updatedAt":
+			out.Values[i] = ec._Wallet_updatedAt(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		case "version":
+			out.Values[i] = ec._Wallet_version(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				atomic.AddUint32(&invalids, 1)
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var webhookDeliveryImplementors = []string{"WebhookDelivery"}
+
+func (ec *executionContext) _WebhookDelivery(ctx context.Context, sel ast.SelectionSet, obj *webhook.Delivery) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, webhookDeliveryImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("WebhookDelivery")
+		case "id":
+			out.Values[i] = ec._WebhookDelivery_id(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "subscriptionId":
+			out.Values[i] = ec._WebhookDelivery_subscriptionId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "eventType":
+			out.Values[i] = ec._WebhookDelivery_eventType(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "aggregateId":
+			out.Values[i] = ec._WebhookDelivery_aggregateId(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "eventVersion":
+			out.Values[i] = ec._WebhookDelivery_eventVersion(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "attempts":
+			out.Values[i] = ec._WebhookDelivery_attempts(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "statusCode":
+			out.Values[i] = ec._WebhookDelivery_statusCode(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "succeeded":
+			out.Values[i] = ec._WebhookDelivery_succeeded(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "error":
+			out.Values[i] = ec._WebhookDelivery_error(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "deliveredAt":
+			out.Values[i] = ec._WebhookDelivery_deliveredAt(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var webhookSubscriptionImplementors = []string{"WebhookSubscription"}
+
+func (ec *executionContext) _WebhookSubscription(ctx context.Context, sel ast.SelectionSet, obj *webhook.Subscription) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, webhookSubscriptionImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("WebhookSubscription")
+		case "id":
+			out.Values[i] = ec._WebhookSubscription_id(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "url":
+			out.Values[i] = ec._WebhookSubscription_url(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "eventTypes":
+			out.Values[i] = ec._WebhookSubscription_eventTypes(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "createdAt":
+			out.Values[i] = ec._WebhookSubscription_createdAt(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var webhookSubscriptionCreateResponseImplementors = []string{"WebhookSubscriptionCreateResponse"}
+
+func (ec *executionContext) _WebhookSubscriptionCreateResponse(ctx context.Context, sel ast.SelectionSet, obj *model.WebhookSubscriptionCreateResponse) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, webhookSubscriptionCreateResponseImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("WebhookSubscriptionCreateResponse")
+		case "subscription":
+			out.Values[i] = ec._WebhookSubscriptionCreateResponse_subscription(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "secret":
+			out.Values[i] = ec._WebhookSubscriptionCreateResponse_secret(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var webhookSubscriptionDeleteResponseImplementors = []string{"WebhookSubscriptionDeleteResponse"}
+
+func (ec *executionContext) _WebhookSubscriptionDeleteResponse(ctx context.Context, sel ast.SelectionSet, obj *model.WebhookSubscriptionDeleteResponse) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, webhookSubscriptionDeleteResponseImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("WebhookSubscriptionDeleteResponse")
+		case "subscriptionId":
+			out.Values[i] = ec._WebhookSubscriptionDeleteResponse_subscriptionId(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -3115,15 +8733,323 @@ func (ec *executionContext) marshalNBoolean2bool(ctx context.Context, sel ast.Se
 			ec.Errorf(ctx, "must not be null")
 		}
 	}
-	return res
+	return res
+}
+
+func (ec *executionContext) marshalNCommandAuditEntry2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋauditᚐEntry(ctx context.Context, sel ast.SelectionSet, v audit.Entry) graphql.Marshaler {
+	return ec._CommandAuditEntry(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNCommandAuditEntry2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋauditᚐEntryᚄ(ctx context.Context, sel ast.SelectionSet, v []audit.Entry) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNCommandAuditEntry2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋauditᚐEntry(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) unmarshalNCommandOutcome2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋauditᚐOutcome(ctx context.Context, v interface{}) (audit.Outcome, error) {
+	tmp, err := graphql.UnmarshalString(v)
+	return audit.Outcome(tmp), err
+}
+
+func (ec *executionContext) marshalNCommandOutcome2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋauditᚐOutcome(ctx context.Context, sel ast.SelectionSet, v audit.Outcome) graphql.Marshaler {
+	res := graphql.MarshalString(string(v))
+	if res == graphql.Null {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+	}
+	return res
+}
+
+func (ec *executionContext) marshalNCommandRegistration2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandRegistration(ctx context.Context, sel ast.SelectionSet, v eventsource.CommandRegistration) graphql.Marshaler {
+	return ec._CommandRegistration(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNCommandRegistration2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandRegistrationᚄ(ctx context.Context, sel ast.SelectionSet, v []eventsource.CommandRegistration) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNCommandRegistration2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandRegistration(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) marshalNCommandResult2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandResult(ctx context.Context, sel ast.SelectionSet, v eventsource.CommandResult) graphql.Marshaler {
+	return ec._CommandResult(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNCommandResult2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandResult(ctx context.Context, sel ast.SelectionSet, v *eventsource.CommandResult) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._CommandResult(ctx, sel, v)
+}
+
+func (ec *executionContext) unmarshalNCommandSource2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandSource(ctx context.Context, v interface{}) (eventsource.CommandSource, error) {
+	tmp, err := graphql.UnmarshalString(v)
+	return eventsource.CommandSource(tmp), err
+}
+
+func (ec *executionContext) marshalNCommandSource2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋeventsourceᚐCommandSource(ctx context.Context, sel ast.SelectionSet, v eventsource.CommandSource) graphql.Marshaler {
+	res := graphql.MarshalString(string(v))
+	if res == graphql.Null {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+	}
+	return res
+}
+
+func (ec *executionContext) unmarshalNInt2int(ctx context.Context, v interface{}) (int, error) {
+	return graphql.UnmarshalInt(v)
+}
+
+func (ec *executionContext) marshalNInt2int(ctx context.Context, sel ast.SelectionSet, v int) graphql.Marshaler {
+	res := graphql.MarshalInt(v)
+	if res == graphql.Null {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+	}
+	return res
+}
+
+func (ec *executionContext) unmarshalNInt2int64(ctx context.Context, v interface{}) (int64, error) {
+	return graphql.UnmarshalInt64(v)
+}
+
+func (ec *executionContext) marshalNInt2int64(ctx context.Context, sel ast.SelectionSet, v int64) graphql.Marshaler {
+	res := graphql.MarshalInt64(v)
+	if res == graphql.Null {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+	}
+	return res
+}
+
+func (ec *executionContext) unmarshalNPointsAction2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsAction(ctx context.Context, v interface{}) (loyalty.PointsAction, error) {
+	tmp, err := graphql.UnmarshalString(v)
+	return loyalty.PointsAction(tmp), err
+}
+
+func (ec *executionContext) marshalNPointsAction2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsAction(ctx context.Context, sel ast.SelectionSet, v loyalty.PointsAction) graphql.Marshaler {
+	res := graphql.MarshalString(string(v))
+	if res == graphql.Null {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+	}
+	return res
+}
+
+func (ec *executionContext) marshalNPointsRedeemResponse2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐPointsRedeemResponse(ctx context.Context, sel ast.SelectionSet, v model.PointsRedeemResponse) graphql.Marshaler {
+	return ec._PointsRedeemResponse(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNPointsRedeemResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐPointsRedeemResponse(ctx context.Context, sel ast.SelectionSet, v *model.PointsRedeemResponse) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._PointsRedeemResponse(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNPointsRule2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsRule(ctx context.Context, sel ast.SelectionSet, v loyalty.PointsRule) graphql.Marshaler {
+	return ec._PointsRule(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNPointsRule2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsRuleᚄ(ctx context.Context, sel ast.SelectionSet, v []loyalty.PointsRule) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNPointsRule2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsRule(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) marshalNPointsRule2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋloyaltyᚐPointsRule(ctx context.Context, sel ast.SelectionSet, v *loyalty.PointsRule) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._PointsRule(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNProfile2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐProfile(ctx context.Context, sel ast.SelectionSet, v user.Profile) graphql.Marshaler {
+	return ec._Profile(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNProfileUpdateResponse2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐProfileUpdateResponse(ctx context.Context, sel ast.SelectionSet, v model.ProfileUpdateResponse) graphql.Marshaler {
+	return ec._ProfileUpdateResponse(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNProfileUpdateResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐProfileUpdateResponse(ctx context.Context, sel ast.SelectionSet, v *model.ProfileUpdateResponse) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._ProfileUpdateResponse(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNReadModelRebuild2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐRebuildStatus(ctx context.Context, sel ast.SelectionSet, v user.RebuildStatus) graphql.Marshaler {
+	return ec._ReadModelRebuild(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNReadModelRebuild2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐRebuildStatus(ctx context.Context, sel ast.SelectionSet, v *user.RebuildStatus) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._ReadModelRebuild(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNReferral2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferral(ctx context.Context, sel ast.SelectionSet, v user.Referral) graphql.Marshaler {
+	return ec._Referral(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNReferral2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralᚄ(ctx context.Context, sel ast.SelectionSet, v []user.Referral) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNReferral2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferral(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
 }
 
-func (ec *executionContext) unmarshalNInt2int(ctx context.Context, v interface{}) (int, error) {
-	return graphql.UnmarshalInt(v)
+func (ec *executionContext) unmarshalNReferralStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralStatus(ctx context.Context, v interface{}) (user.ReferralStatus, error) {
+	tmp, err := graphql.UnmarshalString(v)
+	return user.ReferralStatus(tmp), err
 }
 
-func (ec *executionContext) marshalNInt2int(ctx context.Context, sel ast.SelectionSet, v int) graphql.Marshaler {
-	res := graphql.MarshalInt(v)
+func (ec *executionContext) marshalNReferralStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralStatus(ctx context.Context, sel ast.SelectionSet, v user.ReferralStatus) graphql.Marshaler {
+	res := graphql.MarshalString(string(v))
 	if res == graphql.Null {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "must not be null")
@@ -3132,11 +9058,11 @@ func (ec *executionContext) marshalNInt2int(ctx context.Context, sel ast.Selecti
 	return res
 }
 
-func (ec *executionContext) marshalNReferral2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferral(ctx context.Context, sel ast.SelectionSet, v user.Referral) graphql.Marshaler {
-	return ec._Referral(ctx, sel, &v)
+func (ec *executionContext) marshalNSagaInstance2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐInstance(ctx context.Context, sel ast.SelectionSet, v saga.Instance) graphql.Marshaler {
+	return ec._SagaInstance(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNReferral2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralᚄ(ctx context.Context, sel ast.SelectionSet, v []user.Referral) graphql.Marshaler {
+func (ec *executionContext) marshalNSagaInstance2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐInstanceᚄ(ctx context.Context, sel ast.SelectionSet, v []saga.Instance) graphql.Marshaler {
 	ret := make(graphql.Array, len(v))
 	var wg sync.WaitGroup
 	isLen1 := len(v) == 1
@@ -3160,7 +9086,7 @@ func (ec *executionContext) marshalNReferral2ᚕgithubᚗcomᚋdwaynelavonᚋes
 			if !isLen1 {
 				defer wg.Done()
 			}
-			ret[i] = ec.marshalNReferral2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferral(ctx, sel, v[i])
+			ret[i] = ec.marshalNSagaInstance2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐInstance(ctx, sel, v[i])
 		}
 		if isLen1 {
 			f(i)
@@ -3173,12 +9099,12 @@ func (ec *executionContext) marshalNReferral2ᚕgithubᚗcomᚋdwaynelavonᚋes
 	return ret
 }
 
-func (ec *executionContext) unmarshalNReferralStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralStatus(ctx context.Context, v interface{}) (user.ReferralStatus, error) {
+func (ec *executionContext) unmarshalNSagaStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐStatus(ctx context.Context, v interface{}) (saga.Status, error) {
 	tmp, err := graphql.UnmarshalString(v)
-	return user.ReferralStatus(tmp), err
+	return saga.Status(tmp), err
 }
 
-func (ec *executionContext) marshalNReferralStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋuserᚐReferralStatus(ctx context.Context, sel ast.SelectionSet, v user.ReferralStatus) graphql.Marshaler {
+func (ec *executionContext) marshalNSagaStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐStatus(ctx context.Context, sel ast.SelectionSet, v saga.Status) graphql.Marshaler {
 	res := graphql.MarshalString(string(v))
 	if res == graphql.Null {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
@@ -3202,6 +9128,35 @@ func (ec *executionContext) marshalNString2string(ctx context.Context, sel ast.S
 	return res
 }
 
+func (ec *executionContext) unmarshalNString2ᚕstringᚄ(ctx context.Context, v interface{}) ([]string, error) {
+	var vSlice []interface{}
+	if v != nil {
+		if tmp1, ok := v.([]interface{}); ok {
+			vSlice = tmp1
+		} else {
+			vSlice = []interface{}{v}
+		}
+	}
+	var err error
+	res := make([]string, len(vSlice))
+	for i := range vSlice {
+		res[i], err = ec.unmarshalNString2string(ctx, vSlice[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (ec *executionContext) marshalNString2ᚕstringᚄ(ctx context.Context, sel ast.SelectionSet, v []string) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	for i := range v {
+		ret[i] = ec.marshalNString2string(ctx, sel, v[i])
+	}
+
+	return ret
+}
+
 func (ec *executionContext) unmarshalNTime2timeᚐTime(ctx context.Context, v interface{}) (time.Time, error) {
 	return graphql.UnmarshalTime(v)
 }
@@ -3285,6 +9240,140 @@ func (ec *executionContext) marshalNUserDeleteResponse2ᚖgithubᚗcomᚋdwaynel
 	return ec._UserDeleteResponse(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalNUserReferralCancelResponse2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserReferralCancelResponse(ctx context.Context, sel ast.SelectionSet, v model.UserReferralCancelResponse) graphql.Marshaler {
+	return ec._UserReferralCancelResponse(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNUserReferralCancelResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserReferralCancelResponse(ctx context.Context, sel ast.SelectionSet, v *model.UserReferralCancelResponse) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._UserReferralCancelResponse(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNWebhookDelivery2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐDelivery(ctx context.Context, sel ast.SelectionSet, v webhook.Delivery) graphql.Marshaler {
+	return ec._WebhookDelivery(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNWebhookDelivery2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐDeliveryᚄ(ctx context.Context, sel ast.SelectionSet, v []webhook.Delivery) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNWebhookDelivery2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐDelivery(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) marshalNWebhookSubscription2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐSubscription(ctx context.Context, sel ast.SelectionSet, v webhook.Subscription) graphql.Marshaler {
+	return ec._WebhookSubscription(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNWebhookSubscription2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐSubscriptionᚄ(ctx context.Context, sel ast.SelectionSet, v []webhook.Subscription) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNWebhookSubscription2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐSubscription(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) marshalNWebhookSubscription2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwebhookᚐSubscription(ctx context.Context, sel ast.SelectionSet, v *webhook.Subscription) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._WebhookSubscription(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNWebhookSubscriptionCreateResponse2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐWebhookSubscriptionCreateResponse(ctx context.Context, sel ast.SelectionSet, v model.WebhookSubscriptionCreateResponse) graphql.Marshaler {
+	return ec._WebhookSubscriptionCreateResponse(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNWebhookSubscriptionCreateResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐWebhookSubscriptionCreateResponse(ctx context.Context, sel ast.SelectionSet, v *model.WebhookSubscriptionCreateResponse) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._WebhookSubscriptionCreateResponse(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNWebhookSubscriptionDeleteResponse2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐWebhookSubscriptionDeleteResponse(ctx context.Context, sel ast.SelectionSet, v model.WebhookSubscriptionDeleteResponse) graphql.Marshaler {
+	return ec._WebhookSubscriptionDeleteResponse(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNWebhookSubscriptionDeleteResponse2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐWebhookSubscriptionDeleteResponse(ctx context.Context, sel ast.SelectionSet, v *model.WebhookSubscriptionDeleteResponse) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._WebhookSubscriptionDeleteResponse(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalN__Directive2githubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐDirective(ctx context.Context, sel ast.SelectionSet, v introspection.Directive) graphql.Marshaler {
 	return ec.___Directive(ctx, sel, &v)
 }
@@ -3534,6 +9623,89 @@ func (ec *executionContext) marshalOBoolean2ᚖbool(ctx context.Context, sel ast
 	return ec.marshalOBoolean2bool(ctx, sel, *v)
 }
 
+func (ec *executionContext) unmarshalOInt2int(ctx context.Context, v interface{}) (int, error) {
+	return graphql.UnmarshalInt(v)
+}
+
+func (ec *executionContext) marshalOInt2int(ctx context.Context, sel ast.SelectionSet, v int) graphql.Marshaler {
+	return graphql.MarshalInt(v)
+}
+
+func (ec *executionContext) unmarshalOInt2ᚖint(ctx context.Context, v interface{}) (*int, error) {
+	if v == nil {
+		return nil, nil
+	}
+	res, err := ec.unmarshalOInt2int(ctx, v)
+	return &res, err
+}
+
+func (ec *executionContext) marshalOInt2ᚖint(ctx context.Context, sel ast.SelectionSet, v *int) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec.marshalOInt2int(ctx, sel, *v)
+}
+
+func (ec *executionContext) unmarshalOSagaStatus2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐStatusᚄ(ctx context.Context, v interface{}) ([]saga.Status, error) {
+	var vSlice []interface{}
+	if v != nil {
+		if tmp1, ok := v.([]interface{}); ok {
+			vSlice = tmp1
+		} else {
+			vSlice = []interface{}{v}
+		}
+	}
+	var err error
+	res := make([]saga.Status, len(vSlice))
+	for i := range vSlice {
+		res[i], err = ec.unmarshalNSagaStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐStatus(ctx, vSlice[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (ec *executionContext) marshalOSagaStatus2ᚕgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐStatusᚄ(ctx context.Context, sel ast.SelectionSet, v []saga.Status) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNSagaStatus2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋsagaᚐStatus(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
 func (ec *executionContext) unmarshalOString2string(ctx context.Context, v interface{}) (string, error) {
 	return graphql.UnmarshalString(v)
 }
@@ -3557,6 +9729,29 @@ func (ec *executionContext) marshalOString2ᚖstring(ctx context.Context, sel as
 	return ec.marshalOString2string(ctx, sel, *v)
 }
 
+func (ec *executionContext) unmarshalOTime2timeᚐTime(ctx context.Context, v interface{}) (time.Time, error) {
+	return graphql.UnmarshalTime(v)
+}
+
+func (ec *executionContext) marshalOTime2timeᚐTime(ctx context.Context, sel ast.SelectionSet, v time.Time) graphql.Marshaler {
+	return graphql.MarshalTime(v)
+}
+
+func (ec *executionContext) unmarshalOTime2ᚖtimeᚐTime(ctx context.Context, v interface{}) (*time.Time, error) {
+	if v == nil {
+		return nil, nil
+	}
+	res, err := ec.unmarshalOTime2timeᚐTime(ctx, v)
+	return &res, err
+}
+
+func (ec *executionContext) marshalOTime2ᚖtimeᚐTime(ctx context.Context, sel ast.SelectionSet, v *time.Time) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec.marshalOTime2timeᚐTime(ctx, sel, *v)
+}
+
 func (ec *executionContext) marshalOUserReferralCreatedResponse2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋgraphᚋmodelᚐUserReferralCreatedResponse(ctx context.Context, sel ast.SelectionSet, v model.UserReferralCreatedResponse) graphql.Marshaler {
 	return ec._UserReferralCreatedResponse(ctx, sel, &v)
 }
@@ -3568,6 +9763,17 @@ func (ec *executionContext) marshalOUserReferralCreatedResponse2ᚖgithubᚗcom
 	return ec._UserReferralCreatedResponse(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalOWallet2githubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwalletᚐDTO(ctx context.Context, sel ast.SelectionSet, v wallet.DTO) graphql.Marshaler {
+	return ec._Wallet(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalOWallet2ᚖgithubᚗcomᚋdwaynelavonᚋesᚑloyaltyᚑprogramᚋinternalᚋappᚋwalletᚐDTO(ctx context.Context, sel ast.SelectionSet, v *wallet.DTO) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	return ec._Wallet(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalO__EnumValue2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐEnumValueᚄ(ctx context.Context, sel ast.SelectionSet, v []introspection.EnumValue) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
@@ -2,21 +2,58 @@
 
 package model
 
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/webhook"
+)
+
 type NewUser struct {
 	Username string `json:"username"`
 }
 
+type PointsRedeemResponse struct {
+	UserID         string                     `json:"userId"`
+	PointsRedeemed int                        `json:"pointsRedeemed"`
+	Balance        int                        `json:"balance"`
+	Result         *eventsource.CommandResult `json:"result"`
+}
+
+type ProfileUpdateResponse struct {
+	UserID string                     `json:"userId"`
+	Result *eventsource.CommandResult `json:"result"`
+}
+
 type UserCreateResponse struct {
-	UserID   *string `json:"userId"`
-	Username *string `json:"username"`
-	Email    *string `json:"email"`
+	UserID   *string                    `json:"userId"`
+	Username *string                    `json:"username"`
+	Email    *string                    `json:"email"`
+	Result   *eventsource.CommandResult `json:"result"`
 }
 
 type UserDeleteResponse struct {
-	UserID *string `json:"userId"`
+	UserID *string                    `json:"userId"`
+	Result *eventsource.CommandResult `json:"result"`
+}
+
+type UserReferralCancelResponse struct {
+	UserID     string                     `json:"userId"`
+	ReferralID string                     `json:"referralId"`
+	Result     *eventsource.CommandResult `json:"result"`
 }
 
 type UserReferralCreatedResponse struct {
-	UserID            *string `json:"userId"`
-	ReferredUserEmail *string `json:"referredUserEmail"`
+	UserID            *string                    `json:"userId"`
+	ReferredUserEmail *string                    `json:"referredUserEmail"`
+	Flagged           bool                       `json:"flagged"`
+	FlagReason        *string                    `json:"flagReason"`
+	Result            *eventsource.CommandResult `json:"result"`
+}
+
+type WebhookSubscriptionCreateResponse struct {
+	Subscription *webhook.Subscription `json:"subscription"`
+	Secret       string                `json:"secret"`
+}
+
+type WebhookSubscriptionDeleteResponse struct {
+	SubscriptionID *string `json:"subscriptionId"`
 }
@@ -1,8 +1,20 @@
 package graph
 
 import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/audit"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/webhook"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // This file will not be regenerated automatically.
@@ -10,6 +22,83 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	Dispatcher    eventsource.CommandDispatcher
-	UserReadModel user.ReadModel
+	// AdminActorIDs are the actors allowed to run admin mutations
+	AdminActorIDs          []string
+	AuditStore             audit.Store
+	Dispatcher             eventsource.CommandDispatcher
+	PointsRulesService     loyalty.PointsRulesService
+	SagaRunner             saga.Runner
+	UserEventStore         user.EventStore
+	UserReadModel          user.ReadModel
+	UserReadModelRebuilder user.ReadModelRebuilder
+	WalletReadRepo         wallet.ReadRepo
+	WebhookService         webhook.Service
+}
+
+var (
+	errAdminRequired = errors.New("admin actor required")
+	errOwnerRequired = errors.New("actor may only act on their own account")
+)
+
+// requireAdmin fails unless the actor of ctx is an admin
+func (r *Resolver) requireAdmin(ctx context.Context) error {
+	actor := eventsource.ActorFromContext(ctx)
+	for _, v := range r.AdminActorIDs {
+		if v == actor {
+			return nil
+		}
+	}
+	return errAdminRequired
+}
+
+// requireOwner fails unless the actor of ctx is the user or an admin
+func (r *Resolver) requireOwner(ctx context.Context, userID string) error {
+	if eventsource.ActorFromContext(ctx) == userID {
+		return nil
+	}
+	if r.requireAdmin(ctx) == nil {
+		return nil
+	}
+	return errOwnerRequired
+}
+
+// pointsArgument converts the points argument to the type the commands
+// expect, rejecting values it cannot hold instead of wrapping them around
+func pointsArgument(field string, points int) (uint32, error) {
+	if points < 0 || uint64(points) > math.MaxUint32 {
+		return 0, &eventsource.ValidationError{
+			Fields: []eventsource.FieldError{{
+				Field:   field,
+				Rule:    "range",
+				Message: fmt.Sprintf("must be between 0 and %v", uint32(math.MaxUint32)),
+			}},
+		}
+	}
+	return uint32(points), nil
+}
+
+// openedWallet returns the wallet of the user, or nil until it is opened
+func (r *Resolver) openedWallet(ctx context.Context, userID string) (*wallet.DTO, error) {
+	w, err := r.WalletReadRepo.Wallet(ctx, userID)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	return w, err
+}
+
+// idempotentUserID derives the id of the user created with key. Keys are
+// scoped to the actor so that callers cannot claim the users of others
+func idempotentUserID(ctx context.Context, key string) string {
+	return eventsource.NewUUIDFromKey(eventsource.ActorFromContext(ctx) + ":" + key)
+}
+
+// emitted indicates whether the command of result emitted an event of
+// eventType
+func emitted(result *eventsource.CommandResult, eventType string) bool {
+	for _, v := range result.EventTypes {
+		if v == eventType {
+			return true
+		}
+	}
+	return false
 }
@@ -9,17 +9,26 @@ import (
 
 	"github.com/dwaynelavon/es-loyalty-program/graph/generated"
 	"github.com/dwaynelavon/es-loyalty-program/graph/model"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/audit"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/webhook"
 )
 
-func (r *mutationResolver) UserCreate(ctx context.Context, username string, email string, referredByCode *string) (*model.UserCreateResponse, error) {
+func (r *mutationResolver) UserCreate(ctx context.Context, username string, email string, referredByCode *string, idempotencyKey *string) (*model.UserCreateResponse, error) {
 	id := eventsource.NewUUID()
+	if !eventsource.IsStringEmpty(idempotencyKey) {
+		// Retries must target the same aggregate for the key to match
+		id = idempotentUserID(ctx, *idempotencyKey)
+	}
 
-	err := r.Dispatcher.Dispatch(context.Background(), &loyalty.CreateUser{
+	result, err := r.Dispatcher.Dispatch(ctx, &loyalty.CreateUser{
 		CommandModel: eventsource.CommandModel{
-			ID: id,
+			ID:        id,
+			CommandID: eventsource.StringValue(idempotencyKey),
 		},
 		Username:       username,
 		Email:          email,
@@ -29,15 +38,21 @@ func (r *mutationResolver) UserCreate(ctx context.Context, username string, emai
 		return nil, err
 	}
 
+	// A retried command reports the user of the first execution
+	created, errCreated := user.LoadCreated(ctx, r.UserEventStore, id)
+	if errCreated != nil {
+		return nil, errCreated
+	}
 	return &model.UserCreateResponse{
-		Username: &username,
+		Username: &created.Username,
 		UserID:   &id,
-		Email:    &email,
+		Email:    &created.Email,
+		Result:   result,
 	}, nil
 }
 
 func (r *mutationResolver) UserDelete(ctx context.Context, userID string) (*model.UserDeleteResponse, error) {
-	err := r.Dispatcher.Dispatch(context.Background(), &loyalty.DeleteUser{
+	result, err := r.Dispatcher.Dispatch(ctx, &loyalty.DeleteUser{
 		CommandModel: eventsource.CommandModel{
 			ID: userID,
 		},
@@ -47,56 +62,303 @@ func (r *mutationResolver) UserDelete(ctx context.Context, userID string) (*mode
 	}
 	return &model.UserDeleteResponse{
 		UserID: &userID,
+		Result: result,
 	}, nil
 }
 
-func (r *mutationResolver) UserReferralCreate(ctx context.Context, userID string, referredUserEmail string) (*model.UserReferralCreatedResponse, error) {
-	err := r.Dispatcher.Dispatch(ctx, &loyalty.CreateReferral{
+func (r *mutationResolver) UserReferralCreate(ctx context.Context, userID string, referredUserEmail string, idempotencyKey *string) (*model.UserReferralCreatedResponse, error) {
+	errOwner := r.requireOwner(ctx, userID)
+	if errOwner != nil {
+		return nil, errOwner
+	}
+	result, err := r.Dispatcher.Dispatch(ctx, &loyalty.CreateReferral{
 		CommandModel: eventsource.CommandModel{
-			ID: userID,
+			ID:        userID,
+			CommandID: eventsource.StringValue(idempotencyKey),
 		},
 		ReferredUserEmail: referredUserEmail,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &model.UserReferralCreatedResponse{
+
+	response := &model.UserReferralCreatedResponse{
 		UserID:            &userID,
 		ReferredUserEmail: &referredUserEmail,
+		Result:            result,
+	}
+	if !emitted(result, user.UserReferralFlaggedEventType) {
+		return response, nil
+	}
+
+	flag, errFlag := user.LoadReferralFlagged(ctx, r.UserEventStore, userID, result.Version)
+	if errFlag != nil {
+		return nil, errFlag
+	}
+	reason := string(flag.Reason)
+	response.Flagged = true
+	response.FlagReason = &reason
+	return response, nil
+}
+
+func (r *mutationResolver) UserReferralCancel(ctx context.Context, userID string, referralID string, reason *string, idempotencyKey *string) (*model.UserReferralCancelResponse, error) {
+	errOwner := r.requireOwner(ctx, userID)
+	if errOwner != nil {
+		return nil, errOwner
+	}
+	result, err := r.Dispatcher.Dispatch(ctx, &loyalty.CancelReferral{
+		CommandModel: eventsource.CommandModel{
+			ID:        userID,
+			CommandID: eventsource.StringValue(idempotencyKey),
+		},
+		ReferralID: referralID,
+		Reason:     eventsource.StringValue(reason),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.UserReferralCancelResponse{
+		UserID:     userID,
+		ReferralID: referralID,
+		Result:     result,
+	}, nil
+}
+
+func (r *mutationResolver) ProfileUpdate(ctx context.Context, userID string, name *string, birthday *string, phone *string, idempotencyKey *string) (*model.ProfileUpdateResponse, error) {
+	errOwner := r.requireOwner(ctx, userID)
+	if errOwner != nil {
+		return nil, errOwner
+	}
+	result, err := r.Dispatcher.Dispatch(ctx, &loyalty.UpdateProfile{
+		CommandModel: eventsource.CommandModel{
+			ID:        userID,
+			CommandID: eventsource.StringValue(idempotencyKey),
+		},
+		Name:     name,
+		Birthday: birthday,
+		Phone:    phone,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.ProfileUpdateResponse{
+		UserID: userID,
+		Result: result,
 	}, nil
 }
 
+func (r *mutationResolver) PointsRedeem(ctx context.Context, userID string, points int, reason *string, idempotencyKey *string) (*model.PointsRedeemResponse, error) {
+	errOwner := r.requireOwner(ctx, userID)
+	if errOwner != nil {
+		return nil, errOwner
+	}
+	redeemPoints, errPoints := pointsArgument("points", points)
+	if errPoints != nil {
+		return nil, errPoints
+	}
+	result, err := r.Dispatcher.Dispatch(ctx, &loyalty.RedeemPoints{
+		CommandModel: eventsource.CommandModel{
+			ID:        userID,
+			CommandID: eventsource.StringValue(idempotencyKey),
+		},
+		Points: redeemPoints,
+		Reason: eventsource.StringValue(reason),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	redeemed, errRedeemed := user.LoadPointsRedeemed(
+		ctx,
+		r.UserEventStore,
+		userID,
+		result.Version,
+	)
+	if errRedeemed != nil {
+		return nil, errRedeemed
+	}
+	return &model.PointsRedeemResponse{
+		UserID:         userID,
+		PointsRedeemed: int(redeemed.PointsRedeemed),
+		Balance:        int(redeemed.Balance),
+		Result:         result,
+	}, nil
+}
+
+func (r *mutationResolver) PointsRuleUpdate(ctx context.Context, action loyalty.PointsAction, points int, expectedVersion *int) (*loyalty.PointsRule, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	rulePoints, errPoints := pointsArgument("points", points)
+	if errPoints != nil {
+		return nil, errPoints
+	}
+	return r.PointsRulesService.UpdateRule(ctx, action, rulePoints, expectedVersion)
+}
+
+func (r *mutationResolver) UserReadModelRebuild(ctx context.Context) (*user.RebuildStatus, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	return r.UserReadModelRebuilder.Rebuild(ctx)
+}
+
+func (r *mutationResolver) UserReadModelRollback(ctx context.Context) (*user.RebuildStatus, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	return r.UserReadModelRebuilder.Rollback(ctx)
+}
+
+func (r *mutationResolver) WebhookSubscriptionCreate(ctx context.Context, url string, eventTypes []string, secret *string) (*model.WebhookSubscriptionCreateResponse, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	subscription, err := r.WebhookService.CreateSubscription(ctx, url, eventTypes, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &model.WebhookSubscriptionCreateResponse{
+		Subscription: subscription,
+		Secret:       subscription.Secret,
+	}, nil
+}
+
+func (r *mutationResolver) WebhookSubscriptionDelete(ctx context.Context, subscriptionID string) (*model.WebhookSubscriptionDeleteResponse, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	err := r.WebhookService.DeleteSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.WebhookSubscriptionDeleteResponse{
+		SubscriptionID: &subscriptionID,
+	}, nil
+}
+
+func (r *pointsRuleResolver) Points(ctx context.Context, obj *loyalty.PointsRule) (int, error) {
+	return int(obj.Points), nil
+}
+
 func (r *queryResolver) Users(ctx context.Context) ([]user.DTO, error) {
 	return r.UserReadModel.Users(ctx)
 }
 
+func (r *queryResolver) Wallet(ctx context.Context, userID string) (*wallet.DTO, error) {
+	return r.openedWallet(ctx, userID)
+}
+
+func (r *queryResolver) UserReadModelRebuildStatus(ctx context.Context) (*user.RebuildStatus, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	return r.UserReadModelRebuilder.Status(ctx)
+}
+
+func (r *queryResolver) WebhookSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	return r.WebhookService.Subscriptions(ctx)
+}
+
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, subscriptionID string) ([]webhook.Delivery, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	return r.WebhookService.Deliveries(ctx, subscriptionID)
+}
+
+func (r *queryResolver) CommandAuditLog(ctx context.Context, aggregateID *string, actor *string, limit *int) ([]audit.Entry, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	query := audit.Query{
+		AggregateID: eventsource.StringValue(aggregateID),
+		Actor:       eventsource.StringValue(actor),
+	}
+	if limit != nil {
+		query.Limit = *limit
+	}
+	return audit.Entries(ctx, r.AuditStore, query)
+}
+
+func (r *queryResolver) RegisteredCommands(ctx context.Context) ([]eventsource.CommandRegistration, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	return r.Dispatcher.RegisteredCommands(), nil
+}
+
+func (r *queryResolver) Sagas(ctx context.Context, statuses []saga.Status) ([]saga.Instance, error) {
+	errAdmin := r.requireAdmin(ctx)
+	if errAdmin != nil {
+		return nil, errAdmin
+	}
+	if len(statuses) == 0 {
+		statuses = []saga.Status{saga.StatusRunning, saga.StatusFailed}
+	}
+	return r.SagaRunner.Instances(ctx, statuses...)
+}
+
+func (r *queryResolver) PointsRules(ctx context.Context) ([]loyalty.PointsRule, error) {
+	return r.PointsRulesService.Rules(), nil
+}
+
 func (r *userResolver) Points(ctx context.Context, obj *user.DTO) (int, error) {
 	return int(obj.Points), nil
 }
 
+func (r *userResolver) Wallet(ctx context.Context, obj *user.DTO) (*wallet.DTO, error) {
+	return r.openedWallet(ctx, obj.UserID)
+}
+
 func (r *userResolver) Referrals(ctx context.Context, obj *user.DTO) ([]user.Referral, error) {
 	return r.UserReadModel.Referrals(ctx, obj.UserID)
 }
 
+func (r *walletResolver) Balance(ctx context.Context, obj *wallet.DTO) (int, error) {
+	return int(obj.Balance), nil
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
+// PointsRule returns generated.PointsRuleResolver implementation.
+func (r *Resolver) PointsRule() generated.PointsRuleResolver { return &pointsRuleResolver{r} }
+
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
 // User returns generated.UserResolver implementation.
 func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
 
+// Wallet returns generated.WalletResolver implementation.
+func (r *Resolver) Wallet() generated.WalletResolver { return &walletResolver{r} }
+
 type mutationResolver struct{ *Resolver }
+type pointsRuleResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type userResolver struct{ *Resolver }
+type walletResolver struct{ *Resolver }
 
 // !!! WARNING !!!
 // The code below was going to be deleted when updating resolvers. It has been copied here so you have
 // one last chance to move it out of harms way if you want. There are two reasons this happens:
-//  - When renaming or deleting a resolver the old code will be put in here. You can safely delete
-//    it when you're done.
-//  - You have helper methods in this file. Move them out to keep these resolver files clean.
+//   - When renaming or deleting a resolver the old code will be put in here. You can safely delete
+//     it when you're done.
+//   - You have helper methods in this file. Move them out to keep these resolver files clean.
 func (r *userResolver) Verison(ctx context.Context, obj *user.DTO) (int, error) {
 	panic(fmt.Errorf("not implemented"))
 }
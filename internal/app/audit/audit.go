@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errQueryRequired = errors.New("audit query must include an aggregate id or an actor")
+
+	// SystemActor is recorded for commands issued without an actor, e.g.
+	// by a saga reacting to an event recorded before actors were tracked
+	SystemActor = "system"
+
+	defaultLimit = 100
+
+	// redactedFields are the command fields holding personal data. Their
+	// values are left out of the recorded payloads
+	redactedFields = map[string]bool{
+		"email":             true,
+		"referredUserEmail": true,
+		"name":              true,
+		"phone":             true,
+		"birthday":          true,
+	}
+	redactedValue = "[redacted]"
+)
+
+// Outcome describes how a dispatched command ended
+type Outcome string
+
+// Outcomes of a dispatched command
+const (
+	OutcomeSucceeded Outcome = "Succeeded"
+
+	// OutcomeRejected marks commands that failed validation
+	OutcomeRejected Outcome = "Rejected"
+	OutcomeFailed   Outcome = "Failed"
+)
+
+// Entry records a dispatched command
+type Entry struct {
+	ID          string                    `json:"id" firestore:"id"`
+	CommandType string                    `json:"commandType" firestore:"commandType"`
+	AggregateID string                    `json:"aggregateId" firestore:"aggregateId"`
+	CommandID   string                    `json:"commandId" firestore:"commandId"`
+	Payload     string                    `json:"payload" firestore:"payload"`
+	Actor       string                    `json:"actor" firestore:"actor"`
+	Source      eventsource.CommandSource `json:"source" firestore:"source"`
+	Outcome     Outcome                   `json:"outcome" firestore:"outcome"`
+	Error       string                    `json:"error" firestore:"error"`
+	DurationMs  int64                     `json:"durationMs" firestore:"durationMs"`
+	IssuedAt    time.Time                 `json:"issuedAt" firestore:"issuedAt"`
+}
+
+// Query selects audit entries by aggregate, actor or both. The most
+// recent entries are returned first
+type Query struct {
+	AggregateID string
+	Actor       string
+
+	// Limit defaults to 100. Optional
+	Limit int
+}
+
+// Store persists the audit log
+type Store interface {
+	Record(ctx context.Context, entry Entry) error
+	Entries(ctx context.Context, query Query) ([]Entry, error)
+}
+
+// Middleware records every dispatched command in store, including
+// commands rejected by the middlewares it wraps. It should be the
+// outermost middleware. Failing to record an entry does not fail the command
+func Middleware(store Store, logger *zap.Logger) eventsource.Middleware {
+	return func(next eventsource.HandlerFunc) eventsource.HandlerFunc {
+		return func(
+			ctx context.Context,
+			cmd eventsource.Command,
+		) (*eventsource.CommandResult, error) {
+			issuedAt := time.Now()
+			result, err := next(ctx, cmd)
+
+			entry := newEntry(ctx, cmd, err)
+			entry.IssuedAt = issuedAt
+			entry.DurationMs = time.Since(issuedAt).Milliseconds()
+
+			errRecord := store.Record(eventsource.DetachContext(ctx), entry)
+			if errRecord != nil {
+				logger.Error(
+					"unable to record command audit entry",
+					zap.Error(errRecord),
+					zap.String("command", entry.CommandType),
+					zap.String("aggregateId", entry.AggregateID),
+				)
+			}
+			return result, err
+		}
+	}
+}
+
+// Entries returns the audit entries matching query
+func Entries(ctx context.Context, store Store, query Query) ([]Entry, error) {
+	if eventsource.IsStringEmpty(&query.AggregateID) &&
+		eventsource.IsStringEmpty(&query.Actor) {
+		return nil, errQueryRequired
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultLimit
+	}
+	return store.Entries(ctx, query)
+}
+
+/* ----- helpers ----- */
+func newEntry(
+	ctx context.Context,
+	cmd eventsource.Command,
+	err error,
+) Entry {
+	entry := Entry{
+		ID:          eventsource.NewUUID(),
+		CommandType: eventsource.CommandType(cmd),
+		AggregateID: cmd.AggregateID(),
+		Actor:       eventsource.ActorFromContext(ctx),
+		Source:      eventsource.CommandSourceFromContext(ctx),
+		Outcome:     OutcomeSucceeded,
+	}
+	if eventsource.IsStringEmpty(&entry.Actor) {
+		entry.Actor = SystemActor
+	}
+	if v, ok := cmd.(eventsource.IdempotentCommand); ok {
+		entry.CommandID = v.IdempotencyKey()
+	}
+	payload, errPayload := redactedPayload(cmd)
+	if errPayload == nil {
+		entry.Payload = payload
+	}
+
+	if err != nil {
+		entry.Outcome = OutcomeFailed
+		entry.Error = err.Error()
+		if _, ok := eventsource.AsValidationError(err); ok {
+			entry.Outcome = OutcomeRejected
+		}
+	}
+	return entry
+}
+
+// redactedPayload encodes cmd with the values of its personal data fields
+// replaced
+func redactedPayload(cmd eventsource.Command) (string, error) {
+	encoded, err := json.Marshal(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	var fields map[string]interface{}
+	errFields := json.Unmarshal(encoded, &fields)
+	if errFields != nil {
+		// Commands that do not encode to an object have no fields to redact
+		return string(encoded), nil
+	}
+	for k, v := range fields {
+		if redactedFields[k] && v != nil && v != "" {
+			fields[k] = redactedValue
+		}
+	}
+
+	redacted, errRedacted := json.Marshal(fields)
+	if errRedacted != nil {
+		return "", errRedacted
+	}
+	return string(redacted), nil
+}
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestMiddleware_RecordsOutcomes(t *testing.T) {
+	assert := assert.New(t)
+
+	store := &memoryStore{}
+	dispatcher := newTestDispatcher(t, store)
+	ctx := eventsource.WithActor(context.Background(), "admin-1")
+	ctx = eventsource.WithCommandSource(ctx, eventsource.CommandSourceGraphQL)
+
+	_, err := dispatcher.Dispatch(ctx, &auditedCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1", CommandID: "key-1"},
+		Name:         "valid",
+	})
+	assert.Nil(err)
+
+	_, errRejected := dispatcher.Dispatch(ctx, &auditedCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+	})
+	assert.NotNil(errRejected)
+
+	_, errFailed := dispatcher.Dispatch(ctx, &auditedCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+		Name:         "fail",
+	})
+	assert.NotNil(errFailed)
+
+	entries := store.entries
+	if assert.Len(entries, 3) {
+		assert.Equal(OutcomeSucceeded, entries[0].Outcome)
+		assert.Equal(eventsource.CommandType(&auditedCommand{}), entries[0].CommandType)
+		assert.Equal("agg-1", entries[0].AggregateID)
+		assert.Equal("key-1", entries[0].CommandID)
+		assert.Equal("admin-1", entries[0].Actor)
+		assert.Equal(eventsource.CommandSourceGraphQL, entries[0].Source)
+		assert.Contains(entries[0].Payload, `"Name":"valid"`)
+		assert.Empty(entries[0].Error)
+
+		assert.Equal(OutcomeRejected, entries[1].Outcome)
+		assert.NotEmpty(entries[1].Error)
+
+		assert.Equal(OutcomeFailed, entries[2].Outcome)
+		assert.Contains(entries[2].Error, "handler failed")
+	}
+}
+
+func TestMiddleware_DefaultsToSystemActor(t *testing.T) {
+	assert := assert.New(t)
+
+	store := &memoryStore{}
+	dispatcher := newTestDispatcher(t, store)
+
+	_, err := dispatcher.Dispatch(context.Background(), &auditedCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+		Name:         "valid",
+	})
+	assert.Nil(err)
+
+	if assert.Len(store.entries, 1) {
+		assert.Equal(SystemActor, store.entries[0].Actor)
+		assert.Equal(eventsource.CommandSourceSystem, store.entries[0].Source)
+	}
+}
+
+func TestMiddleware_IgnoresStoreFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	store := &memoryStore{err: errors.New("store unavailable")}
+	dispatcher := newTestDispatcher(t, store)
+
+	_, err := dispatcher.Dispatch(context.Background(), &auditedCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+		Name:         "valid",
+	})
+	assert.Nil(err)
+}
+
+func TestMiddleware_RedactsPersonalData(t *testing.T) {
+	assert := assert.New(t)
+
+	store := &memoryStore{}
+	dispatcher := newTestDispatcher(t, store)
+
+	_, err := dispatcher.Dispatch(context.Background(), &profileCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+		Email:        "ada@example.com",
+		Phone:        "+15555550100",
+		Birthday:     "1990-01-01",
+	})
+	assert.Nil(err)
+
+	if assert.Len(store.entries, 1) {
+		payload := store.entries[0].Payload
+		assert.NotContains(payload, "ada@example.com")
+		assert.NotContains(payload, "+15555550100")
+		assert.NotContains(payload, "1990-01-01")
+		assert.Contains(payload, `"email":"[redacted]"`)
+		assert.Contains(payload, `"name":""`)
+		assert.Contains(payload, `"ID":"agg-1"`)
+	}
+}
+
+func TestEntries_RequiresAggregateOrActor(t *testing.T) {
+	assert := assert.New(t)
+
+	store := &memoryStore{}
+	_, err := Entries(context.Background(), store, Query{})
+	assert.Equal(errQueryRequired, err)
+
+	_, errActor := Entries(context.Background(), store, Query{Actor: "admin-1"})
+	assert.Nil(errActor)
+	assert.Equal(defaultLimit, store.lastQuery.Limit)
+}
+
+/* ----- helpers ----- */
+type auditedCommand struct {
+	eventsource.CommandModel
+	Name string `validate:"required"`
+}
+
+type profileCommand struct {
+	eventsource.CommandModel
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+	Birthday string `json:"birthday"`
+}
+
+type auditedCommandHandler struct{}
+
+func (h *auditedCommandHandler) Handle(
+	ctx context.Context,
+	cmd eventsource.Command,
+) (*eventsource.CommandResult, error) {
+	if v, ok := cmd.(*auditedCommand); ok && v.Name == "fail" {
+		return nil, errors.New("handler failed")
+	}
+	return nil, nil
+}
+
+func (h *auditedCommandHandler) CommandsHandled() []eventsource.Command {
+	return []eventsource.Command{&auditedCommand{}, &profileCommand{}}
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	err       error
+	entries   []Entry
+	lastQuery Query
+}
+
+func (m *memoryStore) Record(ctx context.Context, entry Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryStore) Entries(ctx context.Context, query Query) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastQuery = query
+	return m.entries, nil
+}
+
+func newTestDispatcher(t *testing.T, store Store) eventsource.CommandDispatcher {
+	logger := zaptest.NewLogger(t)
+
+	dispatcher := eventsource.NewDispatcher(logger)
+	dispatcher.Use(
+		Middleware(store, logger),
+		eventsource.ValidationMiddleware(),
+	)
+	dispatcher.RegisterHandler(&auditedCommandHandler{})
+	return dispatcher
+}
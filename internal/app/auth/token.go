@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errSecretRequired = errors.New("token secret required")
+	errMalformedToken = errors.New("malformed token")
+	errSignature      = errors.New("invalid token signature")
+	errExpiredToken   = errors.New("token has expired")
+	errActorRequired  = errors.New("token has no actor")
+)
+
+// claims are the signed contents of a token
+type claims struct {
+	Actor     string `json:"sub"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Tokens issues and verifies the bearer tokens that identify actors. A
+// token is the base64 encoded claims and their HMAC-SHA256 signature,
+// joined by a dot
+type Tokens struct {
+	secret []byte
+}
+
+// NewTokens creates Tokens signed with secret
+func NewTokens(secret string) (*Tokens, error) {
+	if secret == "" {
+		return nil, errSecretRequired
+	}
+	return &Tokens{secret: []byte(secret)}, nil
+}
+
+// Issue signs a token for actor that expires at expiresAt
+func (t *Tokens) Issue(actor string, expiresAt time.Time) (string, error) {
+	if actor == "" {
+		return "", errActorRequired
+	}
+	payload, err := json.Marshal(claims{
+		Actor:     actor,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "unable to encode token claims")
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + t.sign(encoded), nil
+}
+
+// Verify returns the actor of token when its signature is valid and it
+// has not expired at now
+func (t *Tokens) Verify(token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return "", errMalformedToken
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(t.sign(parts[0]))) {
+		return "", errSignature
+	}
+
+	payload, errDecode := base64.RawURLEncoding.DecodeString(parts[0])
+	if errDecode != nil {
+		return "", errMalformedToken
+	}
+	var c claims
+	errUnmarshal := json.Unmarshal(payload, &c)
+	if errUnmarshal != nil {
+		return "", errMalformedToken
+	}
+
+	if c.Actor == "" {
+		return "", errActorRequired
+	}
+	if !now.Before(time.Unix(c.ExpiresAt, 0)) {
+		return "", errExpiredToken
+	}
+	return c.Actor, nil
+}
+
+func (t *Tokens) sign(encoded string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokens_IssueAndVerify(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	tokens, err := NewTokens("secret")
+	assert.Nil(err)
+
+	token, errIssue := tokens.Issue("admin", now.Add(time.Hour))
+	assert.Nil(errIssue)
+
+	actor, errVerify := tokens.Verify(token, now)
+	assert.Nil(errVerify)
+	assert.Equal("admin", actor)
+}
+
+func TestTokens_VerifyRejects(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	tokens, _ := NewTokens("secret")
+	others, _ := NewTokens("other secret")
+
+	valid, _ := tokens.Issue("user-1", now.Add(time.Hour))
+	expired, _ := tokens.Issue("user-1", now)
+	foreign, _ := others.Issue("admin", now.Add(time.Hour))
+	parts := strings.Split(valid, ".")
+	forged, _ := tokens.Issue("admin", now.Add(time.Hour))
+	forged = strings.Split(forged, ".")[0] + "." + parts[1]
+
+	tests := map[string]string{
+		"empty":            "",
+		"malformed":        "abc",
+		"expired":          expired,
+		"other secret":     foreign,
+		"swapped claims":   forged,
+		"tampered payload": "x" + valid,
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tokens.Verify(token, now)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestNewTokens_SecretRequired(t *testing.T) {
+	_, err := NewTokens("")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,48 @@
+package eventsource
+
+import "context"
+
+// Checkpoint records the last event version a projection has processed
+// for each event stream
+type Checkpoint struct {
+	// Projection is the name of the projection the checkpoint belongs to
+	Projection string
+
+	// Versions maps an aggregate id to the last version projected
+	Versions map[string]int
+}
+
+// NewCheckpoint creates an empty checkpoint for a projection
+func NewCheckpoint(projection string) *Checkpoint {
+	return &Checkpoint{
+		Projection: projection,
+		Versions:   make(map[string]int),
+	}
+}
+
+// Version returns the last version projected for an aggregate
+func (c *Checkpoint) Version(aggregateID string) int {
+	return c.Versions[aggregateID]
+}
+
+// Copy returns a copy of the checkpoint recorded under another projection name
+func (c *Checkpoint) Copy(projection string) *Checkpoint {
+	checkpoint := NewCheckpoint(projection)
+	for k, v := range c.Versions {
+		checkpoint.Versions[k] = v
+	}
+	return checkpoint
+}
+
+// CheckpointStore persists the position of projections
+type CheckpointStore interface {
+	// Load retrieves the checkpoint for a projection. A projection without
+	// a stored checkpoint returns an empty checkpoint
+	Load(ctx context.Context, projection string) (*Checkpoint, error)
+
+	// Save records the version processed for a single event stream
+	Save(ctx context.Context, projection, aggregateID string, version int) error
+
+	// Replace overwrites every stream version recorded for a projection
+	Replace(ctx context.Context, checkpoint *Checkpoint) error
+}
@@ -6,13 +6,62 @@ type Command interface {
 	AggregateID() string
 }
 
+// IdempotentCommand is implemented by commands that may carry an
+// idempotency key. A command dispatched again with a key that has already
+// been processed for its aggregate is not executed twice
+type IdempotentCommand interface {
+	Command
+
+	// IdempotencyKey returns the key of the command. Optional
+	IdempotencyKey() string
+}
+
 // CommandModel provides a composable struct that implements Command
 type CommandModel struct {
 	// ID contains the aggregate id
 	ID string
+
+	// CommandID contains the client supplied idempotency key. Optional
+	CommandID string `json:"commandId" validate:"max=128"`
 }
 
 // AggregateID implements the Command interface; returns the aggregate id
 func (m CommandModel) AggregateID() string {
 	return m.ID
 }
+
+// IdempotencyKey implements the IdempotentCommand interface
+func (m CommandModel) IdempotencyKey() string {
+	return m.CommandID
+}
+
+// SetIdempotencyKey sets the idempotency key of the command
+func (m *CommandModel) SetIdempotencyKey(key string) {
+	m.CommandID = key
+}
+
+// CommandResult describes the outcome of a handled command
+type CommandResult struct {
+	// AggregateID contains the id of the aggregate the command applied to
+	AggregateID string `json:"aggregateId"`
+
+	// Version is the aggregate version after the emitted events
+	Version int `json:"version"`
+
+	// EventTypes lists the types of the emitted events in order
+	EventTypes []string `json:"eventTypes"`
+}
+
+// NewCommandResult creates the CommandResult of the events emitted by a
+// command. The version is taken from the last event
+func NewCommandResult(aggregateID string, events []Event) *CommandResult {
+	result := &CommandResult{
+		AggregateID: aggregateID,
+		EventTypes:  make([]string, 0, len(events)),
+	}
+	for _, v := range events {
+		result.EventTypes = append(result.EventTypes, v.EventType)
+		result.Version = v.Version
+	}
+	return result
+}
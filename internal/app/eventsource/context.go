@@ -0,0 +1,94 @@
+package eventsource
+
+import (
+	"context"
+	"time"
+)
+
+// detachedContext carries the values of its parent context but is never
+// cancelled and has no deadline
+type detachedContext struct {
+	parent context.Context
+}
+
+// DetachContext returns a context that carries the values of ctx, such as
+// tracing and auth information, without its cancellation or deadline.
+// Work that outlives the request that triggered it should use a detached context
+func DetachContext(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+type commandIDKey struct{}
+
+// WithCommandID returns a context carrying the idempotency key of the
+// command being handled. Repositories record it on the events they save
+func WithCommandID(ctx context.Context, commandID string) context.Context {
+	return context.WithValue(ctx, commandIDKey{}, commandID)
+}
+
+// CommandIDFromContext returns the idempotency key of the command being
+// handled, if any
+func CommandIDFromContext(ctx context.Context) string {
+	commandID, _ := ctx.Value(commandIDKey{}).(string)
+	return commandID
+}
+
+// CommandSource describes what issued a command
+type CommandSource string
+
+// Sources commands can be issued from
+const (
+	CommandSourceGraphQL   CommandSource = "GraphQL"
+	CommandSourceSaga      CommandSource = "Saga"
+	CommandSourceScheduler CommandSource = "Scheduler"
+	CommandSourceSystem    CommandSource = "System"
+)
+
+type commandSourceKey struct{}
+
+// WithCommandSource returns a context recording what issues the commands
+// dispatched with it
+func WithCommandSource(ctx context.Context, source CommandSource) context.Context {
+	return context.WithValue(ctx, commandSourceKey{}, source)
+}
+
+// CommandSourceFromContext returns the source of the commands dispatched
+// with ctx. Commands without a source are issued by the system
+func CommandSourceFromContext(ctx context.Context) CommandSource {
+	source, ok := ctx.Value(commandSourceKey{}).(CommandSource)
+	if !ok {
+		return CommandSourceSystem
+	}
+	return source
+}
+
+type actorKey struct{}
+
+// WithActor returns a context recording the actor on whose behalf commands
+// are dispatched
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor on whose behalf commands are
+// dispatched, if any
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -2,6 +2,7 @@ package eventsource
 
 import (
 	"context"
+	"sort"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -10,25 +11,39 @@ import (
 var (
 	errMissingDispatchHandlerForCommand = errors.New("command does not have a registered command handler")
 	errBlankCommandAggID                = errors.New("all commands must have non-blank aggregate id")
+	errDuplicateCommandHandler          = errors.New("command already has a registered command handler")
 )
 
 type CommandHandler interface {
 	// Apply applies a command to an aggregate to generate a new set of events
-	Handle(context.Context, Command) error
+	Handle(context.Context, Command) (*CommandResult, error)
 
 	// CommandsHandled returns a list of commands the CommandHandler accepts
 	CommandsHandled() []Command
 }
 
 type CommandDispatcher interface {
-	Dispatch(context.Context, Command) error
-	RegisterHandler(CommandHandler)
+	Dispatch(context.Context, Command) (*CommandResult, error)
+
+	// RegisterHandler routes the commands handled by the CommandHandler
+	// to it. It fails without registering any command when one of them
+	// already has a handler
+	RegisterHandler(CommandHandler) error
+
+	// RegisteredCommands lists the registered commands and their
+	// handlers ordered by command type
+	RegisteredCommands() []CommandRegistration
+
+	// Use appends middlewares to the dispatch pipeline. Middlewares run
+	// in the order they are added, the first being the outermost
+	Use(...Middleware)
 }
 
 type dispatcher struct {
-	handlers map[string]CommandHandler
-	logger   *zap.Logger
-	sLogger  *zap.SugaredLogger
+	handlers    map[string]CommandHandler
+	middlewares []Middleware
+	logger      *zap.Logger
+	sLogger     *zap.SugaredLogger
 }
 
 func NewDispatcher(logger *zap.Logger) *dispatcher {
@@ -39,48 +54,95 @@ func NewDispatcher(logger *zap.Logger) *dispatcher {
 	}
 }
 
+// CommandRegistration describes the handler commands of a type are routed to
+type CommandRegistration struct {
+	// CommandType is the fully-qualified type name of the command
+	CommandType string `json:"commandType"`
+
+	// Handler is the fully-qualified type name of the handler
+	Handler string `json:"handler"`
+}
+
 type CommandDescriptor struct {
 	Ctx     context.Context
 	Command Command
 }
 
 /* ----- exported ----- */
-func (d *dispatcher) Dispatch(ctx context.Context, cmd Command) error {
+func (d *dispatcher) Dispatch(ctx context.Context, cmd Command) (*CommandResult, error) {
+	handle := d.handle
+	for i := len(d.middlewares) - 1; i >= 0; i-- {
+		handle = d.middlewares[i](handle)
+	}
+	return handle(ctx, cmd)
+}
+
+func (d *dispatcher) RegisterHandler(c CommandHandler) error {
+	commands := c.CommandsHandled()
+	for _, v := range commands {
+		typeName := CommandType(v)
+		if existing, ok := d.handlers[typeName]; ok {
+			return errors.Wrapf(
+				errDuplicateCommandHandler,
+				"%v is handled by %v, cannot register %v",
+				typeName,
+				qualifiedTypeOf(existing),
+				qualifiedTypeOf(c),
+			)
+		}
+	}
+
+	for _, v := range commands {
+		d.handlers[CommandType(v)] = c
+	}
+	return nil
+}
+
+func (d *dispatcher) RegisteredCommands() []CommandRegistration {
+	registrations := make([]CommandRegistration, 0, len(d.handlers))
+	for k, v := range d.handlers {
+		registrations = append(registrations, CommandRegistration{
+			CommandType: k,
+			Handler:     qualifiedTypeOf(v),
+		})
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].CommandType < registrations[j].CommandType
+	})
+	return registrations
+}
+
+func (d *dispatcher) Use(middlewares ...Middleware) {
+	d.middlewares = append(d.middlewares, middlewares...)
+}
+
+// handle is the innermost HandlerFunc of the dispatch pipeline
+func (d *dispatcher) handle(ctx context.Context, cmd Command) (*CommandResult, error) {
 	var operation Operation = "eventsource.dispatcher.dispatch"
 
 	aggregateID := cmd.AggregateID()
 	if IsStringEmpty(&aggregateID) {
-		return CommandErr(operation, errBlankCommandAggID, nil, cmd)
+		return nil, CommandErr(operation, errBlankCommandAggID, nil, cmd)
 	}
 
 	handler, errHandler := d.getHandler(cmd)
 	if errHandler != nil {
-		return CommandErr(operation, errHandler, nil, cmd)
+		return nil, CommandErr(operation, errHandler, nil, cmd)
 	}
 
-	err := handler.Handle(ctx, cmd)
+	result, err := handler.Handle(ctx, cmd)
 	if err != nil {
-		return wrapErr(err, nil, operation)
+		return nil, wrapErr(err, nil, operation)
 	}
-
-	d.logger.Info("command handled",
-		zap.String("command", typeOf(cmd)),
-		zap.String("aggregateId", cmd.AggregateID()),
-	)
-
-	return nil
-}
-
-func (d *dispatcher) RegisterHandler(c CommandHandler) {
-	commands := c.CommandsHandled()
-	for _, v := range commands {
-		typeName := typeOf(v)
-		d.handlers[typeName] = c
+	if result == nil {
+		result = NewCommandResult(aggregateID, nil)
 	}
+
+	return result, nil
 }
 
 func (d *dispatcher) getHandler(command Command) (CommandHandler, error) {
-	handler, ok := d.handlers[typeOf(command)]
+	handler, ok := d.handlers[CommandType(command)]
 	if !ok {
 		return nil, errMissingDispatchHandlerForCommand
 	}
@@ -15,7 +15,7 @@ func TestDispatch_BlankIDError(t *testing.T) {
 	assert := assert.New(t)
 
 	dispatcher := NewDispatcher(zaptest.NewLogger(t))
-	err := dispatcher.Dispatch(context.Background(), &mockCommand{
+	_, err := dispatcher.Dispatch(context.Background(), &mockCommand{
 		id: "",
 	})
 
@@ -29,7 +29,7 @@ func TestConnect_NoHandlerError(t *testing.T) {
 	command := &mockCommand{
 		id: "123123",
 	}
-	err := dispatcher.Dispatch(context.Background(), command)
+	_, err := dispatcher.Dispatch(context.Background(), command)
 
 	assert.EqualError(
 		errors.Cause(err),
@@ -44,7 +44,7 @@ func TestHandleDispatch(t *testing.T) {
 	dispatcher := NewDispatcher(zaptest.NewLogger(t))
 	dispatcher.RegisterHandler(commandHandler)
 
-	err := dispatcher.Dispatch(
+	result, err := dispatcher.Dispatch(
 		context.Background(),
 		&mockCommand{
 			id: "123123",
@@ -53,6 +53,11 @@ func TestHandleDispatch(t *testing.T) {
 
 	// Expect mocked functions to be called
 	assert.Nil(t, err)
+	assert.Equal(t, &CommandResult{
+		AggregateID: "123123",
+		Version:     1,
+		EventTypes:  []string{event1},
+	}, result)
 	repo.AssertExpectations(t)
 	commandHandler.AssertExpectations(t)
 }
@@ -66,7 +71,7 @@ func TestHandleDispatch_CommandHandlerError(t *testing.T) {
 	dispatcher := NewDispatcher(zaptest.NewLogger(t))
 	dispatcher.RegisterHandler(commandHandler)
 
-	err := dispatcher.Dispatch(
+	_, err := dispatcher.Dispatch(
 		context.Background(),
 		&mockCommand{
 			id: "123123",
@@ -79,6 +84,50 @@ func TestHandleDispatch_CommandHandlerError(t *testing.T) {
 	commandHandler.AssertExpectations(t)
 }
 
+func TestRegisterHandler_ConflictError(t *testing.T) {
+	assert := assert.New(t)
+
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	assert.Nil(dispatcher.RegisterHandler(newMockCommandHandler(nil)))
+
+	err := dispatcher.RegisterHandler(&panicCommandHandler{})
+	assert.Equal(errDuplicateCommandHandler, errors.Cause(err))
+	assert.Contains(err.Error(), qualifiedTypeOf(&panicCommandHandler{}))
+	assert.Len(dispatcher.RegisteredCommands(), 1)
+}
+
+func TestRegisteredCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	assert.Nil(dispatcher.RegisterHandler(newMockCommandHandler(nil)))
+	assert.Nil(dispatcher.RegisterHandler(newConcurrencyCommandHandler(0)))
+
+	assert.Equal([]CommandRegistration{
+		{
+			CommandType: pkgPath + ".mockCommand",
+			Handler:     pkgPath + ".mockCommandHandler",
+		},
+		{
+			CommandType: pkgPath + ".sequencedCommand",
+			Handler:     pkgPath + ".concurrencyCommandHandler",
+		},
+	}, dispatcher.RegisteredCommands())
+}
+
+func TestNewCommandResult(t *testing.T) {
+	result := NewCommandResult("123123", []Event{
+		*NewEvent("123123", event1, 3, nil),
+		*NewEvent("123123", event2, 4, nil),
+	})
+
+	assert.Equal(t, &CommandResult{
+		AggregateID: "123123",
+		Version:     4,
+		EventTypes:  []string{event1, event2},
+	}, result)
+}
+
 /* ----- repo ----- */
 type mockRepo struct {
 	mock.Mock
@@ -118,9 +167,16 @@ type mockCommandHandler struct {
 	mock.Mock
 }
 
-func (m *mockCommandHandler) Handle(ctx context.Context, command Command) error {
+func (m *mockCommandHandler) Handle(ctx context.Context, command Command) (*CommandResult, error) {
 	args := m.Called()
-	return args.Error(0)
+	if args.Error(0) != nil {
+		return nil, args.Error(0)
+	}
+	return &CommandResult{
+		AggregateID: command.AggregateID(),
+		Version:     1,
+		EventTypes:  []string{event1},
+	}, nil
 }
 
 func (m *mockCommandHandler) CommandsHandled() []Command {
@@ -132,6 +188,8 @@ func (m *mockCommandHandler) CommandsHandled() []Command {
 }
 
 /* ----- helpers ----- */
+var pkgPath = "github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+
 func newMockCommandHandler(returnedError error) *mockCommandHandler {
 	commandHandler := new(mockCommandHandler)
 	commandHandler.
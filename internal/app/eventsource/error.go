@@ -166,6 +166,44 @@ func AggregateNotFoundErr(
 	}
 }
 
+/* ----- concurrency conflict ----- */
+
+// concurrencyConflictError is returned by an EventStore when an event with
+// the same aggregate version has already been saved, i.e. the aggregate
+// changed since it was loaded
+type concurrencyConflictError struct {
+	AggregateID string
+	Version     int
+}
+
+func (e *concurrencyConflictError) Error() string {
+	return fmt.Sprintf(
+		"version %v of aggregate %v has already been saved",
+		e.Version,
+		e.AggregateID,
+	)
+}
+
+// Retryable implements the Retryable interface; the command succeeds once
+// it is handled against the current version of the aggregate
+func (e *concurrencyConflictError) Retryable() bool {
+	return true
+}
+
+func ConcurrencyConflictErr(aggregateID string, version int) error {
+	return &concurrencyConflictError{
+		AggregateID: aggregateID,
+		Version:     version,
+	}
+}
+
+// IsConcurrencyConflict indicates whether err was caused by saving an
+// aggregate version that already exists
+func IsConcurrencyConflict(err error) bool {
+	_, ok := errors.Cause(err).(*concurrencyConflictError)
+	return ok
+}
+
 /* ----- invalid payload ----- */
 type invalidPayloadErr struct {
 	ErrorBase
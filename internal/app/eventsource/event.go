@@ -30,24 +30,35 @@ type EventStore interface {
 
 	// Load retrives event records from the store and returns them in ASC order
 	Load(ctx context.Context, aggregateID string, fromVersion int) (History, error)
+
+	// LoadByTypes retrieves the event records of eventTypes from the store
+	// in the order they occurred
+	LoadByTypes(ctx context.Context, eventTypes ...string) (History, error)
+
+	// LoadByCommandID retrieves the events of an aggregate that were
+	// emitted by the command with the given id in ASC order
+	LoadByCommandID(ctx context.Context, aggregateID string, commandID string) (History, error)
 }
 
 // Event contains data related to a single event
 type Event struct {
 	// AggregateID returns the id of the aggregate referenced by the event
-	AggregateID string
+	AggregateID string `firestore:"aggregateId"`
 
 	// Event type describes the type of event that occurred
-	EventType string
+	EventType string `firestore:"eventType"`
 
 	// Version contains the version number of this event
-	Version int
+	Version int `firestore:"version"`
 
 	// At indicates when the event occurred
-	EventAt time.Time
+	EventAt time.Time `firestore:"at"`
 
 	// Data contains extra serialized data related to the specific event. Optional
-	Payload *string
+	Payload *string `firestore:"payload"`
+
+	// CommandID contains the idempotency key of the command that emitted the event. Optional
+	CommandID string `firestore:"commandId"`
 }
 
 // NewEvent creates a new event model. Events are the models to be applied to an Aggregate
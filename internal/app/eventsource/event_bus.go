@@ -28,19 +28,40 @@ type EventHandler interface {
 }
 
 type EventBus interface {
-	Publish([]Event) error
+	// Publish delivers events to the registered handlers. Handlers receive
+	// the values carried by ctx but not its cancellation
+	Publish(context.Context, []Event) error
 	RegisterHandler(EventHandler)
 }
 
+var defaultHandlerTimeout = 5 * time.Second
+
 // TODO: Add tests for event bus
 type eventBus struct {
-	backoffConfig *config.EventBusBackoffConfig
-	sLogger       *zap.SugaredLogger
-	logger        *zap.Logger
-	handlers      map[string][]EventHandler
+	backoffConfig  *config.EventBusBackoffConfig
+	handlerTimeout time.Duration
+	transport      Transport
+	sLogger        *zap.SugaredLogger
+	logger         *zap.Logger
+	handlers       map[string][]EventHandler
 }
 
+// NewEventBus creates an EventBus that delivers events within the process
 func NewEventBus(logger *zap.Logger, configReader *config.Reader) EventBus {
+	return NewEventBusWithTransport(
+		logger,
+		configReader,
+		NewInProcessTransport(),
+	)
+}
+
+// NewEventBusWithTransport creates an EventBus that delivers events
+// through the given Transport
+func NewEventBusWithTransport(
+	logger *zap.Logger,
+	configReader *config.Reader,
+	transport Transport,
+) EventBus {
 	backoffConfig, err := configReader.EventBusBackoffConfig()
 	if err != nil {
 		backoffConfig = &config.EventBusBackoffConfig{
@@ -49,17 +70,30 @@ func NewEventBus(logger *zap.Logger, configReader *config.Reader) EventBus {
 			InitialIntervalMillis: 300,
 		}
 	}
-	return &eventBus{
-		backoffConfig: backoffConfig,
-		sLogger:       logger.Sugar(),
-		logger:        logger,
-		handlers:      make(map[string][]EventHandler),
+	handlerTimeout, errTimeout := configReader.EventBusHandlerTimeout()
+	if errTimeout != nil {
+		handlerTimeout = defaultHandlerTimeout
+	}
+	bus := &eventBus{
+		backoffConfig:  backoffConfig,
+		handlerTimeout: handlerTimeout,
+		transport:      transport,
+		sLogger:        logger.Sugar(),
+		logger:         logger,
+		handlers:       make(map[string][]EventHandler),
 	}
+	transport.Subscribe(bus.receive)
+
+	return bus
 }
 
-func (e *eventBus) Publish(events []Event) error {
+func (e *eventBus) Publish(ctx context.Context, events []Event) error {
 	var op Operation = "eventsource.Publish"
 
+	// Handlers must be able to finish even if the publisher's
+	// request is cancelled once the events are persisted
+	detachedCtx := DetachContext(ctx)
+
 	for _, event := range events {
 		if event.AggregateID == "" {
 			return EventErr(
@@ -69,16 +103,23 @@ func (e *eventBus) Publish(events []Event) error {
 				event,
 			)
 		}
+	}
 
-		err := e.handleEvent(event)
-		if err != nil {
-			return EventErr(
-				op,
-				err,
-				StringToPointer("failed to handle event"),
-				event,
-			)
-		}
+	return e.transport.Send(detachedCtx, events)
+}
+
+// receive handles an event delivered by the transport
+func (e *eventBus) receive(ctx context.Context, event Event) error {
+	var op Operation = "eventsource.receive"
+
+	err := e.handleEvent(ctx, event)
+	if err != nil {
+		return EventErr(
+			op,
+			err,
+			StringToPointer("failed to handle event"),
+			event,
+		)
 	}
 	return nil
 }
@@ -88,7 +129,7 @@ func (e *eventBus) RegisterHandler(handler EventHandler) {
 	for _, eventType := range eventTypes {
 		if v, ok := e.handlers[eventType]; ok {
 			e.handlers[eventType] = append(v, handler)
-			return
+			continue
 		}
 		e.handlers[eventType] = []EventHandler{
 			handler,
@@ -110,7 +151,7 @@ func (e *eventBus) getHandlersByEvent(event Event) ([]EventHandler, error) {
 	)
 }
 
-func (e *eventBus) handleEvent(event Event) error {
+func (e *eventBus) handleEvent(ctx context.Context, event Event) error {
 	handlers, errHandler := e.getHandlersByEvent(event)
 	if errHandler != nil || len(handlers) == 0 {
 		return errHandler
@@ -123,7 +164,7 @@ func (e *eventBus) handleEvent(event Event) error {
 	var wg sync.WaitGroup
 	for _, v := range handlers {
 		wg.Add(1)
-		go e.tryHandleEvent(v, event, errChan, &wg)
+		go e.tryHandleEvent(ctx, v, event, errChan, &wg)
 	}
 	wg.Wait()
 	close(errChan)
@@ -144,12 +185,17 @@ func (e *eventBus) newBackOff() *backoff.ExponentialBackOff {
 }
 
 func (e *eventBus) backoffOperationWithEvent(
+	ctx context.Context,
 	retryCh chan<- error,
 	handler EventHandler,
 	event Event,
 ) backoff.Operation {
 	return func() error {
-		errHandle := handler.Handle(context.Background(), event)
+		// Each attempt gets its own deadline
+		handlerCtx, cancel := context.WithTimeout(ctx, e.handlerTimeout)
+		defer cancel()
+
+		errHandle := handler.Handle(handlerCtx, event)
 		if errHandle != nil {
 			if len(retryCh) < e.backoffConfig.MaxRetry {
 				retryCh <- errHandle
@@ -163,6 +209,7 @@ func (e *eventBus) backoffOperationWithEvent(
 }
 
 func (e *eventBus) tryHandleEvent(
+	ctx context.Context,
 	handler EventHandler,
 	event Event,
 	out chan<- error,
@@ -170,7 +217,7 @@ func (e *eventBus) tryHandleEvent(
 ) {
 	defer wg.Done()
 	retryCh := make(chan error, e.backoffConfig.MaxRetry)
-	operation := e.backoffOperationWithEvent(retryCh, handler, event)
+	operation := e.backoffOperationWithEvent(ctx, retryCh, handler, event)
 
 	notify := func(err error, time time.Duration) {
 		wrappedError := errors.Wrap(
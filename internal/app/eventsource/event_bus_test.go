@@ -11,14 +11,17 @@ import (
 	"go.uber.org/zap/zaptest"
 )
 
-var event1 = "event1"
+var (
+	event1 = "event1"
+	event2 = "event2"
+)
 
 /* ----- tests ----- */
 func TestEventBus_BlankIDError(t *testing.T) {
 	assert := assert.New(t)
 
 	eventBus := NewEventBus(zaptest.NewLogger(t), config.NewReader())
-	err := eventBus.Publish([]Event{
+	err := eventBus.Publish(context.Background(), []Event{
 		*NewEvent("", event1, 1, nil),
 	})
 
@@ -30,7 +33,7 @@ func TestEventBus_NoHandlersError(t *testing.T) {
 
 	event := *NewEvent("abc123", event1, 1, nil)
 	eventBus := NewEventBus(zaptest.NewLogger(t), config.NewReader())
-	err := eventBus.Publish([]Event{
+	err := eventBus.Publish(context.Background(), []Event{
 		event,
 	})
 
@@ -44,7 +47,7 @@ func TestEventBus_HandlePublish(t *testing.T) {
 	eventBus := NewEventBus(zaptest.NewLogger(t), config.NewReader())
 	eventBus.RegisterHandler(eventHandler)
 
-	err := eventBus.Publish([]Event{
+	err := eventBus.Publish(context.Background(), []Event{
 		event,
 	})
 
@@ -53,7 +56,78 @@ func TestEventBus_HandlePublish(t *testing.T) {
 	eventHandler.AssertExpectations(t)
 }
 
+func TestEventBus_RegisterSharedEventTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	eventBus := NewEventBus(zaptest.NewLogger(t), config.NewReader())
+	eventBus.RegisterHandler(newMockEventHandler(nil, nil))
+
+	// The second handler shares event1 but must still be registered for event2
+	secondHandler := &contextRecordingEventHandler{
+		eventTypes: []string{event1, "event2"},
+	}
+	eventBus.RegisterHandler(secondHandler)
+
+	err := eventBus.Publish(context.Background(), []Event{
+		*NewEvent("abc123", "event2", 1, nil),
+	})
+
+	assert.Nil(err)
+	assert.NotNil(secondHandler.ctx)
+}
+
+func TestEventBus_PublishPropagatesContextValues(t *testing.T) {
+	assert := assert.New(t)
+
+	type contextKey string
+	key := contextKey("requestId")
+	ctx, cancel := context.WithCancel(
+		context.WithValue(context.Background(), key, "req-1"),
+	)
+	// Handlers must not observe the publisher's cancellation
+	cancel()
+
+	eventHandler := &contextRecordingEventHandler{}
+	eventBus := NewEventBus(zaptest.NewLogger(t), config.NewReader())
+	eventBus.RegisterHandler(eventHandler)
+
+	err := eventBus.Publish(ctx, []Event{
+		*NewEvent("abc123", event1, 1, nil),
+	})
+
+	assert.Nil(err)
+	assert.Equal("req-1", eventHandler.ctx.Value(key))
+	assert.Nil(eventHandler.err)
+	_, hasDeadline := eventHandler.ctx.Deadline()
+	assert.True(hasDeadline)
+}
+
 /* ----- event handler ----- */
+type contextRecordingEventHandler struct {
+	ctx        context.Context
+	err        error
+	eventTypes []string
+}
+
+func (c *contextRecordingEventHandler) Handle(ctx context.Context, event Event) error {
+	c.ctx = ctx
+	c.err = ctx.Err()
+	return nil
+}
+
+func (c *contextRecordingEventHandler) Sync(ctx context.Context, aggregateID string) error {
+	return nil
+}
+
+func (c *contextRecordingEventHandler) EventTypesHandled() []string {
+	if c.eventTypes != nil {
+		return c.eventTypes
+	}
+	return []string{
+		event1,
+	}
+}
+
 type mockEventHandler struct {
 	mock.Mock
 }
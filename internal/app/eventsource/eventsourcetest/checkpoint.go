@@ -0,0 +1,64 @@
+package eventsourcetest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// MemoryCheckpointStore is a CheckpointStore that keeps checkpoints in memory
+type MemoryCheckpointStore struct {
+	mu       sync.Mutex
+	versions map[string]map[string]int
+}
+
+// NewMemoryCheckpointStore creates an empty MemoryCheckpointStore
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{
+		versions: make(map[string]map[string]int),
+	}
+}
+
+func (m *MemoryCheckpointStore) Load(
+	ctx context.Context,
+	projection string,
+) (*eventsource.Checkpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	checkpoint := eventsource.NewCheckpoint(projection)
+	for k, v := range m.versions[projection] {
+		checkpoint.Versions[k] = v
+	}
+	return checkpoint, nil
+}
+
+func (m *MemoryCheckpointStore) Save(
+	ctx context.Context,
+	projection, aggregateID string,
+	version int,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.versions[projection]; !ok {
+		m.versions[projection] = make(map[string]int)
+	}
+	m.versions[projection][aggregateID] = version
+	return nil
+}
+
+func (m *MemoryCheckpointStore) Replace(
+	ctx context.Context,
+	checkpoint *eventsource.Checkpoint,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.versions[checkpoint.Projection] = make(map[string]int)
+	for k, v := range checkpoint.Versions {
+		m.versions[checkpoint.Projection][k] = v
+	}
+	return nil
+}
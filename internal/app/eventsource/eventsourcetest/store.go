@@ -0,0 +1,102 @@
+// Package eventsourcetest provides in-memory eventsource doubles shared by
+// the tests of the aggregates
+package eventsourcetest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// MemoryStore is an EventStore that keeps events in memory. Like the
+// Firestore store, it rejects events of a version that has already been
+// saved with a concurrency conflict
+type MemoryStore struct {
+	mu     sync.Mutex
+	events eventsource.History
+}
+
+// NewMemoryStore creates a MemoryStore holding history
+func NewMemoryStore(history ...eventsource.Event) *MemoryStore {
+	return &MemoryStore{
+		events: append(eventsource.History{}, history...),
+	}
+}
+
+func (m *MemoryStore) Save(ctx context.Context, events ...eventsource.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, v := range events {
+		for _, saved := range m.events {
+			if saved.AggregateID == v.AggregateID && saved.Version == v.Version {
+				return eventsource.ConcurrencyConflictErr(v.AggregateID, v.Version)
+			}
+		}
+	}
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *MemoryStore) Load(
+	ctx context.Context,
+	aggregateID string,
+	afterVersion int,
+) (eventsource.History, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := eventsource.History{}
+	for _, v := range m.events {
+		if v.AggregateID == aggregateID && v.Version > afterVersion {
+			history = append(history, v)
+		}
+	}
+	return history, nil
+}
+
+func (m *MemoryStore) LoadByTypes(
+	ctx context.Context,
+	eventTypes ...string,
+) (eventsource.History, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := eventsource.History{}
+	for _, v := range m.events {
+		for _, eventType := range eventTypes {
+			if v.EventType == eventType {
+				history = append(history, v)
+				break
+			}
+		}
+	}
+	return history, nil
+}
+
+func (m *MemoryStore) LoadByCommandID(
+	ctx context.Context,
+	aggregateID string,
+	commandID string,
+) (eventsource.History, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := eventsource.History{}
+	for _, v := range m.events {
+		if v.AggregateID == aggregateID && v.CommandID == commandID {
+			history = append(history, v)
+		}
+	}
+	return history, nil
+}
+
+// NopEventBus is an EventBus that drops published events
+type NopEventBus struct {
+	eventsource.EventBus
+}
+
+func (b *NopEventBus) Publish(ctx context.Context, events []eventsource.Event) error {
+	return nil
+}
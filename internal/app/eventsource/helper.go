@@ -16,11 +16,30 @@ func typeOf(i interface{}) string {
 	return reflect.TypeOf(i).Elem().Name()
 }
 
+// qualifiedTypeOf returns the name of a type prefixed with its package
+// path, e.g. github.com/org/repo/pkg.Type. Pointers are dereferenced
+func qualifiedTypeOf(i interface{}) string {
+	t := reflect.TypeOf(i)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
 // NewUUID returns a new v4 uuid as a string
 func NewUUID() string {
 	return uuid.New().String()
 }
 
+// NewUUIDFromKey returns a v5 uuid derived from key as a string. The same
+// key always yields the same uuid
+func NewUUIDFromKey(key string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(key)).String()
+}
+
 // Int returns a pointer to int.
 //
 // There are a number of places where a pointer to int
@@ -79,6 +98,14 @@ func StringToPointer(str string) *string {
 	return &str
 }
 
+// StringValue returns the value of a string pointer or an empty string
+func StringValue(str *string) string {
+	if str == nil {
+		return ""
+	}
+	return *str
+}
+
 // switch v := i.(type) {
 // case *int, *int32, *int64, *uint32, *float32, *float64:
 // 	return v == nil || *v == 0
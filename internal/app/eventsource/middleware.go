@@ -0,0 +1,175 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var errCommandPanicked = errors.New("command handler panicked")
+
+// HandlerFunc dispatches a command to its CommandHandler
+type HandlerFunc func(ctx context.Context, cmd Command) (*CommandResult, error)
+
+// Middleware wraps a HandlerFunc to add behaviour around every dispatched
+// command
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Validator is implemented by commands that can check their own fields
+// before they are handled
+type Validator interface {
+	Validate() error
+}
+
+// TimingFunc receives the time taken to dispatch a command
+type TimingFunc func(command string, elapsed time.Duration, err error)
+
+// LoggingMiddleware logs the outcome of every dispatched command
+func LoggingMiddleware(logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (*CommandResult, error) {
+			result, err := next(ctx, cmd)
+			if err != nil {
+				logger.Error("command failed",
+					zap.Error(err),
+					zap.String("command", typeOf(cmd)),
+					zap.String("aggregateId", cmd.AggregateID()),
+				)
+				return nil, err
+			}
+
+			logger.Info("command handled",
+				zap.String("command", typeOf(cmd)),
+				zap.String("aggregateId", cmd.AggregateID()),
+				zap.Int("version", result.Version),
+			)
+			return result, nil
+		}
+	}
+}
+
+// TimingMiddleware reports how long every dispatched command took
+func TimingMiddleware(record TimingFunc) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (*CommandResult, error) {
+			start := time.Now()
+			result, err := next(ctx, cmd)
+			record(typeOf(cmd), time.Since(start), err)
+			return result, err
+		}
+	}
+}
+
+// RecoveryMiddleware turns a panic in a command handler into an error
+func RecoveryMiddleware(logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (result *CommandResult, err error) {
+			var operation Operation = "eventsource.RecoveryMiddleware"
+
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				logger.Error("recovered from command handler panic",
+					zap.String("panic", fmt.Sprintf("%v", r)),
+					zap.String("command", typeOf(cmd)),
+					zap.Stack("stack"),
+				)
+				err = CommandErr(
+					operation,
+					errors.Wrapf(errCommandPanicked, "%v", r),
+					nil,
+					cmd,
+				)
+			}()
+
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// ValidationMiddleware rejects commands that fail the rules declared in
+// their validate struct tags or, for commands implementing Validator,
+// whose Validate method returns an error
+func ValidationMiddleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (*CommandResult, error) {
+			var operation Operation = "eventsource.ValidationMiddleware"
+
+			err := Validate(cmd)
+			if err != nil {
+				return nil, CommandErr(operation, err, nil, cmd)
+			}
+
+			if v, ok := cmd.(Validator); ok {
+				errValidate := v.Validate()
+				if errValidate != nil {
+					return nil, CommandErr(operation, errValidate, nil, cmd)
+				}
+			}
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// IdempotencyMiddleware returns the original result of commands whose
+// idempotency key has already been processed for their aggregate instead
+// of executing them again. The key of new commands is carried in the
+// context so that it is recorded on the emitted events.
+//
+// Concurrent duplicates of the same command conflict on the aggregate
+// version; run under ConflictRetryMiddleware, the retried duplicate
+// returns the result of the first
+func IdempotencyMiddleware(store EventStore) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (*CommandResult, error) {
+			var operation Operation = "eventsource.IdempotencyMiddleware"
+
+			// The key of a command must not leak into the commands it
+			// triggers, e.g. through sagas
+			v, ok := cmd.(IdempotentCommand)
+			if !ok || v.IdempotencyKey() == "" {
+				return next(WithCommandID(ctx, ""), cmd)
+			}
+
+			history, err := store.LoadByCommandID(
+				ctx,
+				cmd.AggregateID(),
+				v.IdempotencyKey(),
+			)
+			if err != nil {
+				return nil, CommandErr(
+					operation,
+					err,
+					StringToPointer("unable to load events for command id"),
+					cmd,
+				)
+			}
+			if len(history) > 0 {
+				return NewCommandResult(cmd.AggregateID(), history), nil
+			}
+
+			return next(WithCommandID(ctx, v.IdempotencyKey()), cmd)
+		}
+	}
+}
+
+// ConflictRetryMiddleware handles commands again, up to attempts times in
+// total, when saving their events conflicts with a concurrent change of
+// the aggregate. Handlers load the aggregate again so that their checks,
+// e.g. the balance of a redemption, see the concurrent change
+func ConflictRetryMiddleware(attempts int) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (*CommandResult, error) {
+			result, err := next(ctx, cmd)
+			for i := 1; i < attempts && IsConcurrencyConflict(err); i++ {
+				result, err = next(ctx, cmd)
+			}
+			return result, err
+		}
+	}
+}
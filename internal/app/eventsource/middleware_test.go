@@ -0,0 +1,208 @@
+package eventsource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestDispatcher_MiddlewareOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	calls := []string{}
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, cmd Command) (*CommandResult, error) {
+				calls = append(calls, name+":before")
+				result, err := next(ctx, cmd)
+				calls = append(calls, name+":after")
+				return result, err
+			}
+		}
+	}
+
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	dispatcher.RegisterHandler(newMockCommandHandler(nil))
+	dispatcher.Use(record("first"), record("second"))
+
+	_, err := dispatcher.Dispatch(context.Background(), &mockCommand{id: "123123"})
+
+	assert.Nil(err)
+	assert.Equal(
+		[]string{"first:before", "second:before", "second:after", "first:after"},
+		calls,
+	)
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	dispatcher.RegisterHandler(&panicCommandHandler{})
+	dispatcher.Use(RecoveryMiddleware(zaptest.NewLogger(t)))
+
+	_, err := dispatcher.Dispatch(context.Background(), &mockCommand{id: "123123"})
+
+	assert.Equal(errCommandPanicked, errors.Cause(err))
+}
+
+func TestValidationMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	errInvalid := errors.New("invalid command")
+
+	commandHandler := newMockCommandHandler(nil)
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	dispatcher.RegisterHandler(commandHandler)
+	dispatcher.Use(ValidationMiddleware())
+
+	_, err := dispatcher.Dispatch(
+		context.Background(),
+		&validatingCommand{id: "123123", err: errInvalid},
+	)
+
+	assert.EqualError(errors.Cause(err), errInvalid.Error())
+	commandHandler.AssertNotCalled(t, "Handle")
+}
+
+func TestTimingMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	var (
+		recordedCommand string
+		recordedErr     error
+	)
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	dispatcher.Use(TimingMiddleware(
+		func(command string, elapsed time.Duration, err error) {
+			recordedCommand = command
+			recordedErr = err
+		},
+	))
+
+	_, err := dispatcher.Dispatch(context.Background(), &mockCommand{id: "123123"})
+
+	assert.Equal("mockCommand", recordedCommand)
+	assert.Equal(err, recordedErr)
+	assert.EqualError(errors.Cause(err), errMissingDispatchHandlerForCommand.Error())
+}
+
+func TestIdempotencyMiddleware_ReturnsOriginalResult(t *testing.T) {
+	assert := assert.New(t)
+
+	store := new(mockEventStore)
+	store.
+		On("LoadByCommandID", "123123", "key-1").
+		Return(History{*NewEvent("123123", event1, 4, nil)}, nil)
+
+	commandHandler := newMockCommandHandler(nil)
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	dispatcher.RegisterHandler(commandHandler)
+	dispatcher.Use(IdempotencyMiddleware(store))
+
+	result, err := dispatcher.Dispatch(
+		context.Background(),
+		&idempotentCommand{CommandModel{ID: "123123", CommandID: "key-1"}},
+	)
+
+	assert.Nil(err)
+	assert.Equal(4, result.Version)
+	assert.Equal([]string{event1}, result.EventTypes)
+	commandHandler.AssertNotCalled(t, "Handle")
+}
+
+func TestIdempotencyMiddleware_CarriesCommandID(t *testing.T) {
+	assert := assert.New(t)
+
+	store := new(mockEventStore)
+	store.On("LoadByCommandID", "123123", "key-1").Return(History{}, nil)
+
+	commandIDs := []string{}
+	middleware := IdempotencyMiddleware(store)
+	handle := middleware(func(ctx context.Context, cmd Command) (*CommandResult, error) {
+		commandIDs = append(commandIDs, CommandIDFromContext(ctx))
+		return nil, nil
+	})
+
+	ctx := WithCommandID(context.Background(), "parent-key")
+	_, errKey := handle(ctx, &idempotentCommand{CommandModel{ID: "123123", CommandID: "key-1"}})
+	_, errNoKey := handle(ctx, &idempotentCommand{CommandModel{ID: "123123"}})
+
+	assert.Nil(errKey)
+	assert.Nil(errNoKey)
+	assert.Equal([]string{"key-1", ""}, commandIDs)
+	store.AssertExpectations(t)
+}
+
+/* ----- command ----- */
+func TestConflictRetryMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "no conflict", conflicts: 0, wantCalls: 1},
+		{name: "conflict then success", conflicts: 2, wantCalls: 3},
+		{name: "too many conflicts", conflicts: 5, wantCalls: 3, wantErr: true},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			calls := 0
+			handle := ConflictRetryMiddleware(3)(
+				func(ctx context.Context, cmd Command) (*CommandResult, error) {
+					calls++
+					if calls <= v.conflicts {
+						return nil, errors.Wrap(
+							ConcurrencyConflictErr(cmd.AggregateID(), 2),
+							"unable to save events",
+						)
+					}
+					return NewCommandResult(cmd.AggregateID(), nil), nil
+				},
+			)
+
+			_, err := handle(context.Background(), &mockCommand{id: "123123"})
+			assert.Equal(v.wantCalls, calls)
+			assert.Equal(v.wantErr, IsConcurrencyConflict(err))
+			assert.Equal(v.wantErr, IsRetryable(err))
+		})
+	}
+}
+
+type validatingCommand struct {
+	id  string
+	err error
+}
+
+func (c *validatingCommand) AggregateID() string {
+	return c.id
+}
+
+func (c *validatingCommand) Validate() error {
+	return c.err
+}
+
+type idempotentCommand struct {
+	CommandModel
+}
+
+/* ----- command handler ----- */
+type panicCommandHandler struct{}
+
+func (h *panicCommandHandler) Handle(ctx context.Context, command Command) (*CommandResult, error) {
+	panic("handler exploded")
+}
+
+func (h *panicCommandHandler) CommandsHandled() []Command {
+	return []Command{
+		&mockCommand{},
+	}
+}
@@ -0,0 +1,313 @@
+package eventsource
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Projection applies events to a read model
+type Projection interface {
+	// Name uniquely identifies the projection and its checkpoint
+	Name() string
+
+	// EventTypesHandled returns a list of events the Projection accepts
+	EventTypesHandled() []string
+
+	// Project applies a single event to the read model
+	Project(context.Context, Event) error
+}
+
+// Projector is an EventHandler that tracks the progress of a Projection
+// with a checkpoint so that it can resume from the stored position
+type Projector interface {
+	EventHandler
+
+	// CatchUp projects the events of the types the projection handles
+	// that were recorded in the event store after the stored checkpoint
+	CatchUp(ctx context.Context) error
+
+	// Replace swaps the projector's checkpoint for one produced elsewhere,
+	// such as by a rebuild. The swap function runs while live events are
+	// held back and receives the outgoing checkpoint so that the read model
+	// and checkpoint change together. The replacement is stored before swap
+	// runs so that other instances reloading after the swap find it, and the
+	// outgoing checkpoint is restored if swap fails
+	Replace(
+		ctx context.Context,
+		checkpoint *Checkpoint,
+		swap func(ctx context.Context, previous *Checkpoint) error,
+	) error
+
+	// Reload discards the checkpoint held in memory and loads it from the
+	// store, such as after another instance replaced it
+	Reload(ctx context.Context) error
+}
+
+// Progress describes how far a projector has caught up
+type Progress struct {
+	Projection string
+	Processed  int
+	Total      int
+}
+
+// ProgressFunc receives progress updates while a projector catches up
+type ProgressFunc func(Progress)
+
+type projector struct {
+	projection  Projection
+	store       EventStore
+	checkpoints CheckpointStore
+	logger      *zap.Logger
+	progress    ProgressFunc
+
+	mu         sync.Mutex
+	checkpoint *Checkpoint
+}
+
+// ProjectorParams represent the params needed to instantiate a new projector
+type ProjectorParams struct {
+	Projection  Projection
+	Store       EventStore
+	Checkpoints CheckpointStore
+	Logger      *zap.Logger
+
+	// Progress is notified after each event projected by CatchUp. Optional
+	Progress ProgressFunc
+}
+
+// NewProjector creates a new instance of a Projector
+func NewProjector(p ProjectorParams) Projector {
+	return &projector{
+		projection:  p.Projection,
+		store:       p.Store,
+		checkpoints: p.Checkpoints,
+		logger:      p.Logger,
+		progress:    p.Progress,
+	}
+}
+
+// EventTypesHandled implements the EventHandler interface
+func (p *projector) EventTypesHandled() []string {
+	return p.projection.EventTypesHandled()
+}
+
+// Handle implements the EventHandler interface. Events that have already
+// been projected are skipped and gaps in a stream are filled from the store.
+// The checkpoint only records the events that were applied, so a stream
+// whose last events were of types the projection ignores is synced too
+func (p *projector) Handle(ctx context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	checkpoint, err := p.loadCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	lastVersion := checkpoint.Version(event.AggregateID)
+	if event.Version <= lastVersion {
+		return nil
+	}
+	if event.Version > lastVersion+1 {
+		return p.syncStream(ctx, event.AggregateID, lastVersion)
+	}
+
+	return p.project(ctx, event)
+}
+
+// Sync implements the EventHandler interface; projects the events of a
+// single stream recorded after the stored checkpoint
+func (p *projector) Sync(ctx context.Context, aggregateID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	checkpoint, err := p.loadCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	return p.syncStream(ctx, aggregateID, checkpoint.Version(aggregateID))
+}
+
+// CatchUp implements the Projector interface
+func (p *projector) CatchUp(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	checkpoint, err := p.loadCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	history, errHistory := p.store.LoadByTypes(ctx, p.projection.EventTypesHandled()...)
+	if errHistory != nil {
+		return errors.Wrap(errHistory, "unable to load event history")
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].EventAt.Before(history[j].EventAt)
+	})
+
+	pending := History{}
+	for _, v := range history {
+		if v.Version > checkpoint.Version(v.AggregateID) {
+			pending = append(pending, v)
+		}
+	}
+
+	for i, v := range pending {
+		errProject := p.project(ctx, v)
+		if errProject != nil {
+			return errProject
+		}
+		p.notifyProgress(i+1, len(pending))
+	}
+
+	p.logger.Info(
+		"projection caught up",
+		zap.String("projection", p.projection.Name()),
+		zap.Int("count", len(pending)),
+	)
+
+	return nil
+}
+
+// Replace implements the Projector interface
+func (p *projector) Replace(
+	ctx context.Context,
+	checkpoint *Checkpoint,
+	swap func(ctx context.Context, previous *Checkpoint) error,
+) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	previous, err := p.loadCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	replacement := checkpoint.Copy(p.projection.Name())
+	errReplace := p.checkpoints.Replace(ctx, replacement)
+	if errReplace != nil {
+		return errors.Wrap(errReplace, "unable to replace projection checkpoint")
+	}
+
+	errSwap := swap(ctx, previous.Copy(previous.Projection))
+	if errSwap != nil {
+		errRestore := p.checkpoints.Replace(ctx, previous)
+		if errRestore != nil {
+			p.logger.Error(
+				"unable to restore projection checkpoint",
+				zap.String("projection", p.projection.Name()),
+				zap.Error(errRestore),
+			)
+		}
+		return errSwap
+	}
+
+	p.checkpoint = replacement
+	return nil
+}
+
+// Reload implements the Projector interface
+func (p *projector) Reload(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.checkpoint = nil
+	_, err := p.loadCheckpoint(ctx)
+	return err
+}
+
+func (p *projector) syncStream(
+	ctx context.Context,
+	aggregateID string,
+	afterVersion int,
+) error {
+	p.logger.Info(
+		"syncing projection",
+		zap.String("projection", p.projection.Name()),
+		zap.String("aggregateId", aggregateID),
+		zap.Int("afterVersion", afterVersion),
+	)
+
+	history, err := p.store.Load(ctx, aggregateID, afterVersion)
+	if err != nil {
+		return errors.Wrap(err, "unable to load aggregate history")
+	}
+
+	for _, v := range history {
+		errProject := p.project(ctx, v)
+		if errProject != nil {
+			return errProject
+		}
+	}
+
+	return nil
+}
+
+// project applies the event and advances the checkpoint. Events the
+// projection does not handle are skipped without saving the checkpoint
+func (p *projector) project(ctx context.Context, event Event) error {
+	if !p.handles(event.EventType) {
+		return nil
+	}
+
+	errProject := p.projection.Project(ctx, event)
+	if errProject != nil {
+		return errProject
+	}
+
+	errSave := p.checkpoints.Save(
+		ctx,
+		p.projection.Name(),
+		event.AggregateID,
+		event.Version,
+	)
+	if errSave != nil {
+		return errors.Wrap(errSave, "unable to save projection checkpoint")
+	}
+
+	p.checkpoint.Versions[event.AggregateID] = event.Version
+	return nil
+}
+
+func (p *projector) loadCheckpoint(ctx context.Context) (*Checkpoint, error) {
+	if p.checkpoint != nil {
+		return p.checkpoint, nil
+	}
+
+	checkpoint, err := p.checkpoints.Load(ctx, p.projection.Name())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load projection checkpoint")
+	}
+	if checkpoint.Versions == nil {
+		checkpoint.Versions = make(map[string]int)
+	}
+
+	p.checkpoint = checkpoint
+	return checkpoint, nil
+}
+
+func (p *projector) notifyProgress(processed, total int) {
+	if p.progress == nil {
+		return
+	}
+	p.progress(Progress{
+		Projection: p.projection.Name(),
+		Processed:  processed,
+		Total:      total,
+	})
+}
+
+func (p *projector) handles(eventType string) bool {
+	for _, v := range p.projection.EventTypesHandled() {
+		if v == eventType {
+			return true
+		}
+	}
+	return false
+}
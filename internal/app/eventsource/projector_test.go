@@ -0,0 +1,299 @@
+package eventsource
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestProjector_CatchUpFromCheckpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	store := new(mockEventStore)
+	store.On("LoadByTypes", []string{event1}).Return(History{
+		newTestEvent("a", 1, 0),
+		newTestEvent("b", 1, 1),
+		newTestEvent("a", 2, 2),
+		newTestEvent("b", 2, 3),
+	}, nil)
+	checkpoints := newMemoryCheckpointStore()
+	checkpoints.Save(context.Background(), "test", "a", 2)
+	checkpoints.Save(context.Background(), "test", "b", 1)
+	projection := new(mockProjection)
+
+	projector := newTestProjector(t, projection, store, checkpoints)
+	err := projector.CatchUp(context.Background())
+
+	assert.Nil(err)
+	assert.Equal([]string{"b:2"}, projection.projected)
+	assert.Equal(2, checkpoints.versions["test"]["b"])
+	store.AssertExpectations(t)
+}
+
+func TestProjector_HandleSkipsProjectedEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	checkpoints := newMemoryCheckpointStore()
+	checkpoints.Save(context.Background(), "test", "a", 2)
+	projection := new(mockProjection)
+
+	projector := newTestProjector(t, projection, new(mockEventStore), checkpoints)
+	err := projector.Handle(context.Background(), newTestEvent("a", 2, 0))
+
+	assert.Nil(err)
+	assert.Empty(projection.projected)
+}
+
+func TestProjector_HandleFillsStreamGap(t *testing.T) {
+	assert := assert.New(t)
+
+	store := new(mockEventStore)
+	store.On("Load", "a", 1).Return(History{
+		newTestEvent("a", 2, 0),
+		newTestEvent("a", 3, 1),
+	}, nil)
+	checkpoints := newMemoryCheckpointStore()
+	checkpoints.Save(context.Background(), "test", "a", 1)
+	projection := new(mockProjection)
+
+	projector := newTestProjector(t, projection, store, checkpoints)
+	err := projector.Handle(context.Background(), newTestEvent("a", 3, 1))
+
+	assert.Nil(err)
+	assert.Equal([]string{"a:2", "a:3"}, projection.projected)
+	assert.Equal(3, checkpoints.versions["test"]["a"])
+	store.AssertExpectations(t)
+}
+
+func TestProjector_SyncSkipsIgnoredEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	ignored := NewEvent("a", event2, 2, nil)
+	store := new(mockEventStore)
+	store.On("Load", "a", 0).Return(History{
+		newTestEvent("a", 1, 0),
+		*ignored,
+	}, nil)
+	checkpoints := newMemoryCheckpointStore()
+	projection := new(mockProjection)
+
+	projector := newTestProjector(t, projection, store, checkpoints)
+	err := projector.Sync(context.Background(), "a")
+
+	// The checkpoint stays at the last event that was applied
+	assert.Nil(err)
+	assert.Equal([]string{"a:1"}, projection.projected)
+	assert.Equal(1, checkpoints.versions["test"]["a"])
+	store.AssertExpectations(t)
+}
+
+func TestProjector_Replace(t *testing.T) {
+	assert := assert.New(t)
+
+	checkpoints := newMemoryCheckpointStore()
+	checkpoints.Save(context.Background(), "test", "a", 4)
+	projection := new(mockProjection)
+
+	replacement := NewCheckpoint("shadow")
+	replacement.Versions["a"] = 2
+
+	var archived *Checkpoint
+	projector := newTestProjector(t, projection, new(mockEventStore), checkpoints)
+	err := projector.Replace(
+		context.Background(),
+		replacement,
+		func(ctx context.Context, previous *Checkpoint) error {
+			archived = previous
+			return nil
+		},
+	)
+
+	assert.Nil(err)
+	assert.Equal(4, archived.Version("a"))
+	assert.Equal(2, checkpoints.versions["test"]["a"])
+
+	// Events after the replaced checkpoint are projected again
+	errHandle := projector.Handle(context.Background(), newTestEvent("a", 3, 0))
+	assert.Nil(errHandle)
+	assert.Equal([]string{"a:3"}, projection.projected)
+}
+
+func TestProjector_ReplaceRestoresCheckpointWhenSwapFails(t *testing.T) {
+	assert := assert.New(t)
+
+	checkpoints := newMemoryCheckpointStore()
+	checkpoints.Save(context.Background(), "test", "a", 4)
+
+	replacement := NewCheckpoint("shadow")
+	replacement.Versions["a"] = 2
+
+	errSwap := errors.New("swap failed")
+	projector := newTestProjector(t, new(mockProjection), new(mockEventStore), checkpoints)
+	err := projector.Replace(
+		context.Background(),
+		replacement,
+		func(ctx context.Context, previous *Checkpoint) error {
+			// The replacement is stored before the swap runs
+			assert.Equal(2, checkpoints.versions["test"]["a"])
+			return errSwap
+		},
+	)
+
+	assert.Equal(errSwap, err)
+	assert.Equal(4, checkpoints.versions["test"]["a"])
+}
+
+func TestProjector_Reload(t *testing.T) {
+	assert := assert.New(t)
+
+	checkpoints := newMemoryCheckpointStore()
+	checkpoints.Save(context.Background(), "test", "a", 4)
+	projection := new(mockProjection)
+
+	projector := newTestProjector(t, projection, new(mockEventStore), checkpoints)
+	errHandle := projector.Handle(context.Background(), newTestEvent("a", 3, 0))
+	assert.Nil(errHandle)
+	assert.Empty(projection.projected)
+
+	// Another instance replaces the checkpoint
+	replacement := NewCheckpoint("test")
+	replacement.Versions["a"] = 2
+	checkpoints.Replace(context.Background(), replacement)
+
+	errReload := projector.Reload(context.Background())
+	assert.Nil(errReload)
+
+	errHandle = projector.Handle(context.Background(), newTestEvent("a", 3, 0))
+	assert.Nil(errHandle)
+	assert.Equal([]string{"a:3"}, projection.projected)
+}
+
+/* ----- event store ----- */
+type mockEventStore struct {
+	mock.Mock
+}
+
+func (m *mockEventStore) Save(ctx context.Context, events ...Event) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *mockEventStore) Load(
+	ctx context.Context,
+	aggregateID string,
+	fromVersion int,
+) (History, error) {
+	args := m.Called(aggregateID, fromVersion)
+	return args.Get(0).(History), args.Error(1)
+}
+
+func (m *mockEventStore) LoadByTypes(
+	ctx context.Context,
+	eventTypes ...string,
+) (History, error) {
+	args := m.Called(eventTypes)
+	return args.Get(0).(History), args.Error(1)
+}
+
+func (m *mockEventStore) LoadByCommandID(
+	ctx context.Context,
+	aggregateID string,
+	commandID string,
+) (History, error) {
+	args := m.Called(aggregateID, commandID)
+	return args.Get(0).(History), args.Error(1)
+}
+
+/* ----- checkpoint store ----- */
+type memoryCheckpointStore struct {
+	versions map[string]map[string]int
+}
+
+func newMemoryCheckpointStore() *memoryCheckpointStore {
+	return &memoryCheckpointStore{
+		versions: make(map[string]map[string]int),
+	}
+}
+
+func (m *memoryCheckpointStore) Load(
+	ctx context.Context,
+	projection string,
+) (*Checkpoint, error) {
+	checkpoint := NewCheckpoint(projection)
+	for k, v := range m.versions[projection] {
+		checkpoint.Versions[k] = v
+	}
+	return checkpoint, nil
+}
+
+func (m *memoryCheckpointStore) Save(
+	ctx context.Context,
+	projection, aggregateID string,
+	version int,
+) error {
+	if _, ok := m.versions[projection]; !ok {
+		m.versions[projection] = make(map[string]int)
+	}
+	m.versions[projection][aggregateID] = version
+	return nil
+}
+
+func (m *memoryCheckpointStore) Replace(
+	ctx context.Context,
+	checkpoint *Checkpoint,
+) error {
+	m.versions[checkpoint.Projection] = make(map[string]int)
+	for k, v := range checkpoint.Versions {
+		m.versions[checkpoint.Projection][k] = v
+	}
+	return nil
+}
+
+/* ----- projection ----- */
+type mockProjection struct {
+	projected []string
+}
+
+func (m *mockProjection) Name() string {
+	return "test"
+}
+
+func (m *mockProjection) EventTypesHandled() []string {
+	return []string{event1}
+}
+
+func (m *mockProjection) Project(ctx context.Context, event Event) error {
+	m.projected = append(
+		m.projected,
+		event.AggregateID+":"+strconv.Itoa(event.Version),
+	)
+	return nil
+}
+
+/* ----- helpers ----- */
+func newTestProjector(
+	t *testing.T,
+	projection Projection,
+	store EventStore,
+	checkpoints CheckpointStore,
+) Projector {
+	return NewProjector(ProjectorParams{
+		Projection:  projection,
+		Store:       store,
+		Checkpoints: checkpoints,
+		Logger:      zaptest.NewLogger(t),
+	})
+}
+
+func newTestEvent(aggregateID string, version int, offset int) Event {
+	event := NewEvent(aggregateID, event1, version, nil)
+	event.EventAt = time.Unix(0, 0).Add(time.Duration(offset) * time.Second)
+	return *event
+}
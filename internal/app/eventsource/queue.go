@@ -0,0 +1,340 @@
+package eventsource
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errCommandQueueClosed = errors.New("command queue closed")
+	errCommandQueueFull   = errors.New("command queue full")
+)
+
+var defaultQueueConcurrency = 8
+
+// CommandFuture resolves to the result of a queued command
+type CommandFuture interface {
+	// Done is closed once the command has been handled
+	Done() <-chan struct{}
+
+	// Result blocks until the command has been handled or ctx is done.
+	// The command is still handled when ctx is done first
+	Result(ctx context.Context) (*CommandResult, error)
+}
+
+// QueueStats reports the state of a QueuedDispatcher
+type QueueStats struct {
+	// Depth is the number of commands waiting for a worker
+	Depth int `json:"depth"`
+
+	// InFlight is the number of commands being handled
+	InFlight int `json:"inFlight"`
+
+	// Aggregates is the number of aggregates with queued or in flight commands
+	Aggregates int `json:"aggregates"`
+}
+
+// QueuedDispatcher is a CommandDispatcher that queues commands on one
+// worker per aggregate. Commands for the same aggregate are handled one at
+// a time in the order they were queued, and at most MaxConcurrency
+// commands are handled at once across aggregates
+type QueuedDispatcher interface {
+	CommandDispatcher
+
+	// Enqueue queues cmd and returns a future for its result. The command
+	// does not inherit the cancellation of ctx
+	Enqueue(ctx context.Context, cmd Command) (CommandFuture, error)
+
+	// Stats returns the current queue depth and concurrency
+	Stats() QueueStats
+
+	// Close stops accepting commands and waits for queued commands to be
+	// handled or for ctx to be done
+	Close(ctx context.Context) error
+}
+
+// QueuedDispatcherParams represent the params needed to instantiate a new QueuedDispatcher
+type QueuedDispatcherParams struct {
+	// Dispatcher handles the dequeued commands
+	Dispatcher CommandDispatcher
+	Logger     *zap.Logger
+
+	// MaxConcurrency bounds the commands handled at once across
+	// aggregates. Defaults to 8
+	MaxConcurrency int
+
+	// MaxDepth bounds the commands waiting for a worker. Zero is unbounded
+	MaxDepth int
+}
+
+type queuedDispatcher struct {
+	dispatcher CommandDispatcher
+	logger     *zap.Logger
+	maxDepth   int
+
+	// slots limits the commands handled at once
+	slots chan struct{}
+
+	mu       sync.Mutex
+	queues   map[string][]*queuedCommand
+	depth    int
+	inFlight int
+	closed   bool
+	workers  sync.WaitGroup
+
+	// waiting counts, per aggregate, the commands of its worker waiting on
+	// the queue of another aggregate
+	waiting map[string]int
+}
+
+type queuedCommand struct {
+	ctx    context.Context
+	cmd    Command
+	future *commandFuture
+}
+
+// workerKey marks the context of commands handled by a queue worker with
+// the aggregate id of the worker
+type workerKey struct{}
+
+// NewQueuedDispatcher creates a QueuedDispatcher in front of a dispatcher
+func NewQueuedDispatcher(p QueuedDispatcherParams) QueuedDispatcher {
+	concurrency := p.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultQueueConcurrency
+	}
+
+	return &queuedDispatcher{
+		dispatcher: p.Dispatcher,
+		logger:     p.Logger,
+		maxDepth:   p.MaxDepth,
+		slots:      make(chan struct{}, concurrency),
+		queues:     make(map[string][]*queuedCommand),
+		waiting:    make(map[string]int),
+	}
+}
+
+/* ----- exported ----- */
+
+// Dispatch queues cmd and waits for its result. Commands dispatched while
+// handling a queued command, e.g. by sagas, are handled by dispatchNested
+func (q *queuedDispatcher) Dispatch(
+	ctx context.Context,
+	cmd Command,
+) (*CommandResult, error) {
+	if worker, isWorker := ctx.Value(workerKey{}).(string); isWorker {
+		return q.dispatchNested(ctx, worker, cmd)
+	}
+
+	future, err := q.Enqueue(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return future.Result(ctx)
+}
+
+func (q *queuedDispatcher) Enqueue(
+	ctx context.Context,
+	cmd Command,
+) (CommandFuture, error) {
+	var operation Operation = "eventsource.queuedDispatcher.Enqueue"
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, CommandErr(operation, errCommandQueueClosed, nil, cmd)
+	}
+	if q.maxDepth > 0 && q.depth >= q.maxDepth {
+		return nil, CommandErr(operation, errCommandQueueFull, nil, cmd)
+	}
+	return q.enqueue(ctx, cmd), nil
+}
+
+func (q *queuedDispatcher) RegisterHandler(c CommandHandler) error {
+	return q.dispatcher.RegisterHandler(c)
+}
+
+func (q *queuedDispatcher) RegisteredCommands() []CommandRegistration {
+	return q.dispatcher.RegisteredCommands()
+}
+
+func (q *queuedDispatcher) Use(middlewares ...Middleware) {
+	q.dispatcher.Use(middlewares...)
+}
+
+func (q *queuedDispatcher) Stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return QueueStats{
+		Depth:      q.depth,
+		InFlight:   q.inFlight,
+		Aggregates: len(q.queues),
+	}
+}
+
+func (q *queuedDispatcher) Close(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.logger.Info("command queue drained")
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "command queue did not drain")
+	}
+}
+
+/* ----- worker ----- */
+
+// enqueue appends cmd to the queue of its aggregate, starting a worker
+// when the aggregate has none. The caller holds q.mu
+func (q *queuedDispatcher) enqueue(ctx context.Context, cmd Command) *commandFuture {
+	queued := &queuedCommand{
+		ctx:    DetachContext(ctx),
+		cmd:    cmd,
+		future: newCommandFuture(),
+	}
+
+	aggregateID := cmd.AggregateID()
+	pending, hasWorker := q.queues[aggregateID]
+	q.queues[aggregateID] = append(pending, queued)
+	q.depth++
+
+	if !hasWorker {
+		q.workers.Add(1)
+		go q.work(aggregateID)
+	}
+
+	return queued.future
+}
+
+// dispatchNested handles a command dispatched by the worker of an
+// aggregate. Commands of the same aggregate run inline, as waiting on its
+// own queue would deadlock. Commands of another aggregate are queued
+// behind its other commands, and the worker releases its slot while
+// waiting so that the other worker can run.
+//
+// A worker never waits on an aggregate whose worker is itself waiting, so
+// waits cannot form a cycle. Such commands run inline instead; the event
+// store rejects the write of the waiting worker should both write the
+// aggregate
+func (q *queuedDispatcher) dispatchNested(
+	ctx context.Context,
+	worker string,
+	cmd Command,
+) (*CommandResult, error) {
+	aggregateID := cmd.AggregateID()
+
+	q.mu.Lock()
+	if aggregateID == worker || q.waiting[aggregateID] > 0 {
+		q.mu.Unlock()
+		return q.dispatcher.Dispatch(ctx, cmd)
+	}
+	// Commands being handled may queue commands while the queue closes or
+	// is full, so that they can finish
+	future := q.enqueue(ctx, cmd)
+	q.waiting[worker]++
+	q.mu.Unlock()
+
+	<-q.slots
+	result, err := future.Result(ctx)
+	q.slots <- struct{}{}
+
+	q.mu.Lock()
+	q.waiting[worker]--
+	if q.waiting[worker] == 0 {
+		delete(q.waiting, worker)
+	}
+	q.mu.Unlock()
+
+	return result, err
+}
+
+// work handles the commands of an aggregate until its queue is empty
+func (q *queuedDispatcher) work(aggregateID string) {
+	defer q.workers.Done()
+
+	for {
+		queued := q.dequeue(aggregateID)
+		if queued == nil {
+			return
+		}
+
+		q.slots <- struct{}{}
+		q.mu.Lock()
+		q.inFlight++
+		q.mu.Unlock()
+
+		ctx := context.WithValue(queued.ctx, workerKey{}, aggregateID)
+		result, err := q.dispatcher.Dispatch(ctx, queued.cmd)
+		<-q.slots
+
+		q.mu.Lock()
+		q.inFlight--
+		q.mu.Unlock()
+
+		queued.future.resolve(result, err)
+	}
+}
+
+// dequeue pops the next command of an aggregate. The aggregate is removed
+// once its queue is empty, which ends its worker
+func (q *queuedDispatcher) dequeue(aggregateID string) *queuedCommand {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending := q.queues[aggregateID]
+	if len(pending) == 0 {
+		delete(q.queues, aggregateID)
+		return nil
+	}
+
+	q.queues[aggregateID] = pending[1:]
+	q.depth--
+	return pending[0]
+}
+
+/* ----- future ----- */
+type commandFuture struct {
+	done   chan struct{}
+	result *CommandResult
+	err    error
+}
+
+func newCommandFuture() *commandFuture {
+	return &commandFuture{
+		done: make(chan struct{}),
+	}
+}
+
+func (f *commandFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+func (f *commandFuture) Result(ctx context.Context) (*CommandResult, error) {
+	select {
+	case <-f.done:
+		return f.result, f.err
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "command result not ready")
+	}
+}
+
+func (f *commandFuture) resolve(result *CommandResult, err error) {
+	f.result = result
+	f.err = err
+	close(f.done)
+}
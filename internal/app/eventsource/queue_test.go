@@ -0,0 +1,366 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestQueuedDispatcher_SerializesPerAggregate(t *testing.T) {
+	assert := assert.New(t)
+
+	handler := newConcurrencyCommandHandler(time.Millisecond)
+	queue := newTestQueuedDispatcher(t, handler, 4, 0)
+
+	futures := []CommandFuture{}
+	for i := 0; i < 20; i++ {
+		future, err := queue.Enqueue(
+			context.Background(),
+			&sequencedCommand{id: fmt.Sprintf("agg-%v", i%2), sequence: i},
+		)
+		assert.Nil(err)
+		futures = append(futures, future)
+	}
+	for _, v := range futures {
+		_, err := v.Result(context.Background())
+		assert.Nil(err)
+	}
+
+	assert.Equal(1, handler.maxPerAggregate)
+	assert.Equal([]int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}, handler.order["agg-0"])
+}
+
+func TestQueuedDispatcher_BoundsConcurrency(t *testing.T) {
+	assert := assert.New(t)
+
+	handler := newConcurrencyCommandHandler(5 * time.Millisecond)
+	queue := newTestQueuedDispatcher(t, handler, 2, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := queue.Dispatch(
+				context.Background(),
+				&sequencedCommand{id: fmt.Sprintf("agg-%v", i), sequence: i},
+			)
+			assert.Nil(err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(handler.maxConcurrent, 2)
+	assert.Equal(QueueStats{}, queue.Stats())
+}
+
+func TestQueuedDispatcher_ReturnsResult(t *testing.T) {
+	assert := assert.New(t)
+
+	queue := newTestQueuedDispatcher(t, newMockCommandHandler(nil), 1, 0)
+	result, err := queue.Dispatch(context.Background(), &mockCommand{id: "123123"})
+
+	assert.Nil(err)
+	assert.Equal("123123", result.AggregateID)
+}
+
+func TestQueuedDispatcher_NestedDispatch(t *testing.T) {
+	assert := assert.New(t)
+
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	queue := NewQueuedDispatcher(QueuedDispatcherParams{
+		Dispatcher:     dispatcher,
+		Logger:         zaptest.NewLogger(t),
+		MaxConcurrency: 1,
+	})
+	handler := &nestingCommandHandler{queue: queue}
+	dispatcher.RegisterHandler(handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := queue.Dispatch(ctx, &mockCommand{id: "a"})
+
+	assert.Nil(err)
+	assert.Equal([]string{"a", "a", "b"}, handler.handled)
+}
+
+func TestQueuedDispatcher_CrossAggregateNestedDispatch(t *testing.T) {
+	assert := assert.New(t)
+
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	queue := NewQueuedDispatcher(QueuedDispatcherParams{
+		Dispatcher:     dispatcher,
+		Logger:         zaptest.NewLogger(t),
+		MaxConcurrency: 2,
+	})
+	handler := &crossingCommandHandler{queue: queue}
+	handler.started.Add(2)
+	dispatcher.RegisterHandler(handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// a dispatches to b while b dispatches to a
+	var wg sync.WaitGroup
+	for _, v := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			_, err := queue.Dispatch(ctx, &mockCommand{id: id})
+			assert.Nil(err)
+		}(v)
+	}
+	wg.Wait()
+}
+
+func TestQueuedDispatcher_CrossAggregateDispatchIsQueued(t *testing.T) {
+	assert := assert.New(t)
+
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	queue := NewQueuedDispatcher(QueuedDispatcherParams{
+		Dispatcher:     dispatcher,
+		Logger:         zaptest.NewLogger(t),
+		MaxConcurrency: 1,
+	})
+	handler := newConcurrencyCommandHandler(time.Millisecond)
+	dispatcher.RegisterHandler(handler)
+	dispatcher.RegisterHandler(&forwardingCommandHandler{queue: queue, to: "b"})
+
+	futures := []CommandFuture{}
+	for i := 0; i < 5; i++ {
+		future, err := queue.Enqueue(
+			context.Background(),
+			&sequencedCommand{id: "b", sequence: i},
+		)
+		assert.Nil(err)
+		futures = append(futures, future)
+	}
+
+	// The worker of a releases the only slot while waiting on b
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := queue.Dispatch(ctx, &mockCommand{id: "a"})
+	assert.Nil(err)
+	for _, v := range futures {
+		_, errResult := v.Result(ctx)
+		assert.Nil(errResult)
+	}
+
+	assert.Equal(1, handler.maxPerAggregate)
+	assert.Equal([]int{0, 1, 2, 3, 4, 5}, handler.order["b"])
+	assert.Equal(QueueStats{}, queue.Stats())
+}
+
+func TestQueuedDispatcher_Full(t *testing.T) {
+	assert := assert.New(t)
+
+	handler := newConcurrencyCommandHandler(20 * time.Millisecond)
+	queue := newTestQueuedDispatcher(t, handler, 1, 1)
+
+	_, errFirst := queue.Enqueue(context.Background(), &sequencedCommand{id: "a"})
+	// Wait for the first command to leave the queue
+	for queue.Stats().InFlight == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_, errSecond := queue.Enqueue(context.Background(), &sequencedCommand{id: "a"})
+	_, errThird := queue.Enqueue(context.Background(), &sequencedCommand{id: "a"})
+
+	assert.Nil(errFirst)
+	assert.Nil(errSecond)
+	assert.Equal(errCommandQueueFull, errors.Cause(errThird))
+}
+
+func TestQueuedDispatcher_Close(t *testing.T) {
+	assert := assert.New(t)
+
+	queue := newTestQueuedDispatcher(t, newConcurrencyCommandHandler(time.Millisecond), 1, 0)
+	future, err := queue.Enqueue(context.Background(), &sequencedCommand{id: "a"})
+	assert.Nil(err)
+
+	assert.Nil(queue.Close(context.Background()))
+	select {
+	case <-future.Done():
+	default:
+		t.Fatal("queued command was not handled before close")
+	}
+
+	_, errClosed := queue.Enqueue(context.Background(), &sequencedCommand{id: "a"})
+	assert.Equal(errCommandQueueClosed, errors.Cause(errClosed))
+}
+
+/* ----- command ----- */
+type sequencedCommand struct {
+	id       string
+	sequence int
+}
+
+func (c *sequencedCommand) AggregateID() string {
+	return c.id
+}
+
+/* ----- command handler ----- */
+type concurrencyCommandHandler struct {
+	delay time.Duration
+
+	mu              sync.Mutex
+	order           map[string][]int
+	active          map[string]int
+	concurrent      int
+	maxConcurrent   int
+	maxPerAggregate int
+}
+
+func newConcurrencyCommandHandler(delay time.Duration) *concurrencyCommandHandler {
+	return &concurrencyCommandHandler{
+		delay:  delay,
+		order:  make(map[string][]int),
+		active: make(map[string]int),
+	}
+}
+
+func (h *concurrencyCommandHandler) Handle(
+	ctx context.Context,
+	command Command,
+) (*CommandResult, error) {
+	id := command.AggregateID()
+
+	h.mu.Lock()
+	h.order[id] = append(h.order[id], command.(*sequencedCommand).sequence)
+	h.active[id]++
+	h.concurrent++
+	if h.active[id] > h.maxPerAggregate {
+		h.maxPerAggregate = h.active[id]
+	}
+	if h.concurrent > h.maxConcurrent {
+		h.maxConcurrent = h.concurrent
+	}
+	h.mu.Unlock()
+
+	time.Sleep(h.delay)
+
+	h.mu.Lock()
+	h.active[id]--
+	h.concurrent--
+	h.mu.Unlock()
+
+	return NewCommandResult(id, nil), nil
+}
+
+func (h *concurrencyCommandHandler) CommandsHandled() []Command {
+	return []Command{&sequencedCommand{}}
+}
+
+// nestingCommandHandler dispatches a command for the same and for
+// another aggregate while handling the first command of "a"
+type nestingCommandHandler struct {
+	queue   QueuedDispatcher
+	mu      sync.Mutex
+	handled []string
+}
+
+func (h *nestingCommandHandler) Handle(
+	ctx context.Context,
+	command Command,
+) (*CommandResult, error) {
+	h.mu.Lock()
+	h.handled = append(h.handled, command.AggregateID())
+	first := len(h.handled) == 1
+	h.mu.Unlock()
+
+	if first {
+		_, errSame := h.queue.Dispatch(ctx, &mockCommand{id: "a"})
+		if errSame != nil {
+			return nil, errSame
+		}
+		_, errOther := h.queue.Dispatch(ctx, &mockCommand{id: "b"})
+		if errOther != nil {
+			return nil, errOther
+		}
+	}
+	return NewCommandResult(command.AggregateID(), nil), nil
+}
+
+func (h *nestingCommandHandler) CommandsHandled() []Command {
+	return []Command{&mockCommand{}}
+}
+
+// crossingCommandHandler dispatches a command for the other aggregate
+// once the first commands of "a" and "b" are both being handled
+type crossingCommandHandler struct {
+	queue   QueuedDispatcher
+	started sync.WaitGroup
+	once    sync.Map
+}
+
+func (h *crossingCommandHandler) Handle(
+	ctx context.Context,
+	command Command,
+) (*CommandResult, error) {
+	id := command.AggregateID()
+	if _, nested := h.once.LoadOrStore(id, true); nested {
+		return NewCommandResult(id, nil), nil
+	}
+
+	h.started.Done()
+	h.started.Wait()
+
+	other := "a"
+	if id == "a" {
+		other = "b"
+	}
+	_, err := h.queue.Dispatch(ctx, &mockCommand{id: other})
+	if err != nil {
+		return nil, err
+	}
+	return NewCommandResult(id, nil), nil
+}
+
+func (h *crossingCommandHandler) CommandsHandled() []Command {
+	return []Command{&mockCommand{}}
+}
+
+// forwardingCommandHandler dispatches a sequenced command for another
+// aggregate while handling a command
+type forwardingCommandHandler struct {
+	queue QueuedDispatcher
+	to    string
+}
+
+func (h *forwardingCommandHandler) Handle(
+	ctx context.Context,
+	command Command,
+) (*CommandResult, error) {
+	_, err := h.queue.Dispatch(ctx, &sequencedCommand{id: h.to, sequence: 5})
+	if err != nil {
+		return nil, err
+	}
+	return NewCommandResult(command.AggregateID(), nil), nil
+}
+
+func (h *forwardingCommandHandler) CommandsHandled() []Command {
+	return []Command{&mockCommand{}}
+}
+
+/* ----- helpers ----- */
+func newTestQueuedDispatcher(
+	t *testing.T,
+	handler CommandHandler,
+	maxConcurrency int,
+	maxDepth int,
+) QueuedDispatcher {
+	dispatcher := NewDispatcher(zaptest.NewLogger(t))
+	dispatcher.RegisterHandler(handler)
+	return NewQueuedDispatcher(QueuedDispatcherParams{
+		Dispatcher:     dispatcher,
+		Logger:         zaptest.NewLogger(t),
+		MaxConcurrency: maxConcurrency,
+		MaxDepth:       maxDepth,
+	})
+}
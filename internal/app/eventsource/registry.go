@@ -0,0 +1,125 @@
+package eventsource
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errUnregisteredCommandType = errors.New("command type is not registered")
+	errAmbiguousCommandType    = errors.New("command type name is registered by several packages")
+)
+
+// CommandRegistry maps command type names to command types so that
+// commands can be serialized and restored, e.g. by a scheduler
+type CommandRegistry interface {
+	// Register adds the types of the given commands to the registry
+	Register(...Command)
+
+	// Marshal serializes cmd and returns its type name
+	Marshal(cmd Command) (string, []byte, error)
+
+	// Unmarshal restores a command of a registered type
+	Unmarshal(commandType string, payload []byte) (Command, error)
+}
+
+type commandRegistry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+
+	// bareTypes maps the unqualified names commands were registered under
+	// before CommandType included their package, so that commands
+	// serialized under those names can still be restored. Names shared by
+	// several packages map to nil
+	bareTypes map[string]reflect.Type
+}
+
+// NewCommandRegistry creates an empty CommandRegistry
+func NewCommandRegistry() CommandRegistry {
+	return &commandRegistry{
+		types:     make(map[string]reflect.Type),
+		bareTypes: make(map[string]reflect.Type),
+	}
+}
+
+// CommandType returns the fully-qualified type name a command is
+// registered and dispatched under
+func CommandType(cmd Command) string {
+	return qualifiedTypeOf(cmd)
+}
+
+func (r *commandRegistry) Register(commands ...Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, v := range commands {
+		t := reflect.TypeOf(v).Elem()
+		r.types[CommandType(v)] = t
+
+		bare := typeOf(v)
+		if registered, ok := r.bareTypes[bare]; ok && registered != t {
+			r.bareTypes[bare] = nil
+			continue
+		}
+		r.bareTypes[bare] = t
+	}
+}
+
+func (r *commandRegistry) Marshal(cmd Command) (string, []byte, error) {
+	commandType := CommandType(cmd)
+
+	r.mu.RLock()
+	_, ok := r.types[commandType]
+	r.mu.RUnlock()
+	if !ok {
+		return "", nil, errors.Wrapf(errUnregisteredCommandType, "%v", commandType)
+	}
+
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "unable to serialize command %v", commandType)
+	}
+	return commandType, payload, nil
+}
+
+// Unmarshal implements the CommandRegistry interface. Command types that
+// are not qualified by their package are looked up by their bare name
+func (r *commandRegistry) Unmarshal(commandType string, payload []byte) (Command, error) {
+	t, errLookup := r.lookup(commandType)
+	if errLookup != nil {
+		return nil, errLookup
+	}
+
+	value := reflect.New(t)
+	err := json.Unmarshal(payload, value.Interface())
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to deserialize command %v", commandType)
+	}
+
+	cmd, ok := value.Interface().(Command)
+	if !ok {
+		return nil, errors.Errorf("type %v does not implement Command", commandType)
+	}
+	return cmd, nil
+}
+
+func (r *commandRegistry) lookup(commandType string) (reflect.Type, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if t, ok := r.types[commandType]; ok {
+		return t, nil
+	}
+
+	t, ok := r.bareTypes[commandType]
+	if !ok {
+		return nil, errors.Wrapf(errUnregisteredCommandType, "%v", commandType)
+	}
+	if t == nil {
+		return nil, errors.Wrapf(errAmbiguousCommandType, "%v", commandType)
+	}
+	return t, nil
+}
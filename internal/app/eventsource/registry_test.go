@@ -0,0 +1,77 @@
+package eventsource
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+/* ----- tests ----- */
+func TestCommandRegistry_RoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := NewCommandRegistry()
+	registry.Register(&idempotentCommand{})
+
+	commandType, payload, err := registry.Marshal(&idempotentCommand{
+		CommandModel: CommandModel{ID: "agg-1", CommandID: "key-1"},
+	})
+	assert.Nil(err)
+	assert.Equal(CommandType(&idempotentCommand{}), commandType)
+
+	cmd, errUnmarshal := registry.Unmarshal(commandType, payload)
+	assert.Nil(errUnmarshal)
+	if assert.IsType(&idempotentCommand{}, cmd) {
+		assert.Equal("agg-1", cmd.AggregateID())
+		assert.Equal("key-1", cmd.(IdempotentCommand).IdempotencyKey())
+	}
+}
+
+func TestCommandRegistry_UnmarshalBareTypeName(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := NewCommandRegistry()
+	registry.Register(&idempotentCommand{})
+
+	// Scheduled before command types were qualified by their package
+	cmd, err := registry.Unmarshal(
+		"idempotentCommand",
+		[]byte(`{"ID":"agg-1","commandId":"key-1"}`),
+	)
+	assert.Nil(err)
+	if assert.IsType(&idempotentCommand{}, cmd) {
+		assert.Equal("agg-1", cmd.AggregateID())
+	}
+}
+
+func TestCommandRegistry_AmbiguousBareTypeName(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := NewCommandRegistry().(*commandRegistry)
+	registry.Register(&idempotentCommand{})
+
+	// Stands in for a command of the same name registered by another package
+	registry.bareTypes["idempotentCommand"] = nil
+
+	_, err := registry.Unmarshal("idempotentCommand", []byte("{}"))
+	assert.Equal(errAmbiguousCommandType, errors.Cause(err))
+
+	_, errQualified := registry.Unmarshal(
+		CommandType(&idempotentCommand{}),
+		[]byte("{}"),
+	)
+	assert.Nil(errQualified)
+}
+
+func TestCommandRegistry_Unregistered(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := NewCommandRegistry()
+
+	_, _, err := registry.Marshal(&idempotentCommand{})
+	assert.Equal(errUnregisteredCommandType, errors.Cause(err))
+
+	_, errUnmarshal := registry.Unmarshal("unknown", []byte("{}"))
+	assert.Equal(errUnregisteredCommandType, errors.Cause(errUnmarshal))
+}
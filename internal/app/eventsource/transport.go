@@ -0,0 +1,47 @@
+package eventsource
+
+import "context"
+
+// ReceiveFunc is called by a Transport for every event it delivers
+type ReceiveFunc func(ctx context.Context, event Event) error
+
+// Transport moves published events from an EventBus to the handlers
+// registered on every EventBus subscribed to it
+type Transport interface {
+	// Send delivers events to the subscribers of the transport
+	Send(ctx context.Context, events []Event) error
+
+	// Subscribe registers the function that receives delivered events
+	Subscribe(receive ReceiveFunc)
+}
+
+// inProcessTransport delivers events synchronously to a subscriber
+// in the same process
+type inProcessTransport struct {
+	receive ReceiveFunc
+}
+
+// NewInProcessTransport creates a Transport that delivers events to
+// handlers within the same process. Errors returned by the handlers are
+// returned to the publisher
+func NewInProcessTransport() Transport {
+	return &inProcessTransport{}
+}
+
+func (t *inProcessTransport) Send(ctx context.Context, events []Event) error {
+	if t.receive == nil {
+		return nil
+	}
+
+	for _, event := range events {
+		err := t.receive(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *inProcessTransport) Subscribe(receive ReceiveFunc) {
+	t.receive = receive
+}
@@ -0,0 +1,211 @@
+package eventsource
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Validation rules supported by the validate struct tag
+const (
+	RuleRequired = "required"
+	RuleEmail    = "email"
+	RuleMin      = "min"
+	RuleMax      = "max"
+)
+
+const validateTag = "validate"
+
+// FieldError describes a single field that failed validation. Field is
+// the json name of the field so that it matches the API argument
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every field of a command that failed validation
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, v := range e.Fields {
+		messages = append(messages, v.Field+" "+v.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// AsValidationError returns the ValidationError err was caused by, if any
+func AsValidationError(err error) (*ValidationError, bool) {
+	v, ok := errors.Cause(err).(*ValidationError)
+	return v, ok
+}
+
+// Validate checks the fields of a struct against the rules declared in
+// their validate tags, e.g. `validate:"required,email,max=254"`.
+//
+// Rules:
+//
+//	required   strings must not be blank, pointers must not be nil
+//	email      strings must be a bare email address
+//	min=N      minimum length for strings, minimum value for numbers
+//	max=N      maximum length for strings, maximum value for numbers
+//
+// Nil pointers are only checked by required. Embedded structs are
+// validated as part of the enclosing struct
+func Validate(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fieldErrors, err := validateStruct(value)
+	if err != nil {
+		return err
+	}
+	if len(fieldErrors) > 0 {
+		return &ValidationError{Fields: fieldErrors}
+	}
+	return nil
+}
+
+func validateStruct(value reflect.Value) ([]FieldError, error) {
+	fieldErrors := []FieldError{}
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		fieldValue := value.Field(i)
+
+		if field.Anonymous && fieldValue.Kind() == reflect.Struct {
+			embedded, err := validateStruct(fieldValue)
+			if err != nil {
+				return nil, err
+			}
+			fieldErrors = append(fieldErrors, embedded...)
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup(validateTag)
+		if !ok || tag == "" {
+			continue
+		}
+
+		for _, rule := range strings.Split(tag, ",") {
+			fieldError, err := validateRule(fieldValue, strings.TrimSpace(rule))
+			if err != nil {
+				return nil, errors.Wrapf(err, "field %v", field.Name)
+			}
+			if fieldError != nil {
+				fieldError.Field = fieldName(field)
+				fieldErrors = append(fieldErrors, *fieldError)
+				// Report a single failing rule per field
+				break
+			}
+		}
+	}
+	return fieldErrors, nil
+}
+
+func validateRule(value reflect.Value, rule string) (*FieldError, error) {
+	name, param := rule, ""
+	if i := strings.Index(rule, "="); i >= 0 {
+		name, param = rule[:i], rule[i+1:]
+	}
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			if name == RuleRequired {
+				return newFieldError(name, "is required"), nil
+			}
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+
+	switch name {
+	case RuleRequired:
+		if value.Kind() == reflect.String && strings.TrimSpace(value.String()) == "" {
+			return newFieldError(name, "is required"), nil
+		}
+		return nil, nil
+	case RuleEmail:
+		if value.Kind() != reflect.String {
+			return nil, errors.Errorf("rule %v only applies to strings", name)
+		}
+		if !isEmail(value.String()) {
+			return newFieldError(name, "must be a valid email address"), nil
+		}
+		return nil, nil
+	case RuleMin, RuleMax:
+		return validateBound(value, name, param)
+	}
+	return nil, errors.Errorf("unknown validation rule %v", name)
+}
+
+func validateBound(value reflect.Value, rule string, param string) (*FieldError, error) {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %v parameter", rule)
+	}
+
+	var (
+		actual float64
+		unit   string
+	)
+	switch value.Kind() {
+	case reflect.String:
+		actual = float64(utf8.RuneCountInString(value.String()))
+		unit = " characters"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	default:
+		return nil, errors.Errorf("rule %v does not apply to %v", rule, value.Kind())
+	}
+
+	if rule == RuleMin && actual < bound {
+		return newFieldError(rule, fmt.Sprintf("must be at least %v%v", param, unit)), nil
+	}
+	if rule == RuleMax && actual > bound {
+		return newFieldError(rule, fmt.Sprintf("must be at most %v%v", param, unit)), nil
+	}
+	return nil, nil
+}
+
+/* ----- helpers ----- */
+func newFieldError(rule string, message string) *FieldError {
+	return &FieldError{
+		Rule:    rule,
+		Message: message,
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func isEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
@@ -0,0 +1,79 @@
+package eventsource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/* ----- tests ----- */
+func TestValidate_ListsEveryFailingField(t *testing.T) {
+	assert := assert.New(t)
+
+	err := Validate(&validatedCommand{
+		CommandModel: CommandModel{ID: "123123"},
+		Name:         "",
+		Email:        "not-an-email",
+		Points:       0,
+	})
+
+	validationErr, ok := AsValidationError(err)
+	assert.True(ok)
+	assert.Equal([]FieldError{
+		{Field: "name", Rule: RuleRequired, Message: "is required"},
+		{Field: "email", Rule: RuleEmail, Message: "must be a valid email address"},
+		{Field: "points", Rule: RuleMin, Message: "must be at least 1"},
+	}, validationErr.Fields)
+}
+
+func TestValidate_Valid(t *testing.T) {
+	code := "abc"
+	err := Validate(&validatedCommand{
+		CommandModel: CommandModel{ID: "123123"},
+		Name:         "name",
+		Email:        "user@example.com",
+		Points:       10,
+		Code:         &code,
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestValidate_MaxLength(t *testing.T) {
+	assert := assert.New(t)
+
+	code := "abcdef"
+	err := Validate(&validatedCommand{
+		CommandModel: CommandModel{ID: "123123"},
+		Name:         "name",
+		Email:        "Name <user@example.com>",
+		Points:       10,
+		Code:         &code,
+	})
+
+	validationErr, ok := AsValidationError(err)
+	assert.True(ok)
+	assert.Equal([]FieldError{
+		{Field: "email", Rule: RuleEmail, Message: "must be a valid email address"},
+		{Field: "code", Rule: RuleMax, Message: "must be at most 5 characters"},
+	}, validationErr.Fields)
+}
+
+func TestValidate_UnknownRule(t *testing.T) {
+	err := Validate(&struct {
+		Name string `validate:"unknown"`
+	}{})
+
+	_, ok := AsValidationError(err)
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+/* ----- command ----- */
+type validatedCommand struct {
+	CommandModel
+	Name   string  `json:"name" validate:"required"`
+	Email  string  `json:"email" validate:"required,email"`
+	Points uint32  `json:"points" validate:"min=1"`
+	Code   *string `json:"code" validate:"max=5"`
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/audit"
+)
+
+type store struct {
+	firestoreClient *firestore.Client
+}
+
+// NewStore instantiates a new instance of the audit Store
+func NewStore(firestoreClient *firestore.Client) audit.Store {
+	return &store{
+		firestoreClient: firestoreClient,
+	}
+}
+
+var auditCollection = "command_audit"
+
+func (s *store) Record(ctx context.Context, entry audit.Entry) error {
+	_, err := s.firestoreClient.
+		Collection(auditCollection).
+		Doc(entry.ID).
+		Create(ctx, entry)
+
+	return err
+}
+
+// Entries requires composite indexes on (aggregateId, issuedAt desc),
+// (actor, issuedAt desc) and (aggregateId, actor, issuedAt desc)
+func (s *store) Entries(
+	ctx context.Context,
+	q audit.Query,
+) ([]audit.Entry, error) {
+	query := s.firestoreClient.
+		Collection(auditCollection).
+		Query
+	if q.AggregateID != "" {
+		query = query.Where("aggregateId", "==", q.AggregateID)
+	}
+	if q.Actor != "" {
+		query = query.Where("actor", "==", q.Actor)
+	}
+
+	docs, err := query.
+		OrderBy("issuedAt", firestore.Desc).
+		Limit(q.Limit).
+		Documents(ctx).
+		GetAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []audit.Entry{}
+	for _, v := range docs {
+		var entry audit.Entry
+		errData := v.DataTo(&entry)
+		if errData != nil {
+			return nil, errData
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,142 @@
+package checkpoint
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"google.golang.org/api/iterator"
+)
+
+type store struct {
+	firestoreClient *firestore.Client
+}
+
+// NewStore instantiates a new instance of the CheckpointStore. The version
+// of each stream is kept in its own document under the projection so that
+// checkpoints are not bound by the size limit of a single document and
+// concurrent saves of different streams do not contend
+func NewStore(firestoreClient *firestore.Client) eventsource.CheckpointStore {
+	return &store{
+		firestoreClient: firestoreClient,
+	}
+}
+
+var (
+	checkpointCollection = "projection_checkpoints"
+	streamCollection     = "streams"
+
+	// maxBatchWrites is the number of writes Firestore accepts in a batch
+	maxBatchWrites = 500
+)
+
+type streamDocument struct {
+	Version int `firestore:"version"`
+}
+
+func (s *store) Load(
+	ctx context.Context,
+	projection string,
+) (*eventsource.Checkpoint, error) {
+	checkpoint := eventsource.NewCheckpoint(projection)
+
+	iter := s.getStreamCollection(projection).Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, errNext := iter.Next()
+		if errNext == iterator.Done {
+			break
+		}
+		if errNext != nil {
+			return nil, errNext
+		}
+
+		var record streamDocument
+		errData := doc.DataTo(&record)
+		if errData != nil {
+			return nil, errData
+		}
+		checkpoint.Versions[doc.Ref.ID] = record.Version
+	}
+	return checkpoint, nil
+}
+
+func (s *store) Save(
+	ctx context.Context,
+	projection, aggregateID string,
+	version int,
+) error {
+	_, err := s.
+		getStreamCollection(projection).
+		Doc(aggregateID).
+		Set(ctx, streamDocument{Version: version})
+
+	return err
+}
+
+// Replace writes the versions of checkpoint and deletes the streams it
+// does not record. Checkpoints larger than a batch are not replaced
+// atomically
+func (s *store) Replace(
+	ctx context.Context,
+	checkpoint *eventsource.Checkpoint,
+) error {
+	streams := s.getStreamCollection(checkpoint.Projection)
+	existing, err := streams.DocumentRefs(ctx).GetAll()
+	if err != nil {
+		return err
+	}
+
+	writes := []func(*firestore.WriteBatch){}
+	for _, v := range existing {
+		if _, ok := checkpoint.Versions[v.ID]; !ok {
+			ref := v
+			writes = append(writes, func(b *firestore.WriteBatch) {
+				b.Delete(ref)
+			})
+		}
+	}
+	for k, v := range checkpoint.Versions {
+		ref, record := streams.Doc(k), streamDocument{Version: v}
+		writes = append(writes, func(b *firestore.WriteBatch) {
+			b.Set(ref, record)
+		})
+	}
+
+	return s.commit(ctx, writes)
+}
+
+/* ----- helpers ----- */
+func (s *store) getCheckpointDoc(projection string) *firestore.DocumentRef {
+	return s.firestoreClient.
+		Collection(checkpointCollection).
+		Doc(projection)
+}
+
+func (s *store) getStreamCollection(projection string) *firestore.CollectionRef {
+	return s.getCheckpointDoc(projection).Collection(streamCollection)
+}
+
+// commit applies writes in batches of at most maxBatchWrites
+func (s *store) commit(
+	ctx context.Context,
+	writes []func(*firestore.WriteBatch),
+) error {
+	for len(writes) > 0 {
+		n := len(writes)
+		if n > maxBatchWrites {
+			n = maxBatchWrites
+		}
+
+		batch := s.firestoreClient.Batch()
+		for _, v := range writes[:n] {
+			v(batch)
+		}
+		_, err := batch.Commit(ctx)
+		if err != nil {
+			return err
+		}
+		writes = writes[n:]
+	}
+	return nil
+}
@@ -2,11 +2,14 @@ package event
 
 import (
 	"context"
+	"fmt"
 	"sort"
 
 	"cloud.google.com/go/firestore"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type store struct {
@@ -20,7 +23,13 @@ func NewStore(firestoreClient *firestore.Client) eventsource.EventStore {
 	}
 }
 
-var eventCollection = "events"
+var (
+	eventCollection = "events"
+
+	// maxInValues is the number of values Firestore accepts in an "in"
+	// filter
+	maxInValues = 10
+)
 
 func (s *store) Save(ctx context.Context, events ...eventsource.Event) error {
 	if len(events) == 0 {
@@ -41,15 +50,24 @@ func (s *store) Save(ctx context.Context, events ...eventsource.Event) error {
 			"at":          v.EventAt,
 			"payload":     v.Payload,
 			"eventType":   v.EventType,
+			"commandId":   v.CommandID,
 		}
 
+		// Creating the document of a version that exists fails the whole
+		// batch, so concurrent writers of an aggregate cannot both succeed
 		batch.Create(
-			ref.NewDoc(),
+			ref.Doc(eventDocID(v)),
 			m,
 		)
 	}
 
 	_, err := batch.Commit(ctx)
+	if status.Code(err) == codes.AlreadyExists {
+		return eventsource.ConcurrencyConflictErr(
+			sortedEvents[0].AggregateID,
+			sortedEvents[0].Version,
+		)
+	}
 	if err != nil {
 		return err
 	}
@@ -77,6 +95,67 @@ func (s *store) Load(
 	return transformDocumentsToHistory(docs)
 }
 
+// LoadByTypes queries the event types in groups of maxInValues and merges
+// the results by the time the events occurred
+func (s *store) LoadByTypes(
+	ctx context.Context,
+	eventTypes ...string,
+) (eventsource.History, error) {
+	history := eventsource.History{}
+	for len(eventTypes) > 0 {
+		n := len(eventTypes)
+		if n > maxInValues {
+			n = maxInValues
+		}
+
+		docs, errQuery := s.firestoreClient.
+			Collection(eventCollection).
+			Where("eventType", "in", eventTypes[:n]).
+			OrderBy("at", firestore.Asc).
+			Documents(ctx).
+			GetAll()
+		if errQuery != nil {
+			return nil, errQuery
+		}
+
+		events, err := transformDocumentsToHistory(docs)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, events...)
+		eventTypes = eventTypes[n:]
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].EventAt.Before(history[j].EventAt)
+	})
+	return history, nil
+}
+
+func (s *store) LoadByCommandID(
+	ctx context.Context,
+	aggregateID string,
+	commandID string,
+) (eventsource.History, error) {
+	docs, errQuery := s.firestoreClient.
+		Collection(eventCollection).
+		OrderBy("version", firestore.Asc).
+		Where("aggregateId", "==", aggregateID).
+		Where("commandId", "==", commandID).
+		Documents(ctx).
+		GetAll()
+
+	if errQuery != nil {
+		return nil, errQuery
+	}
+	return transformDocumentsToHistory(docs)
+}
+
+// eventDocID identifies the document of an event by aggregate and version
+func eventDocID(event eventsource.Event) string {
+	return fmt.Sprintf("%v-%v", event.AggregateID, event.Version)
+}
+
 func transformDocumentsToHistory(
 	docs []*firestore.DocumentSnapshot,
 ) (eventsource.History, error) {
@@ -0,0 +1,125 @@
+package pointsrules
+
+import (
+	"context"
+	"strconv"
+
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type store struct {
+	firestoreClient *firestore.Client
+}
+
+// NewStore instantiates a new instance of the PointsRulesStore
+func NewStore(firestoreClient *firestore.Client) loyalty.PointsRulesStore {
+	return &store{
+		firestoreClient: firestoreClient,
+	}
+}
+
+var (
+	rulesCollection    = "points_rules"
+	versionsCollection = "versions"
+)
+
+func (s *store) Rules(ctx context.Context) ([]loyalty.PointsRule, error) {
+	docs, err := s.firestoreClient.
+		Collection(rulesCollection).
+		Documents(ctx).
+		GetAll()
+
+	if err != nil {
+		return nil, err
+	}
+	return transformDocumentsToRules(docs)
+}
+
+// SaveRule replaces the current rule of the action and keeps every
+// version in a subcollection of the rule
+func (s *store) SaveRule(ctx context.Context, rule loyalty.PointsRule) error {
+	ref := s.firestoreClient.
+		Collection(rulesCollection).
+		Doc(string(rule.Action))
+
+	return s.firestoreClient.RunTransaction(
+		ctx,
+		func(ctx context.Context, tx *firestore.Transaction) error {
+			currentVersion := 0
+			doc, err := tx.Get(ref)
+			if err != nil && status.Code(err) != codes.NotFound {
+				return err
+			}
+			if err == nil {
+				var current loyalty.PointsRule
+				errData := doc.DataTo(&current)
+				if errData != nil {
+					return errData
+				}
+				currentVersion = current.Version
+			}
+			if currentVersion != rule.Version-1 {
+				return loyalty.ErrPointsRuleConflict
+			}
+
+			errSet := tx.Set(ref, rule)
+			if errSet != nil {
+				return errSet
+			}
+			return tx.Create(
+				ref.Collection(versionsCollection).Doc(strconv.Itoa(rule.Version)),
+				rule,
+			)
+		},
+	)
+}
+
+// Watch listens to snapshots of the rules collection
+func (s *store) Watch(
+	ctx context.Context,
+	onChange func([]loyalty.PointsRule),
+) error {
+	snapshots := s.firestoreClient.
+		Collection(rulesCollection).
+		Snapshots(ctx)
+	defer snapshots.Stop()
+
+	for {
+		snapshot, err := snapshots.Next()
+		if ctx.Err() != nil || status.Code(err) == codes.Canceled {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		docs, errDocs := snapshot.Documents.GetAll()
+		if errDocs != nil {
+			return errDocs
+		}
+		rules, errRules := transformDocumentsToRules(docs)
+		if errRules != nil {
+			return errRules
+		}
+		onChange(rules)
+	}
+}
+
+/* ----- helpers ----- */
+func transformDocumentsToRules(
+	docs []*firestore.DocumentSnapshot,
+) ([]loyalty.PointsRule, error) {
+	rules := make([]loyalty.PointsRule, 0, len(docs))
+	for _, v := range docs {
+		var rule loyalty.PointsRule
+		errData := v.DataTo(&rule)
+		if errData != nil {
+			return nil, errData
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
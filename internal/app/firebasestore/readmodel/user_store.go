@@ -2,17 +2,44 @@ package readmodel
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	defaultUserCollection = "users_es"
+	readModelCollection   = "read_models"
+	userReadModelDoc      = "users"
 )
 
 type userStore struct {
 	firestoreClient *firestore.Client
+
+	// pinned stores always use collection and never consult the
+	// read model document for the active collection
+	pinned bool
+
+	// collection caches the active collection. It is refreshed on
+	// SwitchCollection and by WatchCollection when another instance
+	// switches it
+	mu         sync.RWMutex
+	collection string
+}
+
+// readModelDocument records which collection serves a read model
+type readModelDocument struct {
+	Active   string `firestore:"active"`
+	Previous string `firestore:"previous"`
 }
 
 // NewUserStore instantiates a new instance of the EventRepo
-func NewUserStore(firestoreClient *firestore.Client) user.ReadRepo {
+func NewUserStore(firestoreClient *firestore.Client) user.SwitchableReadRepo {
 	return &userStore{
 		firestoreClient: firestoreClient,
 	}
@@ -22,10 +49,12 @@ func (s *userStore) CreateUser(
 	ctx context.Context,
 	user user.DTO,
 ) error {
-	_, err := s.
-		getUserDoc(user.UserID).
-		Set(ctx, user)
+	userDoc, errDoc := s.getUserDoc(ctx, user.UserID)
+	if errDoc != nil {
+		return errDoc
+	}
 
+	_, err := userDoc.Set(ctx, user)
 	return err
 }
 
@@ -35,9 +64,14 @@ func (s *userStore) CreateReferral(
 	referral user.Referral,
 	version int,
 ) error {
-	referralRef := s.getUserReferralCollection(userID).Doc(referral.ID)
+	userDoc, errDoc := s.getUserDoc(ctx, userID)
+	if errDoc != nil {
+		return errDoc
+	}
+
+	referralRef := getUserReferralCollection(userDoc).Doc(referral.ID)
 	batch := s.
-		batchUpdateWithVersion(userID, version).
+		batchUpdateWithVersion(userDoc, version).
 		Create(referralRef, referral)
 
 	_, err := batch.Commit(ctx)
@@ -48,18 +82,24 @@ func (s *userStore) DeleteUser(
 	ctx context.Context,
 	userID string,
 ) error {
+	userDoc, errDoc := s.getUserDoc(ctx, userID)
+	if errDoc != nil {
+		return errDoc
+	}
+
 	// TODO: Is soft delete more appropriate here
 	// If using soft delete, need to update version also
-	_, err := s.
-		getUserDoc(userID).
-		Delete(ctx)
-
+	_, err := userDoc.Delete(ctx)
 	return err
 }
 
 func (s *userStore) Users(ctx context.Context) ([]user.DTO, error) {
-	docs, err := s.
-		getUserCollection().
+	userCollection, errCollection := s.getUserCollection(ctx)
+	if errCollection != nil {
+		return nil, errCollection
+	}
+
+	docs, err := userCollection.
 		Documents(ctx).
 		GetAll()
 
@@ -74,13 +114,20 @@ func (s *userStore) UpdateReferralStatus(
 	ctx context.Context,
 	userID, referralID string,
 	status user.ReferralStatus,
+	updatedAt time.Time,
 	version int,
 ) error {
-	referralRef := s.getUserReferralCollection(userID).Doc(referralID)
+	userDoc, errDoc := s.getUserDoc(ctx, userID)
+	if errDoc != nil {
+		return errDoc
+	}
+
+	referralRef := getUserReferralCollection(userDoc).Doc(referralID)
 	batch := s.
-		batchUpdateWithVersion(userID, version).
+		batchUpdateWithVersion(userDoc, version).
 		Update(referralRef, []firestore.Update{
 			{Path: "status", Value: string(status)},
+			{Path: "updatedAt", Value: updatedAt},
 		})
 
 	_, err := batch.Commit(ctx)
@@ -93,13 +140,61 @@ func (s *userStore) EarnPoints(
 	points uint32,
 	version int,
 ) error {
-	_, err := s.
-		getUserDoc(userID).
-		Update(ctx, []firestore.Update{
-			{Path: "points", Value: firestore.Increment(points)},
-			{Path: "version", Value: version},
+	userDoc, errDoc := s.getUserDoc(ctx, userID)
+	if errDoc != nil {
+		return errDoc
+	}
+
+	_, err := userDoc.Update(ctx, []firestore.Update{
+		{Path: "points", Value: firestore.Increment(points)},
+		{Path: "version", Value: version},
+	})
+
+	return err
+}
+
+func (s *userStore) RevokePoints(
+	ctx context.Context,
+	userID string,
+	points uint32,
+	version int,
+) error {
+	return s.decrementPoints(ctx, userID, points, version)
+}
+
+func (s *userStore) RedeemPoints(
+	ctx context.Context,
+	userID string,
+	points uint32,
+	version int,
+) error {
+	return s.decrementPoints(ctx, userID, points, version)
+}
+
+func (s *userStore) UpdateProfile(
+	ctx context.Context,
+	userID string,
+	profile user.Profile,
+	completedAt *time.Time,
+	version int,
+) error {
+	userDoc, errDoc := s.getUserDoc(ctx, userID)
+	if errDoc != nil {
+		return errDoc
+	}
+
+	updates := []firestore.Update{
+		{Path: "profile", Value: profile},
+		{Path: "version", Value: version},
+	}
+	if completedAt != nil {
+		updates = append(updates, firestore.Update{
+			Path:  "profileCompletedAt",
+			Value: *completedAt,
 		})
+	}
 
+	_, err := userDoc.Update(ctx, updates)
 	return err
 }
 
@@ -107,12 +202,23 @@ func (s *userStore) UserByReferralCode(
 	ctx context.Context,
 	referralCode string,
 ) (*user.DTO, error) {
-	doc, err := s.
-		getUserCollection().
+	userCollection, errCollection := s.getUserCollection(ctx)
+	if errCollection != nil {
+		return nil, errCollection
+	}
+
+	doc, err := userCollection.
 		Where("referralCode", "==", referralCode).
 		Documents(ctx).
 		Next()
 
+	if err == iterator.Done {
+		return nil, status.Errorf(
+			codes.NotFound,
+			"no user with referral code %v",
+			referralCode,
+		)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -124,10 +230,12 @@ func (s *userStore) User(
 	ctx context.Context,
 	userID string,
 ) (*user.DTO, error) {
-	doc, err := s.
-		getUserDoc(userID).
-		Get(ctx)
+	userDoc, errDoc := s.getUserDoc(ctx, userID)
+	if errDoc != nil {
+		return nil, errDoc
+	}
 
+	doc, err := userDoc.Get(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -139,8 +247,12 @@ func (s *userStore) Referrals(
 	ctx context.Context,
 	userID string,
 ) ([]user.Referral, error) {
-	docs, err := s.
-		getUserReferralCollection(userID).
+	userDoc, errDoc := s.getUserDoc(ctx, userID)
+	if errDoc != nil {
+		return nil, errDoc
+	}
+
+	docs, err := getUserReferralCollection(userDoc).
 		Documents(ctx).
 		GetAll()
 
@@ -151,30 +263,202 @@ func (s *userStore) Referrals(
 	return transformSnapshotsToReferrals(docs)
 }
 
+/* ----- collection switching ----- */
+// ActiveCollection returns the cached active collection, reading it from
+// the read model document on first use
+func (s *userStore) ActiveCollection(ctx context.Context) (string, error) {
+	s.mu.RLock()
+	collection := s.collection
+	s.mu.RUnlock()
+	if collection != "" {
+		return collection, nil
+	}
+
+	record, err := s.loadReadModelDocument(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.setCollection(record.Active)
+	return record.Active, nil
+}
+
+func (s *userStore) PreviousCollection(ctx context.Context) (string, error) {
+	record, err := s.loadReadModelDocument(ctx)
+	if err != nil {
+		return "", err
+	}
+	return record.Previous, nil
+}
+
+func (s *userStore) Shadow(collection string) user.ReadRepo {
+	return &userStore{
+		firestoreClient: s.firestoreClient,
+		pinned:          true,
+		collection:      collection,
+	}
+}
+
+func (s *userStore) SwitchCollection(
+	ctx context.Context,
+	collection string,
+) error {
+	ref := s.getReadModelDoc()
+	errTransaction := s.firestoreClient.RunTransaction(
+		ctx,
+		func(ctx context.Context, tx *firestore.Transaction) error {
+			record := readModelDocument{Active: defaultUserCollection}
+			doc, errGet := tx.Get(ref)
+			if errGet != nil && status.Code(errGet) != codes.NotFound {
+				return errGet
+			}
+			if errGet == nil {
+				errData := doc.DataTo(&record)
+				if errData != nil {
+					return errData
+				}
+			}
+
+			return tx.Set(ref, readModelDocument{
+				Active:   collection,
+				Previous: record.Active,
+			})
+		},
+	)
+	if errTransaction != nil {
+		return errTransaction
+	}
+
+	s.setCollection(collection)
+	return nil
+}
+
+// WatchCollection listens to the read model document and refreshes the
+// cached active collection when it changes, calling onSwitch with the new
+// collection. It blocks until ctx is done
+func (s *userStore) WatchCollection(
+	ctx context.Context,
+	onSwitch func(ctx context.Context, collection string),
+) error {
+	snapshots := s.getReadModelDoc().Snapshots(ctx)
+	defer snapshots.Stop()
+
+	for {
+		doc, err := snapshots.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		record := readModelDocument{Active: defaultUserCollection}
+		if doc.Exists() {
+			errData := doc.DataTo(&record)
+			if errData != nil {
+				return errData
+			}
+		}
+
+		s.mu.Lock()
+		switched := s.collection != "" && s.collection != record.Active
+		s.collection = record.Active
+		s.mu.Unlock()
+
+		if switched {
+			onSwitch(ctx, record.Active)
+		}
+	}
+}
+
 /* ----- helpers ----- */
-func (s *userStore) getUserCollection() *firestore.CollectionRef {
+func (s *userStore) setCollection(collection string) {
+	if s.pinned {
+		return
+	}
+
+	s.mu.Lock()
+	s.collection = collection
+	s.mu.Unlock()
+}
+
+// decrementPoints decrements the user's points atomically so that
+// concurrent projections of the same user cannot overwrite each other
+func (s *userStore) decrementPoints(
+	ctx context.Context,
+	userID string,
+	points uint32,
+	version int,
+) error {
+	userDoc, errDoc := s.getUserDoc(ctx, userID)
+	if errDoc != nil {
+		return errDoc
+	}
+
+	_, err := userDoc.Update(ctx, []firestore.Update{
+		{Path: "points", Value: firestore.Increment(-int64(points))},
+		{Path: "version", Value: version},
+	})
+
+	return err
+}
+func (s *userStore) getUserCollection(
+	ctx context.Context,
+) (*firestore.CollectionRef, error) {
+	collection, err := s.ActiveCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return s.firestoreClient.
-		Collection("users_es")
+		Collection(collection), nil
+}
+
+func getUserReferralCollection(
+	userDoc *firestore.DocumentRef,
+) *firestore.CollectionRef {
+	return userDoc.Collection("referrals")
+}
+
+func (s *userStore) getUserDoc(
+	ctx context.Context,
+	userID string,
+) (*firestore.DocumentRef, error) {
+	userCollection, err := s.getUserCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return userCollection.Doc(userID), nil
 }
 
-func (s *userStore) getUserReferralCollection(userID string) *firestore.CollectionRef {
-	return s.
-		getUserDoc(userID).
-		Collection("referrals")
+func (s *userStore) getReadModelDoc() *firestore.DocumentRef {
+	return s.firestoreClient.
+		Collection(readModelCollection).
+		Doc(userReadModelDoc)
 }
 
-func (s *userStore) getUserDoc(userID string) *firestore.DocumentRef {
-	return s.
-		getUserCollection().
-		Doc(userID)
+func (s *userStore) loadReadModelDocument(
+	ctx context.Context,
+) (*readModelDocument, error) {
+	record := readModelDocument{Active: defaultUserCollection}
+
+	doc, err := s.getReadModelDoc().Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return &record, nil
+		}
+		return nil, err
+	}
+
+	errData := doc.DataTo(&record)
+	return &record, errData
 }
 
 func (s *userStore) batchUpdateWithVersion(
-	userID string,
+	userRef *firestore.DocumentRef,
 	version int,
 ) *firestore.WriteBatch {
 	batch := s.firestoreClient.Batch()
-	userRef := s.getUserDoc(userID)
 	return batch.Update(
 		userRef,
 		[]firestore.Update{{Path: "version", Value: version}},
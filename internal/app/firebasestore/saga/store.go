@@ -0,0 +1,211 @@
+package saga
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type store struct {
+	firestoreClient *firestore.Client
+}
+
+// NewStore instantiates a new instance of the saga Store
+func NewStore(firestoreClient *firestore.Client) saga.Store {
+	return &store{
+		firestoreClient: firestoreClient,
+	}
+}
+
+var (
+	sagaCollection  = "sagas"
+	leaseCollection = "saga_leases"
+)
+
+// lease is the document of a lease of the saga runner
+type lease struct {
+	Owner     string    `firestore:"owner"`
+	ExpiresAt time.Time `firestore:"expiresAt"`
+}
+
+func (s *store) Instance(
+	ctx context.Context,
+	id string,
+) (*saga.Instance, error) {
+	doc, err := s.
+		getInstanceDoc(id).
+		Get(ctx)
+
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var instance saga.Instance
+	errData := doc.DataTo(&instance)
+	if errData != nil {
+		return nil, errData
+	}
+	return &instance, nil
+}
+
+func (s *store) Claim(
+	ctx context.Context,
+	instance saga.Instance,
+) (*saga.Instance, error) {
+	var claimed saga.Instance
+	ref := s.getInstanceDoc(instance.ID)
+	errTransaction := s.firestoreClient.RunTransaction(
+		ctx,
+		func(ctx context.Context, tx *firestore.Transaction) error {
+			current, err := getInstance(tx, ref)
+			if err != nil {
+				return err
+			}
+			if current == nil {
+				claimed = instance
+				return tx.Set(ref, claimed)
+			}
+
+			claimed = *current
+			if claimed.Settled() {
+				return nil
+			}
+			if claimed.LeasedByOther(instance.LeaseOwner, time.Now()) {
+				return saga.ErrInstanceClaimed
+			}
+			claimed.LeaseOwner = instance.LeaseOwner
+			claimed.LeaseExpiresAt = instance.LeaseExpiresAt
+			return tx.Set(ref, claimed)
+		},
+	)
+	if errTransaction != nil {
+		return nil, errTransaction
+	}
+	return &claimed, nil
+}
+
+func (s *store) Save(ctx context.Context, instance saga.Instance) error {
+	ref := s.getInstanceDoc(instance.ID)
+	return s.firestoreClient.RunTransaction(
+		ctx,
+		func(ctx context.Context, tx *firestore.Transaction) error {
+			current, err := getInstance(tx, ref)
+			if err != nil {
+				return err
+			}
+			if current != nil &&
+				current.LeasedByOther(instance.LeaseOwner, time.Now()) {
+				return saga.ErrInstanceClaimed
+			}
+			return tx.Set(ref, instance)
+		},
+	)
+}
+
+// Instances requires a composite index on (status, updatedAt asc)
+func (s *store) Instances(
+	ctx context.Context,
+	statuses ...saga.Status,
+) ([]saga.Instance, error) {
+	values := make([]string, 0, len(statuses))
+	for _, v := range statuses {
+		values = append(values, string(v))
+	}
+
+	docs, err := s.firestoreClient.
+		Collection(sagaCollection).
+		Where("status", "in", values).
+		OrderBy("updatedAt", firestore.Asc).
+		Documents(ctx).
+		GetAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []saga.Instance{}
+	for _, v := range docs {
+		var instance saga.Instance
+		errData := v.DataTo(&instance)
+		if errData != nil {
+			return nil, errData
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func (s *store) Lease(
+	ctx context.Context,
+	name string,
+	owner string,
+	until time.Time,
+) (bool, error) {
+	leased := false
+	ref := s.firestoreClient.
+		Collection(leaseCollection).
+		Doc(name)
+	errTransaction := s.firestoreClient.RunTransaction(
+		ctx,
+		func(ctx context.Context, tx *firestore.Transaction) error {
+			leased = false
+			doc, err := tx.Get(ref)
+			if err != nil && status.Code(err) != codes.NotFound {
+				return err
+			}
+			if err == nil {
+				var current lease
+				errData := doc.DataTo(&current)
+				if errData != nil {
+					return errData
+				}
+				if current.Owner != owner && time.Now().Before(current.ExpiresAt) {
+					return nil
+				}
+			}
+
+			leased = true
+			return tx.Set(ref, lease{Owner: owner, ExpiresAt: until})
+		},
+	)
+	if errTransaction != nil {
+		return false, errTransaction
+	}
+	return leased, nil
+}
+
+/* ----- helpers ----- */
+
+// getInstance returns the instance stored at ref, or nil if there is none
+func getInstance(
+	tx *firestore.Transaction,
+	ref *firestore.DocumentRef,
+) (*saga.Instance, error) {
+	doc, err := tx.Get(ref)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var instance saga.Instance
+	errData := doc.DataTo(&instance)
+	if errData != nil {
+		return nil, errData
+	}
+	return &instance, nil
+}
+
+func (s *store) getInstanceDoc(id string) *firestore.DocumentRef {
+	return s.firestoreClient.
+		Collection(sagaCollection).
+		Doc(id)
+}
@@ -0,0 +1,149 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/scheduler"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type store struct {
+	firestoreClient *firestore.Client
+}
+
+// NewStore instantiates a new instance of the scheduler Store
+func NewStore(firestoreClient *firestore.Client) scheduler.Store {
+	return &store{
+		firestoreClient: firestoreClient,
+	}
+}
+
+var (
+	scheduleCollection   = "scheduled_commands"
+	deadLetterCollection = "scheduled_commands_dead_letter"
+)
+
+func (s *store) Save(ctx context.Context, entry scheduler.Entry) error {
+	_, err := s.
+		getEntryDoc(entry.Key).
+		Set(ctx, entry)
+
+	return err
+}
+
+func (s *store) Cancel(ctx context.Context, key string) error {
+	_, err := s.
+		getEntryDoc(key).
+		Delete(ctx)
+
+	return err
+}
+
+func (s *store) Due(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]scheduler.Entry, error) {
+	docs, err := s.firestoreClient.
+		Collection(scheduleCollection).
+		Where("dueAt", "<=", now).
+		OrderBy("dueAt", firestore.Asc).
+		Limit(limit).
+		Documents(ctx).
+		GetAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]scheduler.Entry, 0, len(docs))
+	for _, v := range docs {
+		var entry scheduler.Entry
+		errData := v.DataTo(&entry)
+		if errData != nil {
+			return nil, errData
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (s *store) Complete(ctx context.Context, key string, id string) error {
+	return s.updateIfCurrent(ctx, key, id, func(
+		tx *firestore.Transaction,
+		ref *firestore.DocumentRef,
+	) error {
+		return tx.Delete(ref)
+	})
+}
+
+func (s *store) Retry(ctx context.Context, entry scheduler.Entry) error {
+	return s.updateIfCurrent(ctx, entry.Key, entry.ID, func(
+		tx *firestore.Transaction,
+		ref *firestore.DocumentRef,
+	) error {
+		return tx.Set(ref, entry)
+	})
+}
+
+// DeadLetter moves the entry to the dead letter collection, which Due does
+// not query. Entries are kept under their id so that an entry rescheduled
+// under the same key does not overwrite an earlier dead letter
+func (s *store) DeadLetter(ctx context.Context, entry scheduler.Entry) error {
+	return s.updateIfCurrent(ctx, entry.Key, entry.ID, func(
+		tx *firestore.Transaction,
+		ref *firestore.DocumentRef,
+	) error {
+		deadLetterRef := s.firestoreClient.
+			Collection(deadLetterCollection).
+			Doc(entry.ID)
+		errSet := tx.Set(deadLetterRef, entry)
+		if errSet != nil {
+			return errSet
+		}
+		return tx.Delete(ref)
+	})
+}
+
+/* ----- helpers ----- */
+func (s *store) getEntryDoc(key string) *firestore.DocumentRef {
+	return s.firestoreClient.
+		Collection(scheduleCollection).
+		Doc(key)
+}
+
+// updateIfCurrent applies update unless the entry has been cancelled or
+// replaced by a new scheduling
+func (s *store) updateIfCurrent(
+	ctx context.Context,
+	key string,
+	id string,
+	update func(*firestore.Transaction, *firestore.DocumentRef) error,
+) error {
+	ref := s.getEntryDoc(key)
+	return s.firestoreClient.RunTransaction(
+		ctx,
+		func(ctx context.Context, tx *firestore.Transaction) error {
+			doc, err := tx.Get(ref)
+			if status.Code(err) == codes.NotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			var current scheduler.Entry
+			errData := doc.DataTo(&current)
+			if errData != nil {
+				return errData
+			}
+			if current.ID != id {
+				return nil
+			}
+			return update(tx, ref)
+		},
+	)
+}
@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+)
+
+type store struct {
+	firestoreClient *firestore.Client
+}
+
+// NewStore instantiates a new instance of the wallet ReadRepo
+func NewStore(firestoreClient *firestore.Client) wallet.ReadRepo {
+	return &store{
+		firestoreClient: firestoreClient,
+	}
+}
+
+var walletCollection = "wallets"
+
+func (s *store) OpenWallet(ctx context.Context, w wallet.DTO) error {
+	_, err := s.
+		getWalletDoc(w.WalletID).
+		Set(ctx, w)
+
+	return err
+}
+
+func (s *store) AdjustBalance(
+	ctx context.Context,
+	walletID string,
+	delta int64,
+	version int,
+) error {
+	_, err := s.
+		getWalletDoc(walletID).
+		Update(ctx, []firestore.Update{
+			{Path: "balance", Value: firestore.Increment(delta)},
+			{Path: "version", Value: version},
+			{Path: "updatedAt", Value: time.Now()},
+		})
+
+	return err
+}
+
+func (s *store) Wallet(ctx context.Context, userID string) (*wallet.DTO, error) {
+	doc, err := s.
+		getWalletDoc(wallet.ID(userID)).
+		Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var w wallet.DTO
+	errData := doc.DataTo(&w)
+	if errData != nil {
+		return nil, errData
+	}
+	return &w, nil
+}
+
+/* ----- helpers ----- */
+func (s *store) getWalletDoc(walletID string) *firestore.DocumentRef {
+	return s.firestoreClient.
+		Collection(walletCollection).
+		Doc(walletID)
+}
@@ -0,0 +1,156 @@
+package webhook
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/webhook"
+)
+
+type store struct {
+	firestoreClient *firestore.Client
+}
+
+// NewStore instantiates a new instance of the webhook Store
+func NewStore(firestoreClient *firestore.Client) webhook.Store {
+	return &store{
+		firestoreClient: firestoreClient,
+	}
+}
+
+var (
+	subscriptionCollection = "webhook_subscriptions"
+	deliveryCollection     = "deliveries"
+)
+
+func (s *store) CreateSubscription(
+	ctx context.Context,
+	subscription webhook.Subscription,
+) error {
+	_, err := s.
+		getSubscriptionDoc(subscription.ID).
+		Create(ctx, subscription)
+
+	return err
+}
+
+func (s *store) DeleteSubscription(
+	ctx context.Context,
+	subscriptionID string,
+) error {
+	_, err := s.
+		getSubscriptionDoc(subscriptionID).
+		Delete(ctx)
+
+	return err
+}
+
+func (s *store) Subscriptions(
+	ctx context.Context,
+) ([]webhook.Subscription, error) {
+	docs, err := s.
+		getSubscriptionCollection().
+		OrderBy("createdAt", firestore.Asc).
+		Documents(ctx).
+		GetAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transformSnapshotsToSubscriptions(docs)
+}
+
+func (s *store) SubscriptionsByEventType(
+	ctx context.Context,
+	eventType string,
+) ([]webhook.Subscription, error) {
+	docs, err := s.
+		getSubscriptionCollection().
+		Where("eventTypes", "array-contains", eventType).
+		Documents(ctx).
+		GetAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transformSnapshotsToSubscriptions(docs)
+}
+
+func (s *store) RecordDelivery(
+	ctx context.Context,
+	delivery webhook.Delivery,
+) error {
+	_, err := s.
+		getDeliveryCollection(delivery.SubscriptionID).
+		Doc(delivery.ID).
+		Set(ctx, delivery)
+
+	return err
+}
+
+func (s *store) Deliveries(
+	ctx context.Context,
+	subscriptionID string,
+) ([]webhook.Delivery, error) {
+	docs, err := s.
+		getDeliveryCollection(subscriptionID).
+		OrderBy("deliveredAt", firestore.Desc).
+		Documents(ctx).
+		GetAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transformSnapshotsToDeliveries(docs)
+}
+
+/* ----- helpers ----- */
+func (s *store) getSubscriptionCollection() *firestore.CollectionRef {
+	return s.firestoreClient.
+		Collection(subscriptionCollection)
+}
+
+func (s *store) getSubscriptionDoc(subscriptionID string) *firestore.DocumentRef {
+	return s.
+		getSubscriptionCollection().
+		Doc(subscriptionID)
+}
+
+func (s *store) getDeliveryCollection(subscriptionID string) *firestore.CollectionRef {
+	return s.
+		getSubscriptionDoc(subscriptionID).
+		Collection(deliveryCollection)
+}
+
+func transformSnapshotsToSubscriptions(
+	snapshots []*firestore.DocumentSnapshot,
+) ([]webhook.Subscription, error) {
+	subscriptions := []webhook.Subscription{}
+	for _, v := range snapshots {
+		var subscription webhook.Subscription
+		err := v.DataTo(&subscription)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
+
+func transformSnapshotsToDeliveries(
+	snapshots []*firestore.DocumentSnapshot,
+) ([]webhook.Delivery, error) {
+	deliveries := []webhook.Delivery{}
+	for _, v := range snapshots {
+		var delivery webhook.Delivery
+		err := v.DataTo(&delivery)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,410 @@
+package loyalty
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Facts are the attributes award rules are evaluated against, keyed by the
+// Fact* names. A rule condition on a missing fact never matches
+type Facts map[string]interface{}
+
+// Facts award rules can be evaluated against
+const (
+	// FactNow is the time the awarded action happened at
+	FactNow                            = "now"
+	FactUserCreatedAt                  = "user.createdAt"
+	FactUserEmailDomain                = "user.emailDomain"
+	FactUserPoints                     = "user.points"
+	FactUserReferralsCompleted         = "user.referralsCompleted"
+	FactUserReferralsCompletedThisYear = "user.referralsCompletedThisYear"
+)
+
+type factKind string
+
+const (
+	factKindTime   factKind = "time"
+	factKindNumber factKind = "number"
+	factKindString factKind = "string"
+)
+
+var factKinds = map[string]factKind{
+	FactNow:                            factKindTime,
+	FactUserCreatedAt:                  factKindTime,
+	FactUserEmailDomain:                factKindString,
+	FactUserPoints:                     factKindNumber,
+	FactUserReferralsCompleted:         factKindNumber,
+	FactUserReferralsCompletedThisYear: factKindNumber,
+}
+
+// ConditionOperator compares a fact with the value of a condition
+type ConditionOperator string
+
+// Supported condition operators. The current month, year and day window
+// are relative to FactNow
+const (
+	OperatorEq             ConditionOperator = "eq"
+	OperatorNe             ConditionOperator = "ne"
+	OperatorGt             ConditionOperator = "gt"
+	OperatorGte            ConditionOperator = "gte"
+	OperatorLt             ConditionOperator = "lt"
+	OperatorLte            ConditionOperator = "lte"
+	OperatorIn             ConditionOperator = "in"
+	OperatorInCurrentMonth ConditionOperator = "inCurrentMonth"
+	OperatorInCurrentYear  ConditionOperator = "inCurrentYear"
+	OperatorWithinDays     ConditionOperator = "withinDays"
+)
+
+var operatorKinds = map[ConditionOperator][]factKind{
+	OperatorEq:             {factKindNumber, factKindString},
+	OperatorNe:             {factKindNumber, factKindString},
+	OperatorGt:             {factKindNumber},
+	OperatorGte:            {factKindNumber},
+	OperatorLt:             {factKindNumber},
+	OperatorLte:            {factKindNumber},
+	OperatorIn:             {factKindNumber, factKindString},
+	OperatorInCurrentMonth: {factKindTime},
+	OperatorInCurrentYear:  {factKindTime},
+	OperatorWithinDays:     {factKindTime},
+}
+
+// EffectType is how an award rule changes the awarded points
+type EffectType string
+
+const (
+	// EffectMultiply multiplies the points by Value, rounding to the
+	// nearest point
+	EffectMultiply EffectType = "multiply"
+	// EffectAdd adds Value, which may be negative, to the points
+	EffectAdd EffectType = "add"
+	// EffectSet replaces the points with Value, e.g. 0 to cap rewards
+	EffectSet EffectType = "set"
+)
+
+// AwardCondition holds when Fact compared with Value using Operator is
+// true. Value is a number or a string, a list of them for OperatorIn and
+// omitted for OperatorInCurrentMonth and OperatorInCurrentYear
+type AwardCondition struct {
+	Fact     string            `json:"fact"`
+	Operator ConditionOperator `json:"operator"`
+	Value    interface{}       `json:"value,omitempty"`
+}
+
+type AwardEffect struct {
+	Type  EffectType `json:"type"`
+	Value float64    `json:"value"`
+}
+
+// AwardRule changes the points granted for Action when all its Conditions
+// hold. Matching rules are applied in the order they are declared in
+type AwardRule struct {
+	ID          string           `json:"id"`
+	Description string           `json:"description,omitempty"`
+	Action      PointsAction     `json:"action"`
+	Conditions  []AwardCondition `json:"conditions"`
+	Effect      AwardEffect      `json:"effect"`
+}
+
+// Award is the outcome of evaluating the award rules of an action
+type Award struct {
+	Action      PointsAction
+	Points      uint32
+	RuleVersion int
+
+	// MatchedRuleIDs are the ids of the award rules that changed the
+	// points of the PointsRule, in the order they were applied
+	MatchedRuleIDs []string
+}
+
+// ParseAwardRules decodes and validates a JSON list of award rules
+func ParseAwardRules(data []byte) ([]AwardRule, error) {
+	rules := []AwardRule{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, errors.Wrap(err, "unable to decode award rules")
+	}
+
+	if err := ValidateAwardRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ValidateAwardRules checks that rules have unique ids, apply to supported
+// actions and only use known facts with operators and values they support
+func ValidateAwardRules(rules []AwardRule) error {
+	supported := make(map[PointsAction]bool)
+	for _, v := range DefaultPointsRules() {
+		supported[v.Action] = true
+	}
+
+	ids := make(map[string]bool)
+	for i, v := range rules {
+		if strings.TrimSpace(v.ID) == "" {
+			return errors.Errorf("award rule %v: id is required", i)
+		}
+		if ids[v.ID] {
+			return errors.Errorf("award rule %v: duplicate id", v.ID)
+		}
+		ids[v.ID] = true
+
+		if !supported[v.Action] {
+			return errors.Errorf(
+				"award rule %v: point action %v not supported",
+				v.ID,
+				v.Action,
+			)
+		}
+		for _, c := range v.Conditions {
+			if err := validateCondition(c); err != nil {
+				return errors.Wrapf(err, "award rule %v", v.ID)
+			}
+		}
+		if err := validateEffect(v.Effect); err != nil {
+			return errors.Wrapf(err, "award rule %v", v.ID)
+		}
+	}
+	return nil
+}
+
+// EvaluateAwardRules applies the award rules of rule.Action whose
+// conditions hold for facts to the points of rule. Points never go below 0
+func EvaluateAwardRules(
+	rule PointsRule,
+	rules []AwardRule,
+	facts Facts,
+) *Award {
+	award := &Award{
+		Action:         rule.Action,
+		RuleVersion:    rule.Version,
+		MatchedRuleIDs: []string{},
+	}
+
+	points := float64(rule.Points)
+	for _, v := range rules {
+		if v.Action != rule.Action || !conditionsHold(v.Conditions, facts) {
+			continue
+		}
+
+		switch v.Effect.Type {
+		case EffectMultiply:
+			points = math.Round(points * v.Effect.Value)
+		case EffectAdd:
+			points += v.Effect.Value
+		case EffectSet:
+			points = v.Effect.Value
+		}
+		award.MatchedRuleIDs = append(award.MatchedRuleIDs, v.ID)
+	}
+
+	award.Points = uint32(math.Min(math.Max(points, 0), math.MaxUint32))
+	return award
+}
+
+/* ----- validation ----- */
+func validateCondition(c AwardCondition) error {
+	kind, ok := factKinds[c.Fact]
+	if !ok {
+		return errors.Errorf("unknown fact %v", c.Fact)
+	}
+	if !operatorSupports(c.Operator, kind) {
+		return errors.Errorf(
+			"operator %v not supported by %v fact %v",
+			c.Operator,
+			kind,
+			c.Fact,
+		)
+	}
+
+	switch c.Operator {
+	case OperatorInCurrentMonth, OperatorInCurrentYear:
+		if c.Value != nil {
+			return errors.Errorf("operator %v takes no value", c.Operator)
+		}
+	case OperatorWithinDays:
+		days, isNumber := toNumber(c.Value)
+		if !isNumber || days < 0 {
+			return errors.Errorf("operator %v takes a number of days", c.Operator)
+		}
+	case OperatorIn:
+		values, isList := c.Value.([]interface{})
+		if !isList || len(values) == 0 {
+			return errors.Errorf("operator %v takes a list of values", c.Operator)
+		}
+		for _, v := range values {
+			if !valueIsKind(v, kind) {
+				return errors.Errorf("fact %v takes %v values", c.Fact, kind)
+			}
+		}
+	default:
+		if !valueIsKind(c.Value, kind) {
+			return errors.Errorf("fact %v takes %v values", c.Fact, kind)
+		}
+	}
+	return nil
+}
+
+func validateEffect(e AwardEffect) error {
+	switch e.Type {
+	case EffectMultiply:
+		if e.Value <= 0 {
+			return errors.New("multiply effect takes a positive value")
+		}
+	case EffectAdd:
+		if e.Value == 0 || e.Value != math.Trunc(e.Value) {
+			return errors.New("add effect takes a non zero whole number")
+		}
+	case EffectSet:
+		if e.Value < 0 || e.Value != math.Trunc(e.Value) {
+			return errors.New("set effect takes a non negative whole number")
+		}
+	default:
+		return errors.Errorf("unknown effect type %v", e.Type)
+	}
+	return nil
+}
+
+func operatorSupports(operator ConditionOperator, kind factKind) bool {
+	for _, v := range operatorKinds[operator] {
+		if v == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func valueIsKind(value interface{}, kind factKind) bool {
+	switch kind {
+	case factKindNumber:
+		_, ok := toNumber(value)
+		return ok
+	case factKindString:
+		_, ok := value.(string)
+		return ok
+	}
+	return false
+}
+
+/* ----- evaluation ----- */
+func conditionsHold(conditions []AwardCondition, facts Facts) bool {
+	for _, v := range conditions {
+		if !conditionHolds(v, facts) {
+			return false
+		}
+	}
+	return true
+}
+
+func conditionHolds(c AwardCondition, facts Facts) bool {
+	fact, ok := facts[c.Fact]
+	if !ok {
+		return false
+	}
+
+	switch factKinds[c.Fact] {
+	case factKindTime:
+		return timeConditionHolds(c, fact, facts)
+	case factKindNumber:
+		return numberConditionHolds(c, fact)
+	case factKindString:
+		return stringConditionHolds(c, fact)
+	}
+	return false
+}
+
+func timeConditionHolds(c AwardCondition, fact interface{}, facts Facts) bool {
+	at, isTime := fact.(time.Time)
+	now, isNow := facts[FactNow].(time.Time)
+	if !isTime || !isNow {
+		return false
+	}
+	at = at.In(now.Location())
+
+	switch c.Operator {
+	case OperatorInCurrentMonth:
+		return at.Year() == now.Year() && at.Month() == now.Month()
+	case OperatorInCurrentYear:
+		return at.Year() == now.Year()
+	case OperatorWithinDays:
+		days, _ := toNumber(c.Value)
+		return now.Sub(at) <= time.Duration(days*float64(24*time.Hour))
+	}
+	return false
+}
+
+func numberConditionHolds(c AwardCondition, fact interface{}) bool {
+	number, isNumber := toNumber(fact)
+	if !isNumber {
+		return false
+	}
+
+	if c.Operator == OperatorIn {
+		values, _ := c.Value.([]interface{})
+		for _, v := range values {
+			if value, _ := toNumber(v); value == number {
+				return true
+			}
+		}
+		return false
+	}
+
+	value, _ := toNumber(c.Value)
+	switch c.Operator {
+	case OperatorEq:
+		return number == value
+	case OperatorNe:
+		return number != value
+	case OperatorGt:
+		return number > value
+	case OperatorGte:
+		return number >= value
+	case OperatorLt:
+		return number < value
+	case OperatorLte:
+		return number <= value
+	}
+	return false
+}
+
+func stringConditionHolds(c AwardCondition, fact interface{}) bool {
+	str, isString := fact.(string)
+	if !isString {
+		return false
+	}
+
+	value, _ := c.Value.(string)
+	switch c.Operator {
+	case OperatorEq:
+		return strings.EqualFold(str, value)
+	case OperatorNe:
+		return !strings.EqualFold(str, value)
+	case OperatorIn:
+		values, _ := c.Value.([]interface{})
+		for _, v := range values {
+			if listed, _ := v.(string); strings.EqualFold(str, listed) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	}
+	return 0, false
+}
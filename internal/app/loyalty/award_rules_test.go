@@ -0,0 +1,237 @@
+package loyalty
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/* ----- tests ----- */
+func TestParseAwardRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		valid bool
+	}{
+		{
+			name: "valid",
+			rules: `[
+				{
+					"id": "double-new-users",
+					"action": "ReferUser",
+					"conditions": [
+						{"fact": "user.createdAt", "operator": "inCurrentMonth"}
+					],
+					"effect": {"type": "multiply", "value": 2}
+				},
+				{
+					"id": "cap-referrals",
+					"action": "ReferUser",
+					"conditions": [
+						{"fact": "user.referralsCompletedThisYear", "operator": "gt", "value": 10}
+					],
+					"effect": {"type": "set", "value": 0}
+				},
+				{
+					"id": "partners",
+					"action": "SignUpWithoutReferral",
+					"conditions": [
+						{"fact": "user.emailDomain", "operator": "in", "value": ["partner.com"]}
+					],
+					"effect": {"type": "add", "value": 50}
+				}
+			]`,
+			valid: true,
+		},
+		{
+			name:  "malformed",
+			rules: `{"id": "a"}`,
+		},
+		{
+			name:  "unknown field",
+			rules: `[{"id": "a", "action": "ReferUser", "effect": {"type": "add", "value": 1}, "priority": 1}]`,
+		},
+		{
+			name:  "missing id",
+			rules: `[{"action": "ReferUser", "effect": {"type": "add", "value": 1}}]`,
+		},
+		{
+			name: "duplicate id",
+			rules: `[
+				{"id": "a", "action": "ReferUser", "effect": {"type": "add", "value": 1}},
+				{"id": "a", "action": "ReferUser", "effect": {"type": "add", "value": 1}}
+			]`,
+		},
+		{
+			name:  "unsupported action",
+			rules: `[{"id": "a", "action": "Unknown", "effect": {"type": "add", "value": 1}}]`,
+		},
+		{
+			name: "unknown fact",
+			rules: `[{"id": "a", "action": "ReferUser",
+				"conditions": [{"fact": "user.age", "operator": "gt", "value": 1}],
+				"effect": {"type": "add", "value": 1}}]`,
+		},
+		{
+			name: "operator not supported by fact",
+			rules: `[{"id": "a", "action": "ReferUser",
+				"conditions": [{"fact": "user.emailDomain", "operator": "gt", "value": 1}],
+				"effect": {"type": "add", "value": 1}}]`,
+		},
+		{
+			name: "value of wrong kind",
+			rules: `[{"id": "a", "action": "ReferUser",
+				"conditions": [{"fact": "user.points", "operator": "eq", "value": "1"}],
+				"effect": {"type": "add", "value": 1}}]`,
+		},
+		{
+			name: "empty in list",
+			rules: `[{"id": "a", "action": "ReferUser",
+				"conditions": [{"fact": "user.points", "operator": "in", "value": []}],
+				"effect": {"type": "add", "value": 1}}]`,
+		},
+		{
+			name:  "unknown effect",
+			rules: `[{"id": "a", "action": "ReferUser", "effect": {"type": "divide", "value": 2}}]`,
+		},
+		{
+			name:  "fractional set",
+			rules: `[{"id": "a", "action": "ReferUser", "effect": {"type": "set", "value": 1.5}}]`,
+		},
+		{
+			name:  "negative multiply",
+			rules: `[{"id": "a", "action": "ReferUser", "effect": {"type": "multiply", "value": -1}}]`,
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := ParseAwardRules([]byte(v.rules))
+			assert.Equal(t, v.valid, err == nil, "error: %v", err)
+		})
+	}
+}
+
+func TestEvaluateAwardRules(t *testing.T) {
+	now := time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+	rule := PointsRule{Action: PointsActionReferUser, Points: 200, Version: 3}
+
+	double := AwardRule{
+		ID:     "double-new-users",
+		Action: PointsActionReferUser,
+		Conditions: []AwardCondition{
+			{Fact: FactUserCreatedAt, Operator: OperatorInCurrentMonth},
+		},
+		Effect: AwardEffect{Type: EffectMultiply, Value: 2},
+	}
+	capped := AwardRule{
+		ID:     "cap-referrals",
+		Action: PointsActionReferUser,
+		Conditions: []AwardCondition{
+			{Fact: FactUserReferralsCompletedThisYear, Operator: OperatorGte, Value: float64(10)},
+		},
+		Effect: AwardEffect{Type: EffectSet, Value: 0},
+	}
+	penalty := AwardRule{
+		ID:     "penalty",
+		Action: PointsActionReferUser,
+		Conditions: []AwardCondition{
+			{Fact: FactUserEmailDomain, Operator: OperatorIn, Value: []interface{}{"Example.com"}},
+		},
+		Effect: AwardEffect{Type: EffectAdd, Value: -500},
+	}
+	other := AwardRule{
+		ID:     "other-action",
+		Action: PointsActionSignUpWithReferral,
+		Effect: AwardEffect{Type: EffectAdd, Value: 10},
+	}
+	rules := []AwardRule{double, capped, penalty, other}
+
+	tests := []struct {
+		name    string
+		facts   Facts
+		points  uint32
+		matched []string
+	}{
+		{
+			name:    "no facts",
+			facts:   Facts{FactNow: now},
+			points:  200,
+			matched: []string{},
+		},
+		{
+			name: "created this month",
+			facts: Facts{
+				FactNow:                            now,
+				FactUserCreatedAt:                  now.AddDate(0, 0, -14),
+				FactUserReferralsCompletedThisYear: 3,
+			},
+			points:  400,
+			matched: []string{"double-new-users"},
+		},
+		{
+			name: "created last month",
+			facts: Facts{
+				FactNow:           now,
+				FactUserCreatedAt: now.AddDate(0, -1, 0),
+			},
+			points:  200,
+			matched: []string{},
+		},
+		{
+			name: "capped",
+			facts: Facts{
+				FactNow:                            now,
+				FactUserCreatedAt:                  now,
+				FactUserReferralsCompletedThisYear: 10,
+			},
+			points:  0,
+			matched: []string{"double-new-users", "cap-referrals"},
+		},
+		{
+			name: "never negative",
+			facts: Facts{
+				FactNow:             now,
+				FactUserEmailDomain: "example.com",
+			},
+			points:  0,
+			matched: []string{"penalty"},
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			award := EvaluateAwardRules(rule, rules, v.facts)
+			assert.Equal(t, PointsActionReferUser, award.Action)
+			assert.Equal(t, 3, award.RuleVersion)
+			assert.Equal(t, v.points, award.Points)
+			assert.Equal(t, v.matched, award.MatchedRuleIDs)
+		})
+	}
+}
+
+func TestEvaluateAwardRules_WithinDays(t *testing.T) {
+	now := time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+	rule := PointsRule{Action: PointsActionSignUpWithoutReferral, Points: 100}
+	rules := []AwardRule{{
+		ID:     "early-birds",
+		Action: PointsActionSignUpWithoutReferral,
+		Conditions: []AwardCondition{
+			{Fact: FactUserCreatedAt, Operator: OperatorWithinDays, Value: float64(7)},
+		},
+		Effect: AwardEffect{Type: EffectAdd, Value: 25},
+	}}
+
+	recent := EvaluateAwardRules(rule, rules, Facts{
+		FactNow:           now,
+		FactUserCreatedAt: now.AddDate(0, 0, -7),
+	})
+	assert.Equal(t, uint32(125), recent.Points)
+
+	old := EvaluateAwardRules(rule, rules, Facts{
+		FactNow:           now,
+		FactUserCreatedAt: now.AddDate(0, 0, -8),
+	})
+	assert.Equal(t, uint32(100), old.Points)
+}
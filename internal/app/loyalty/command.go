@@ -1,13 +1,33 @@
 package loyalty
 
-import "github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+import (
+	"regexp"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// BirthdayLayout is the format of profile birthdays
+const BirthdayLayout = "2006-01-02"
+
+// Validation rules checked by UpdateProfile in addition to its validate
+// struct tags
+const (
+	RuleDate  = "date"
+	RulePast  = "past"
+	RulePhone = "phone"
+)
+
+// phonePattern accepts an optional leading + followed by 7 to 15 digits,
+// which may be grouped with spaces, dashes, dots or parentheses
+var phonePattern = regexp.MustCompile(`^\+?[0-9 ().-]+$`)
 
 // CreateUser command
 type CreateUser struct {
 	eventsource.CommandModel
-	Username       string  `json:"username"`
-	Email          string  `json:"email"`
-	ReferredByCode *string `json:"referredByCode"`
+	Username       string  `json:"username" validate:"required,min=3,max=32"`
+	Email          string  `json:"email" validate:"required,email,max=254"`
+	ReferredByCode *string `json:"referredByCode" validate:"max=32"`
 }
 
 // DeleteUser command
@@ -18,19 +38,168 @@ type DeleteUser struct {
 // CreateReferral command
 type CreateReferral struct {
 	eventsource.CommandModel
-	ReferredUserEmail string `json:"referredUserEmail"`
+	ReferredUserEmail string `json:"referredUserEmail" validate:"required,email,max=254"`
 }
 
 // CompleteReferral command
 type CompleteReferral struct {
 	eventsource.CommandModel
-	ReferredUserEmail string `json:"referredUserEmail"`
-	ReferredUserID    string `json:"referredUserId"`
-	ReferredByCode    string `json:"referredByCode"`
+	ReferredUserEmail string `json:"referredUserEmail" validate:"required,email,max=254"`
+	ReferredUserID    string `json:"referredUserId" validate:"required"`
+	ReferredByCode    string `json:"referredByCode" validate:"required,max=32"`
+}
+
+// ExpireReferral command
+type ExpireReferral struct {
+	eventsource.CommandModel
+	ReferralID string `json:"referralId" validate:"required"`
+}
+
+// MarkReferralSent command
+type MarkReferralSent struct {
+	eventsource.CommandModel
+	ReferralID string `json:"referralId" validate:"required"`
+}
+
+// CancelReferral command
+type CancelReferral struct {
+	eventsource.CommandModel
+	ReferralID string `json:"referralId" validate:"required"`
+	Reason     string `json:"reason" validate:"max=256"`
 }
 
 // EarnPoints command
 type EarnPoints struct {
 	eventsource.CommandModel
-	Points uint32 `json:"points"`
+	Points uint32 `json:"points" validate:"min=1"`
+
+	// RuleAction and RuleVersion identify the points rule that granted
+	// the points, if any, and AwardRuleIDs the award rules that changed them
+	RuleAction   PointsAction `json:"ruleAction"`
+	RuleVersion  int          `json:"ruleVersion"`
+	AwardRuleIDs []string     `json:"awardRuleIds"`
+}
+
+// RevokePoints command
+type RevokePoints struct {
+	eventsource.CommandModel
+	Points uint32 `json:"points" validate:"min=1"`
+	Reason string `json:"reason" validate:"max=256"`
+}
+
+// RedeemPoints command
+type RedeemPoints struct {
+	eventsource.CommandModel
+	Points uint32 `json:"points" validate:"min=1"`
+	Reason string `json:"reason" validate:"max=256"`
+}
+
+// OpenWallet command
+type OpenWallet struct {
+	eventsource.CommandModel
+	UserID string `json:"userId" validate:"required"`
+}
+
+// EarnWalletPoints command
+type EarnWalletPoints struct {
+	eventsource.CommandModel
+	Points uint32 `json:"points" validate:"min=1"`
+	Reason string `json:"reason" validate:"max=256"`
+}
+
+// RedeemWalletPoints command
+type RedeemWalletPoints struct {
+	eventsource.CommandModel
+	Points uint32 `json:"points" validate:"min=1"`
+	Reason string `json:"reason" validate:"max=256"`
+}
+
+// AdjustWalletPoints command
+type AdjustWalletPoints struct {
+	eventsource.CommandModel
+	Delta  int64  `json:"delta"`
+	Reason string `json:"reason" validate:"required,max=256"`
+}
+
+// ExpireWalletPoints command
+type ExpireWalletPoints struct {
+	eventsource.CommandModel
+	Points uint32 `json:"points" validate:"min=1"`
+}
+
+// TransferWalletPoints command
+type TransferWalletPoints struct {
+	eventsource.CommandModel
+	ToUserID string `json:"toUserId" validate:"required"`
+	Points   uint32 `json:"points" validate:"min=1"`
+}
+
+// UpdateProfile command. Fields left nil are not changed and empty fields
+// are cleared
+type UpdateProfile struct {
+	eventsource.CommandModel
+	Name     *string `json:"name" validate:"max=128"`
+	Birthday *string `json:"birthday"`
+	Phone    *string `json:"phone" validate:"max=32"`
+}
+
+// Validate implements the Validator interface
+func (c *UpdateProfile) Validate() error {
+	if c.Name == nil && c.Birthday == nil && c.Phone == nil {
+		return &eventsource.ValidationError{
+			Fields: []eventsource.FieldError{
+				{
+					Field:   "name",
+					Rule:    eventsource.RuleRequired,
+					Message: "is required when birthday and phone are omitted",
+				},
+			},
+		}
+	}
+
+	var fieldErrors []eventsource.FieldError
+	if birthday := eventsource.StringValue(c.Birthday); birthday != "" {
+		date, err := time.Parse(BirthdayLayout, birthday)
+		switch {
+		case err != nil:
+			fieldErrors = append(fieldErrors, eventsource.FieldError{
+				Field:   "birthday",
+				Rule:    RuleDate,
+				Message: "must be formatted as " + BirthdayLayout,
+			})
+		case date.After(time.Now()):
+			fieldErrors = append(fieldErrors, eventsource.FieldError{
+				Field:   "birthday",
+				Rule:    RulePast,
+				Message: "must not be in the future",
+			})
+		}
+	}
+
+	if phone := eventsource.StringValue(c.Phone); phone != "" && !isPhone(phone) {
+		fieldErrors = append(fieldErrors, eventsource.FieldError{
+			Field:   "phone",
+			Rule:    RulePhone,
+			Message: "must be a phone number",
+		})
+	}
+
+	if len(fieldErrors) > 0 {
+		return &eventsource.ValidationError{Fields: fieldErrors}
+	}
+	return nil
+}
+
+func isPhone(phone string) bool {
+	if !phonePattern.MatchString(phone) {
+		return false
+	}
+
+	digits := 0
+	for _, v := range phone {
+		if v >= '0' && v <= '9' {
+			digits++
+		}
+	}
+	return digits >= 7 && digits <= 15
 }
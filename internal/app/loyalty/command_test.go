@@ -0,0 +1,121 @@
+package loyalty
+
+import (
+	"testing"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommands_ValidationRulesAreValid(t *testing.T) {
+	commands := []eventsource.Command{
+		&CreateUser{},
+		&DeleteUser{},
+		&CreateReferral{},
+		&CompleteReferral{},
+		&MarkReferralSent{},
+		&CancelReferral{},
+		&EarnPoints{},
+		&UpdateProfile{},
+	}
+	for _, v := range commands {
+		err := eventsource.Validate(v)
+		if err == nil {
+			continue
+		}
+		_, ok := eventsource.AsValidationError(err)
+		assert.True(t, ok, err.Error())
+	}
+}
+
+func TestCreateUser_Validation(t *testing.T) {
+	assert := assert.New(t)
+
+	err := eventsource.Validate(&CreateUser{
+		CommandModel: eventsource.CommandModel{ID: "123123"},
+		Username:     "ab",
+		Email:        "invalid",
+	})
+
+	validationErr, ok := eventsource.AsValidationError(err)
+	assert.True(ok)
+	assert.Len(validationErr.Fields, 2)
+	assert.Equal("username", validationErr.Fields[0].Field)
+	assert.Equal("email", validationErr.Fields[1].Field)
+}
+
+func TestUpdateProfile_Validate(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		command UpdateProfile
+		field   string
+		rule    string
+	}{
+		{name: "no fields", field: "name", rule: eventsource.RuleRequired},
+		{
+			name: "complete",
+			command: UpdateProfile{
+				Name:     str("Ada Lovelace"),
+				Birthday: str("1815-12-10"),
+				Phone:    str("+44 (20) 7946-0958"),
+			},
+		},
+		{name: "cleared fields", command: UpdateProfile{Phone: str("")}},
+		{
+			name:    "malformed birthday",
+			command: UpdateProfile{Birthday: str("10/12/1815")},
+			field:   "birthday",
+			rule:    RuleDate,
+		},
+		{
+			name:    "future birthday",
+			command: UpdateProfile{Birthday: str("2999-01-01")},
+			field:   "birthday",
+			rule:    RulePast,
+		},
+		{
+			name:    "letters in phone",
+			command: UpdateProfile{Phone: str("555-CALL-NOW")},
+			field:   "phone",
+			rule:    RulePhone,
+		},
+		{
+			name:    "short phone",
+			command: UpdateProfile{Phone: str("12345")},
+			field:   "phone",
+			rule:    RulePhone,
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := v.command.Validate()
+			if v.field == "" {
+				assert.Nil(err)
+				return
+			}
+
+			validationErr, ok := eventsource.AsValidationError(err)
+			if assert.True(ok) && assert.Len(validationErr.Fields, 1) {
+				assert.Equal(v.field, validationErr.Fields[0].Field)
+				assert.Equal(v.rule, validationErr.Fields[0].Rule)
+			}
+		})
+	}
+}
+
+func TestUpdateProfile_ValidateReportsEveryField(t *testing.T) {
+	assert := assert.New(t)
+	birthday, phone := "2999-01-01", "12345"
+
+	err := (&UpdateProfile{Birthday: &birthday, Phone: &phone}).Validate()
+	validationErr, ok := eventsource.AsValidationError(err)
+	assert.True(ok)
+	assert.Len(validationErr.Fields, 2)
+	assert.Equal("birthday", validationErr.Fields[0].Field)
+	assert.Equal("phone", validationErr.Fields[1].Field)
+}
@@ -1,6 +1,10 @@
 package loyalty
 
-import "github.com/pkg/errors"
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 type PointsAction string
 
@@ -8,34 +12,70 @@ const (
 	PointsActionReferUser             PointsAction = "ReferUser"
 	PointsActionSignUpWithReferral    PointsAction = "SignUpWithReferral"
 	PointsActionSignUpWithoutReferral PointsAction = "SignUpWithoutReferral"
+	PointsActionCompleteProfile       PointsAction = "CompleteProfile"
 )
 
+// PointsRule grants Points for an action. Every change to a rule
+// increments its Version, which is recorded on the points it grants
+type PointsRule struct {
+	Action    PointsAction `json:"action" firestore:"action"`
+	Points    uint32       `json:"points" firestore:"points"`
+	Version   int          `json:"version" firestore:"version"`
+	UpdatedAt time.Time    `json:"updatedAt" firestore:"updatedAt"`
+	UpdatedBy string       `json:"updatedBy" firestore:"updatedBy"`
+}
+
+// DefaultPointsRules returns the rules used until they are changed
+func DefaultPointsRules() []PointsRule {
+	return []PointsRule{
+		{Action: PointsActionReferUser, Points: 200, Version: 1},
+		{Action: PointsActionSignUpWithReferral, Points: 200, Version: 1},
+		{Action: PointsActionSignUpWithoutReferral, Points: 100, Version: 1},
+		{Action: PointsActionCompleteProfile, Points: 50, Version: 1},
+	}
+}
+
 type PointsMappingService interface {
-	Map(action PointsAction) (*uint32, error)
+	// Map returns the rule that currently applies to action
+	Map(action PointsAction) (*PointsRule, error)
+
+	// Award returns the points granted for action once the award rules
+	// are evaluated against facts
+	Award(action PointsAction, facts Facts) (*Award, error)
 }
 
-type pointsMappingService struct{}
+type pointsMappingService struct {
+	rules      map[PointsAction]PointsRule
+	awardRules []AwardRule
+}
 
+// NewPointsActionMappingService creates a PointsMappingService that always
+// maps actions with DefaultPointsRules and applies no award rules
 func NewPointsActionMappingService() PointsMappingService {
-	return &pointsMappingService{}
+	rules := make(map[PointsAction]PointsRule)
+	for _, v := range DefaultPointsRules() {
+		rules[v.Action] = v
+	}
+	return &pointsMappingService{
+		rules: rules,
+	}
 }
 
-func (p *pointsMappingService) Map(action PointsAction) (*uint32, error) {
-	//TODO: Mapping should live in a store so that values can be changed
-	// without re-deploying any applications using the values
-	switch action {
-	case PointsActionReferUser:
-		fallthrough
-	case PointsActionSignUpWithReferral:
-		return getPointValuePointer(200), nil
-	case PointsActionSignUpWithoutReferral:
-		return getPointValuePointer(100), nil
-	default:
+func (p *pointsMappingService) Map(action PointsAction) (*PointsRule, error) {
+	rule, ok := p.rules[action]
+	if !ok {
 		return nil, errors.Errorf("point action %v not supported", action)
 	}
+	return &rule, nil
 }
 
-func getPointValuePointer(points int) *uint32 {
-	p := uint32(points)
-	return &p
+func (p *pointsMappingService) Award(
+	action PointsAction,
+	facts Facts,
+) (*Award, error) {
+	rule, err := p.Map(action)
+	if err != nil {
+		return nil, err
+	}
+	return EvaluateAwardRules(*rule, p.awardRules, facts), nil
 }
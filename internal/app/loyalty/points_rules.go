@@ -0,0 +1,256 @@
+package loyalty
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/audit"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrPointsRuleConflict is returned when a rule is saved over a
+	// version other than the one it was changed from
+	ErrPointsRuleConflict = errors.New("points rule was changed concurrently")
+
+	defaultWatchRetryDelay = 5 * time.Second
+)
+
+// PointsRulesStore persists points rules
+type PointsRulesStore interface {
+	Rules(ctx context.Context) ([]PointsRule, error)
+
+	// SaveRule stores rule, failing with ErrPointsRuleConflict unless the
+	// stored rule of its action is at rule.Version-1
+	SaveRule(ctx context.Context, rule PointsRule) error
+
+	// Watch calls onChange with every rule whenever rules change, until
+	// ctx is done
+	Watch(ctx context.Context, onChange func([]PointsRule)) error
+}
+
+// PointsRulesService is a PointsMappingService whose rules are kept in a
+// store and can be changed at runtime
+type PointsRulesService interface {
+	PointsMappingService
+
+	// Rules returns the cached rules ordered by action
+	Rules() []PointsRule
+
+	// UpdateRule changes the points granted for action. When
+	// expectedVersion is set, the update fails with ErrPointsRuleConflict
+	// unless it is the current version of the rule
+	UpdateRule(
+		ctx context.Context,
+		action PointsAction,
+		points uint32,
+		expectedVersion *int,
+	) (*PointsRule, error)
+
+	// Stop stops watching the store for changes
+	Stop()
+}
+
+// PointsRulesParams represent the params needed to instantiate a new
+// PointsRulesService. AwardRules are expected to be validated
+type PointsRulesParams struct {
+	Store      PointsRulesStore
+	AwardRules []AwardRule
+	Logger     *zap.Logger
+}
+
+type pointsRulesService struct {
+	store      PointsRulesStore
+	awardRules []AwardRule
+	logger     *zap.Logger
+
+	mu    sync.RWMutex
+	rules map[PointsAction]PointsRule
+
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+// NewPointsRulesService loads the rules from the store, storing the
+// defaults of rules that are missing, and keeps them cached. The cache is
+// refreshed whenever the store notifies a change
+func NewPointsRulesService(
+	ctx context.Context,
+	p PointsRulesParams,
+) (PointsRulesService, error) {
+	s := &pointsRulesService{
+		store:      p.Store,
+		awardRules: p.AwardRules,
+		logger:     p.Logger,
+		rules:      make(map[PointsAction]PointsRule),
+		stopped:    make(chan struct{}),
+	}
+
+	errLoad := s.load(ctx)
+	if errLoad != nil {
+		return nil, errLoad
+	}
+
+	watchCtx, cancel := context.WithCancel(eventsource.DetachContext(ctx))
+	s.cancel = cancel
+	go s.watch(watchCtx)
+
+	return s, nil
+}
+
+func (s *pointsRulesService) Map(action PointsAction) (*PointsRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rule, ok := s.rules[action]
+	if !ok {
+		return nil, errors.Errorf("point action %v not supported", action)
+	}
+	return &rule, nil
+}
+
+func (s *pointsRulesService) Award(
+	action PointsAction,
+	facts Facts,
+) (*Award, error) {
+	rule, err := s.Map(action)
+	if err != nil {
+		return nil, err
+	}
+	return EvaluateAwardRules(*rule, s.awardRules, facts), nil
+}
+
+func (s *pointsRulesService) Rules() []PointsRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules := make([]PointsRule, 0, len(s.rules))
+	for _, v := range s.rules {
+		rules = append(rules, v)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Action < rules[j].Action
+	})
+	return rules
+}
+
+func (s *pointsRulesService) UpdateRule(
+	ctx context.Context,
+	action PointsAction,
+	points uint32,
+	expectedVersion *int,
+) (*PointsRule, error) {
+	current, err := s.Map(action)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != nil && *expectedVersion != current.Version {
+		return nil, ErrPointsRuleConflict
+	}
+
+	actor := eventsource.ActorFromContext(ctx)
+	if eventsource.IsStringEmpty(&actor) {
+		actor = audit.SystemActor
+	}
+
+	rule := PointsRule{
+		Action:    action,
+		Points:    points,
+		Version:   current.Version + 1,
+		UpdatedAt: time.Now(),
+		UpdatedBy: actor,
+	}
+	errSave := s.store.SaveRule(ctx, rule)
+	if errSave != nil {
+		return nil, errSave
+	}
+
+	s.cache([]PointsRule{rule})
+	return &rule, nil
+}
+
+func (s *pointsRulesService) Stop() {
+	s.cancel()
+	<-s.stopped
+}
+
+/* ----- helpers ----- */
+func (s *pointsRulesService) load(ctx context.Context) error {
+	stored, err := s.store.Rules(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to load points rules")
+	}
+	s.cache(stored)
+
+	for _, v := range DefaultPointsRules() {
+		if _, errMap := s.Map(v.Action); errMap == nil {
+			continue
+		}
+
+		v.UpdatedAt = time.Now()
+		v.UpdatedBy = audit.SystemActor
+		errSave := s.store.SaveRule(ctx, v)
+		// Another instance stored the default first; the watch picks it up
+		if errors.Cause(errSave) == ErrPointsRuleConflict {
+			continue
+		}
+		if errSave != nil {
+			return errors.Wrapf(errSave, "unable to store default rule %v", v.Action)
+		}
+		s.cache([]PointsRule{v})
+	}
+	return nil
+}
+
+// cache keeps the newest version of each rule of a supported action
+func (s *pointsRulesService) cache(rules []PointsRule) {
+	supported := make(map[PointsAction]bool)
+	for _, v := range DefaultPointsRules() {
+		supported[v.Action] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range rules {
+		current, ok := s.rules[v.Action]
+		if !supported[v.Action] || (ok && current.Version >= v.Version) {
+			continue
+		}
+
+		s.rules[v.Action] = v
+		if ok {
+			s.logger.Info(
+				"points rule changed",
+				zap.String("action", string(v.Action)),
+				zap.Uint32("points", v.Points),
+				zap.Int("version", v.Version),
+				zap.String("updatedBy", v.UpdatedBy),
+			)
+		}
+	}
+}
+
+func (s *pointsRulesService) watch(ctx context.Context) {
+	defer close(s.stopped)
+
+	for {
+		err := s.store.Watch(ctx, s.cache)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.logger.Error("points rules watch failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(defaultWatchRetryDelay):
+		}
+	}
+}
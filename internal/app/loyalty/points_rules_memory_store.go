@@ -0,0 +1,85 @@
+package loyalty
+
+import (
+	"context"
+	"sync"
+)
+
+type memoryPointsRulesStore struct {
+	mu       sync.Mutex
+	rules    map[PointsAction]PointsRule
+	watchers map[chan []PointsRule]struct{}
+}
+
+// NewMemoryPointsRulesStore creates a PointsRulesStore that keeps rules in
+// memory. Rules do not survive a restart
+func NewMemoryPointsRulesStore() PointsRulesStore {
+	return &memoryPointsRulesStore{
+		rules:    make(map[PointsAction]PointsRule),
+		watchers: make(map[chan []PointsRule]struct{}),
+	}
+}
+
+func (m *memoryPointsRulesStore) Rules(ctx context.Context) ([]PointsRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshot(), nil
+}
+
+func (m *memoryPointsRulesStore) SaveRule(ctx context.Context, rule PointsRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.rules[rule.Action].Version != rule.Version-1 {
+		return ErrPointsRuleConflict
+	}
+	m.rules[rule.Action] = rule
+
+	rules := m.snapshot()
+	for v := range m.watchers {
+		// Watchers only need the latest rules, so a pending notification
+		// is replaced instead of blocking while the lock is held
+		select {
+		case <-v:
+		default:
+		}
+		v <- rules
+	}
+	return nil
+}
+
+func (m *memoryPointsRulesStore) Watch(
+	ctx context.Context,
+	onChange func([]PointsRule),
+) error {
+	changes := make(chan []PointsRule, 1)
+
+	m.mu.Lock()
+	m.watchers[changes] = struct{}{}
+	rules := m.snapshot()
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		delete(m.watchers, changes)
+		m.mu.Unlock()
+	}()
+
+	onChange(rules)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case v := <-changes:
+			onChange(v)
+		}
+	}
+}
+
+func (m *memoryPointsRulesStore) snapshot() []PointsRule {
+	rules := make([]PointsRule, 0, len(m.rules))
+	for _, v := range m.rules {
+		rules = append(rules, v)
+	}
+	return rules
+}
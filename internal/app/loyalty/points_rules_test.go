@@ -0,0 +1,90 @@
+package loyalty
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestPointsRulesService_StoresDefaults(t *testing.T) {
+	assert := assert.New(t)
+	store := NewMemoryPointsRulesStore()
+	s := newTestPointsRulesService(t, store)
+
+	rule, err := s.Map(PointsActionSignUpWithoutReferral)
+	assert.Nil(err)
+	assert.Equal(uint32(100), rule.Points)
+	assert.Equal(1, rule.Version)
+
+	stored, errStored := store.Rules(context.Background())
+	assert.Nil(errStored)
+	assert.Len(stored, len(DefaultPointsRules()))
+
+	_, errMap := s.Map(PointsAction("Unknown"))
+	assert.NotNil(errMap)
+}
+
+func TestPointsRulesService_UpdateRule(t *testing.T) {
+	assert := assert.New(t)
+	ctx := eventsource.WithActor(context.Background(), "admin-1")
+	s := newTestPointsRulesService(t, NewMemoryPointsRulesStore())
+
+	rule, err := s.UpdateRule(ctx, PointsActionReferUser, 250, nil)
+	assert.Nil(err)
+	assert.Equal(uint32(250), rule.Points)
+	assert.Equal(2, rule.Version)
+	assert.Equal("admin-1", rule.UpdatedBy)
+
+	mapped, errMap := s.Map(PointsActionReferUser)
+	assert.Nil(errMap)
+	assert.Equal(*rule, *mapped)
+
+	stale := 1
+	_, errConflict := s.UpdateRule(ctx, PointsActionReferUser, 300, &stale)
+	assert.Equal(ErrPointsRuleConflict, errConflict)
+
+	current := 2
+	_, errUpdate := s.UpdateRule(ctx, PointsActionReferUser, 300, &current)
+	assert.Nil(errUpdate)
+}
+
+func TestPointsRulesService_NotifiedOfChanges(t *testing.T) {
+	assert := assert.New(t)
+	store := NewMemoryPointsRulesStore()
+	s := newTestPointsRulesService(t, store)
+	other := newTestPointsRulesService(t, store)
+
+	_, err := other.UpdateRule(
+		context.Background(),
+		PointsActionSignUpWithReferral,
+		500,
+		nil,
+	)
+	assert.Nil(err)
+
+	assert.Eventually(func() bool {
+		rule, errMap := s.Map(PointsActionSignUpWithReferral)
+		return errMap == nil && rule.Points == 500 && rule.Version == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+/* ----- helpers ----- */
+func newTestPointsRulesService(
+	t *testing.T,
+	store PointsRulesStore,
+) PointsRulesService {
+	s, err := NewPointsRulesService(context.Background(), PointsRulesParams{
+		Store:  store,
+		Logger: zaptest.NewLogger(t),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Stop)
+	return s
+}
@@ -76,6 +76,12 @@ func (r *repository) Apply(
 		)
 	}
 
+	if commandID := eventsource.CommandIDFromContext(ctx); commandID != "" {
+		for i := range events {
+			events[i].CommandID = commandID
+		}
+	}
+
 	err = r.Save(ctx, events...)
 	if err != nil {
 		return nil, nil, err
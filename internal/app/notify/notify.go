@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Notification is a message to a user about an event that concerns them
+type Notification struct {
+	// EventType is the type of the event the notification is about
+	EventType string
+
+	UserID string
+	Email  string
+
+	// Data holds the event details the message refers to
+	Data map[string]string
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+type logNotifier struct {
+	logger *zap.Logger
+}
+
+// NewLogNotifier creates a Notifier that logs notifications instead of
+// delivering them
+func NewLogNotifier(logger *zap.Logger) Notifier {
+	return &logNotifier{
+		logger: logger,
+	}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.logger.Info(
+		"notification",
+		zap.String("eventType", notification.EventType),
+		zap.String("userId", notification.UserID),
+		zap.String("email", notification.Email),
+		zap.Any("data", notification.Data),
+	)
+	return nil
+}
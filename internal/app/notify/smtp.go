@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type smtpNotifier struct {
+	addr      string
+	from      string
+	auth      smtp.Auth
+	templates *Templates
+	logger    *zap.Logger
+}
+
+// SMTPParams represent the params needed to instantiate an SMTP Notifier
+type SMTPParams struct {
+	// Addr is the host:port of the SMTP server
+	Addr string
+	From string
+
+	// Auth authenticates with the server. Optional
+	Auth smtp.Auth
+
+	Templates *Templates
+	Logger    *zap.Logger
+}
+
+// NewSMTPNotifier creates a Notifier that emails notifications rendered
+// with the template of their event type
+func NewSMTPNotifier(p SMTPParams) Notifier {
+	return &smtpNotifier{
+		addr:      p.Addr,
+		from:      p.From,
+		auth:      p.Auth,
+		templates: p.Templates,
+		logger:    p.Logger,
+	}
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Email == "" {
+		return errors.Errorf(
+			"no email to send %v notification to",
+			notification.EventType,
+		)
+	}
+
+	message, err := n.templates.Render(notification)
+	if err != nil {
+		return err
+	}
+
+	errSend := n.send(ctx, message.To, n.encode(message))
+	if errSend != nil {
+		return errors.Wrapf(
+			errSend,
+			"unable to send %v notification",
+			notification.EventType,
+		)
+	}
+
+	n.logger.Debug(
+		"notification sent",
+		zap.String("eventType", notification.EventType),
+		zap.String("userId", notification.UserID),
+	)
+	return nil
+}
+
+// send delivers msg to the recipient like smtp.SendMail, but dials with
+// ctx and bounds the whole conversation by its deadline. Cancelling ctx
+// closes the connection so that a stalled server does not block the caller
+func (n *smtpNotifier) send(ctx context.Context, to string, msg []byte) error {
+	host, _, err := net.SplitHostPort(n.addr)
+	if err != nil {
+		return errors.Wrap(err, "invalid smtp address")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if errDeadline := conn.SetDeadline(deadline); errDeadline != nil {
+			return errDeadline
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	err = n.converse(conn, host, to, msg)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// converse runs the SMTP conversation smtp.SendMail would on conn
+func (n *smtpNotifier) converse(
+	conn net.Conn,
+	host string,
+	to string,
+	msg []byte,
+) error {
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		errTLS := client.StartTLS(&tls.Config{ServerName: host})
+		if errTLS != nil {
+			return errTLS
+		}
+	}
+	if n.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support AUTH")
+		}
+		if errAuth := client.Auth(n.auth); errAuth != nil {
+			return errAuth
+		}
+	}
+
+	if errMail := client.Mail(n.from); errMail != nil {
+		return errMail
+	}
+	if errRcpt := client.Rcpt(to); errRcpt != nil {
+		return errRcpt
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, errWrite := w.Write(msg); errWrite != nil {
+		return errWrite
+	}
+	if errClose := w.Close(); errClose != nil {
+		return errClose
+	}
+	return client.Quit()
+}
+
+// encode formats message as an RFC 5322 email
+func (n *smtpNotifier) encode(message *Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %v\r\n", n.from)
+	fmt.Fprintf(&b, "To: %v\r\n", headerValue(message.To))
+	fmt.Fprintf(&b, "Subject: %v\r\n", mime.QEncoding.Encode("utf-8", headerValue(message.Subject)))
+	fmt.Fprintf(&b, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(
+		strings.ReplaceAll(message.Body, "\r\n", "\n"),
+		"\n",
+		"\r\n",
+	))
+	return b.Bytes()
+}
+
+// headerValue strips line breaks so that rendered values cannot add headers
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
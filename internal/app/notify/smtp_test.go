@@ -0,0 +1,218 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestSMTPNotifier_SendsRenderedTemplate(t *testing.T) {
+	assert := assert.New(t)
+	server := newSMTPServer(t, "")
+
+	templates, err := NewTemplates(nil)
+	assert.Nil(err)
+	notifier := NewSMTPNotifier(SMTPParams{
+		Addr:      server.addr(),
+		From:      "loyalty@example.com",
+		Templates: templates,
+		Logger:    zaptest.NewLogger(t),
+	})
+
+	errNotify := notifier.Notify(context.Background(), Notification{
+		EventType: "UserReferralCreated",
+		UserID:    "user-1",
+		Email:     "grace@example.com",
+		Data: map[string]string{
+			"username":     "ada",
+			"referralCode": "ABC123",
+		},
+	})
+	assert.Nil(errNotify)
+
+	mails := server.received()
+	if assert.Len(mails, 1) {
+		assert.Equal("loyalty@example.com", mails[0].from)
+		assert.Equal([]string{"grace@example.com"}, mails[0].to)
+		assert.Contains(mails[0].data, "To: grace@example.com\r\n")
+		assert.Contains(
+			mails[0].data,
+			"Subject: ada invited you to the loyalty program\r\n",
+		)
+		assert.Contains(mails[0].data, "referral code ABC123")
+	}
+}
+
+func TestSMTPNotifier_RejectedRecipient(t *testing.T) {
+	assert := assert.New(t)
+	server := newSMTPServer(t, "550 mailbox unavailable")
+
+	templates, err := NewTemplates(nil)
+	assert.Nil(err)
+	notifier := NewSMTPNotifier(SMTPParams{
+		Addr:      server.addr(),
+		From:      "loyalty@example.com",
+		Templates: templates,
+		Logger:    zaptest.NewLogger(t),
+	})
+
+	errNotify := notifier.Notify(context.Background(), Notification{
+		EventType: "UserReferralCreated",
+		Email:     "nobody@example.com",
+	})
+	assert.NotNil(errNotify)
+	assert.Empty(server.received())
+}
+
+func TestSMTPNotifier_StalledServerTimesOut(t *testing.T) {
+	assert := assert.New(t)
+
+	// The listener accepts connections but never greets the client
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	templates, err := NewTemplates(nil)
+	assert.Nil(err)
+	notifier := NewSMTPNotifier(SMTPParams{
+		Addr:      listener.Addr().String(),
+		From:      "loyalty@example.com",
+		Templates: templates,
+		Logger:    zaptest.NewLogger(t),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	errNotify := notifier.Notify(ctx, Notification{
+		EventType: "UserReferralCreated",
+		Email:     "grace@example.com",
+	})
+	assert.NotNil(errNotify)
+	assert.Equal(context.DeadlineExceeded, errors.Cause(errNotify))
+	assert.Less(int64(time.Since(start)), int64(5*time.Second))
+}
+
+/* ----- helpers ----- */
+type mail struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is a local stand-in for an SMTP server accepting the subset
+// of the protocol net/smtp uses without TLS or authentication
+type smtpServer struct {
+	listener net.Listener
+
+	// rcptReply replaces the reply to RCPT commands when set
+	rcptReply string
+
+	mu    sync.Mutex
+	mails []mail
+}
+
+func newSMTPServer(t *testing.T, rcptReply string) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{listener: listener, rcptReply: rcptReply}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, errAccept := listener.Accept()
+			if errAccept != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *smtpServer) received() []mail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]mail{}, s.mails...)
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost ESMTP")
+	current := mail{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current = mail{from: strings.Trim(line[len("MAIL FROM:"):], "<>")}
+			reply("250 OK")
+
+		case strings.HasPrefix(command, "RCPT TO:"):
+			if s.rcptReply != "" {
+				reply(s.rcptReply)
+				continue
+			}
+			current.to = append(current.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+
+		case command == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, errData := reader.ReadString('\n')
+				if errData != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			current.data = data.String()
+
+			s.mu.Lock()
+			s.mails = append(s.mails, current)
+			s.mu.Unlock()
+			reply("250 OK")
+
+		case command == "QUIT":
+			reply("221 bye")
+			return
+
+		default:
+			reply("250 OK")
+		}
+	}
+}
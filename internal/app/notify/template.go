@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Template is the message sent for notifications about an event type.
+// Subject and Body are text/template templates executed against the
+// Notification, e.g. {{.Data.referralCode}}
+type Template struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Message is a rendered notification
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Templates renders notifications with the template of their event type
+type Templates struct {
+	subjects map[string]*template.Template
+	bodies   map[string]*template.Template
+}
+
+// DefaultTemplates are used for the event types a templates file does not
+// override
+var DefaultTemplates = map[string]Template{
+	"UserReferralCreated": {
+		Subject: "{{.Data.username}} invited you to the loyalty program",
+		Body: "Hi,\n\n{{.Data.username}} thinks you would enjoy the loyalty program. " +
+			"Sign up with the referral code {{.Data.referralCode}} and you will " +
+			"both earn points.\n",
+	},
+	"UserReferralExpired": {
+		Subject: "Your invitation to {{.Data.referredUserEmail}} expired",
+		Body: "Hi {{.Data.username}},\n\n{{.Data.referredUserEmail}} did not sign " +
+			"up in time and your referral has expired. You can invite them again " +
+			"at any time.\n",
+	},
+}
+
+// NewTemplates parses templates, keyed by event type, on top of the
+// DefaultTemplates
+func NewTemplates(templates map[string]Template) (*Templates, error) {
+	t := &Templates{
+		subjects: make(map[string]*template.Template),
+		bodies:   make(map[string]*template.Template),
+	}
+
+	merged := make(map[string]Template)
+	for k, v := range DefaultTemplates {
+		merged[k] = v
+	}
+	for k, v := range templates {
+		merged[k] = v
+	}
+
+	for eventType, v := range merged {
+		if v.Subject == "" || v.Body == "" {
+			return nil, errors.Errorf(
+				"template for %v requires a subject and a body",
+				eventType,
+			)
+		}
+
+		subject, errSubject := parseTemplate(eventType+".subject", v.Subject)
+		if errSubject != nil {
+			return nil, errSubject
+		}
+		body, errBody := parseTemplate(eventType+".body", v.Body)
+		if errBody != nil {
+			return nil, errBody
+		}
+		t.subjects[eventType] = subject
+		t.bodies[eventType] = body
+	}
+	return t, nil
+}
+
+// ParseTemplates parses a JSON object of templates keyed by event type
+func ParseTemplates(data []byte) (*Templates, error) {
+	templates := map[string]Template{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&templates)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse notification templates")
+	}
+	return NewTemplates(templates)
+}
+
+// Render renders notification with the template of its event type
+func (t *Templates) Render(notification Notification) (*Message, error) {
+	subject, exists := t.subjects[notification.EventType]
+	if !exists {
+		return nil, errors.Errorf(
+			"no notification template for %v",
+			notification.EventType,
+		)
+	}
+
+	var subjectBuffer, bodyBuffer bytes.Buffer
+	errSubject := subject.Execute(&subjectBuffer, notification)
+	if errSubject != nil {
+		return nil, errors.Wrap(errSubject, "unable to render notification subject")
+	}
+	errBody := t.bodies[notification.EventType].Execute(&bodyBuffer, notification)
+	if errBody != nil {
+		return nil, errors.Wrap(errBody, "unable to render notification body")
+	}
+
+	return &Message{
+		To:      notification.Email,
+		Subject: subjectBuffer.String(),
+		Body:    bodyBuffer.String(),
+	}, nil
+}
+
+func parseTemplate(name string, text string) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse template %v", name)
+	}
+	return t, nil
+}
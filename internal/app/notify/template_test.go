@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTemplates_OverridesDefaults(t *testing.T) {
+	assert := assert.New(t)
+	templates, err := ParseTemplates([]byte(`{
+		"UserReferralCreated": {
+			"subject": "Join {{.Data.username}}",
+			"body": "Use {{.Data.referralCode}}"
+		}
+	}`))
+	assert.Nil(err)
+
+	invite, errInvite := templates.Render(Notification{
+		EventType: "UserReferralCreated",
+		Email:     "grace@example.com",
+		Data: map[string]string{
+			"username":     "ada",
+			"referralCode": "ABC123",
+		},
+	})
+	assert.Nil(errInvite)
+	assert.Equal(&Message{
+		To:      "grace@example.com",
+		Subject: "Join ada",
+		Body:    "Use ABC123",
+	}, invite)
+
+	// Event types the file does not declare keep their default template
+	expired, errExpired := templates.Render(Notification{
+		EventType: "UserReferralExpired",
+		Data:      map[string]string{"referredUserEmail": "grace@example.com"},
+	})
+	assert.Nil(errExpired)
+	assert.Equal("Your invitation to grace@example.com expired", expired.Subject)
+}
+
+func TestParseTemplates_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":  `{"UserReferralCreated": {"subject": "a", "body": "b", "html": "c"}}`,
+		"missing body":   `{"UserReferralCreated": {"subject": "a"}}`,
+		"invalid syntax": `{"UserReferralCreated": {"subject": "{{.Data", "body": "b"}}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseTemplates([]byte(data))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestTemplates_RenderUnknownEventType(t *testing.T) {
+	templates, err := NewTemplates(nil)
+	assert.Nil(t, err)
+
+	_, errRender := templates.Render(Notification{EventType: "PointsEarned"})
+	assert.NotNil(t, errRender)
+}
@@ -0,0 +1,247 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	gcpubsub "cloud.google.com/go/pubsub"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Message attributes set on every published event so that subscribers
+// can filter without decoding the message body
+const (
+	AttributeEventType   = "eventType"
+	AttributeAggregateID = "aggregateId"
+)
+
+var errTransportNotStarted = errors.New("pubsub transport has not been started")
+
+// message is the wire format of an event
+type message struct {
+	AggregateID string    `json:"aggregateId"`
+	EventType   string    `json:"eventType"`
+	Version     int       `json:"version"`
+	EventAt     time.Time `json:"at"`
+	Payload     *string   `json:"payload"`
+	CommandID   string    `json:"commandId,omitempty"`
+}
+
+// Transport is an eventsource.Transport backed by Google Cloud Pub/Sub.
+// Replicas share one subscription, so each event is handled by a single
+// replica, which handlers changing state such as sagas and projections
+// rely on
+type Transport interface {
+	eventsource.Transport
+
+	// Start creates the topic and subscription if they do not exist
+	// and begins receiving events
+	Start(ctx context.Context) error
+
+	// Stop stops receiving events and waits for in-flight events to be handled
+	Stop() error
+}
+
+type transport struct {
+	client         *gcpubsub.Client
+	topicID        string
+	subscriptionID string
+	logger         *zap.Logger
+
+	topic   *gcpubsub.Topic
+	receive eventsource.ReceiveFunc
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// TransportParams represent the params needed to instantiate a new Transport
+type TransportParams struct {
+	Client         *gcpubsub.Client
+	TopicID        string
+	SubscriptionID string
+	Logger         *zap.Logger
+}
+
+// NewTransport creates a new instance of a Pub/Sub Transport
+func NewTransport(p TransportParams) Transport {
+	return &transport{
+		client:         p.Client,
+		topicID:        p.TopicID,
+		subscriptionID: p.SubscriptionID,
+		logger:         p.Logger,
+	}
+}
+
+// Send implements the Transport interface. Send returns once Pub/Sub
+// has accepted every event; handler errors are not returned to the publisher
+func (t *transport) Send(ctx context.Context, events []eventsource.Event) error {
+	if t.topic == nil {
+		return errTransportNotStarted
+	}
+
+	results := make([]*gcpubsub.PublishResult, len(events))
+	for i, v := range events {
+		data, err := json.Marshal(message{
+			AggregateID: v.AggregateID,
+			EventType:   v.EventType,
+			Version:     v.Version,
+			EventAt:     v.EventAt,
+			Payload:     v.Payload,
+			CommandID:   v.CommandID,
+		})
+		if err != nil {
+			return errors.Wrap(err, "unable to serialize event")
+		}
+
+		results[i] = t.topic.Publish(ctx, &gcpubsub.Message{
+			Data: data,
+			Attributes: map[string]string{
+				AttributeEventType:   v.EventType,
+				AttributeAggregateID: v.AggregateID,
+			},
+		})
+	}
+
+	for _, v := range results {
+		_, err := v.Get(ctx)
+		if err != nil {
+			return errors.Wrap(err, "unable to publish event")
+		}
+	}
+	return nil
+}
+
+// Subscribe implements the Transport interface
+func (t *transport) Subscribe(receive eventsource.ReceiveFunc) {
+	t.receive = receive
+}
+
+// Start implements the Transport interface
+func (t *transport) Start(ctx context.Context) error {
+	topic, err := t.ensureTopic(ctx)
+	if err != nil {
+		return err
+	}
+	t.topic = topic
+
+	subscription, errSubscription := t.ensureSubscription(ctx, topic)
+	if errSubscription != nil {
+		return errSubscription
+	}
+
+	receiveCtx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		errReceive := subscription.Receive(receiveCtx, t.handleMessage)
+		if errReceive != nil {
+			t.logger.Error(
+				"pubsub subscription stopped receiving",
+				zap.Error(errReceive),
+				zap.String("subscription", t.subscriptionID),
+			)
+		}
+	}()
+
+	return nil
+}
+
+// Stop implements the Transport interface
+func (t *transport) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+
+	if t.topic != nil {
+		t.topic.Stop()
+	}
+	return nil
+}
+
+func (t *transport) handleMessage(ctx context.Context, msg *gcpubsub.Message) {
+	var m message
+	err := json.Unmarshal(msg.Data, &m)
+	if err != nil {
+		// A malformed message will never succeed so it is not redelivered
+		t.logger.Error(
+			"unable to deserialize event message",
+			zap.Error(err),
+			zap.String("messageId", msg.ID),
+		)
+		msg.Ack()
+		return
+	}
+
+	if t.receive == nil {
+		msg.Nack()
+		return
+	}
+
+	errReceive := t.receive(ctx, eventsource.Event{
+		AggregateID: m.AggregateID,
+		EventType:   m.EventType,
+		Version:     m.Version,
+		EventAt:     m.EventAt,
+		Payload:     m.Payload,
+		CommandID:   m.CommandID,
+	})
+	if errReceive != nil {
+		t.logger.Error(
+			"unable to handle event message",
+			zap.Error(errReceive),
+			zap.String("messageId", msg.ID),
+		)
+		msg.Nack()
+		return
+	}
+
+	msg.Ack()
+}
+
+func (t *transport) ensureTopic(ctx context.Context) (*gcpubsub.Topic, error) {
+	topic := t.client.Topic(t.topicID)
+	exists, err := topic.Exists(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to check pubsub topic")
+	}
+	if exists {
+		return topic, nil
+	}
+
+	topic, err = t.client.CreateTopic(ctx, t.topicID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create pubsub topic")
+	}
+	return topic, nil
+}
+
+func (t *transport) ensureSubscription(
+	ctx context.Context,
+	topic *gcpubsub.Topic,
+) (*gcpubsub.Subscription, error) {
+	subscription := t.client.Subscription(t.subscriptionID)
+	exists, err := subscription.Exists(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to check pubsub subscription")
+	}
+	if exists {
+		return subscription, nil
+	}
+
+	subscription, err = t.client.CreateSubscription(
+		ctx,
+		t.subscriptionID,
+		gcpubsub.SubscriptionConfig{Topic: topic},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create pubsub subscription")
+	}
+	return subscription, nil
+}
@@ -0,0 +1,158 @@
+package pubsub
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	gcpubsub "cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+/* ----- tests ----- */
+
+// TestTransport_SendAndReceive runs against the Pub/Sub emulator when
+// PUBSUB_EMULATOR_HOST is set and against an in-memory fake otherwise
+func TestTransport_SendAndReceive(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	client := newTestClient(t)
+	defer client.Close()
+
+	suffix := eventsource.NewUUID()
+	transport := NewTransport(TransportParams{
+		Client:         client,
+		TopicID:        "events-" + suffix,
+		SubscriptionID: "events-test-" + suffix,
+		Logger:         zaptest.NewLogger(t),
+	})
+
+	received := make(chan eventsource.Event, 1)
+	transport.Subscribe(func(ctx context.Context, event eventsource.Event) error {
+		received <- event
+		return nil
+	})
+
+	assert.Nil(transport.Start(ctx))
+	defer transport.Stop()
+
+	event := eventsource.NewEvent("abc123", "UserCreated", 1, []byte(`{"a":1}`))
+	event.CommandID = "command-1"
+	assert.Nil(transport.Send(ctx, []eventsource.Event{*event}))
+
+	select {
+	case v := <-received:
+		assert.Equal(event.AggregateID, v.AggregateID)
+		assert.Equal(event.EventType, v.EventType)
+		assert.Equal(event.Version, v.Version)
+		assert.Equal(*event.Payload, *v.Payload)
+		assert.Equal(event.CommandID, v.CommandID)
+	case <-time.After(10 * time.Second):
+		t.Fatal("event was not received")
+	}
+}
+
+// TestTransport_SharedSubscriptionHandlesOnce checks that replicas
+// sharing a subscription compete for events instead of each handling them
+func TestTransport_SharedSubscriptionHandlesOnce(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	client := newTestClient(t)
+	defer client.Close()
+
+	suffix := eventsource.NewUUID()
+	received := make(chan eventsource.Event, 10)
+	replicas := []Transport{}
+	for i := 0; i < 2; i++ {
+		replica := NewTransport(TransportParams{
+			Client:         client,
+			TopicID:        "events-" + suffix,
+			SubscriptionID: "events-test-" + suffix,
+			Logger:         zaptest.NewLogger(t),
+		})
+		replica.Subscribe(func(ctx context.Context, event eventsource.Event) error {
+			received <- event
+			return nil
+		})
+		assert.Nil(replica.Start(ctx))
+		defer replica.Stop()
+		replicas = append(replicas, replica)
+	}
+
+	events := []eventsource.Event{}
+	for i := 1; i <= 4; i++ {
+		events = append(events, *eventsource.NewEvent("abc123", "UserCreated", i, nil))
+	}
+	assert.Nil(replicas[0].Send(ctx, events))
+
+	versions := map[int]int{}
+	timeout := time.After(10 * time.Second)
+	for len(versions) < len(events) {
+		select {
+		case v := <-received:
+			versions[v.Version]++
+		case <-timeout:
+			t.Fatal("events were not received")
+		}
+	}
+
+	// Give a second delivery the chance to arrive
+	select {
+	case v := <-received:
+		t.Fatalf("event %v was handled twice", v.Version)
+	case <-time.After(500 * time.Millisecond):
+	}
+	for _, v := range versions {
+		assert.Equal(1, v)
+	}
+}
+
+func TestTransport_SendBeforeStartError(t *testing.T) {
+	assert := assert.New(t)
+
+	transport := NewTransport(TransportParams{
+		Logger: zaptest.NewLogger(t),
+	})
+	err := transport.Send(context.Background(), []eventsource.Event{})
+
+	assert.EqualError(err, errTransportNotStarted.Error())
+}
+
+/* ----- helpers ----- */
+func newTestClient(t *testing.T) *gcpubsub.Client {
+	ctx := context.Background()
+
+	if _, ok := os.LookupEnv("PUBSUB_EMULATOR_HOST"); ok {
+		client, err := gcpubsub.NewClient(ctx, "es-loyalty-program-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	srv := pstest.NewServer()
+	t.Cleanup(func() { srv.Close() })
+
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := gcpubsub.NewClient(
+		ctx,
+		"es-loyalty-program-test",
+		option.WithGRPCConn(conn),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
@@ -0,0 +1,51 @@
+package saga
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+)
+
+// ErrInstanceClaimed is returned when another run holds the lease of a
+// saga instance, e.g. while a redelivered event is handled concurrently.
+// The run is retried when the event is redelivered
+var ErrInstanceClaimed = errors.New("saga instance is claimed by another run")
+
+type permanentError struct {
+	error
+}
+
+func (e permanentError) Cause() error {
+	return e.error
+}
+
+// Permanent lets packages that saga depends on, such as the scheduler,
+// recognise permanent errors
+func (e permanentError) Permanent() bool {
+	return true
+}
+
+// Permanent marks err as a failure that retrying cannot fix
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// IsPermanent indicates whether err cannot be fixed by retrying. Errors
+// marked with Permanent and validation errors are permanent
+func IsPermanent(err error) bool {
+	for err != nil {
+		if _, ok := err.(permanentError); ok {
+			return true
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = cause.Cause()
+	}
+
+	_, ok := eventsource.AsValidationError(err)
+	return ok
+}
@@ -0,0 +1,495 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errInstanceIDRequired = errors.New("saga type and correlation id must be defined")
+	defaultMaxAttempts    = 5
+	defaultLeaseDuration  = time.Minute
+)
+
+// Status represents the state of a saga instance
+type Status string
+
+const (
+	// StatusRunning marks instances with steps left to run. Instances
+	// that stay running are stuck and retried when their event is redelivered
+	StatusRunning Status = "Running"
+
+	// StatusCompleted marks instances whose steps have all run
+	StatusCompleted Status = "Completed"
+
+	// StatusCompensated marks instances that failed permanently and whose
+	// completed steps have been compensated
+	StatusCompensated Status = "Compensated"
+
+	// StatusFailed marks instances that failed permanently and could not
+	// be compensated. They require manual intervention
+	StatusFailed Status = "Failed"
+)
+
+// Instance is the persisted state of a saga for one correlation id
+type Instance struct {
+	ID               string    `json:"id" firestore:"id"`
+	Type             string    `json:"type" firestore:"type"`
+	CorrelationID    string    `json:"correlationId" firestore:"correlationId"`
+	AggregateID      string    `json:"aggregateId" firestore:"aggregateId"`
+	Status           Status    `json:"status" firestore:"status"`
+	CompletedSteps   []string  `json:"completedSteps" firestore:"completedSteps"`
+	CompensatedSteps []string  `json:"compensatedSteps" firestore:"compensatedSteps"`
+	Attempts         int       `json:"attempts" firestore:"attempts"`
+	LastError        string    `json:"lastError" firestore:"lastError"`
+	StartedAt        time.Time `json:"startedAt" firestore:"startedAt"`
+	UpdatedAt        time.Time `json:"updatedAt" firestore:"updatedAt"`
+
+	// LeaseOwner identifies the run that claimed the instance. Other runs
+	// cannot claim it before LeaseExpiresAt
+	LeaseOwner     string    `json:"leaseOwner" firestore:"leaseOwner"`
+	LeaseExpiresAt time.Time `json:"leaseExpiresAt" firestore:"leaseExpiresAt"`
+}
+
+// Completed indicates whether the step has run for the instance
+func (i *Instance) Completed(step string) bool {
+	return contains(i.CompletedSteps, step)
+}
+
+// Done indicates whether the instance reached a terminal status
+func (i *Instance) Done() bool {
+	return i.Status != StatusRunning
+}
+
+// Settled indicates whether the instance completed or was compensated.
+// Unlike failed instances, settled ones are never run again
+func (i *Instance) Settled() bool {
+	return i.Status == StatusCompleted || i.Status == StatusCompensated
+}
+
+// LeasedByOther indicates whether a run other than owner holds the lease
+// of the instance at now
+func (i *Instance) LeasedByOther(owner string, now time.Time) bool {
+	return i.LeaseOwner != "" &&
+		i.LeaseOwner != owner &&
+		now.Before(i.LeaseExpiresAt)
+}
+
+// Store persists saga instances
+type Store interface {
+	// Instance returns the instance with id, or nil if there is none
+	Instance(ctx context.Context, id string) (*Instance, error)
+
+	// Claim atomically leases the stored instance with the id of instance
+	// to its LeaseOwner until its LeaseExpiresAt, saving instance when
+	// there is none yet. Settled instances are returned without being
+	// leased. Fails with ErrInstanceClaimed while another run holds the
+	// lease
+	Claim(ctx context.Context, instance Instance) (*Instance, error)
+
+	// Save saves instance, failing with ErrInstanceClaimed when another
+	// run has claimed it since
+	Save(ctx context.Context, instance Instance) error
+
+	// Instances returns the instances in any of statuses, the least
+	// recently updated first
+	Instances(ctx context.Context, statuses ...Status) ([]Instance, error)
+
+	// Lease acquires the lease with name for owner until the given time,
+	// returning false while another owner holds it. Leasing until a past
+	// time releases the lease
+	Lease(ctx context.Context, name string, owner string, until time.Time) (bool, error)
+}
+
+// ResumeFunc resumes an interrupted or failed instance, e.g. by running
+// its definition again
+type ResumeFunc func(ctx context.Context, instance Instance) error
+
+// Step is a unit of work of a saga
+type Step struct {
+	Name   string
+	Action func(ctx context.Context) error
+
+	// Compensate undoes Action when a later step fails permanently. Optional
+	Compensate func(ctx context.Context) error
+}
+
+// Definition describes the steps a saga instance runs in order
+type Definition struct {
+	Type          string
+	CorrelationID string
+	Steps         []Step
+
+	// AggregateID identifies the aggregate whose events drive the saga,
+	// e.g. to resume it on recovery. Optional
+	AggregateID string
+}
+
+// Runner runs saga definitions, persisting the steps that completed so
+// that a retried saga resumes after its last completed step
+type Runner interface {
+	// Run runs the steps of definition that have not completed yet.
+	// Transient failures are returned and retried on the next Run. When a
+	// step fails permanently, or has failed MaxAttempts times, the
+	// completed steps are compensated in reverse order
+	Run(ctx context.Context, definition Definition) (*Instance, error)
+
+	// Instance returns the instance of sagaType for correlationID, or nil
+	// if it has not run yet
+	Instance(ctx context.Context, sagaType string, correlationID string) (*Instance, error)
+
+	// Instances returns the instances in any of statuses
+	Instances(ctx context.Context, statuses ...Status) ([]Instance, error)
+
+	// Compensate compensates the completed steps of the failed instance of
+	// definition again. Instances that have not failed are left as is
+	Compensate(ctx context.Context, definition Definition) (*Instance, error)
+
+	// Recover calls resume with the running and failed instances of
+	// sagaTypes, the least recently updated first, until ctx is done. A
+	// lease ensures a single replica recovers at a time; the others return
+	// without recovering. Instances that cannot be resumed are logged so
+	// that they do not block the others
+	Recover(ctx context.Context, resume ResumeFunc, sagaTypes ...string) error
+
+	// Exclusive calls fn while holding the lease name, e.g. to recover
+	// work that has no instance yet. Like Recover, replicas that cannot
+	// take the lease return without calling fn
+	Exclusive(ctx context.Context, name string, fn func(ctx context.Context) error) error
+}
+
+// RunnerParams represent the params needed to instantiate a new Runner
+type RunnerParams struct {
+	Store  Store
+	Logger *zap.Logger
+
+	// MaxAttempts is the number of failed runs after which a saga is
+	// compensated. Defaults to 5. Optional
+	MaxAttempts int
+
+	// LeaseDuration is how long a run holds its instance without saving
+	// it, after which another run may claim it. Defaults to a minute.
+	// Optional
+	LeaseDuration time.Duration
+}
+
+type runner struct {
+	store         Store
+	logger        *zap.Logger
+	maxAttempts   int
+	leaseDuration time.Duration
+}
+
+// NewRunner creates a new instance of Runner
+func NewRunner(p RunnerParams) Runner {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	leaseDuration := p.LeaseDuration
+	if leaseDuration <= 0 {
+		leaseDuration = defaultLeaseDuration
+	}
+	return &runner{
+		store:         p.Store,
+		logger:        p.Logger,
+		maxAttempts:   maxAttempts,
+		leaseDuration: leaseDuration,
+	}
+}
+
+// InstanceID returns the id of the instance of sagaType for correlationID
+func InstanceID(sagaType string, correlationID string) string {
+	return fmt.Sprintf("%v:%v", sagaType, correlationID)
+}
+
+func (r *runner) Run(
+	ctx context.Context,
+	definition Definition,
+) (*Instance, error) {
+	if eventsource.IsStringEmpty(&definition.Type) ||
+		eventsource.IsStringEmpty(&definition.CorrelationID) {
+		return nil, errInstanceIDRequired
+	}
+
+	// Concurrent deliveries of the same trigger must not run the steps
+	// twice, so the instance is claimed before any step runs
+	instance, err := r.claim(ctx, definition)
+	if err != nil {
+		return nil, err
+	}
+	if instance.Settled() {
+		return instance, nil
+	}
+	// Failed instances are compensated again by Compensate only
+	if instance.Status == StatusFailed {
+		return instance, r.release(ctx, instance)
+	}
+
+	for _, v := range definition.Steps {
+		if instance.Completed(v.Name) {
+			continue
+		}
+
+		errStep := v.Action(ctx)
+		if errStep != nil {
+			return instance, r.fail(ctx, definition, instance, v.Name, errStep)
+		}
+
+		instance.CompletedSteps = append(instance.CompletedSteps, v.Name)
+		errSave := r.save(ctx, instance)
+		if errSave != nil {
+			return instance, errSave
+		}
+	}
+
+	instance.Status = StatusCompleted
+	instance.LastError = ""
+	return instance, r.release(ctx, instance)
+}
+
+func (r *runner) Compensate(
+	ctx context.Context,
+	definition Definition,
+) (*Instance, error) {
+	instance, err := r.claim(ctx, definition)
+	if err != nil {
+		return nil, err
+	}
+	if instance.Settled() {
+		return instance, nil
+	}
+	if instance.Status != StatusFailed {
+		return instance, r.release(ctx, instance)
+	}
+
+	instance.Status = r.compensate(ctx, definition, instance)
+	return instance, r.release(ctx, instance)
+}
+
+func (r *runner) Recover(
+	ctx context.Context,
+	resume ResumeFunc,
+	sagaTypes ...string,
+) error {
+	lease := "recovery:" + strings.Join(sagaTypes, ",")
+	return r.Exclusive(ctx, lease, func(ctx context.Context) error {
+		instances, errInstances := r.store.Instances(ctx, StatusRunning, StatusFailed)
+		if errInstances != nil {
+			return errors.Wrap(errInstances, "unable to load saga instances to recover")
+		}
+
+		recovered := 0
+		for _, v := range instances {
+			if !contains(sagaTypes, v.Type) {
+				continue
+			}
+			if ctx.Err() != nil {
+				return errors.Wrapf(ctx.Err(), "saga recovery stopped after %v instances", recovered)
+			}
+
+			errResume := resume(ctx, v)
+			if errResume != nil {
+				r.logger.Error(
+					"unable to recover saga instance",
+					zap.Error(errResume),
+					zap.String("sagaId", v.ID),
+				)
+				continue
+			}
+			recovered++
+		}
+
+		r.logger.Info("sagas recovered", zap.Int("count", recovered))
+		return nil
+	})
+}
+
+func (r *runner) Exclusive(
+	ctx context.Context,
+	name string,
+	fn func(ctx context.Context) error,
+) error {
+	owner := eventsource.NewUUID()
+	until, ok := ctx.Deadline()
+	if !ok {
+		until = time.Now().Add(r.leaseDuration)
+	}
+
+	leased, err := r.store.Lease(ctx, name, owner, until)
+	if err != nil {
+		return errors.Wrapf(err, "unable to lease %v", name)
+	}
+	if !leased {
+		r.logger.Info("lease held by another replica", zap.String("lease", name))
+		return nil
+	}
+	defer func() {
+		// The lease expires by itself should releasing it fail
+		_, errRelease := r.store.Lease(context.Background(), name, owner, time.Time{})
+		if errRelease != nil {
+			r.logger.Warn(
+				"unable to release lease",
+				zap.Error(errRelease),
+				zap.String("lease", name),
+			)
+		}
+	}()
+
+	return fn(ctx)
+}
+
+func (r *runner) Instance(
+	ctx context.Context,
+	sagaType string,
+	correlationID string,
+) (*Instance, error) {
+	return r.store.Instance(ctx, InstanceID(sagaType, correlationID))
+}
+
+func (r *runner) Instances(
+	ctx context.Context,
+	statuses ...Status,
+) ([]Instance, error) {
+	return r.store.Instances(ctx, statuses...)
+}
+
+/* ----- helpers ----- */
+// claim leases the instance of definition to a new run, creating the
+// instance when it has not run yet
+func (r *runner) claim(
+	ctx context.Context,
+	definition Definition,
+) (*Instance, error) {
+	id := InstanceID(definition.Type, definition.CorrelationID)
+	now := time.Now()
+	instance, err := r.store.Claim(ctx, Instance{
+		ID:               id,
+		Type:             definition.Type,
+		CorrelationID:    definition.CorrelationID,
+		AggregateID:      definition.AggregateID,
+		Status:           StatusRunning,
+		CompletedSteps:   []string{},
+		CompensatedSteps: []string{},
+		StartedAt:        now,
+		UpdatedAt:        now,
+		LeaseOwner:       eventsource.NewUUID(),
+		LeaseExpiresAt:   now.Add(r.leaseDuration),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to claim saga instance %v", id)
+	}
+	return instance, nil
+}
+
+func (r *runner) fail(
+	ctx context.Context,
+	definition Definition,
+	instance *Instance,
+	step string,
+	cause error,
+) error {
+	instance.Attempts++
+	instance.LastError = fmt.Sprintf("%v: %v", step, cause.Error())
+
+	if !IsPermanent(cause) && instance.Attempts < r.maxAttempts {
+		errSave := r.release(ctx, instance)
+		if errSave != nil {
+			r.logger.Error(
+				"unable to save failed saga instance",
+				zap.Error(errSave),
+				zap.String("sagaId", instance.ID),
+			)
+		}
+		return cause
+	}
+
+	r.logger.Error(
+		"saga failed permanently, compensating",
+		zap.Error(cause),
+		zap.String("sagaId", instance.ID),
+		zap.String("step", step),
+		zap.Int("attempts", instance.Attempts),
+	)
+	instance.Status = r.compensate(ctx, definition, instance)
+
+	errSave := r.release(ctx, instance)
+	if errSave != nil {
+		return errSave
+	}
+	return cause
+}
+
+// compensate undoes the completed steps in reverse order and returns the
+// resulting status of the instance
+func (r *runner) compensate(
+	ctx context.Context,
+	definition Definition,
+	instance *Instance,
+) Status {
+	for i := len(definition.Steps) - 1; i >= 0; i-- {
+		step := definition.Steps[i]
+		if !instance.Completed(step.Name) ||
+			contains(instance.CompensatedSteps, step.Name) ||
+			step.Compensate == nil {
+			continue
+		}
+
+		err := step.Compensate(ctx)
+		if err != nil {
+			r.logger.Error(
+				"saga compensation failed",
+				zap.Error(err),
+				zap.String("sagaId", instance.ID),
+				zap.String("step", step.Name),
+			)
+			instance.LastError = fmt.Sprintf(
+				"%v; compensating %v: %v",
+				instance.LastError,
+				step.Name,
+				err.Error(),
+			)
+			return StatusFailed
+		}
+		instance.CompensatedSteps = append(instance.CompensatedSteps, step.Name)
+	}
+	return StatusCompensated
+}
+
+// save saves the progress of instance and renews its lease
+func (r *runner) save(ctx context.Context, instance *Instance) error {
+	instance.UpdatedAt = time.Now()
+	instance.LeaseExpiresAt = instance.UpdatedAt.Add(r.leaseDuration)
+	return r.persist(ctx, instance)
+}
+
+// release saves instance and ends its lease so that the next run can
+// claim it right away
+func (r *runner) release(ctx context.Context, instance *Instance) error {
+	instance.UpdatedAt = time.Now()
+	instance.LeaseExpiresAt = time.Time{}
+	return r.persist(ctx, instance)
+}
+
+func (r *runner) persist(ctx context.Context, instance *Instance) error {
+	err := r.store.Save(ctx, *instance)
+	if err != nil {
+		return errors.Wrapf(err, "unable to save saga instance %v", instance.ID)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,322 @@
+package saga_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga/sagatest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestRunner_CompletesSteps(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	recorder := &stepRecorder{}
+	runner := newTestRunner(t, sagatest.NewMemoryStore())
+	definition := recorder.definition("a", "b")
+
+	instance, err := runner.Run(ctx, definition)
+	assert.Nil(err)
+	assert.Equal(saga.StatusCompleted, instance.Status)
+	assert.Equal([]string{"a", "b"}, instance.CompletedSteps)
+
+	_, errRerun := runner.Run(ctx, definition)
+	assert.Nil(errRerun)
+	assert.Equal([]string{"a", "b"}, recorder.calls())
+}
+
+func TestRunner_ResumesAfterTransientFailure(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	recorder := &stepRecorder{failures: map[string]error{
+		"b": errors.New("unavailable"),
+	}}
+	runner := newTestRunner(t, sagatest.NewMemoryStore())
+	definition := recorder.definition("a", "b", "c")
+
+	instance, err := runner.Run(ctx, definition)
+	assert.NotNil(err)
+	assert.Equal(saga.StatusRunning, instance.Status)
+	assert.Equal([]string{"a"}, instance.CompletedSteps)
+	assert.Equal(1, instance.Attempts)
+	assert.Contains(instance.LastError, "unavailable")
+
+	recorder.failures = nil
+	instance, err = runner.Run(ctx, definition)
+	assert.Nil(err)
+	assert.Equal(saga.StatusCompleted, instance.Status)
+
+	// Step a is not repeated
+	assert.Equal([]string{"a", "b", "b", "c"}, recorder.calls())
+}
+
+func TestRunner_CompensatesPermanentFailure(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	recorder := &stepRecorder{failures: map[string]error{
+		"c": saga.Permanent(errors.New("rejected")),
+	}}
+	runner := newTestRunner(t, sagatest.NewMemoryStore())
+
+	instance, err := runner.Run(ctx, recorder.definition("a", "b", "c"))
+	assert.NotNil(err)
+	assert.Equal(saga.StatusCompensated, instance.Status)
+	assert.Equal([]string{"b", "a"}, instance.CompensatedSteps)
+	assert.Equal(
+		[]string{"a", "b", "c", "undo:b", "undo:a"},
+		recorder.calls(),
+	)
+
+	// Compensated instances are not run again
+	_, errRerun := runner.Run(ctx, recorder.definition("a", "b", "c"))
+	assert.Nil(errRerun)
+	assert.Len(recorder.calls(), 5)
+}
+
+func TestRunner_CompensatesAfterMaxAttempts(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	recorder := &stepRecorder{failures: map[string]error{
+		"b": errors.New("unavailable"),
+	}}
+	store := sagatest.NewMemoryStore()
+	runner := saga.NewRunner(saga.RunnerParams{
+		Store:       store,
+		Logger:      zaptest.NewLogger(t),
+		MaxAttempts: 2,
+	})
+	definition := recorder.definition("a", "b")
+
+	_, err := runner.Run(ctx, definition)
+	assert.NotNil(err)
+
+	instance, errRetry := runner.Run(ctx, definition)
+	assert.NotNil(errRetry)
+	assert.Equal(saga.StatusCompensated, instance.Status)
+	assert.Equal(2, instance.Attempts)
+
+	instances, _ := runner.Instances(ctx, saga.StatusCompensated)
+	assert.Len(instances, 1)
+}
+
+func TestRunner_FailedCompensation(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	recorder := &stepRecorder{failures: map[string]error{
+		"b":      saga.Permanent(errors.New("rejected")),
+		"undo:a": errors.New("unavailable"),
+	}}
+	runner := newTestRunner(t, sagatest.NewMemoryStore())
+
+	instance, err := runner.Run(ctx, recorder.definition("a", "b"))
+	assert.NotNil(err)
+	assert.Equal(saga.StatusFailed, instance.Status)
+	assert.Empty(instance.CompensatedSteps)
+	assert.Contains(instance.LastError, "compensating a")
+
+	instances, _ := runner.Instances(ctx, saga.StatusRunning, saga.StatusFailed)
+	if assert.Len(instances, 1) {
+		assert.Equal(saga.InstanceID("test", "correlation-1"), instances[0].ID)
+	}
+}
+
+func TestRunner_ClaimsInstance(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	runner := newTestRunner(t, sagatest.NewMemoryStore())
+	definition := saga.Definition{
+		Type:          "test",
+		CorrelationID: "correlation-1",
+		Steps: []saga.Step{{
+			Name: "a",
+			Action: func(context.Context) error {
+				close(started)
+				<-release
+				return nil
+			},
+		}},
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := runner.Run(ctx, definition)
+		done <- err
+	}()
+	<-started
+
+	// A concurrent delivery does not run the steps again
+	_, errClaimed := runner.Run(ctx, definition)
+	assert.Equal(saga.ErrInstanceClaimed, errors.Cause(errClaimed))
+
+	close(release)
+	assert.Nil(<-done)
+
+	instance, err := runner.Run(ctx, definition)
+	assert.Nil(err)
+	assert.Equal(saga.StatusCompleted, instance.Status)
+}
+
+func TestRunner_ClaimsExpiredLease(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	// The run holding the lease stopped without releasing it
+	store := sagatest.NewMemoryStore()
+	_, errClaim := store.Claim(ctx, saga.Instance{
+		ID:             saga.InstanceID("test", "correlation-1"),
+		Type:           "test",
+		CorrelationID:  "correlation-1",
+		Status:         saga.StatusRunning,
+		LeaseOwner:     "crashed",
+		LeaseExpiresAt: time.Now().Add(-time.Second),
+	})
+	assert.Nil(errClaim)
+
+	recorder := &stepRecorder{}
+	instance, err := newTestRunner(t, store).Run(ctx, recorder.definition("a"))
+	assert.Nil(err)
+	assert.Equal(saga.StatusCompleted, instance.Status)
+	assert.Equal([]string{"a"}, recorder.calls())
+}
+
+func TestRunner_RecoverResumesInstances(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	store := sagatest.NewMemoryStore()
+	runner := newTestRunner(t, store)
+	interrupted := &stepRecorder{failures: map[string]error{
+		"b": errors.New("unavailable"),
+	}}
+	_, errRun := runner.Run(ctx, interrupted.definition("a", "b"))
+	assert.NotNil(errRun)
+
+	resumed := []string{}
+	resume := func(ctx context.Context, instance saga.Instance) error {
+		resumed = append(resumed, instance.ID)
+		return nil
+	}
+
+	// Another replica is recovering
+	leased, errLease := store.Lease(
+		ctx,
+		"recovery:test",
+		"replica-2",
+		time.Now().Add(time.Minute),
+	)
+	assert.True(leased)
+	assert.Nil(errLease)
+	assert.Nil(runner.Recover(ctx, resume, "test"))
+	assert.Empty(resumed)
+
+	_, errRelease := store.Lease(ctx, "recovery:test", "replica-2", time.Time{})
+	assert.Nil(errRelease)
+	assert.Nil(runner.Recover(ctx, resume, "other"))
+	assert.Empty(resumed)
+	assert.Nil(runner.Recover(ctx, resume, "test"))
+	assert.Equal([]string{saga.InstanceID("test", "correlation-1")}, resumed)
+}
+
+func TestRunner_CompensateRetriesFailedCompensation(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	recorder := &stepRecorder{failures: map[string]error{
+		"b":      saga.Permanent(errors.New("rejected")),
+		"undo:a": errors.New("unavailable"),
+	}}
+	runner := newTestRunner(t, sagatest.NewMemoryStore())
+	definition := recorder.definition("a", "b")
+
+	instance, _ := runner.Run(ctx, definition)
+	assert.Equal(saga.StatusFailed, instance.Status)
+
+	// Running a failed instance does not compensate it again
+	_, errRun := runner.Run(ctx, definition)
+	assert.Nil(errRun)
+	assert.Equal([]string{"a", "b", "undo:a"}, recorder.calls())
+
+	recorder.failures = nil
+	compensated, err := runner.Compensate(ctx, definition)
+	assert.Nil(err)
+	assert.Equal(saga.StatusCompensated, compensated.Status)
+	assert.Equal([]string{"a"}, compensated.CompensatedSteps)
+	assert.Equal([]string{"a", "b", "undo:a", "undo:a"}, recorder.calls())
+}
+
+func TestIsPermanent(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(saga.IsPermanent(nil))
+	assert.False(saga.IsPermanent(errors.New("unavailable")))
+	assert.True(saga.IsPermanent(saga.Permanent(errors.New("rejected"))))
+	assert.True(saga.IsPermanent(errors.Wrap(saga.Permanent(errors.New("rejected")), "step")))
+	assert.True(saga.IsPermanent(&eventsource.ValidationError{}))
+
+	// Packages saga depends on recognise permanent errors by this method
+	marked, ok := saga.Permanent(errors.New("rejected")).(interface{ Permanent() bool })
+	assert.True(ok && marked.Permanent())
+}
+
+/* ----- helpers ----- */
+type stepRecorder struct {
+	mu       sync.Mutex
+	failures map[string]error
+	called   []string
+}
+
+func (r *stepRecorder) run(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.called = append(r.called, name)
+	return r.failures[name]
+}
+
+func (r *stepRecorder) calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.called...)
+}
+
+func (r *stepRecorder) definition(steps ...string) saga.Definition {
+	definition := saga.Definition{
+		Type:          "test",
+		CorrelationID: "correlation-1",
+	}
+	for _, v := range steps {
+		name := v
+		definition.Steps = append(definition.Steps, saga.Step{
+			Name: name,
+			Action: func(context.Context) error {
+				return r.run(name)
+			},
+			Compensate: func(context.Context) error {
+				return r.run("undo:" + name)
+			},
+		})
+	}
+	return definition
+}
+
+func newTestRunner(t *testing.T, store saga.Store) saga.Runner {
+	return saga.NewRunner(saga.RunnerParams{
+		Store:  store,
+		Logger: zaptest.NewLogger(t),
+	})
+}
@@ -0,0 +1,129 @@
+// Package sagatest provides an in-memory saga Store shared by the tests of
+// the sagas
+package sagatest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
+)
+
+// MemoryStore is a saga Store that keeps instances in memory. Like the
+// Firestore store, it claims and saves instances atomically
+type MemoryStore struct {
+	mu        sync.Mutex
+	instances map[string]saga.Instance
+	leases    map[string]lease
+}
+
+type lease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		instances: make(map[string]saga.Instance),
+		leases:    make(map[string]lease),
+	}
+}
+
+func (m *MemoryStore) Instance(
+	ctx context.Context,
+	id string,
+) (*saga.Instance, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	instance, ok := m.instances[id]
+	if !ok {
+		return nil, nil
+	}
+	return &instance, nil
+}
+
+func (m *MemoryStore) Claim(
+	ctx context.Context,
+	instance saga.Instance,
+) (*saga.Instance, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	claimed, ok := m.instances[instance.ID]
+	if !ok {
+		m.put(instance)
+		return &instance, nil
+	}
+	if claimed.Settled() {
+		return &claimed, nil
+	}
+	if claimed.LeasedByOther(instance.LeaseOwner, time.Now()) {
+		return nil, saga.ErrInstanceClaimed
+	}
+
+	claimed.LeaseOwner = instance.LeaseOwner
+	claimed.LeaseExpiresAt = instance.LeaseExpiresAt
+	m.put(claimed)
+	return &claimed, nil
+}
+
+func (m *MemoryStore) Save(ctx context.Context, instance saga.Instance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.instances[instance.ID]
+	if ok && current.LeasedByOther(instance.LeaseOwner, time.Now()) {
+		return saga.ErrInstanceClaimed
+	}
+	m.put(instance)
+	return nil
+}
+
+func (m *MemoryStore) Instances(
+	ctx context.Context,
+	statuses ...saga.Status,
+) ([]saga.Instance, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	instances := []saga.Instance{}
+	for _, v := range m.instances {
+		for _, s := range statuses {
+			if v.Status == s {
+				instances = append(instances, v)
+			}
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].UpdatedAt.Before(instances[j].UpdatedAt)
+	})
+	return instances, nil
+}
+
+func (m *MemoryStore) Lease(
+	ctx context.Context,
+	name string,
+	owner string,
+	until time.Time,
+) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.leases[name]
+	if ok && current.owner != owner && time.Now().Before(current.expiresAt) {
+		return false, nil
+	}
+	m.leases[name] = lease{owner: owner, expiresAt: until}
+	return true, nil
+}
+
+// put stores a copy of instance so that callers cannot change it in place
+func (m *MemoryStore) put(instance saga.Instance) {
+	instance.CompletedSteps = append([]string{}, instance.CompletedSteps...)
+	instance.CompensatedSteps = append([]string{}, instance.CompensatedSteps...)
+	m.instances[instance.ID] = instance
+}
@@ -0,0 +1,81 @@
+package saga
+
+import (
+	"context"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/scheduler"
+	"github.com/pkg/errors"
+)
+
+var errTimeoutNameRequired = errors.New("saga timeout name must be defined")
+
+// Timeout dispatches Command at DueAt unless it is cancelled first. The
+// events emitted by the command feed back into the saga that registered it
+type Timeout struct {
+	SagaType      string
+	CorrelationID string
+
+	// Name distinguishes the timeouts of a saga instance
+	Name string
+
+	// DueAt should be derived from the event that registers the timeout
+	// so that a redelivered event does not push the deadline back
+	DueAt   time.Time
+	Command eventsource.Command
+}
+
+// Timeouts registers deadlines for saga instances
+type Timeouts interface {
+	// Register schedules the timeout, replacing a registered timeout
+	// with the same saga type, correlation id and name
+	Register(ctx context.Context, timeout Timeout) error
+
+	// Cancel removes a registered timeout. Cancelling a timeout that fired
+	// or was never registered is not an error
+	Cancel(ctx context.Context, sagaType string, correlationID string, name string) error
+}
+
+type timeouts struct {
+	scheduler scheduler.Scheduler
+}
+
+// NewTimeouts creates Timeouts that are dispatched by s. Time is measured
+// by the Clock of s
+func NewTimeouts(s scheduler.Scheduler) Timeouts {
+	return &timeouts{
+		scheduler: s,
+	}
+}
+
+// TimeoutKey returns the scheduler key of a saga timeout
+func TimeoutKey(sagaType string, correlationID string, name string) string {
+	return "saga:" + InstanceID(sagaType, correlationID) + ":" + name
+}
+
+func (t *timeouts) Register(ctx context.Context, timeout Timeout) error {
+	if eventsource.IsStringEmpty(&timeout.SagaType) ||
+		eventsource.IsStringEmpty(&timeout.CorrelationID) {
+		return errInstanceIDRequired
+	}
+	if eventsource.IsStringEmpty(&timeout.Name) {
+		return errTimeoutNameRequired
+	}
+
+	return t.scheduler.Schedule(
+		ctx,
+		TimeoutKey(timeout.SagaType, timeout.CorrelationID, timeout.Name),
+		timeout.DueAt,
+		timeout.Command,
+	)
+}
+
+func (t *timeouts) Cancel(
+	ctx context.Context,
+	sagaType string,
+	correlationID string,
+	name string,
+) error {
+	return t.scheduler.Cancel(ctx, TimeoutKey(sagaType, correlationID, name))
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts time so that scheduling can be tested deterministically
+type Clock interface {
+	Now() time.Time
+
+	// After returns a channel that receives the time once d has elapsed
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+// NewClock returns a Clock backed by the system time
+func NewClock() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// FakeClock is a Clock whose time only moves when advanced
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFakeClock creates a FakeClock set to now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	at := c.now.Add(d)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: at, ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d and fires the channels of every
+// After call that is now due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, v := range c.waiters {
+		if v.at.After(c.now) {
+			pending = append(pending, v)
+			continue
+		}
+		v.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+// Waiters returns the number of After calls that have not fired yet
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
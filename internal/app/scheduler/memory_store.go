@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryStore struct {
+	mu           sync.Mutex
+	entries      map[string]Entry
+	deadLettered map[string]Entry
+}
+
+// NewMemoryStore creates a Store that keeps entries in memory. Entries do
+// not survive a restart
+func NewMemoryStore() Store {
+	return &memoryStore{
+		entries:      make(map[string]Entry),
+		deadLettered: make(map[string]Entry),
+	}
+}
+
+func (m *memoryStore) Save(ctx context.Context, entry Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[entry.Key] = entry
+	return nil
+}
+
+func (m *memoryStore) Cancel(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *memoryStore) Due(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	due := []Entry{}
+	for _, v := range m.entries {
+		if !v.DueAt.After(now) {
+			due = append(due, v)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].DueAt.Before(due[j].DueAt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (m *memoryStore) Complete(ctx context.Context, key string, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.entries[key]; ok && v.ID == id {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+func (m *memoryStore) Retry(ctx context.Context, entry Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.entries[entry.Key]; ok && v.ID == entry.ID {
+		m.entries[entry.Key] = entry
+	}
+	return nil
+}
+
+func (m *memoryStore) DeadLetter(ctx context.Context, entry Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := m.entries[entry.Key]; ok && v.ID == entry.ID {
+		delete(m.entries, entry.Key)
+		m.deadLettered[entry.Key] = entry
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errKeyRequired       = errors.New("scheduled command key must be defined")
+	errSchedulerStarted  = errors.New("scheduler already started")
+	defaultPollInterval  = 10 * time.Second
+	defaultRetryDelay    = 30 * time.Second
+	defaultMaxRetryDelay = time.Hour
+	defaultBatchSize     = 100
+	defaultMaxAttempts   = 10
+)
+
+// EntryStatus is the status of a scheduled entry
+type EntryStatus string
+
+const (
+	// EntryStatusPending entries are dispatched once due
+	EntryStatusPending EntryStatus = "pending"
+
+	// EntryStatusDeadLettered entries failed MaxAttempts times, or failed
+	// permanently, and are no longer dispatched
+	EntryStatusDeadLettered EntryStatus = "deadLettered"
+)
+
+// Entry is a command scheduled to be dispatched once DueAt has passed
+type Entry struct {
+	// Key identifies the entry; scheduling with an existing key replaces it
+	Key string `firestore:"key"`
+
+	// ID is unique to each scheduling and is used as the idempotency key
+	// of the dispatched command
+	ID          string    `firestore:"id"`
+	CommandType string    `firestore:"commandType"`
+	Payload     string    `firestore:"payload"`
+	DueAt       time.Time `firestore:"dueAt"`
+	Attempts    int       `firestore:"attempts"`
+	LastError   string    `firestore:"lastError"`
+	CreatedAt   time.Time `firestore:"createdAt"`
+
+	Status EntryStatus `firestore:"status"`
+
+	// Actor is the actor the command is dispatched on behalf of
+	Actor string `firestore:"actor"`
+}
+
+// Store persists scheduled entries
+type Store interface {
+	// Save creates the entry or replaces the entry with the same key
+	Save(ctx context.Context, entry Entry) error
+
+	// Cancel removes the entry with key. Cancelling a missing entry is not an error
+	Cancel(ctx context.Context, key string) error
+
+	// Due returns up to limit entries due at or before now ordered by DueAt
+	Due(ctx context.Context, now time.Time, limit int) ([]Entry, error)
+
+	// Complete removes the entry with key unless it has been replaced
+	// since id was scheduled
+	Complete(ctx context.Context, key string, id string) error
+
+	// Retry updates the entry unless it has been replaced since it was loaded
+	Retry(ctx context.Context, entry Entry) error
+
+	// DeadLetter moves the entry out of the schedule, so that it is never
+	// due again, unless it has been replaced since it was loaded
+	DeadLetter(ctx context.Context, entry Entry) error
+}
+
+// Scheduler dispatches commands through a CommandDispatcher once they are
+// due. Delivery is at least once: an entry is only removed after its
+// command is dispatched successfully, and the command carries the entry id
+// as idempotency key so that repeated dispatches are not executed twice
+type Scheduler interface {
+	// Schedule stores cmd to be dispatched at dueAt under key
+	Schedule(ctx context.Context, key string, dueAt time.Time, cmd eventsource.Command) error
+
+	// Cancel removes the command scheduled under key
+	Cancel(ctx context.Context, key string) error
+
+	// RunDue dispatches the commands that are due and returns how many
+	// were dispatched successfully
+	RunDue(ctx context.Context) (int, error)
+
+	// Start polls for due commands until Stop is called
+	Start(ctx context.Context) error
+	Stop()
+}
+
+// Params represent the params needed to instantiate a new Scheduler
+type Params struct {
+	Store      Store
+	Registry   eventsource.CommandRegistry
+	Dispatcher eventsource.CommandDispatcher
+	Logger     *zap.Logger
+
+	// Clock defaults to the system clock. Optional
+	Clock Clock
+
+	// PollInterval defaults to 10s. Optional
+	PollInterval time.Duration
+
+	// RetryDelay is the delay before the first retry of a failed
+	// command. It doubles on every attempt. Defaults to 30s. Optional
+	RetryDelay time.Duration
+
+	// MaxAttempts is how many times a command is dispatched before it is
+	// dead-lettered. Permanent failures are dead-lettered on the first
+	// attempt. Defaults to 10. Optional
+	MaxAttempts int
+}
+
+type scheduler struct {
+	store        Store
+	registry     eventsource.CommandRegistry
+	dispatcher   eventsource.CommandDispatcher
+	clock        Clock
+	logger       *zap.Logger
+	pollInterval time.Duration
+	retryDelay   time.Duration
+	maxAttempts  int
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// New creates a new instance of Scheduler
+func New(p Params) Scheduler {
+	clock := p.Clock
+	if clock == nil {
+		clock = NewClock()
+	}
+	pollInterval := p.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	retryDelay := p.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = defaultRetryDelay
+	}
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	return &scheduler{
+		store:        p.Store,
+		registry:     p.Registry,
+		dispatcher:   p.Dispatcher,
+		clock:        clock,
+		logger:       p.Logger,
+		pollInterval: pollInterval,
+		retryDelay:   retryDelay,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+func (s *scheduler) Schedule(
+	ctx context.Context,
+	key string,
+	dueAt time.Time,
+	cmd eventsource.Command,
+) error {
+	if eventsource.IsStringEmpty(&key) {
+		return errKeyRequired
+	}
+
+	commandType, payload, err := s.registry.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+
+	errSave := s.store.Save(ctx, Entry{
+		Key:         key,
+		ID:          eventsource.NewUUID(),
+		CommandType: commandType,
+		Payload:     string(payload),
+		DueAt:       dueAt,
+		Actor:       eventsource.ActorFromContext(ctx),
+		CreatedAt:   s.clock.Now(),
+		Status:      EntryStatusPending,
+	})
+	if errSave != nil {
+		return errors.Wrap(errSave, "unable to schedule command")
+	}
+	return nil
+}
+
+func (s *scheduler) Cancel(ctx context.Context, key string) error {
+	if eventsource.IsStringEmpty(&key) {
+		return errKeyRequired
+	}
+	return s.store.Cancel(ctx, key)
+}
+
+func (s *scheduler) RunDue(ctx context.Context) (int, error) {
+	entries, err := s.store.Due(ctx, s.clock.Now(), defaultBatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to load due commands")
+	}
+
+	dispatched := 0
+	for _, v := range entries {
+		errDispatch := s.dispatch(ctx, v)
+		if errDispatch != nil {
+			s.retry(ctx, v, errDispatch)
+			continue
+		}
+
+		dispatched++
+		errComplete := s.store.Complete(ctx, v.Key, v.ID)
+		if errComplete != nil {
+			// The entry is dispatched again; its idempotency key
+			// prevents it from being executed twice
+			s.logger.Error(
+				"unable to complete scheduled command",
+				zap.Error(errComplete),
+				zap.String("key", v.Key),
+			)
+		}
+	}
+	return dispatched, nil
+}
+
+func (s *scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		return errSchedulerStarted
+	}
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+
+	go s.poll(eventsource.DetachContext(ctx), s.stop, s.stopped)
+	return nil
+}
+
+func (s *scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.stopped
+	s.stop = nil
+}
+
+/* ----- helpers ----- */
+func (s *scheduler) poll(
+	ctx context.Context,
+	stop <-chan struct{},
+	stopped chan<- struct{},
+) {
+	defer close(stopped)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-s.clock.After(s.pollInterval):
+			_, err := s.RunDue(ctx)
+			if err != nil {
+				s.logger.Error("scheduler poll failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *scheduler) dispatch(ctx context.Context, entry Entry) error {
+	cmd, err := s.registry.Unmarshal(entry.CommandType, []byte(entry.Payload))
+	if err != nil {
+		return err
+	}
+
+	if v, ok := cmd.(idempotencyKeySetter); ok {
+		v.SetIdempotencyKey("schedule:" + entry.ID)
+	}
+
+	ctx = eventsource.WithActor(ctx, entry.Actor)
+	ctx = eventsource.WithCommandSource(ctx, eventsource.CommandSourceScheduler)
+	_, errDispatch := s.dispatcher.Dispatch(ctx, cmd)
+	return errDispatch
+}
+
+// retry reschedules a failed entry with backoff, or dead-letters it once
+// it has failed maxAttempts times or retrying it cannot succeed
+func (s *scheduler) retry(ctx context.Context, entry Entry, cause error) {
+	entry.Attempts++
+	entry.LastError = cause.Error()
+	if entry.Attempts >= s.maxAttempts || isPermanent(cause) {
+		s.deadLetter(ctx, entry, cause)
+		return
+	}
+	entry.DueAt = s.clock.Now().Add(s.backoff(entry.Attempts))
+
+	s.logger.Error(
+		"scheduled command failed",
+		zap.Error(cause),
+		zap.String("key", entry.Key),
+		zap.String("commandType", entry.CommandType),
+		zap.Int("attempts", entry.Attempts),
+		zap.Time("retryAt", entry.DueAt),
+	)
+
+	err := s.store.Retry(ctx, entry)
+	if err != nil {
+		s.logger.Error(
+			"unable to reschedule failed command",
+			zap.Error(err),
+			zap.String("key", entry.Key),
+		)
+	}
+}
+
+func (s *scheduler) deadLetter(ctx context.Context, entry Entry, cause error) {
+	entry.Status = EntryStatusDeadLettered
+
+	s.logger.Error(
+		"scheduled command dead-lettered",
+		zap.Error(cause),
+		zap.String("key", entry.Key),
+		zap.String("commandType", entry.CommandType),
+		zap.Int("attempts", entry.Attempts),
+	)
+
+	err := s.store.DeadLetter(ctx, entry)
+	if err != nil {
+		s.logger.Error(
+			"unable to dead-letter failed command",
+			zap.Error(err),
+			zap.String("key", entry.Key),
+		)
+	}
+}
+
+func (s *scheduler) backoff(attempts int) time.Duration {
+	delay := s.retryDelay
+	for i := 1; i < attempts && delay < defaultMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > defaultMaxRetryDelay {
+		return defaultMaxRetryDelay
+	}
+	return delay
+}
+
+// isPermanent indicates whether retrying cannot fix err. Validation errors
+// and errors marked as permanent, such as by saga.Permanent, are permanent
+func isPermanent(err error) bool {
+	for err != nil {
+		if v, ok := err.(permanentError); ok && v.Permanent() {
+			return true
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = cause.Cause()
+	}
+
+	_, ok := eventsource.AsValidationError(err)
+	return ok
+}
+
+type idempotencyKeySetter interface {
+	SetIdempotencyKey(key string)
+}
+
+type permanentError interface {
+	Permanent() bool
+}
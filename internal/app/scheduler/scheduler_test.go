@@ -0,0 +1,358 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestScheduler_DispatchesDueCommands(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	clock := NewFakeClock(testNow)
+	handler := &recordingCommandHandler{}
+	s := newTestScheduler(t, clock, handler)
+
+	assert.Nil(s.Schedule(ctx, "later", testNow.Add(time.Hour), &remindCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-2"},
+		Message:      "later",
+	}))
+	assert.Nil(s.Schedule(ctx, "soon", testNow.Add(time.Minute), &remindCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+		Message:      "soon",
+	}))
+
+	dispatched, err := s.RunDue(ctx)
+	assert.Nil(err)
+	assert.Equal(0, dispatched)
+
+	clock.Advance(time.Minute)
+	dispatched, err = s.RunDue(ctx)
+	assert.Nil(err)
+	assert.Equal(1, dispatched)
+
+	clock.Advance(time.Hour)
+	dispatched, err = s.RunDue(ctx)
+	assert.Nil(err)
+	assert.Equal(1, dispatched)
+
+	commands := handler.handled()
+	if assert.Len(commands, 2) {
+		assert.Equal("soon", commands[0].Message)
+		assert.Equal("agg-1", commands[0].AggregateID())
+		assert.Equal("later", commands[1].Message)
+		assert.Contains(commands[1].IdempotencyKey(), "schedule:")
+	}
+
+	dispatched, err = s.RunDue(ctx)
+	assert.Nil(err)
+	assert.Equal(0, dispatched)
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	clock := NewFakeClock(testNow)
+	handler := &recordingCommandHandler{}
+	s := newTestScheduler(t, clock, handler)
+
+	assert.Nil(s.Schedule(ctx, "reminder", testNow.Add(time.Minute), &remindCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+	}))
+	assert.Nil(s.Cancel(ctx, "reminder"))
+	assert.Nil(s.Cancel(ctx, "reminder"))
+	assert.Equal(errKeyRequired, s.Cancel(ctx, ""))
+
+	clock.Advance(time.Hour)
+	dispatched, err := s.RunDue(ctx)
+	assert.Nil(err)
+	assert.Equal(0, dispatched)
+	assert.Empty(handler.handled())
+}
+
+func TestScheduler_RetriesFailedCommands(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	clock := NewFakeClock(testNow)
+	handler := &recordingCommandHandler{failures: 2}
+	store := NewMemoryStore()
+	s := newTestSchedulerWithStore(t, clock, handler, store)
+
+	assert.Nil(s.Schedule(ctx, "reminder", testNow, &remindCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+	}))
+
+	dispatched, err := s.RunDue(ctx)
+	assert.Nil(err)
+	assert.Equal(0, dispatched)
+
+	entries, _ := store.Due(ctx, testNow.Add(time.Hour), 0)
+	if assert.Len(entries, 1) {
+		assert.Equal(1, entries[0].Attempts)
+		assert.Equal(testNow.Add(time.Second), entries[0].DueAt)
+		assert.NotEmpty(entries[0].LastError)
+	}
+
+	// The delay doubles after every failed attempt
+	clock.Advance(time.Second)
+	dispatched, _ = s.RunDue(ctx)
+	assert.Equal(0, dispatched)
+
+	clock.Advance(time.Second)
+	dispatched, _ = s.RunDue(ctx)
+	assert.Equal(0, dispatched)
+
+	clock.Advance(time.Second)
+	dispatched, _ = s.RunDue(ctx)
+	assert.Equal(1, dispatched)
+	assert.Len(handler.handled(), 1)
+
+	entries, _ = store.Due(ctx, testNow.Add(time.Hour), 0)
+	assert.Empty(entries)
+}
+
+func TestScheduler_DeadLettersAfterMaxAttempts(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	clock := NewFakeClock(testNow)
+	handler := &recordingCommandHandler{failures: defaultMaxAttempts + 1}
+	store := NewMemoryStore()
+	s := newTestSchedulerWithStore(t, clock, handler, store)
+
+	assert.Nil(s.Schedule(ctx, "reminder", testNow, &remindCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+	}))
+
+	for i := 0; i < defaultMaxAttempts; i++ {
+		dispatched, err := s.RunDue(ctx)
+		assert.Nil(err)
+		assert.Equal(0, dispatched)
+		clock.Advance(time.Hour)
+	}
+
+	entries, _ := store.Due(ctx, clock.Now().Add(24*time.Hour), 0)
+	assert.Empty(entries)
+
+	deadLettered := store.(*memoryStore).deadLettered["reminder"]
+	assert.Equal(EntryStatusDeadLettered, deadLettered.Status)
+	assert.Equal(defaultMaxAttempts, deadLettered.Attempts)
+	assert.NotEmpty(deadLettered.LastError)
+}
+
+func TestScheduler_DeadLettersPermanentFailures(t *testing.T) {
+	tests := map[string]error{
+		"validation": errors.Wrap(
+			&eventsource.ValidationError{Fields: []eventsource.FieldError{
+				{Field: "id", Rule: eventsource.RuleRequired, Message: "is required"},
+			}},
+			"invalid command",
+		),
+		"permanent": testPermanentError{errors.New("user not found")},
+	}
+
+	for name, failure := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			ctx := context.Background()
+
+			clock := NewFakeClock(testNow)
+			handler := &recordingCommandHandler{failures: 1, err: failure}
+			store := NewMemoryStore()
+			s := newTestSchedulerWithStore(t, clock, handler, store)
+
+			assert.Nil(s.Schedule(ctx, "reminder", testNow, &remindCommand{
+				CommandModel: eventsource.CommandModel{ID: "agg-1"},
+			}))
+
+			dispatched, err := s.RunDue(ctx)
+			assert.Nil(err)
+			assert.Equal(0, dispatched)
+
+			entries, _ := store.Due(ctx, clock.Now().Add(24*time.Hour), 0)
+			assert.Empty(entries)
+
+			deadLettered := store.(*memoryStore).deadLettered["reminder"]
+			assert.Equal(EntryStatusDeadLettered, deadLettered.Status)
+			assert.Equal(1, deadLettered.Attempts)
+		})
+	}
+}
+
+func TestScheduler_RescheduleReplacesEntry(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	clock := NewFakeClock(testNow)
+	handler := &recordingCommandHandler{}
+	s := newTestScheduler(t, clock, handler)
+
+	assert.Nil(s.Schedule(ctx, "reminder", testNow.Add(time.Minute), &remindCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+		Message:      "first",
+	}))
+	assert.Nil(s.Schedule(ctx, "reminder", testNow.Add(time.Hour), &remindCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+		Message:      "second",
+	}))
+
+	clock.Advance(time.Minute)
+	dispatched, _ := s.RunDue(ctx)
+	assert.Equal(0, dispatched)
+
+	clock.Advance(time.Hour)
+	dispatched, _ = s.RunDue(ctx)
+	assert.Equal(1, dispatched)
+
+	commands := handler.handled()
+	if assert.Len(commands, 1) {
+		assert.Equal("second", commands[0].Message)
+	}
+}
+
+func TestScheduler_StartPollsWithClock(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	clock := NewFakeClock(testNow)
+	handler := &recordingCommandHandler{}
+	s := newTestScheduler(t, clock, handler)
+
+	assert.Nil(s.Schedule(ctx, "reminder", testNow.Add(time.Minute), &remindCommand{
+		CommandModel: eventsource.CommandModel{ID: "agg-1"},
+	}))
+	assert.Nil(s.Start(ctx))
+	assert.Equal(errSchedulerStarted, s.Start(ctx))
+
+	waitForWaiters(t, clock)
+	clock.Advance(time.Minute)
+	waitForWaiters(t, clock)
+
+	s.Stop()
+	assert.Len(handler.handled(), 1)
+}
+
+func TestFakeClock_Advance(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := NewFakeClock(testNow)
+	immediate := clock.After(0)
+	later := clock.After(time.Minute)
+
+	assert.Equal(testNow, <-immediate)
+	assert.Equal(1, clock.Waiters())
+
+	clock.Advance(30 * time.Second)
+	assert.Len(later, 0)
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(testNow.Add(time.Minute), <-later)
+	assert.Equal(0, clock.Waiters())
+}
+
+/* ----- helpers ----- */
+var testNow = time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+type remindCommand struct {
+	eventsource.CommandModel
+	Message string
+}
+
+type recordingCommandHandler struct {
+	mu       sync.Mutex
+	failures int
+	commands []*remindCommand
+
+	// err is returned by the failures when set
+	err error
+}
+
+func (h *recordingCommandHandler) Handle(
+	ctx context.Context,
+	cmd eventsource.Command,
+) (*eventsource.CommandResult, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.failures > 0 {
+		h.failures--
+		if h.err != nil {
+			return nil, h.err
+		}
+		return nil, errors.New("handler failed")
+	}
+	h.commands = append(h.commands, cmd.(*remindCommand))
+	return nil, nil
+}
+
+func (h *recordingCommandHandler) CommandsHandled() []eventsource.Command {
+	return []eventsource.Command{&remindCommand{}}
+}
+
+func (h *recordingCommandHandler) handled() []*remindCommand {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*remindCommand{}, h.commands...)
+}
+
+func newTestScheduler(
+	t *testing.T,
+	clock Clock,
+	handler eventsource.CommandHandler,
+) Scheduler {
+	return newTestSchedulerWithStore(t, clock, handler, NewMemoryStore())
+}
+
+func newTestSchedulerWithStore(
+	t *testing.T,
+	clock Clock,
+	handler eventsource.CommandHandler,
+	store Store,
+) Scheduler {
+	logger := zaptest.NewLogger(t)
+
+	dispatcher := eventsource.NewDispatcher(logger)
+	dispatcher.RegisterHandler(handler)
+
+	registry := eventsource.NewCommandRegistry()
+	registry.Register(&remindCommand{})
+
+	return New(Params{
+		Store:        store,
+		Registry:     registry,
+		Dispatcher:   dispatcher,
+		Logger:       logger,
+		Clock:        clock,
+		PollInterval: time.Second,
+		RetryDelay:   time.Second,
+	})
+}
+
+type testPermanentError struct {
+	error
+}
+
+func (e testPermanentError) Permanent() bool {
+	return true
+}
+
+// waitForWaiters blocks until the poll loop is waiting on the clock
+func waitForWaiters(t *testing.T, clock *FakeClock) {
+	deadline := time.Now().Add(time.Second)
+	for clock.Waiters() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("scheduler is not waiting on the clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -14,7 +14,14 @@ var (
 	UserCreatedEventType           = "UserCreated"
 	UserReferralCreatedEventType   = "UserReferralCreated"
 	UserReferralCompletedEventType = "UserReferralCompleted"
+	UserReferralExpiredEventType   = "UserReferralExpired"
+	UserReferralSentEventType      = "UserReferralSent"
+	UserReferralCancelledEventType = "UserReferralCancelled"
+	UserReferralFlaggedEventType   = "UserReferralFlagged"
 	PointsEarnedEventType          = "PointsEarned"
+	PointsRevokedEventType         = "PointsRevoked"
+	PointsRedeemedEventType        = "PointsRedeemed"
+	ProfileUpdatedEventType        = "ProfileUpdated"
 )
 
 // ReferralStatus represents the state of a referral
@@ -24,6 +31,8 @@ const (
 	ReferralStatusCreated   ReferralStatus = "Created"
 	ReferralStatusSent      ReferralStatus = "Sent"
 	ReferralStatusCompleted ReferralStatus = "Completed"
+	ReferralStatusExpired   ReferralStatus = "Expired"
+	ReferralStatusCancelled ReferralStatus = "Cancelled"
 )
 
 func GetReferralStatus(status *string) (ReferralStatus, error) {
@@ -38,6 +47,10 @@ func GetReferralStatus(status *string) (ReferralStatus, error) {
 		return ReferralStatusSent, nil
 	case string(ReferralStatusCompleted):
 		return ReferralStatusCompleted, nil
+	case string(ReferralStatusExpired):
+		return ReferralStatusExpired, nil
+	case string(ReferralStatusCancelled):
+		return ReferralStatusCancelled, nil
 	default:
 		return "", errInvalidStatus
 	}
@@ -48,6 +61,7 @@ type Referral struct {
 	ID                string         `json:"id" firestore:"id"`
 	ReferralCode      string         `json:"referralCode" firestore:"referralCode"`
 	ReferredUserEmail string         `json:"referredUserEmail" firestore:"referredUserEmail"`
+	ReferredUserID    string         `json:"referredUserId,omitempty" firestore:"referredUserId,omitempty"`
 	Status            ReferralStatus `json:"status" firestore:"status"`
 	CreatedAt         time.Time      `json:"createdAt" firestore:"createdAt"`
 	UpdatedAt         time.Time      `json:"updatedAt" firestore:"updatedAt"`
@@ -63,9 +77,16 @@ type User struct {
 	// TODO: should this be a pointer?
 	ReferralCode *string    `json:"referralCode"`
 	Referrals    []Referral `json:"referrals"`
+	Profile      Profile    `json:"profile"`
 	CreatedAt    time.Time  `json:"createdAt"`
 	UpdatedAt    time.Time  `json:"updatedAt"`
 	DeletedAt    *time.Time `json:"deletedAt"`
+
+	// ProfileCompletedAt is when the profile was first completed
+	ProfileCompletedAt *time.Time `json:"profileCompletedAt"`
+
+	// ReferralFlags are the referrals rejected by the ReferralPolicy
+	ReferralFlags []ReferralFlag `json:"referralFlags"`
 }
 
 // NewUser creates a new instance of the User aggregate
@@ -78,6 +99,16 @@ func NewUser(id string) eventsource.Aggregate {
 	}
 }
 
+// Referral returns the referral with id, or nil if the user has none
+func (u *User) Referral(id string) *Referral {
+	for i := range u.Referrals {
+		if u.Referrals[i].ID == id {
+			return &u.Referrals[i]
+		}
+	}
+	return nil
+}
+
 // EventVersion returns the current event version
 func (u *User) EventVersion() int {
 	return u.Version
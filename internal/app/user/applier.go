@@ -14,6 +14,20 @@ func GetApplier(event eventsource.Event) (eventsource.Applier, error) {
 			},
 		}, nil
 
+	case PointsRevokedEventType:
+		return &PointsRevoked{
+			ApplierModel: eventsource.ApplierModel{
+				Event: event,
+			},
+		}, nil
+
+	case PointsRedeemedEventType:
+		return &PointsRedeemed{
+			ApplierModel: eventsource.ApplierModel{
+				Event: event,
+			},
+		}, nil
+
 	case UserCreatedEventType:
 		return &Created{
 			ApplierModel: eventsource.ApplierModel{
@@ -42,6 +56,41 @@ func GetApplier(event eventsource.Event) (eventsource.Applier, error) {
 			},
 		}, nil
 
+	case UserReferralExpiredEventType:
+		return &ReferralExpired{
+			ApplierModel: eventsource.ApplierModel{
+				Event: event,
+			},
+		}, nil
+
+	case UserReferralSentEventType:
+		return &ReferralSent{
+			ApplierModel: eventsource.ApplierModel{
+				Event: event,
+			},
+		}, nil
+
+	case UserReferralCancelledEventType:
+		return &ReferralCancelled{
+			ApplierModel: eventsource.ApplierModel{
+				Event: event,
+			},
+		}, nil
+
+	case UserReferralFlaggedEventType:
+		return &ReferralFlagged{
+			ApplierModel: eventsource.ApplierModel{
+				Event: event,
+			},
+		}, nil
+
+	case ProfileUpdatedEventType:
+		return &ProfileUpdated{
+			ApplierModel: eventsource.ApplierModel{
+				Event: event,
+			},
+		}, nil
+
 	default:
 		return nil, errors.New("no registered applier for event type")
 	}
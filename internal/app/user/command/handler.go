@@ -2,6 +2,7 @@ package command
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
@@ -15,6 +16,7 @@ import (
 type handler struct {
 	eventBus eventsource.EventBus
 	repo     eventsource.EventRepo
+	policy   user.ReferralPolicy
 	logger   *zap.Logger
 }
 
@@ -22,6 +24,10 @@ type CommandHandlerParams struct {
 	EventBus eventsource.EventBus
 	Repo     eventsource.EventRepo
 	Logger   *zap.Logger
+
+	// ReferralPolicy is enforced when referrals are created or completed.
+	// Its limits are off when unset
+	ReferralPolicy user.ReferralPolicy
 }
 
 func NewUserCommandHandler(
@@ -30,15 +36,23 @@ func NewUserCommandHandler(
 	return &handler{
 		eventBus: params.EventBus,
 		repo:     params.Repo,
+		policy:   params.ReferralPolicy,
 		logger:   params.Logger,
 	}
 }
 
-// Handle implements the CommandHandler interface
+// Handle implements the CommandHandler interface. Commands are validated
+// as by the ValidationMiddleware so that callers bypassing the dispatcher
+// get the same errors
 func (c *handler) Handle(
 	ctx context.Context,
 	cmd eventsource.Command,
-) error {
+) (*eventsource.CommandResult, error) {
+	errValidate := eventsource.Validate(cmd)
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
 	var err error
 	events := []eventsource.Event{}
 
@@ -53,13 +67,30 @@ func (c *handler) Handle(
 		events, err = c.handleDeleteUser(ctx, v)
 	case *loyalty.EarnPoints:
 		events, err = c.handleEarnPoints(ctx, v)
+	case *loyalty.ExpireReferral:
+		events, err = c.handleExpireReferral(ctx, v)
+	case *loyalty.MarkReferralSent:
+		events, err = c.handleMarkReferralSent(ctx, v)
+	case *loyalty.CancelReferral:
+		events, err = c.handleCancelReferral(ctx, v)
+	case *loyalty.RedeemPoints:
+		events, err = c.handleRedeemPoints(ctx, v)
+	case *loyalty.RevokePoints:
+		events, err = c.handleRevokePoints(ctx, v)
+	case *loyalty.UpdateProfile:
+		events, err = c.handleUpdateProfile(ctx, v)
 	}
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	errPublish := c.eventBus.Publish(ctx, events)
+	if errPublish != nil {
+		return nil, errPublish
 	}
 
-	return c.eventBus.Publish(events)
+	return eventsource.NewCommandResult(cmd.AggregateID(), events), nil
 }
 
 // CommandsHandled implements the CommandHandler interface
@@ -70,6 +101,12 @@ func (c *handler) CommandsHandled() []eventsource.Command {
 		&loyalty.CreateUser{},
 		&loyalty.DeleteUser{},
 		&loyalty.EarnPoints{},
+		&loyalty.ExpireReferral{},
+		&loyalty.MarkReferralSent{},
+		&loyalty.CancelReferral{},
+		&loyalty.RedeemPoints{},
+		&loyalty.RevokePoints{},
+		&loyalty.UpdateProfile{},
 	}
 }
 
@@ -77,12 +114,6 @@ func (c *handler) handleCompleteReferral(
 	ctx context.Context,
 	command *loyalty.CompleteReferral,
 ) ([]eventsource.Event, error) {
-	if eventsource.IsStringEmpty(&command.ReferredUserID) {
-		return nil, errors.New(
-			"ReferredUserID must be defined when completing referral",
-		)
-	}
-
 	aggregate, err := c.loadUserAggregate(ctx, command.AggregateID())
 	if err != nil {
 		return nil, err
@@ -95,17 +126,76 @@ func (c *handler) handleCompleteReferral(
 		)
 	}
 
-	applier := user.NewReferralCompletedApplier(
+	referral := findReferral(aggregate.Referrals, command.ReferredUserEmail)
+	if referral != nil && referral.Status == user.ReferralStatusCompleted &&
+		(referral.ReferredUserID == "" ||
+			referral.ReferredUserID == command.ReferredUserID) {
+		return []eventsource.Event{}, nil
+	}
+
+	// Expired and cancelled invites cannot be completed so that closing an
+	// invite withholds its reward
+	if referral != nil && referral.Status != user.ReferralStatusCompleted {
+		_, errTransition := aggregate.TransitionReferral(
+			referral.ID,
+			user.ReferralStatusCompleted,
+		)
+		if errTransition != nil {
+			return nil, &eventsource.ValidationError{
+				Fields: []eventsource.FieldError{{
+					Field:   "referredUserEmail",
+					Rule:    user.RuleReferralOpen,
+					Message: fmt.Sprintf("has a %v referral", referral.Status),
+				}},
+			}
+		}
+	}
+
+	open := referral != nil && referral.Status.Open()
+	reason := c.policy.Check(aggregate, user.ReferralAttempt{
+		ReferredUserEmail: command.ReferredUserEmail,
+		ReferredUserID:    command.ReferredUserID,
+		NewReferral:       !open,
+		At:                time.Now(),
+	})
+	if reason != "" {
+		return c.flagReferral(ctx, aggregate, reason, user.ReferralFlaggedPayload{
+			ReferredUserEmail: command.ReferredUserEmail,
+			ReferredUserID:    command.ReferredUserID,
+			Command:           "CompleteReferral",
+		})
+	}
+
+	if open {
+		return c.transitionReferral(
+			ctx,
+			aggregate,
+			referral.ID,
+			user.ReferralStatusCompleted,
+			user.NewReferralCompletedApplier,
+			user.UserReferralCompletedEventType,
+			user.ReferralCompletedPayload{
+				ReferralID:     referral.ID,
+				ReferredUserID: command.ReferredUserID,
+			},
+		)
+	}
+
+	// Sometimes the user can use a referral code to sign up even if the
+	// referring user hasn't formally invited them. In this case we create a
+	// new referral with a completed status
+	applier := user.NewReferralCreatedApplier(
 		aggregate.ID,
-		user.UserReferralCompletedEventType,
+		user.UserReferralCreatedEventType,
 		aggregate.Version+1,
 	)
-	payload := getCompleteReferralPayload(
-		aggregate.Referrals,
-		command.ReferredUserEmail,
-		*aggregate.ReferralCode,
-	)
-	errSetPayload := applier.SetSerializedPayload(payload)
+	errSetPayload := applier.SetSerializedPayload(user.ReferralCreatedPayload{
+		ReferredUserEmail: command.ReferredUserEmail,
+		ReferralCode:      *aggregate.ReferralCode,
+		ReferralStatus:    string(user.ReferralStatusCompleted),
+		ReferralID:        eventsource.NewUUID(),
+		ReferredUserID:    command.ReferredUserID,
+	})
 	if errSetPayload != nil {
 		return nil, errSetPayload
 	}
@@ -123,10 +213,13 @@ func (c *handler) handleCreateUser(
 	ctx context.Context,
 	command *loyalty.CreateUser,
 ) ([]eventsource.Event, error) {
-	if eventsource.IsAnyStringEmpty(&command.Username, &command.Email) {
-		return nil, errors.New(
-			"email and username must be defined when creating user",
-		)
+	// A retry without an idempotency key must not create the user again
+	_, errLoad := c.repo.Load(ctx, command.AggregateID(), 0)
+	if errLoad == nil {
+		return nil, errors.Errorf("user %v already exists", command.AggregateID())
+	}
+	if !eventsource.IsNotFound(errLoad) {
+		return nil, errLoad
 	}
 
 	referralCode, errReferralCode := generateReferralCode()
@@ -163,12 +256,6 @@ func (c *handler) handleCreateReferral(
 	ctx context.Context,
 	command *loyalty.CreateReferral,
 ) ([]eventsource.Event, error) {
-	if eventsource.IsStringEmpty(&command.ReferredUserEmail) {
-		return nil, errors.New(
-			"ReferredUserEmail must be defined when creating referral",
-		)
-	}
-
 	aggregate, err := c.loadUserAggregate(ctx, command.AggregateID())
 	if err != nil {
 		return nil, err
@@ -180,6 +267,18 @@ func (c *handler) handleCreateReferral(
 			command.AggregateID())
 	}
 
+	reason := c.policy.Check(aggregate, user.ReferralAttempt{
+		ReferredUserEmail: command.ReferredUserEmail,
+		NewReferral:       true,
+		At:                time.Now(),
+	})
+	if reason != "" {
+		return c.flagReferral(ctx, aggregate, reason, user.ReferralFlaggedPayload{
+			ReferredUserEmail: command.ReferredUserEmail,
+			Command:           "CreateReferral",
+		})
+	}
+
 	applier := user.NewReferralCreatedApplier(
 		command.AggregateID(),
 		user.UserReferralCreatedEventType,
@@ -240,10 +339,6 @@ func (c *handler) handleEarnPoints(
 	ctx context.Context,
 	command *loyalty.EarnPoints,
 ) ([]eventsource.Event, error) {
-	if command.Points == 0 {
-		return nil, errors.New("points earned must be greater than zero")
-	}
-
 	aggregate, err := c.loadUserAggregate(ctx, command.AggregateID())
 	if err != nil {
 		return nil, err
@@ -256,6 +351,131 @@ func (c *handler) handleEarnPoints(
 	)
 	errSetPayload := applier.SetSerializedPayload(user.PointsEarnedPayload{
 		PointsEarned: command.Points,
+		RuleAction:   string(command.RuleAction),
+		RuleVersion:  command.RuleVersion,
+		AwardRuleIDs: command.AwardRuleIDs,
+	})
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	events := []eventsource.Event{applier.EventModel()}
+	errSave := c.persist(ctx, events)
+	if errSave != nil {
+		return nil, errSave
+	}
+
+	return events, nil
+}
+
+// handleExpireReferral expires the referral unless it was completed or
+// expired already, in which case no event is emitted
+func (c *handler) handleExpireReferral(
+	ctx context.Context,
+	command *loyalty.ExpireReferral,
+) ([]eventsource.Event, error) {
+	aggregate, err := c.loadUserAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+
+	// Expiry timeouts may fire after the referral was closed otherwise
+	referral := aggregate.Referral(command.ReferralID)
+	if referral != nil && !referral.Status.Open() {
+		return []eventsource.Event{}, nil
+	}
+
+	return c.transitionReferral(
+		ctx,
+		aggregate,
+		command.ReferralID,
+		user.ReferralStatusExpired,
+		user.NewReferralExpiredApplier,
+		user.UserReferralExpiredEventType,
+		user.ReferralExpiredPayload{ReferralID: command.ReferralID},
+	)
+}
+
+func (c *handler) handleMarkReferralSent(
+	ctx context.Context,
+	command *loyalty.MarkReferralSent,
+) ([]eventsource.Event, error) {
+	aggregate, err := c.loadUserAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.transitionReferral(
+		ctx,
+		aggregate,
+		command.ReferralID,
+		user.ReferralStatusSent,
+		user.NewReferralSentApplier,
+		user.UserReferralSentEventType,
+		user.ReferralSentPayload{ReferralID: command.ReferralID},
+	)
+}
+
+func (c *handler) handleCancelReferral(
+	ctx context.Context,
+	command *loyalty.CancelReferral,
+) ([]eventsource.Event, error) {
+	aggregate, err := c.loadUserAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+
+	// Completed referrals are cancelled by compensating sign up sagas only
+	referral := aggregate.Referral(command.ReferralID)
+	if referral != nil && referral.Status == user.ReferralStatusCompleted &&
+		eventsource.CommandSourceFromContext(ctx) != eventsource.CommandSourceSaga {
+		return nil, errors.Wrapf(
+			user.ErrIllegalReferralTransition,
+			"completed referral %v can only be cancelled by its sign up saga",
+			command.ReferralID,
+		)
+	}
+
+	return c.transitionReferral(
+		ctx,
+		aggregate,
+		command.ReferralID,
+		user.ReferralStatusCancelled,
+		user.NewReferralCancelledApplier,
+		user.UserReferralCancelledEventType,
+		user.ReferralCancelledPayload{
+			ReferralID: command.ReferralID,
+			Reason:     command.Reason,
+		},
+	)
+}
+
+func (c *handler) handleRedeemPoints(
+	ctx context.Context,
+	command *loyalty.RedeemPoints,
+) ([]eventsource.Event, error) {
+	aggregate, err := c.loadUserAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+	if command.Points > aggregate.Points {
+		return nil, errors.Errorf(
+			"cannot redeem %v points, user %v has %v",
+			command.Points,
+			command.AggregateID(),
+			aggregate.Points,
+		)
+	}
+
+	applier := user.NewPointsRedeemedApplier(
+		command.AggregateID(),
+		user.PointsRedeemedEventType,
+		aggregate.Version+1,
+	)
+	errSetPayload := applier.SetSerializedPayload(user.PointsRedeemedPayload{
+		PointsRedeemed: command.Points,
+		Balance:        aggregate.Points - command.Points,
+		Reason:         command.Reason,
 	})
 	if errSetPayload != nil {
 		return nil, errSetPayload
@@ -270,6 +490,152 @@ func (c *handler) handleEarnPoints(
 	return events, nil
 }
 
+func (c *handler) handleRevokePoints(
+	ctx context.Context,
+	command *loyalty.RevokePoints,
+) ([]eventsource.Event, error) {
+	aggregate, err := c.loadUserAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+	if command.Points > aggregate.Points {
+		return nil, errors.Errorf(
+			"cannot revoke %v points, user %v has %v",
+			command.Points,
+			command.AggregateID(),
+			aggregate.Points,
+		)
+	}
+
+	applier := user.NewPointsRevokedApplier(
+		command.AggregateID(),
+		user.PointsRevokedEventType,
+		aggregate.Version+1,
+	)
+	errSetPayload := applier.SetSerializedPayload(user.PointsRevokedPayload{
+		PointsRevoked: command.Points,
+		Reason:        command.Reason,
+	})
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	events := []eventsource.Event{applier.EventModel()}
+	errSave := c.persist(ctx, events)
+	if errSave != nil {
+		return nil, errSave
+	}
+
+	return events, nil
+}
+
+func (c *handler) handleUpdateProfile(
+	ctx context.Context,
+	command *loyalty.UpdateProfile,
+) ([]eventsource.Event, error) {
+	aggregate, err := c.loadUserAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+
+	payload := user.ProfileUpdatedPayload{
+		Name:     command.Name,
+		Birthday: command.Birthday,
+		Phone:    command.Phone,
+	}
+	profile := aggregate.Profile.Merge(payload)
+	// Updates that change nothing are accepted without an event
+	if profile == aggregate.Profile {
+		return []eventsource.Event{}, nil
+	}
+	payload.ProfileCompleted = aggregate.ProfileCompletedAt == nil &&
+		profile.Complete()
+
+	applier := user.NewProfileUpdatedApplier(
+		command.AggregateID(),
+		user.ProfileUpdatedEventType,
+		aggregate.Version+1,
+	)
+	errSetPayload := applier.SetSerializedPayload(payload)
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	events := []eventsource.Event{applier.EventModel()}
+	errSave := c.persist(ctx, events)
+	if errSave != nil {
+		return nil, errSave
+	}
+
+	return events, nil
+}
+
+// transitionReferral persists the event moving the referral with
+// referralID to status, unless the referral state machine rejects it
+func (c *handler) transitionReferral(
+	ctx context.Context,
+	aggregate *user.User,
+	referralID string,
+	status user.ReferralStatus,
+	newApplier func(id, eventType string, version int) eventsource.Applier,
+	eventType string,
+	payload interface{},
+) ([]eventsource.Event, error) {
+	_, errTransition := aggregate.TransitionReferral(referralID, status)
+	if errTransition != nil {
+		return nil, errTransition
+	}
+
+	applier := newApplier(aggregate.ID, eventType, aggregate.Version+1)
+	errSetPayload := applier.SetSerializedPayload(payload)
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	events := []eventsource.Event{applier.EventModel()}
+	errSave := c.persist(ctx, events)
+	if errSave != nil {
+		return nil, errSave
+	}
+
+	return events, nil
+}
+
+// flagReferral persists the event recording the referral rejected by the
+// referral policy for reason. Flags are not errors so that the event is
+// published for review
+func (c *handler) flagReferral(
+	ctx context.Context,
+	aggregate *user.User,
+	reason user.ReferralFlagReason,
+	payload user.ReferralFlaggedPayload,
+) ([]eventsource.Event, error) {
+	payload.Reason = reason
+	applier := user.NewReferralFlaggedApplier(
+		aggregate.ID,
+		user.UserReferralFlaggedEventType,
+		aggregate.Version+1,
+	)
+	errSetPayload := applier.SetSerializedPayload(payload)
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	events := []eventsource.Event{applier.EventModel()}
+	errSave := c.persist(ctx, events)
+	if errSave != nil {
+		return nil, errSave
+	}
+
+	c.logger.Warn(
+		"referral flagged",
+		zap.String("userId", aggregate.ID),
+		zap.String("reason", string(reason)),
+		zap.String("command", payload.Command),
+	)
+	return events, nil
+}
+
 func (c *handler) loadUserAggregate(
 	ctx context.Context,
 	aggregateID string,
@@ -314,38 +680,21 @@ func (c *handler) persist(
 
 /* ----- helpers ----- */
 
-/*
-	Sometimes the user can use a referral code to sign up even if the referring user hasn't formally invited them. In this case we create a new referral
-	with a completed status
-*/
-func getCompleteReferralPayload(
-	referrals []user.Referral,
-	referredUserEmail string,
-	referralCode string,
-) interface{} {
-	if referral := findReferral(referrals, referredUserEmail); referral != nil {
-		return user.ReferralCompletedPayload{
-			ReferralID: referral.ID,
-		}
-	}
-	return user.ReferralCreatedPayload{
-		ReferredUserEmail: referredUserEmail,
-		ReferralCode:      referralCode,
-		ReferralStatus:    string(user.ReferralStatusCompleted),
-		ReferralID:        eventsource.NewUUID(),
-	}
-}
-
 func generateReferralCode() (string, error) {
 	return shortid.Generate()
 }
 
+// findReferral returns the latest open referral of referredUserEmail or,
+// when none is open, the latest closed one
 func findReferral(
 	referrals []user.Referral,
 	referredUserEmail string,
 ) (referral *user.Referral) {
 	for _, v := range referrals {
-		if v.ReferredUserEmail == referredUserEmail {
+		if v.ReferredUserEmail != referredUserEmail {
+			continue
+		}
+		if referral == nil || v.Status.Open() || !referral.Status.Open() {
 			r := v
 			referral = &r
 		}
@@ -0,0 +1,573 @@
+package command
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource/eventsourcetest"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestHandler_UpdateProfileCompletesOnce(t *testing.T) {
+	assert := assert.New(t)
+	f := newHandlerFixture(t)
+	f.handle(t, &loyalty.CreateUser{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Username:     "ada",
+		Email:        "ada@example.com",
+	})
+
+	// Partial profiles are not complete
+	partial := f.updateProfile(t, &loyalty.UpdateProfile{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Name:         str("Ada Lovelace"),
+		Birthday:     str("1815-12-10"),
+	})
+	assert.False(partial.ProfileCompleted)
+
+	completed := f.updateProfile(t, &loyalty.UpdateProfile{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Phone:        str("+44 20 7946 0958"),
+	})
+	assert.True(completed.ProfileCompleted)
+
+	aggregate := f.user(t, "user-1")
+	assert.Equal(user.Profile{
+		Name:     "Ada Lovelace",
+		Birthday: "1815-12-10",
+		Phone:    "+44 20 7946 0958",
+	}, aggregate.Profile)
+	assert.NotNil(aggregate.ProfileCompletedAt)
+
+	// Completing the profile again after clearing a field does not count
+	cleared := f.updateProfile(t, &loyalty.UpdateProfile{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Phone:        str(""),
+	})
+	assert.False(cleared.ProfileCompleted)
+
+	recompleted := f.updateProfile(t, &loyalty.UpdateProfile{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Phone:        str("+44 20 7946 0000"),
+	})
+	assert.False(recompleted.ProfileCompleted)
+}
+
+func TestHandler_UpdateProfileWithoutChanges(t *testing.T) {
+	f := newHandlerFixture(t)
+	f.handle(t, &loyalty.CreateUser{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Username:     "ada",
+		Email:        "ada@example.com",
+	})
+	f.updateProfile(t, &loyalty.UpdateProfile{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Name:         str("Ada Lovelace"),
+	})
+
+	result := f.handle(t, &loyalty.UpdateProfile{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Name:         str("Ada Lovelace"),
+	})
+	assert.Empty(t, result.EventTypes)
+}
+
+func TestHandler_ReferralLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newHandlerFixture(t)
+	f.handle(t, &loyalty.CreateUser{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Username:     "ada",
+		Email:        "ada@example.com",
+	})
+	referralCode := *f.user(t, "user-1").ReferralCode
+
+	invite := func(email string) string {
+		f.handle(t, &loyalty.CreateReferral{
+			CommandModel:      eventsource.CommandModel{ID: "user-1"},
+			ReferredUserEmail: email,
+		})
+		referrals := f.user(t, "user-1").Referrals
+		return referrals[len(referrals)-1].ID
+	}
+	status := func(referralID string) user.ReferralStatus {
+		return f.user(t, "user-1").Referral(referralID).Status
+	}
+
+	// Created -> Sent -> Completed
+	sent := invite("grace@example.com")
+	result := f.handle(t, &loyalty.MarkReferralSent{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		ReferralID:   sent,
+	})
+	assert.Equal([]string{user.UserReferralSentEventType}, result.EventTypes)
+	assert.Equal(user.ReferralStatusSent, status(sent))
+
+	f.handle(t, &loyalty.CompleteReferral{
+		CommandModel:      eventsource.CommandModel{ID: "user-1"},
+		ReferredByCode:    referralCode,
+		ReferredUserEmail: "grace@example.com",
+		ReferredUserID:    "user-2",
+	})
+	assert.Equal(user.ReferralStatusCompleted, status(sent))
+
+	// Completed referrals cannot be cancelled by users
+	_, err := f.handler.Handle(ctx, &loyalty.CancelReferral{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		ReferralID:   sent,
+	})
+	assert.Equal(user.ErrIllegalReferralTransition, errors.Cause(err))
+
+	// Created -> Cancelled, after which the invite cannot be sent
+	cancelled := invite("alan@example.com")
+	f.handle(t, &loyalty.CancelReferral{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		ReferralID:   cancelled,
+		Reason:       "sent to the wrong address",
+	})
+	assert.Equal(user.ReferralStatusCancelled, status(cancelled))
+
+	_, errSent := f.handler.Handle(ctx, &loyalty.MarkReferralSent{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		ReferralID:   cancelled,
+	})
+	assert.Equal(user.ErrIllegalReferralTransition, errors.Cause(errSent))
+
+	// Late expiries of closed referrals are ignored
+	expired := f.handle(t, &loyalty.ExpireReferral{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		ReferralID:   cancelled,
+	})
+	assert.Empty(expired.EventTypes)
+}
+
+func TestHandler_CompleteReferralWithoutOpenInvite(t *testing.T) {
+	assert := assert.New(t)
+	f := newHandlerFixture(t)
+	f.handle(t, &loyalty.CreateUser{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Username:     "ada",
+		Email:        "ada@example.com",
+	})
+	referralCode := *f.user(t, "user-1").ReferralCode
+
+	complete := &loyalty.CompleteReferral{
+		CommandModel:      eventsource.CommandModel{ID: "user-1"},
+		ReferredByCode:    referralCode,
+		ReferredUserEmail: "grace@example.com",
+		ReferredUserID:    "user-2",
+	}
+	result := f.handle(t, complete)
+	assert.Equal([]string{user.UserReferralCreatedEventType}, result.EventTypes)
+
+	referrals := f.user(t, "user-1").Referrals
+	assert.Len(referrals, 1)
+	assert.Equal(user.ReferralStatusCompleted, referrals[0].Status)
+
+	// Completing it again changes nothing
+	again := f.handle(t, complete)
+	assert.Empty(again.EventTypes)
+}
+
+func TestHandler_CompleteClosedReferralIsRejected(t *testing.T) {
+	closers := map[string]func(referralID string) eventsource.Command{
+		"expired": func(referralID string) eventsource.Command {
+			return &loyalty.ExpireReferral{
+				CommandModel: eventsource.CommandModel{ID: "user-1"},
+				ReferralID:   referralID,
+			}
+		},
+		"cancelled": func(referralID string) eventsource.Command {
+			return &loyalty.CancelReferral{
+				CommandModel: eventsource.CommandModel{ID: "user-1"},
+				ReferralID:   referralID,
+			}
+		},
+	}
+
+	for name, closer := range closers {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			f := newHandlerFixture(t)
+			f.handle(t, &loyalty.CreateUser{
+				CommandModel: eventsource.CommandModel{ID: "user-1"},
+				Username:     "ada",
+				Email:        "ada@example.com",
+			})
+			f.handle(t, &loyalty.CreateReferral{
+				CommandModel:      eventsource.CommandModel{ID: "user-1"},
+				ReferredUserEmail: "grace@example.com",
+			})
+			aggregate := f.user(t, "user-1")
+			f.handle(t, closer(aggregate.Referrals[0].ID))
+
+			_, err := f.handler.Handle(context.Background(), &loyalty.CompleteReferral{
+				CommandModel:      eventsource.CommandModel{ID: "user-1"},
+				ReferredByCode:    *aggregate.ReferralCode,
+				ReferredUserEmail: "grace@example.com",
+				ReferredUserID:    "user-2",
+			})
+			validationErr, ok := eventsource.AsValidationError(err)
+			if assert.True(ok, "%v", err) {
+				assert.Equal(user.RuleReferralOpen, validationErr.Fields[0].Rule)
+			}
+
+			// No completed referral was created in its place
+			referrals := f.user(t, "user-1").Referrals
+			assert.Len(referrals, 1)
+			assert.NotEqual(user.ReferralStatusCompleted, referrals[0].Status)
+		})
+	}
+}
+
+func TestHandler_CancelCompletedReferralBySagaOnly(t *testing.T) {
+	assert := assert.New(t)
+	f := newHandlerFixture(t)
+	f.handle(t, &loyalty.CreateUser{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Username:     "ada",
+		Email:        "ada@example.com",
+	})
+	f.handle(t, &loyalty.CompleteReferral{
+		CommandModel:      eventsource.CommandModel{ID: "user-1"},
+		ReferredByCode:    *f.user(t, "user-1").ReferralCode,
+		ReferredUserEmail: "grace@example.com",
+		ReferredUserID:    "user-2",
+	})
+	cancel := &loyalty.CancelReferral{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		ReferralID:   f.user(t, "user-1").Referrals[0].ID,
+	}
+
+	_, err := f.handler.Handle(context.Background(), cancel)
+	assert.Equal(user.ErrIllegalReferralTransition, errors.Cause(err))
+
+	// Compensating the sign up saga cancels it
+	ctx := eventsource.WithCommandSource(
+		context.Background(),
+		eventsource.CommandSourceSaga,
+	)
+	result, errSaga := f.handler.Handle(ctx, cancel)
+	assert.Nil(errSaga)
+	assert.Equal([]string{user.UserReferralCancelledEventType}, result.EventTypes)
+	assert.Equal(
+		user.ReferralStatusCancelled,
+		f.user(t, "user-1").Referrals[0].Status,
+	)
+}
+
+func TestHandler_ReferralPolicyFlagsRejectedReferrals(t *testing.T) {
+	assert := assert.New(t)
+	f := newHandlerFixtureWithPolicy(t, user.ReferralPolicy{
+		LifetimeLimit:  2,
+		BlockedDomains: []string{"mailinator.com"},
+	})
+	f.handle(t, &loyalty.CreateUser{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Username:     "ada",
+		Email:        "ada@example.com",
+	})
+	invite := func(email string) *eventsource.CommandResult {
+		return f.handle(t, &loyalty.CreateReferral{
+			CommandModel:      eventsource.CommandModel{ID: "user-1"},
+			ReferredUserEmail: email,
+		})
+	}
+
+	invite("grace@example.com")
+	rejected := []string{
+		"ada+alias@example.com",
+		"bot@mailinator.com",
+		"grace@example.com",
+	}
+	for _, v := range rejected {
+		result := invite(v)
+		assert.Equal([]string{user.UserReferralFlaggedEventType}, result.EventTypes, v)
+	}
+	invite("alan@example.com")
+	capped := invite("linus@example.com")
+	assert.Equal([]string{user.UserReferralFlaggedEventType}, capped.EventTypes)
+
+	aggregate := f.user(t, "user-1")
+	assert.Len(aggregate.Referrals, 2)
+
+	reasons := []user.ReferralFlagReason{}
+	for _, v := range aggregate.ReferralFlags {
+		reasons = append(reasons, v.Reason)
+	}
+	assert.Equal([]user.ReferralFlagReason{
+		user.ReferralFlagSelfReferral,
+		user.ReferralFlagBlockedDomain,
+		user.ReferralFlagDuplicateEmail,
+		user.ReferralFlagLifetimeLimitReached,
+	}, reasons)
+}
+
+func TestHandler_CompleteReferralBySecondAccountIsFlagged(t *testing.T) {
+	assert := assert.New(t)
+	f := newHandlerFixture(t)
+	f.handle(t, &loyalty.CreateUser{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Username:     "ada",
+		Email:        "ada@example.com",
+	})
+	referralCode := *f.user(t, "user-1").ReferralCode
+
+	complete := func(referredUserID string) *eventsource.CommandResult {
+		return f.handle(t, &loyalty.CompleteReferral{
+			CommandModel:      eventsource.CommandModel{ID: "user-1"},
+			ReferredByCode:    referralCode,
+			ReferredUserEmail: "grace@example.com",
+			ReferredUserID:    referredUserID,
+		})
+	}
+	complete("user-2")
+
+	// The same account completing again is a retry
+	assert.Empty(complete("user-2").EventTypes)
+
+	// Another account signing up with the same email is flagged
+	assert.Equal(
+		[]string{user.UserReferralFlaggedEventType},
+		complete("user-3").EventTypes,
+	)
+	flags := f.user(t, "user-1").ReferralFlags
+	if assert.Len(flags, 1) {
+		assert.Equal(user.ReferralFlagDuplicateEmail, flags[0].Reason)
+		assert.Equal("user-3", flags[0].ReferredUserID)
+	}
+}
+
+func TestHandler_AggregateGuards(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newHandlerFixture(t)
+	create := &loyalty.CreateUser{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Username:     "ada",
+		Email:        "ada@example.com",
+	}
+	f.handle(t, create)
+
+	// Users are created once
+	_, errExisting := f.handler.Handle(ctx, create)
+	assert.EqualError(errExisting, "user user-1 already exists")
+
+	// Commands are validated even when they skipped the dispatcher
+	_, errPoints := f.handler.Handle(ctx, &loyalty.EarnPoints{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+	})
+	if validation, ok := eventsource.AsValidationError(errPoints); assert.True(ok) {
+		assert.Equal("points", validation.Fields[0].Field)
+		assert.Equal(eventsource.RuleMin, validation.Fields[0].Rule)
+	}
+	_, errReferral := f.handler.Handle(ctx, &loyalty.CreateReferral{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+	})
+	if validation, ok := eventsource.AsValidationError(errReferral); assert.True(ok) {
+		assert.Equal("referredUserEmail", validation.Fields[0].Field)
+		assert.Equal(eventsource.RuleRequired, validation.Fields[0].Rule)
+	}
+
+	// Deleted users reject commands
+	f.handle(t, &loyalty.DeleteUser{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+	})
+	_, errDeleted := f.handler.Handle(ctx, &loyalty.EarnPoints{
+		CommandModel: eventsource.CommandModel{ID: "user-1"},
+		Points:       10,
+	})
+	assert.EqualError(errDeleted, "user deleted")
+}
+
+func TestHandler_RedeemPoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		points      uint32
+		wantBalance uint32
+		wantErr     bool
+	}{
+		{name: "insufficient balance", points: 101, wantBalance: 100, wantErr: true},
+		{name: "exact balance", points: 100, wantBalance: 0},
+		{name: "partial balance", points: 40, wantBalance: 60},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			assert := assert.New(t)
+			f := newHandlerFixture(t)
+			f.createUserWithPoints(t, "user-1", 100)
+
+			_, err := f.dispatcher.Dispatch(context.Background(), &loyalty.RedeemPoints{
+				CommandModel: eventsource.CommandModel{ID: "user-1"},
+				Points:       v.points,
+			})
+			assert.Equal(v.wantErr, err != nil)
+			assert.Equal(v.wantBalance, f.user(t, "user-1").Points)
+		})
+	}
+}
+
+func TestHandler_RedeemPointsIdempotentRetry(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newHandlerFixture(t)
+	f.createUserWithPoints(t, "user-1", 100)
+
+	redeem := &loyalty.RedeemPoints{
+		CommandModel: eventsource.CommandModel{ID: "user-1", CommandID: "redeem-1"},
+		Points:       60,
+	}
+	first, err := f.dispatcher.Dispatch(ctx, redeem)
+	assert.Nil(err)
+
+	// The retry returns the first result instead of failing on the balance
+	retried, errRetried := f.dispatcher.Dispatch(ctx, redeem)
+	assert.Nil(errRetried)
+	assert.Equal(first.Version, retried.Version)
+	assert.Equal(uint32(40), f.user(t, "user-1").Points)
+}
+
+func TestHandler_ConcurrentRedeemPointsCannotOverspend(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newHandlerFixture(t)
+	f.createUserWithPoints(t, "user-1", 100)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.dispatcher.Dispatch(ctx, &loyalty.RedeemPoints{
+				CommandModel: eventsource.CommandModel{ID: "user-1"},
+				Points:       100,
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	assert.Equal(1, failed)
+	assert.Equal(uint32(0), f.user(t, "user-1").Points)
+}
+
+/* ----- helpers ----- */
+type handlerFixture struct {
+	handler    eventsource.CommandHandler
+	dispatcher eventsource.CommandDispatcher
+	repo       eventsource.EventRepo
+	store      *eventsourcetest.MemoryStore
+}
+
+func newHandlerFixture(t *testing.T) *handlerFixture {
+	return newHandlerFixtureWithPolicy(t, user.ReferralPolicy{})
+}
+
+func newHandlerFixtureWithPolicy(
+	t *testing.T,
+	policy user.ReferralPolicy,
+) *handlerFixture {
+	logger := zaptest.NewLogger(t)
+	store := eventsourcetest.NewMemoryStore()
+	repo := loyalty.NewRepository(loyalty.RepositoryParams{
+		Store:        store,
+		Logger:       logger,
+		NewAggregate: user.NewUser,
+	})
+
+	handler := NewUserCommandHandler(CommandHandlerParams{
+		EventBus:       &eventsourcetest.NopEventBus{},
+		Repo:           repo,
+		Logger:         logger,
+		ReferralPolicy: policy,
+	})
+	dispatcher := eventsource.NewDispatcher(logger)
+	dispatcher.Use(
+		eventsource.ConflictRetryMiddleware(3),
+		eventsource.IdempotencyMiddleware(store),
+	)
+	errRegister := dispatcher.RegisterHandler(handler)
+	if errRegister != nil {
+		t.Fatal(errRegister)
+	}
+
+	return &handlerFixture{
+		handler:    handler,
+		dispatcher: dispatcher,
+		repo:       repo,
+		store:      store,
+	}
+}
+
+func (f *handlerFixture) createUserWithPoints(
+	t *testing.T,
+	userID string,
+	points uint32,
+) {
+	f.handle(t, &loyalty.CreateUser{
+		CommandModel: eventsource.CommandModel{ID: userID},
+		Username:     userID,
+		Email:        userID + "@example.com",
+	})
+	f.handle(t, &loyalty.EarnPoints{
+		CommandModel: eventsource.CommandModel{ID: userID},
+		Points:       points,
+	})
+}
+
+func (f *handlerFixture) handle(
+	t *testing.T,
+	cmd eventsource.Command,
+) *eventsource.CommandResult {
+	result, err := f.handler.Handle(context.Background(), cmd)
+	assert.Nil(t, err)
+	return result
+}
+
+// updateProfile handles cmd and returns the payload of its event
+func (f *handlerFixture) updateProfile(
+	t *testing.T,
+	cmd *loyalty.UpdateProfile,
+) *user.ProfileUpdatedPayload {
+	result := f.handle(t, cmd)
+	assert.Equal(t, []string{user.ProfileUpdatedEventType}, result.EventTypes)
+
+	history, err := f.store.Load(context.Background(), cmd.AggregateID(), 0)
+	assert.Nil(t, err)
+	updated := user.ProfileUpdated{
+		ApplierModel: *eventsource.NewApplierModel(history[len(history)-1]),
+	}
+	payload, errPayload := updated.GetDeserializedPayload()
+	assert.Nil(t, errPayload)
+	return payload
+}
+
+func (f *handlerFixture) user(t *testing.T, userID string) *user.User {
+	aggregate, err := f.repo.Load(context.Background(), userID, 0)
+	assert.Nil(t, err)
+
+	u, errUser := user.AssertUserAggregate(aggregate)
+	assert.Nil(t, errUser)
+	return u
+}
+
+func str(s string) *string {
+	return &s
+}
@@ -1,7 +1,10 @@
 package user
 
 import (
+	"context"
+
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
 )
 
 // Created event is fired when a new user is created
@@ -76,3 +79,27 @@ func (applier *Created) GetDeserializedPayload() (*CreatedPayload, error) {
 
 	return &payload, nil
 }
+
+// LoadCreated returns the payload of the UserCreated event of the user,
+// e.g. to report the user a deduplicated CreateUser created
+func LoadCreated(
+	ctx context.Context,
+	store EventStore,
+	userID string,
+) (*CreatedPayload, error) {
+	history, err := store.Load(ctx, userID, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load history of user %v", userID)
+	}
+
+	for _, v := range history {
+		if v.EventType != UserCreatedEventType {
+			continue
+		}
+		applier := Created{
+			ApplierModel: *eventsource.NewApplierModel(v),
+		}
+		return applier.GetDeserializedPayload()
+	}
+	return nil, errors.Errorf("user %v has no UserCreated event", userID)
+}
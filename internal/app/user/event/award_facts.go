@@ -0,0 +1,64 @@
+package event
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/pkg/errors"
+)
+
+// awardFacts returns the facts the award rules are evaluated against when
+// userID earns points because of event. The user facts are evaluated from
+// the aggregate so that they do not depend on the read model having
+// projected the user
+func (s *userSaga) awardFacts(
+	ctx context.Context,
+	userID string,
+	event eventsource.Event,
+) (loyalty.Facts, error) {
+	facts := loyalty.Facts{
+		loyalty.FactNow: event.EventAt,
+	}
+
+	history, err := s.store.Load(ctx, userID, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load history of user %v", userID)
+	}
+	if len(history) == 0 {
+		return facts, nil
+	}
+
+	aggregate := user.NewUser(userID)
+	errApply := aggregate.Apply(history)
+	if errApply != nil {
+		return nil, errors.Wrapf(errApply, "unable to apply history of user %v", userID)
+	}
+	u, errAssert := user.AssertUserAggregate(aggregate)
+	if errAssert != nil {
+		return nil, errAssert
+	}
+
+	facts[loyalty.FactUserCreatedAt] = u.CreatedAt
+	facts[loyalty.FactUserPoints] = u.Points
+	if at := strings.LastIndex(u.Email, "@"); at != -1 {
+		facts[loyalty.FactUserEmailDomain] = u.Email[at+1:]
+	}
+
+	completed, completedThisYear := 0, 0
+	for _, v := range u.Referrals {
+		if v.Status != user.ReferralStatusCompleted {
+			continue
+		}
+		completed++
+		if v.UpdatedAt.Year() == event.EventAt.Year() {
+			completedThisYear++
+		}
+	}
+	facts[loyalty.FactUserReferralsCompleted] = completed
+	facts[loyalty.FactUserReferralsCompletedThisYear] = completedThisYear
+
+	return facts, nil
+}
@@ -2,6 +2,7 @@ package event
 
 import (
 	"context"
+	"time"
 
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
@@ -12,91 +13,63 @@ import (
 )
 
 type userEventHandler struct {
-	readRepo          user.ReadRepo
-	logger            *zap.Logger
-	sLogger           *zap.SugaredLogger
-	eventStore        eventsource.EventStore
-	isReadModelSynced bool
+	name     string
+	readRepo user.ReadRepo
+	logger   *zap.Logger
+	sLogger  *zap.SugaredLogger
 }
 
-// NewEventHandler creates an instance of EventHandler
+// ProjectionName identifies the checkpoint of the user read model projection
+var ProjectionName = "users"
+
+// NewEventHandler creates an instance of the user read model Projection
 func NewEventHandler(
 	logger *zap.Logger,
 	readRepo user.ReadRepo,
-	eventStore user.EventStore,
-) eventsource.EventHandler {
+) eventsource.Projection {
+	return newEventHandler(ProjectionName, logger, readRepo)
+}
+
+func newEventHandler(
+	name string,
+	logger *zap.Logger,
+	readRepo user.ReadRepo,
+) *userEventHandler {
 	return &userEventHandler{
-		readRepo:   readRepo,
-		eventStore: eventStore,
-		logger:     logger,
-		sLogger:    logger.Sugar(),
+		name:     name,
+		readRepo: readRepo,
+		logger:   logger,
+		sLogger:  logger.Sugar(),
 	}
 }
 
-// EventTypesHandled implements the EventHandler interface
+// Name implements the Projection interface
+func (h *userEventHandler) Name() string {
+	return h.name
+}
+
+// EventTypesHandled implements the Projection interface
 func (h *userEventHandler) EventTypesHandled() []string {
 	return []string{
 		user.UserCreatedEventType,
 		user.UserDeletedEventType,
 		user.UserReferralCreatedEventType,
 		user.UserReferralCompletedEventType,
+		user.UserReferralExpiredEventType,
+		user.UserReferralSentEventType,
+		user.UserReferralCancelledEventType,
 		user.PointsEarnedEventType,
+		user.PointsRevokedEventType,
+		user.PointsRedeemedEventType,
+		user.ProfileUpdatedEventType,
 	}
 }
 
-// Sync implements the EventHandler interface
-func (h *userEventHandler) Sync(
-	ctx context.Context,
-	aggregateID string,
-) error {
-	h.logger.Info(
-		"syncing read model",
-		zap.String("aggregateId", aggregateID),
-	)
-
-	aggregate, errAggregate := h.loadAggregate(ctx, aggregateID)
-	if errAggregate != nil {
-		return errAggregate
-	}
-
-	if aggregate == nil {
-		return nil
-	}
-
-	history, errHistory := h.eventStore.Load(
-		ctx,
-		aggregateID,
-		aggregate.Version,
-	)
-	if errHistory != nil {
-		return errors.Wrap(errHistory, "unable to load aggregate history")
-	}
-
-	for _, v := range history {
-		errHandle := h.handleEvent(ctx, v)
-		if errHandle != nil {
-			return errHandle
-		}
-	}
-
-	h.isReadModelSynced = true
-	return nil
-}
-
-// Handle implements the EventHandler interface
-func (h *userEventHandler) Handle(
+// Project implements the Projection interface
+func (h *userEventHandler) Project(
 	ctx context.Context,
 	event eventsource.Event,
 ) error {
-	var errSync error
-	if !h.isReadModelSynced {
-		errSync = h.Sync(ctx, event.AggregateID)
-		if errSync != nil {
-			return errors.Wrap(errSync, "unable to sync read model with event store")
-		}
-		return nil
-	}
-
 	return h.handleEvent(ctx, event)
 }
 
@@ -134,11 +107,23 @@ func (h *userEventHandler) handleEvent(
 	case user.PointsEarnedEventType:
 		return handlePointsEarned(ctx, event, h.readRepo, aggregate)
 
+	case user.PointsRevokedEventType:
+		return handlePointsRevoked(ctx, event, h.readRepo, aggregate)
+
+	case user.PointsRedeemedEventType:
+		return handlePointsRedeemed(ctx, event, h.readRepo, aggregate)
+
+	case user.ProfileUpdatedEventType:
+		return handleProfileUpdated(ctx, event, h.readRepo, aggregate)
+
 	case user.UserCreatedEventType:
 		return handleUserCreated(ctx, event, h.readRepo)
 
-	case user.UserReferralCompletedEventType:
-		return handleUserReferralCompleted(ctx, event, h.readRepo, aggregate)
+	case user.UserReferralSentEventType,
+		user.UserReferralCompletedEventType,
+		user.UserReferralExpiredEventType,
+		user.UserReferralCancelledEventType:
+		return handleUserReferralTransition(ctx, event, h.readRepo, aggregate)
 
 	case user.UserReferralCreatedEventType:
 		return handleUserReferralCreated(ctx, event, h.readRepo, aggregate)
@@ -174,7 +159,97 @@ func handlePointsEarned(
 		ctx,
 		event.AggregateID,
 		payload.PointsEarned,
-		aggregate.Version+1,
+		event.Version,
+	)
+}
+
+func handlePointsRevoked(
+	ctx context.Context,
+	event eventsource.Event,
+	readRepo user.ReadRepo,
+	aggregate *user.DTO,
+) error {
+	var operation eventsource.Operation = "user.handlePointsRevoked"
+	if aggregate == nil {
+		return eventsource.AggregateNotFoundErr(operation, event.AggregateID)
+	}
+
+	pointsRevokedEvent := user.PointsRevoked{
+		ApplierModel: *eventsource.NewApplierModel(event),
+	}
+
+	payload, errPayload := pointsRevokedEvent.GetDeserializedPayload()
+	if errPayload != nil {
+		return errPayload
+	}
+
+	return readRepo.RevokePoints(
+		ctx,
+		event.AggregateID,
+		payload.PointsRevoked,
+		event.Version,
+	)
+}
+
+func handlePointsRedeemed(
+	ctx context.Context,
+	event eventsource.Event,
+	readRepo user.ReadRepo,
+	aggregate *user.DTO,
+) error {
+	var operation eventsource.Operation = "user.handlePointsRedeemed"
+	if aggregate == nil {
+		return eventsource.AggregateNotFoundErr(operation, event.AggregateID)
+	}
+
+	pointsRedeemedEvent := user.PointsRedeemed{
+		ApplierModel: *eventsource.NewApplierModel(event),
+	}
+
+	payload, errPayload := pointsRedeemedEvent.GetDeserializedPayload()
+	if errPayload != nil {
+		return errPayload
+	}
+
+	return readRepo.RedeemPoints(
+		ctx,
+		event.AggregateID,
+		payload.PointsRedeemed,
+		event.Version,
+	)
+}
+
+func handleProfileUpdated(
+	ctx context.Context,
+	event eventsource.Event,
+	readRepo user.ReadRepo,
+	aggregate *user.DTO,
+) error {
+	var operation eventsource.Operation = "user.handleProfileUpdated"
+	if aggregate == nil {
+		return eventsource.AggregateNotFoundErr(operation, event.AggregateID)
+	}
+
+	profileUpdatedEvent := user.ProfileUpdated{
+		ApplierModel: *eventsource.NewApplierModel(event),
+	}
+
+	payload, errPayload := profileUpdatedEvent.GetDeserializedPayload()
+	if errPayload != nil {
+		return errPayload
+	}
+
+	var completedAt *time.Time
+	if payload.ProfileCompleted {
+		completedAt = &event.EventAt
+	}
+
+	return readRepo.UpdateProfile(
+		ctx,
+		event.AggregateID,
+		aggregate.Profile.Merge(*payload),
+		completedAt,
+		event.Version,
 	)
 }
 
@@ -210,23 +285,28 @@ func handleUserCreated(
 	)
 }
 
-func handleUserReferralCompleted(
+// referralTransitionStatuses maps the referral transition events to the
+// status they move the referral to
+var referralTransitionStatuses = map[string]user.ReferralStatus{
+	user.UserReferralSentEventType:      user.ReferralStatusSent,
+	user.UserReferralCompletedEventType: user.ReferralStatusCompleted,
+	user.UserReferralExpiredEventType:   user.ReferralStatusExpired,
+	user.UserReferralCancelledEventType: user.ReferralStatusCancelled,
+}
+
+func handleUserReferralTransition(
 	ctx context.Context,
 	event eventsource.Event,
 	readRepo user.ReadRepo,
 	aggregate *user.DTO,
 ) error {
-	var operation eventsource.Operation = "user.handleUserReferralCompleted"
+	var operation eventsource.Operation = "user.handleUserReferralTransition"
 
 	if aggregate == nil {
 		return eventsource.AggregateNotFoundErr(operation, event.AggregateID)
 	}
 
-	referralCompletedEvent := user.ReferralCompleted{
-		ApplierModel: *eventsource.NewApplierModel(event),
-	}
-
-	payload, errPayload := referralCompletedEvent.GetDeserializedPayload()
+	referralID, errPayload := user.ReferralTransitionID(event)
 	if errPayload != nil {
 		return errPayload
 	}
@@ -234,9 +314,10 @@ func handleUserReferralCompleted(
 	return readRepo.UpdateReferralStatus(
 		ctx,
 		event.AggregateID,
-		payload.ReferralID,
-		user.ReferralStatusCompleted,
-		aggregate.Version+1,
+		referralID,
+		referralTransitionStatuses[event.EventType],
+		event.EventAt,
+		event.Version,
 	)
 }
 
@@ -282,6 +363,6 @@ func handleUserReferralCreated(
 			CreatedAt:         event.EventAt,
 			UpdatedAt:         event.EventAt,
 		},
-		aggregate.Version+1,
+		event.Version,
 	)
 }
@@ -0,0 +1,284 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errRebuildInProgress    = errors.New("read model rebuild already in progress")
+	errNoPreviousCollection = errors.New("read model has no previous collection to roll back to")
+
+	shadowCollectionPrefix = "users_es_"
+	rebuildProgressEvery   = 100
+)
+
+type rebuilder struct {
+	readRepo    user.SwitchableReadRepo
+	store       eventsource.EventStore
+	checkpoints eventsource.CheckpointStore
+	projector   eventsource.Projector
+	logger      *zap.Logger
+
+	mu     sync.Mutex
+	status user.RebuildStatus
+
+	// running tracks the background rebuild
+	running sync.WaitGroup
+}
+
+// RebuilderParams represent the params needed to instantiate a new rebuilder
+type RebuilderParams struct {
+	ReadRepo    user.SwitchableReadRepo
+	Store       user.EventStore
+	Checkpoints eventsource.CheckpointStore
+	Projector   eventsource.Projector
+	Logger      *zap.Logger
+}
+
+// NewReadModelRebuilder creates an instance of ReadModelRebuilder. The
+// projector must be the live projector of the user read model
+func NewReadModelRebuilder(p RebuilderParams) user.ReadModelRebuilder {
+	return &rebuilder{
+		readRepo:    p.ReadRepo,
+		store:       p.Store,
+		checkpoints: p.Checkpoints,
+		projector:   p.Projector,
+		logger:      p.Logger,
+	}
+}
+
+// Rebuild implements the ReadModelRebuilder interface. The rebuild outlives
+// the request starting it, so it runs detached from ctx
+func (r *rebuilder) Rebuild(ctx context.Context) (*user.RebuildStatus, error) {
+	active, err := r.readRepo.ActiveCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shadow := shadowCollectionPrefix + time.Now().UTC().Format("20060102150405")
+	errStart := r.start(shadow, active)
+	if errStart != nil {
+		return nil, errStart
+	}
+
+	r.logger.Info(
+		"rebuilding read model",
+		zap.String("collection", shadow),
+		zap.String("previousCollection", active),
+	)
+
+	r.running.Add(1)
+	go func() {
+		defer r.running.Done()
+
+		errRebuild := r.rebuild(context.Background(), active, shadow)
+		if errRebuild != nil {
+			r.logger.Error(
+				"unable to rebuild read model",
+				zap.String("collection", shadow),
+				zap.Error(errRebuild),
+			)
+		}
+		r.finish(errRebuild)
+	}()
+
+	return r.currentStatus(), nil
+}
+
+// rebuild projects every event stream into the shadow collection and
+// switches the read model over to it
+func (r *rebuilder) rebuild(ctx context.Context, active, shadow string) error {
+	shadowProjector := eventsource.NewProjector(eventsource.ProjectorParams{
+		Projection: newEventHandler(
+			checkpointName(shadow),
+			r.logger,
+			r.readRepo.Shadow(shadow),
+		),
+		Store:       r.store,
+		Checkpoints: r.checkpoints,
+		Logger:      r.logger,
+		Progress:    r.updateProgress,
+	})
+	errCatchUp := shadowProjector.CatchUp(ctx)
+	if errCatchUp != nil {
+		return errors.Wrap(errCatchUp, "unable to project shadow collection")
+	}
+
+	return r.switchTo(ctx, active, shadow)
+}
+
+// Rollback implements the ReadModelRebuilder interface
+func (r *rebuilder) Rollback(ctx context.Context) (*user.RebuildStatus, error) {
+	active, err := r.readRepo.ActiveCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, errPrevious := r.readRepo.PreviousCollection(ctx)
+	if errPrevious != nil {
+		return nil, errPrevious
+	}
+	if eventsource.IsStringEmpty(&previous) || previous == active {
+		return nil, errNoPreviousCollection
+	}
+
+	errStart := r.start(previous, active)
+	if errStart != nil {
+		return nil, errStart
+	}
+
+	r.logger.Info(
+		"rolling back read model",
+		zap.String("collection", previous),
+		zap.String("previousCollection", active),
+	)
+
+	errSwitch := r.switchTo(ctx, active, previous)
+	r.finish(errSwitch)
+	if errSwitch != nil {
+		return nil, errSwitch
+	}
+
+	return r.Status(ctx)
+}
+
+// Status implements the ReadModelRebuilder interface. Other instances may
+// have switched the read model, so the collections are read from the store
+// unless this instance is rebuilding
+func (r *rebuilder) Status(ctx context.Context) (*user.RebuildStatus, error) {
+	status := r.currentStatus()
+	if status.InProgress {
+		return status, nil
+	}
+
+	active, err := r.readRepo.ActiveCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	previous, errPrevious := r.readRepo.PreviousCollection(ctx)
+	if errPrevious != nil {
+		return nil, errPrevious
+	}
+
+	status.Collection = active
+	status.PreviousCollection = previous
+	return status, nil
+}
+
+// Follow implements the ReadModelRebuilder interface. The instance that
+// switched the read model has already replaced the projector's checkpoint,
+// so only the other instances reload it
+func (r *rebuilder) Follow(ctx context.Context) error {
+	return r.readRepo.WatchCollection(
+		ctx,
+		func(ctx context.Context, collection string) {
+			r.logger.Info(
+				"read model switched by another instance",
+				zap.String("collection", collection),
+			)
+
+			errReload := r.projector.Reload(ctx)
+			if errReload != nil {
+				r.logger.Error(
+					"unable to reload projection checkpoint",
+					zap.String("collection", collection),
+					zap.Error(errReload),
+				)
+			}
+		},
+	)
+}
+
+// switchTo swaps the live projector over to the target collection. The
+// checkpoint of the outgoing collection is archived so that it can be
+// restored by a rollback, and the live projector then catches up on any
+// events that were recorded while the target was being built
+func (r *rebuilder) switchTo(ctx context.Context, active, target string) error {
+	checkpoint, err := r.checkpoints.Load(ctx, checkpointName(target))
+	if err != nil {
+		return errors.Wrap(err, "unable to load checkpoint for collection")
+	}
+
+	errReplace := r.projector.Replace(
+		ctx,
+		checkpoint,
+		func(ctx context.Context, previous *eventsource.Checkpoint) error {
+			errArchive := r.checkpoints.Replace(
+				ctx,
+				previous.Copy(checkpointName(active)),
+			)
+			if errArchive != nil {
+				return errors.Wrap(errArchive, "unable to archive checkpoint")
+			}
+			return r.readRepo.SwitchCollection(ctx, target)
+		},
+	)
+	if errReplace != nil {
+		return errReplace
+	}
+
+	return r.projector.CatchUp(ctx)
+}
+
+func (r *rebuilder) start(collection, previous string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status.InProgress {
+		return errRebuildInProgress
+	}
+
+	r.status = user.RebuildStatus{
+		Collection:         collection,
+		PreviousCollection: previous,
+		InProgress:         true,
+	}
+	return nil
+}
+
+func (r *rebuilder) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status.InProgress = false
+	if err != nil {
+		lastError := err.Error()
+		r.status.LastError = &lastError
+	}
+}
+
+func (r *rebuilder) currentStatus() *user.RebuildStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := r.status
+	return &status
+}
+
+func (r *rebuilder) updateProgress(progress eventsource.Progress) {
+	r.mu.Lock()
+	r.status.EventsProcessed = progress.Processed
+	r.status.TotalEvents = progress.Total
+	r.mu.Unlock()
+
+	if progress.Processed%rebuildProgressEvery == 0 ||
+		progress.Processed == progress.Total {
+		r.logger.Info(
+			"read model rebuild progress",
+			zap.String("projection", progress.Projection),
+			zap.Int("processed", progress.Processed),
+			zap.Int("total", progress.Total),
+		)
+	}
+}
+
+func checkpointName(collection string) string {
+	return ProjectionName + ":" + collection
+}
@@ -0,0 +1,333 @@
+package event
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource/eventsourcetest"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/* ----- tests ----- */
+func TestRebuilder_RebuildSwitchesToShadowCollection(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	f := newRebuilderFixture(t, eventsource.History{
+		newCreatedEvent(t, "user-1", nil),
+		newCreatedEvent(t, "user-2", nil),
+	})
+
+	started, err := f.rebuilder.Rebuild(ctx)
+	assert.Nil(err)
+	assert.True(started.InProgress)
+	assert.True(strings.HasPrefix(started.Collection, shadowCollectionPrefix))
+	assert.Equal(defaultCollection, started.PreviousCollection)
+
+	f.rebuilder.wait()
+	status, errStatus := f.rebuilder.Status(ctx)
+	assert.Nil(errStatus)
+	assert.False(status.InProgress)
+	assert.Nil(status.LastError)
+	assert.Equal(started.Collection, status.Collection)
+	assert.Equal(defaultCollection, status.PreviousCollection)
+	assert.Equal(2, status.EventsProcessed)
+	assert.Equal(2, status.TotalEvents)
+	assert.ElementsMatch(
+		[]string{"user-1", "user-2"},
+		f.readRepo.userIDs(started.Collection),
+	)
+}
+
+func TestRebuilder_RebuildInProgress(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	f := newRebuilderFixture(t, eventsource.History{
+		newCreatedEvent(t, "user-1", nil),
+	})
+	f.readRepo.block = make(chan struct{})
+
+	_, err := f.rebuilder.Rebuild(ctx)
+	assert.Nil(err)
+
+	_, errRebuild := f.rebuilder.Rebuild(ctx)
+	assert.Equal(errRebuildInProgress, errRebuild)
+
+	close(f.readRepo.block)
+	f.rebuilder.wait()
+	status, errStatus := f.rebuilder.Status(ctx)
+	assert.Nil(errStatus)
+	assert.False(status.InProgress)
+}
+
+func TestRebuilder_FailedRebuildKeepsActiveCollection(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	f := newRebuilderFixture(t, eventsource.History{
+		newCreatedEvent(t, "user-1", nil),
+	})
+	f.readRepo.fail = errors.New("firestore unavailable")
+
+	_, err := f.rebuilder.Rebuild(ctx)
+	assert.Nil(err)
+
+	f.rebuilder.wait()
+	status, errStatus := f.rebuilder.Status(ctx)
+	assert.Nil(errStatus)
+	assert.False(status.InProgress)
+	assert.Equal(defaultCollection, status.Collection)
+	if assert.NotNil(status.LastError) {
+		assert.Contains(*status.LastError, "firestore unavailable")
+	}
+}
+
+func TestRebuilder_Rollback(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	f := newRebuilderFixture(t, eventsource.History{
+		newCreatedEvent(t, "user-1", nil),
+	})
+
+	_, errNoPrevious := f.rebuilder.Rollback(ctx)
+	assert.Equal(errNoPreviousCollection, errNoPrevious)
+
+	started, err := f.rebuilder.Rebuild(ctx)
+	assert.Nil(err)
+	f.rebuilder.wait()
+
+	status, errRollback := f.rebuilder.Rollback(ctx)
+	assert.Nil(errRollback)
+	assert.False(status.InProgress)
+	assert.Equal(defaultCollection, status.Collection)
+	assert.Equal(started.Collection, status.PreviousCollection)
+
+	// The checkpoint of the rebuilt collection is archived, so switching
+	// back to it does not project its events again
+	checkpoint, errLoad := f.checkpoints.Load(ctx, checkpointName(started.Collection))
+	assert.Nil(errLoad)
+	assert.Equal(1, checkpoint.Version("user-1"))
+}
+
+func TestRebuilder_StatusReadsCollectionsFromStore(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	f := newRebuilderFixture(t, nil)
+
+	// Another instance switched the read model
+	assert.Nil(f.readRepo.SwitchCollection(ctx, "users_es_other"))
+
+	status, err := f.rebuilder.Status(ctx)
+	assert.Nil(err)
+	assert.Equal("users_es_other", status.Collection)
+	assert.Equal(defaultCollection, status.PreviousCollection)
+}
+
+func TestRebuilder_FollowReloadsCheckpointSwitchedElsewhere(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	f := newRebuilderFixture(t, eventsource.History{
+		newCreatedEvent(t, "user-1", nil),
+	})
+	assert.Nil(f.projector.CatchUp(ctx))
+
+	following := make(chan error)
+	go func() {
+		following <- f.rebuilder.Follow(ctx)
+	}()
+	<-f.readRepo.watching
+
+	// Another instance rebuilt the read model, projecting user-2 into the
+	// collection it switched to
+	checkpoint := eventsource.NewCheckpoint(ProjectionName)
+	checkpoint.Versions["user-1"] = 1
+	checkpoint.Versions["user-2"] = 1
+	assert.Nil(f.checkpoints.Replace(ctx, checkpoint))
+	f.readRepo.switchElsewhere(ctx, "users_es_other")
+
+	errHandle := f.projector.Handle(ctx, newCreatedEvent(t, "user-2", nil))
+	assert.Nil(errHandle)
+	assert.Empty(f.readRepo.userIDs("users_es_other"))
+
+	cancel()
+	assert.Nil(<-following)
+}
+
+/* ----- helpers ----- */
+var defaultCollection = "users_es"
+
+type rebuilderFixture struct {
+	rebuilder   *rebuilder
+	projector   eventsource.Projector
+	readRepo    *memoryReadRepo
+	checkpoints *eventsourcetest.MemoryCheckpointStore
+}
+
+func newRebuilderFixture(
+	t *testing.T,
+	history eventsource.History,
+) *rebuilderFixture {
+	logger := zaptest.NewLogger(t)
+	store := eventsourcetest.NewMemoryStore(history...)
+	checkpoints := eventsourcetest.NewMemoryCheckpointStore()
+	readRepo := newMemoryReadRepo()
+
+	projector := eventsource.NewProjector(eventsource.ProjectorParams{
+		Projection:  NewEventHandler(logger, readRepo),
+		Store:       store,
+		Checkpoints: checkpoints,
+		Logger:      logger,
+	})
+
+	return &rebuilderFixture{
+		rebuilder: NewReadModelRebuilder(RebuilderParams{
+			ReadRepo:    readRepo,
+			Store:       store,
+			Checkpoints: checkpoints,
+			Projector:   projector,
+			Logger:      logger,
+		}).(*rebuilder),
+		projector:   projector,
+		readRepo:    readRepo,
+		checkpoints: checkpoints,
+	}
+}
+
+// wait blocks until the background rebuild, if any, has finished
+func (r *rebuilder) wait() {
+	r.running.Wait()
+}
+
+// memoryReadRepo is a SwitchableReadRepo keeping the users created in each
+// collection. Only the methods used to project UserCreated are implemented
+type memoryReadRepo struct {
+	user.ReadRepo
+
+	mu       sync.Mutex
+	active   string
+	previous string
+	users    map[string][]string
+
+	// block delays the users created in shadow collections until closed
+	block chan struct{}
+
+	// fail is returned when creating users in shadow collections when set
+	fail error
+
+	// watching is closed once WatchCollection has registered onSwitch
+	watching chan struct{}
+	onSwitch func(ctx context.Context, collection string)
+}
+
+func newMemoryReadRepo() *memoryReadRepo {
+	return &memoryReadRepo{
+		active:   defaultCollection,
+		users:    make(map[string][]string),
+		watching: make(chan struct{}),
+	}
+}
+
+func (m *memoryReadRepo) CreateUser(ctx context.Context, dto user.DTO) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users[m.active] = append(m.users[m.active], dto.UserID)
+	return nil
+}
+
+func (m *memoryReadRepo) User(ctx context.Context, userID string) (*user.DTO, error) {
+	return nil, status.Error(codes.NotFound, "user not found")
+}
+
+func (m *memoryReadRepo) ActiveCollection(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active, nil
+}
+
+func (m *memoryReadRepo) PreviousCollection(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.previous, nil
+}
+
+func (m *memoryReadRepo) Shadow(collection string) user.ReadRepo {
+	return &memoryShadowRepo{repo: m, collection: collection}
+}
+
+func (m *memoryReadRepo) SwitchCollection(
+	ctx context.Context,
+	collection string,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.previous = m.active
+	m.active = collection
+	return nil
+}
+
+func (m *memoryReadRepo) WatchCollection(
+	ctx context.Context,
+	onSwitch func(ctx context.Context, collection string),
+) error {
+	m.mu.Lock()
+	m.onSwitch = onSwitch
+	m.mu.Unlock()
+	close(m.watching)
+
+	<-ctx.Done()
+	return nil
+}
+
+// switchElsewhere switches the collection as another instance would
+func (m *memoryReadRepo) switchElsewhere(ctx context.Context, collection string) {
+	m.mu.Lock()
+	m.previous = m.active
+	m.active = collection
+	onSwitch := m.onSwitch
+	m.mu.Unlock()
+
+	onSwitch(ctx, collection)
+}
+
+func (m *memoryReadRepo) userIDs(collection string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string{}, m.users[collection]...)
+}
+
+type memoryShadowRepo struct {
+	user.ReadRepo
+
+	repo       *memoryReadRepo
+	collection string
+}
+
+func (s *memoryShadowRepo) User(ctx context.Context, userID string) (*user.DTO, error) {
+	return s.repo.User(ctx, userID)
+}
+
+func (s *memoryShadowRepo) CreateUser(ctx context.Context, dto user.DTO) error {
+	if s.repo.block != nil {
+		<-s.repo.block
+	}
+	if s.repo.fail != nil {
+		return s.repo.fail
+	}
+
+	s.repo.mu.Lock()
+	defer s.repo.mu.Unlock()
+	s.repo.users[s.collection] = append(s.repo.users[s.collection], dto.UserID)
+	return nil
+}
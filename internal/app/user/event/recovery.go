@@ -0,0 +1,77 @@
+package event
+
+import (
+	"fmt"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+)
+
+// recoveryDecision describes whether the sign up saga of a user has to
+// run to finish its follow-ups
+type recoveryDecision struct {
+	Run    bool
+	Reason string
+}
+
+// decideRecovery decides whether the sign up saga of the user with
+// history has to run. instance is the saga instance of the user, if any.
+//
+// In order:
+//
+//	deleted users are skipped, their commands would be rejected
+//	finished instances are skipped, whatever their outcome
+//	running instances are resumed after their last completed step
+//	users created before sagas were persisted who earned points are skipped;
+//	  sign up points are awarded by the last step of the saga
+//	any other user has not had its follow-ups run and starts the saga
+func decideRecovery(
+	instance *saga.Instance,
+	history eventsource.History,
+) recoveryDecision {
+	if hasEvent(history, user.UserDeletedEventType) {
+		return recoveryDecision{Reason: "user deleted"}
+	}
+
+	if instance != nil {
+		if instance.Done() {
+			return recoveryDecision{
+				Reason: fmt.Sprintf("saga %v", instance.Status),
+			}
+		}
+		return recoveryDecision{
+			Run: true,
+			Reason: fmt.Sprintf(
+				"saga stuck after steps %v",
+				instance.CompletedSteps,
+			),
+		}
+	}
+
+	if hasEvent(history, user.PointsEarnedEventType) {
+		return recoveryDecision{Reason: "sign up points already earned"}
+	}
+
+	return recoveryDecision{Run: true, Reason: "sign up follow-ups never started"}
+}
+
+/* ----- helpers ----- */
+func hasEvent(history eventsource.History, eventType string) bool {
+	for _, v := range history {
+		if v.EventType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func findEvent(history eventsource.History, eventType string) *eventsource.Event {
+	for _, v := range history {
+		if v.EventType == eventType {
+			event := v
+			return &event
+		}
+	}
+	return nil
+}
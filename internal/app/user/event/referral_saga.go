@@ -0,0 +1,506 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/notify"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	// ReferralSagaType identifies the saga expiring referrals that are not
+	// completed in time. Its instances are correlated by referral id
+	ReferralSagaType = "Referral"
+
+	// ReferralExpiry is how long a referral can be completed after it is created
+	ReferralExpiry = 30 * 24 * time.Hour
+
+	// ReferralInviteSagaType identifies the saga sending the invitation of
+	// a referral and marking it sent. Its instances are correlated by
+	// referral id
+	ReferralInviteSagaType = "ReferralInvite"
+
+	// ReferralExpirySagaType identifies the saga notifying the referrer of
+	// an expired referral. Its instances are correlated by referral id so
+	// that redeliveries of the expiry do not notify the referrer again
+	ReferralExpirySagaType = "ReferralExpiry"
+
+	referralExpiryTimeout = "expire"
+)
+
+// ReferralSaga is the user EventHandler sending the invitations of
+// referrals and expiring them
+type ReferralSaga interface {
+	eventsource.EventHandler
+
+	// Recover syncs the users whose invitations were interrupted, e.g. by
+	// a restart, which also registers the expiry of their open referrals
+	// again, and resumes the expiry notifications that were not sent
+	Recover(ctx context.Context) error
+}
+
+type referralSaga struct {
+	timeouts   saga.Timeouts
+	notifier   notify.Notifier
+	dispatcher eventsource.CommandDispatcher
+	runner     saga.Runner
+	store      user.EventStore
+	logger     *zap.Logger
+}
+
+// ReferralSagaParams represent the params needed to instantiate the
+// referral saga
+type ReferralSagaParams struct {
+	Timeouts   saga.Timeouts
+	Notifier   notify.Notifier
+	Dispatcher eventsource.CommandDispatcher
+	Runner     saga.Runner
+	Store      user.EventStore
+	Logger     *zap.Logger
+}
+
+// NewReferralSaga creates the EventHandler that sends the invitation of
+// new referrals, expires them after ReferralExpiry and notifies the
+// referrer. The expiry of referrals that are completed or cancelled first
+// is cancelled
+func NewReferralSaga(p ReferralSagaParams) ReferralSaga {
+	return &referralSaga{
+		timeouts:   p.Timeouts,
+		notifier:   p.Notifier,
+		dispatcher: p.Dispatcher,
+		runner:     p.Runner,
+		store:      p.Store,
+		logger:     p.Logger,
+	}
+}
+
+func (s *referralSaga) EventTypesHandled() []string {
+	return []string{
+		user.UserReferralCreatedEventType,
+		user.UserReferralCompletedEventType,
+		user.UserReferralExpiredEventType,
+		user.UserReferralCancelledEventType,
+		user.UserDeletedEventType,
+	}
+}
+
+func (s *referralSaga) Handle(
+	ctx context.Context,
+	event eventsource.Event,
+) error {
+	ctx = eventsource.WithCommandSource(ctx, eventsource.CommandSourceSaga)
+	switch event.EventType {
+	case user.UserReferralCreatedEventType:
+		return s.handleReferralCreated(ctx, event)
+
+	case user.UserReferralCompletedEventType,
+		user.UserReferralCancelledEventType:
+		return s.handleReferralClosed(ctx, event)
+
+	case user.UserReferralExpiredEventType:
+		return s.handleReferralExpired(ctx, event)
+
+	case user.UserDeletedEventType:
+		return s.cancelOpenReferrals(ctx, event.AggregateID)
+	}
+
+	return nil
+}
+
+// Sync implements the EventHandler interface; registers the expiry of
+// every open referral of the user again, e.g. after timeouts were lost,
+// and resumes the invitations that were not sent
+func (s *referralSaga) Sync(ctx context.Context, aggregateID string) error {
+	history, err := s.store.Load(ctx, aggregateID, 0)
+	if err != nil {
+		return errors.Wrapf(err, "unable to load history of user %v", aggregateID)
+	}
+	if hasEvent(history, user.UserDeletedEventType) {
+		return s.cancelOpenReferrals(ctx, aggregateID)
+	}
+
+	for _, v := range openReferrals(history) {
+		errRegister := s.registerExpiry(ctx, v.event, v.payload.ReferralID)
+		if errRegister != nil {
+			return errRegister
+		}
+	}
+	return s.sendInvites(ctx, history, unsentReferrals(history))
+}
+
+// Recover implements the ReferralSaga interface
+func (s *referralSaga) Recover(ctx context.Context) error {
+	return s.runner.Recover(
+		ctx,
+		s.resume,
+		ReferralInviteSagaType,
+		ReferralExpirySagaType,
+	)
+}
+
+// resume syncs the referrer of an interrupted invitation and notifies the
+// referrer of an expiry again
+func (s *referralSaga) resume(ctx context.Context, instance saga.Instance) error {
+	if instance.AggregateID == "" {
+		return errors.Errorf("saga %v does not record its referrer", instance.ID)
+	}
+
+	ctx = eventsource.WithCommandSource(ctx, eventsource.CommandSourceSaga)
+	if instance.Type != ReferralExpirySagaType {
+		return s.Sync(ctx, instance.AggregateID)
+	}
+
+	history, err := s.store.Load(ctx, instance.AggregateID, 0)
+	if err != nil {
+		return errors.Wrapf(
+			err,
+			"unable to load history of user %v",
+			instance.AggregateID,
+		)
+	}
+	return s.notifyExpired(
+		ctx,
+		history,
+		instance.AggregateID,
+		instance.CorrelationID,
+	)
+}
+
+/* ----- handlers ----- */
+func (s *referralSaga) handleReferralCreated(
+	ctx context.Context,
+	event eventsource.Event,
+) error {
+	payload, err := referralCreatedPayload(event)
+	if err != nil {
+		return err
+	}
+	if payload.ReferralStatus != string(user.ReferralStatusCreated) {
+		return nil
+	}
+
+	errRegister := s.registerExpiry(ctx, event, payload.ReferralID)
+	if errRegister != nil {
+		return errRegister
+	}
+
+	history, errHistory := s.store.Load(ctx, event.AggregateID, 0)
+	if errHistory != nil {
+		return errors.Wrapf(
+			errHistory,
+			"unable to load history of user %v",
+			event.AggregateID,
+		)
+	}
+
+	// The referral may have been sent, cancelled or the user deleted by
+	// the time the event is handled
+	invites := []referralEvent{}
+	for _, v := range unsentReferrals(history) {
+		if v.payload.ReferralID == payload.ReferralID {
+			invites = append(invites, v)
+		}
+	}
+	return s.sendInvites(ctx, history, invites)
+}
+
+func (s *referralSaga) handleReferralClosed(
+	ctx context.Context,
+	event eventsource.Event,
+) error {
+	referralID, err := user.ReferralTransitionID(event)
+	if err != nil {
+		return err
+	}
+
+	return s.timeouts.Cancel(
+		ctx,
+		ReferralSagaType,
+		referralID,
+		referralExpiryTimeout,
+	)
+}
+
+func (s *referralSaga) handleReferralExpired(
+	ctx context.Context,
+	event eventsource.Event,
+) error {
+	expired := user.ReferralExpired{
+		ApplierModel: *eventsource.NewApplierModel(event),
+	}
+	payload, err := expired.GetDeserializedPayload()
+	if err != nil {
+		return err
+	}
+
+	history, errHistory := s.store.Load(ctx, event.AggregateID, 0)
+	if errHistory != nil {
+		return errors.Wrapf(
+			errHistory,
+			"unable to load history of user %v",
+			event.AggregateID,
+		)
+	}
+	return s.notifyExpired(ctx, history, event.AggregateID, payload.ReferralID)
+}
+
+// notifyExpired runs the saga notifying the referrer that the referral
+// expired, which sends the notification once however often the expiry is
+// delivered
+func (s *referralSaga) notifyExpired(
+	ctx context.Context,
+	history eventsource.History,
+	userID string,
+	referralID string,
+) error {
+	referrer, err := createdPayload(history)
+	if err != nil {
+		return err
+	}
+
+	notification := notify.Notification{
+		EventType: user.UserReferralExpiredEventType,
+		UserID:    userID,
+		Email:     referrer.Email,
+		Data: map[string]string{
+			"username":   referrer.Username,
+			"referralId": referralID,
+		},
+	}
+	for _, v := range referralEvents(history) {
+		if v.payload.ReferralID == referralID {
+			notification.Data["referredUserEmail"] = v.payload.ReferredUserEmail
+		}
+	}
+
+	_, errRun := s.runner.Run(ctx, saga.Definition{
+		Type:          ReferralExpirySagaType,
+		CorrelationID: referralID,
+		AggregateID:   userID,
+		Steps: []saga.Step{
+			{
+				Name: "notify-referrer",
+				Action: func(ctx context.Context) error {
+					return s.notifier.Notify(ctx, notification)
+				},
+			},
+		},
+	})
+	return errRun
+}
+
+/* ----- invitations ----- */
+// sendInvites runs the invitation saga of each referral in invites
+func (s *referralSaga) sendInvites(
+	ctx context.Context,
+	history eventsource.History,
+	invites []referralEvent,
+) error {
+	if len(invites) == 0 || hasEvent(history, user.UserDeletedEventType) {
+		return nil
+	}
+
+	referrer, err := createdPayload(history)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range invites {
+		_, errRun := s.runner.Run(ctx, saga.Definition{
+			Type:          ReferralInviteSagaType,
+			CorrelationID: v.payload.ReferralID,
+			AggregateID:   v.event.AggregateID,
+			Steps: []saga.Step{
+				s.sendInviteStep(v, referrer),
+				s.markSentStep(v),
+			},
+		})
+		if errRun != nil {
+			return errRun
+		}
+	}
+	return nil
+}
+
+func (s *referralSaga) sendInviteStep(
+	referral referralEvent,
+	referrer *user.CreatedPayload,
+) saga.Step {
+	return saga.Step{
+		Name: "send-invite",
+		Action: func(ctx context.Context) error {
+			return s.notifier.Notify(ctx, notify.Notification{
+				EventType: user.UserReferralCreatedEventType,
+				UserID:    referral.event.AggregateID,
+				Email:     referral.payload.ReferredUserEmail,
+				Data: map[string]string{
+					"username":     referrer.Username,
+					"referralId":   referral.payload.ReferralID,
+					"referralCode": referral.payload.ReferralCode,
+				},
+			})
+		},
+	}
+}
+
+func (s *referralSaga) markSentStep(referral referralEvent) saga.Step {
+	return saga.Step{
+		Name: "mark-sent",
+		Action: func(ctx context.Context) error {
+			_, err := s.dispatcher.Dispatch(ctx, &loyalty.MarkReferralSent{
+				CommandModel: eventsource.CommandModel{
+					ID:        referral.event.AggregateID,
+					CommandID: sagaCommandID(referral.event, "mark-sent"),
+				},
+				ReferralID: referral.payload.ReferralID,
+			})
+			if errors.Cause(err) == user.ErrIllegalReferralTransition {
+				// Closed while the invitation was sent; retrying cannot help
+				return saga.Permanent(err)
+			}
+			return err
+		},
+	}
+}
+
+/* ----- helpers ----- */
+func (s *referralSaga) registerExpiry(
+	ctx context.Context,
+	event eventsource.Event,
+	referralID string,
+) error {
+	return s.timeouts.Register(ctx, saga.Timeout{
+		SagaType:      ReferralSagaType,
+		CorrelationID: referralID,
+		Name:          referralExpiryTimeout,
+		DueAt:         event.EventAt.Add(ReferralExpiry),
+		Command: &loyalty.ExpireReferral{
+			CommandModel: eventsource.CommandModel{
+				ID: event.AggregateID,
+			},
+			ReferralID: referralID,
+		},
+	})
+}
+
+func (s *referralSaga) cancelOpenReferrals(
+	ctx context.Context,
+	aggregateID string,
+) error {
+	history, err := s.store.Load(ctx, aggregateID, 0)
+	if err != nil {
+		return errors.Wrapf(err, "unable to load history of user %v", aggregateID)
+	}
+
+	for _, v := range openReferrals(history) {
+		errCancel := s.timeouts.Cancel(
+			ctx,
+			ReferralSagaType,
+			v.payload.ReferralID,
+			referralExpiryTimeout,
+		)
+		if errCancel != nil {
+			return errCancel
+		}
+	}
+	return nil
+}
+
+type referralEvent struct {
+	event   eventsource.Event
+	payload *user.ReferralCreatedPayload
+}
+
+// referralEvents returns the referrals created in history
+func referralEvents(history eventsource.History) []referralEvent {
+	referrals := []referralEvent{}
+	for _, v := range history {
+		if v.EventType != user.UserReferralCreatedEventType {
+			continue
+		}
+		payload, err := referralCreatedPayload(v)
+		if err != nil {
+			continue
+		}
+		referrals = append(referrals, referralEvent{event: v, payload: payload})
+	}
+	return referrals
+}
+
+// openReferrals returns the referrals in history that were created but
+// are not closed, i.e. neither completed, expired nor cancelled
+func openReferrals(history eventsource.History) []referralEvent {
+	closed := make(map[string]bool)
+	for _, v := range history {
+		switch v.EventType {
+		case user.UserReferralCompletedEventType,
+			user.UserReferralExpiredEventType,
+			user.UserReferralCancelledEventType:
+			referralID, err := user.ReferralTransitionID(v)
+			if err != nil {
+				continue
+			}
+			closed[referralID] = true
+		}
+	}
+
+	open := []referralEvent{}
+	for _, v := range referralEvents(history) {
+		if v.payload.ReferralStatus == string(user.ReferralStatusCreated) &&
+			!closed[v.payload.ReferralID] {
+			open = append(open, v)
+		}
+	}
+	return open
+}
+
+// unsentReferrals returns the open referrals in history whose invitation
+// has not been marked sent
+func unsentReferrals(history eventsource.History) []referralEvent {
+	sent := make(map[string]bool)
+	for _, v := range history {
+		if v.EventType != user.UserReferralSentEventType {
+			continue
+		}
+		referralID, err := user.ReferralTransitionID(v)
+		if err != nil {
+			continue
+		}
+		sent[referralID] = true
+	}
+
+	unsent := []referralEvent{}
+	for _, v := range openReferrals(history) {
+		if !sent[v.payload.ReferralID] {
+			unsent = append(unsent, v)
+		}
+	}
+	return unsent
+}
+
+func referralCreatedPayload(
+	event eventsource.Event,
+) (*user.ReferralCreatedPayload, error) {
+	created := user.ReferralCreated{
+		ApplierModel: *eventsource.NewApplierModel(event),
+	}
+	return created.GetDeserializedPayload()
+}
+
+func createdPayload(history eventsource.History) (*user.CreatedPayload, error) {
+	event := findEvent(history, user.UserCreatedEventType)
+	if event == nil {
+		return nil, errors.New("user created event not found")
+	}
+
+	created := user.Created{
+		ApplierModel: *eventsource.NewApplierModel(*event),
+	}
+	return created.GetDeserializedPayload()
+}
@@ -0,0 +1,395 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource/eventsourcetest"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/notify"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga/sagatest"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/scheduler"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+var referralCreatedAt = time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+/* ----- tests ----- */
+func TestReferralSaga_ExpiresAfterDeadline(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	created := newReferralCreatedEvent(t, "referrer-1", "referral-1", 2)
+	f := newReferralSagaFixture(t, eventsource.History{
+		newCreatedEvent(t, "referrer-1", nil),
+		created,
+	})
+	assert.Nil(f.saga.Handle(ctx, created))
+
+	f.clock.Advance(ReferralExpiry - time.Minute)
+	dispatched, err := f.scheduler.RunDue(ctx)
+	assert.Nil(err)
+	assert.Equal(0, dispatched)
+
+	f.clock.Advance(time.Minute)
+	dispatched, err = f.scheduler.RunDue(ctx)
+	assert.Nil(err)
+	assert.Equal(1, dispatched)
+	assert.Equal(
+		[]string{"MarkReferralSent:referrer-1", "ExpireReferral:referrer-1"},
+		f.dispatcher.dispatched(),
+	)
+}
+
+func TestReferralSaga_ClosedReferralDoesNotExpire(t *testing.T) {
+	tests := []struct {
+		name    string
+		applier eventsource.Applier
+		payload interface{}
+	}{
+		{
+			name: "completed",
+			applier: user.NewReferralCompletedApplier(
+				"referrer-1",
+				user.UserReferralCompletedEventType,
+				3,
+			),
+			payload: user.ReferralCompletedPayload{ReferralID: "referral-1"},
+		},
+		{
+			name: "cancelled",
+			applier: user.NewReferralCancelledApplier(
+				"referrer-1",
+				user.UserReferralCancelledEventType,
+				3,
+			),
+			payload: user.ReferralCancelledPayload{ReferralID: "referral-1"},
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			assert := assert.New(t)
+			ctx := context.Background()
+
+			created := newReferralCreatedEvent(t, "referrer-1", "referral-1", 2)
+			closed := newReferralEvent(t, v.applier, v.payload)
+			f := newReferralSagaFixture(t, eventsource.History{
+				newCreatedEvent(t, "referrer-1", nil),
+				created,
+				closed,
+			})
+			assert.Nil(f.saga.Handle(ctx, created))
+			assert.Nil(f.saga.Handle(ctx, closed))
+
+			// Sync does not register closed referrals again
+			assert.Nil(f.saga.Sync(ctx, "referrer-1"))
+
+			f.clock.Advance(ReferralExpiry)
+			dispatched, err := f.scheduler.RunDue(ctx)
+			assert.Nil(err)
+			assert.Equal(0, dispatched)
+			assert.Empty(f.dispatcher.dispatched())
+		})
+	}
+}
+
+func TestReferralSaga_SyncRegistersOpenReferrals(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	f := newReferralSagaFixture(t, eventsource.History{
+		newCreatedEvent(t, "referrer-1", nil),
+		newReferralCreatedEvent(t, "referrer-1", "referral-1", 2),
+		newReferralCreatedEvent(t, "referrer-1", "referral-2", 3),
+		newReferralEvent(
+			t,
+			user.NewReferralExpiredApplier(
+				"referrer-1",
+				user.UserReferralExpiredEventType,
+				4,
+			),
+			user.ReferralExpiredPayload{ReferralID: "referral-1"},
+		),
+	})
+	assert.Nil(f.saga.Sync(ctx, "referrer-1"))
+
+	f.clock.Advance(ReferralExpiry)
+	dispatched, err := f.scheduler.RunDue(ctx)
+	assert.Nil(err)
+	assert.Equal(1, dispatched)
+}
+
+func TestReferralSaga_ExpiredNotifiesReferrer(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	expired := newReferralEvent(
+		t,
+		user.NewReferralExpiredApplier(
+			"referrer-1",
+			user.UserReferralExpiredEventType,
+			3,
+		),
+		user.ReferralExpiredPayload{ReferralID: "referral-1"},
+	)
+	f := newReferralSagaFixture(t, eventsource.History{
+		newCreatedEvent(t, "referrer-1", nil),
+		newReferralCreatedEvent(t, "referrer-1", "referral-1", 2),
+		expired,
+	})
+	assert.Nil(f.saga.Handle(ctx, expired))
+
+	notifications := f.notifier.sent()
+	if assert.Len(notifications, 1) {
+		assert.Equal(user.UserReferralExpiredEventType, notifications[0].EventType)
+		assert.Equal("referrer-1", notifications[0].UserID)
+		assert.Equal("referrer-1@example.com", notifications[0].Email)
+		assert.Equal("referral-1", notifications[0].Data["referralId"])
+		assert.Equal(
+			"referral-1@example.com",
+			notifications[0].Data["referredUserEmail"],
+		)
+	}
+
+	// Redelivering the expiry does not notify the referrer again
+	assert.Nil(f.saga.Handle(ctx, expired))
+	assert.Len(f.notifier.sent(), 1)
+}
+
+func TestReferralSaga_RecoverResumesExpiryNotifications(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	expired := newReferralEvent(
+		t,
+		user.NewReferralExpiredApplier(
+			"referrer-1",
+			user.UserReferralExpiredEventType,
+			3,
+		),
+		user.ReferralExpiredPayload{ReferralID: "referral-1"},
+	)
+	f := newReferralSagaFixture(t, eventsource.History{
+		newCreatedEvent(t, "referrer-1", nil),
+		newReferralCreatedEvent(t, "referrer-1", "referral-1", 2),
+		expired,
+	})
+	f.notifier.fail = errors.New("smtp unavailable")
+	assert.NotNil(f.saga.Handle(ctx, expired))
+	assert.Empty(f.notifier.sent())
+
+	f.notifier.fail = nil
+	assert.Nil(f.saga.Recover(ctx))
+	notifications := f.notifier.sent()
+	if assert.Len(notifications, 1) {
+		assert.Equal(user.UserReferralExpiredEventType, notifications[0].EventType)
+		assert.Equal("referral-1", notifications[0].Data["referralId"])
+	}
+}
+
+func TestReferralSaga_SendsInvitationAndMarksSent(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	created := newReferralCreatedEvent(t, "referrer-1", "referral-1", 2)
+	f := newReferralSagaFixture(t, eventsource.History{
+		newCreatedEvent(t, "referrer-1", nil),
+		created,
+	})
+	assert.Nil(f.saga.Handle(ctx, created))
+
+	notifications := f.notifier.sent()
+	if assert.Len(notifications, 1) {
+		assert.Equal(user.UserReferralCreatedEventType, notifications[0].EventType)
+		assert.Equal("referral-1@example.com", notifications[0].Email)
+		assert.Equal("code-referrer-1", notifications[0].Data["referralCode"])
+		assert.Equal("referral-1", notifications[0].Data["referralId"])
+	}
+	assert.Equal([]string{"MarkReferralSent:referrer-1"}, f.dispatcher.dispatched())
+
+	// Redelivering the event does not send the invitation again
+	assert.Nil(f.saga.Handle(ctx, created))
+	assert.Len(f.notifier.sent(), 1)
+	assert.Len(f.dispatcher.dispatched(), 1)
+}
+
+func TestReferralSaga_FailedMarkSentDoesNotResendInvitation(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	created := newReferralCreatedEvent(t, "referrer-1", "referral-1", 2)
+	f := newReferralSagaFixture(t, eventsource.History{
+		newCreatedEvent(t, "referrer-1", nil),
+		created,
+	})
+	f.dispatcher.fail = errors.New("dispatcher unavailable")
+	assert.NotNil(f.saga.Handle(ctx, created))
+	assert.Len(f.notifier.sent(), 1)
+
+	// Sync resumes the saga after the invitation was sent
+	f.dispatcher.fail = nil
+	assert.Nil(f.saga.Sync(ctx, "referrer-1"))
+	assert.Len(f.notifier.sent(), 1)
+	assert.Equal([]string{"MarkReferralSent:referrer-1"}, f.dispatcher.dispatched())
+}
+
+func TestReferralSaga_RecoverResumesInterruptedInvitations(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	created := newReferralCreatedEvent(t, "referrer-1", "referral-1", 2)
+	f := newReferralSagaFixture(t, eventsource.History{
+		newCreatedEvent(t, "referrer-1", nil),
+		created,
+	})
+	f.dispatcher.fail = errors.New("dispatcher unavailable")
+	assert.NotNil(f.saga.Handle(ctx, created))
+
+	f.dispatcher.fail = nil
+	assert.Nil(f.saga.Recover(ctx))
+	assert.Len(f.notifier.sent(), 1)
+	assert.Equal([]string{"MarkReferralSent:referrer-1"}, f.dispatcher.dispatched())
+}
+
+func TestReferralSaga_SyncSkipsSentInvitations(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	f := newReferralSagaFixture(t, eventsource.History{
+		newCreatedEvent(t, "referrer-1", nil),
+		newReferralCreatedEvent(t, "referrer-1", "referral-1", 2),
+		newReferralCreatedEvent(t, "referrer-1", "referral-2", 3),
+		newReferralEvent(
+			t,
+			user.NewReferralSentApplier(
+				"referrer-1",
+				user.UserReferralSentEventType,
+				4,
+			),
+			user.ReferralSentPayload{ReferralID: "referral-1"},
+		),
+	})
+	assert.Nil(f.saga.Sync(ctx, "referrer-1"))
+
+	notifications := f.notifier.sent()
+	if assert.Len(notifications, 1) {
+		assert.Equal("referral-2@example.com", notifications[0].Email)
+	}
+}
+
+/* ----- helpers ----- */
+type referralSagaFixture struct {
+	saga       ReferralSaga
+	scheduler  scheduler.Scheduler
+	clock      *scheduler.FakeClock
+	dispatcher *recordingDispatcher
+	notifier   *recordingNotifier
+}
+
+func newReferralSagaFixture(
+	t *testing.T,
+	history eventsource.History,
+) *referralSagaFixture {
+	logger := zaptest.NewLogger(t)
+	clock := scheduler.NewFakeClock(referralCreatedAt)
+	dispatcher := &recordingDispatcher{}
+	notifier := &recordingNotifier{}
+
+	registry := eventsource.NewCommandRegistry()
+	registry.Register(&loyalty.ExpireReferral{})
+	s := scheduler.New(scheduler.Params{
+		Store:      scheduler.NewMemoryStore(),
+		Registry:   registry,
+		Dispatcher: dispatcher,
+		Logger:     logger,
+		Clock:      clock,
+	})
+
+	return &referralSagaFixture{
+		saga: NewReferralSaga(ReferralSagaParams{
+			Timeouts:   saga.NewTimeouts(s),
+			Notifier:   notifier,
+			Dispatcher: dispatcher,
+			Runner: saga.NewRunner(saga.RunnerParams{
+				Store:  sagatest.NewMemoryStore(),
+				Logger: logger,
+			}),
+			Store:  eventsourcetest.NewMemoryStore(history...),
+			Logger: logger,
+		}),
+		scheduler:  s,
+		clock:      clock,
+		dispatcher: dispatcher,
+		notifier:   notifier,
+	}
+}
+
+func newReferralCreatedEvent(
+	t *testing.T,
+	userID string,
+	referralID string,
+	version int,
+) eventsource.Event {
+	return newReferralEvent(
+		t,
+		user.NewReferralCreatedApplier(
+			userID,
+			user.UserReferralCreatedEventType,
+			version,
+		),
+		user.ReferralCreatedPayload{
+			ReferralID:        referralID,
+			ReferralCode:      "code-" + userID,
+			ReferredUserEmail: referralID + "@example.com",
+			ReferralStatus:    string(user.ReferralStatusCreated),
+		},
+	)
+}
+
+func newReferralEvent(
+	t *testing.T,
+	applier eventsource.Applier,
+	payload interface{},
+) eventsource.Event {
+	err := applier.SetSerializedPayload(payload)
+	assert.Nil(t, err)
+
+	event := applier.EventModel()
+	event.EventAt = referralCreatedAt
+	return event
+}
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []notify.Notification
+
+	// fail is returned instead of recording notifications when set
+	fail error
+}
+
+func (n *recordingNotifier) Notify(
+	ctx context.Context,
+	notification notify.Notification,
+) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.fail != nil {
+		return n.fail
+	}
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func (n *recordingNotifier) sent() []notify.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]notify.Notification{}, n.notifications...)
+}
@@ -2,180 +2,706 @@ package event
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-type saga struct {
+var (
+	// SignUpSagaType identifies the saga awarding sign up and referral
+	// points. Its instances are correlated by the id of the created user
+	SignUpSagaType = "UserSignUp"
+
+	// ProfileCompletionSagaType identifies the saga awarding the profile
+	// completion points. Its instances are correlated by user id
+	ProfileCompletionSagaType = "ProfileCompletion"
+)
+
+// Saga is the user EventHandler awarding sign up, referral and profile
+// completion points
+type Saga interface {
+	eventsource.EventHandler
+
+	// Recover finishes the sagas that were interrupted, e.g. by a
+	// restart, compensates the failed ones again and starts the sign up
+	// sagas that never started
+	Recover(ctx context.Context) error
+}
+
+type userSaga struct {
 	dispatcher    eventsource.CommandDispatcher
+	runner        saga.Runner
+	store         user.EventStore
 	repo          user.ReadRepo
 	pointsMapping loyalty.PointsMappingService
 	logger        *zap.Logger
 }
 
-func NewSaga(
-	logger *zap.Logger,
-	dispatcher eventsource.CommandDispatcher,
-	repo user.ReadRepo,
-	pointsMapping loyalty.PointsMappingService,
-) eventsource.EventHandler {
-	return &saga{
-		dispatcher:    dispatcher,
-		logger:        logger,
-		repo:          repo,
-		pointsMapping: pointsMapping,
+// SagaParams represent the params needed to instantiate the user saga
+type SagaParams struct {
+	Dispatcher    eventsource.CommandDispatcher
+	Runner        saga.Runner
+	Store         user.EventStore
+	Repo          user.ReadRepo
+	PointsMapping loyalty.PointsMappingService
+	Logger        *zap.Logger
+}
+
+func NewSaga(p SagaParams) Saga {
+	return &userSaga{
+		dispatcher:    p.Dispatcher,
+		runner:        p.Runner,
+		store:         p.Store,
+		logger:        p.Logger,
+		repo:          p.Repo,
+		pointsMapping: p.PointsMapping,
+	}
+}
+
+func (s *userSaga) EventTypesHandled() []string {
+	return []string{
+		user.UserCreatedEventType,
+		user.ProfileUpdatedEventType,
 	}
 }
 
-func (s *saga) EventTypesHandled() []string {
-	return []string{user.UserCreatedEventType}
+// Sync implements the EventHandler interface; finishes the sign up saga
+// of the user when decideRecovery requires it and the profile completion
+// saga unless it is done
+func (s *userSaga) Sync(ctx context.Context, aggregateID string) error {
+	history, err := s.store.Load(ctx, aggregateID, 0)
+	if err != nil {
+		return errors.Wrapf(err, "unable to load history of user %v", aggregateID)
+	}
+
+	errSignUp := s.syncSignUp(ctx, aggregateID, history)
+	if errSignUp != nil {
+		return errSignUp
+	}
+	return s.syncProfileCompletion(ctx, aggregateID, history)
 }
 
-func (s *saga) Sync(ctx context.Context, aggregateID string) error {
-	panic("sync for user event saga not implemented")
+func (s *userSaga) syncSignUp(
+	ctx context.Context,
+	aggregateID string,
+	history eventsource.History,
+) error {
+	created := findEvent(history, user.UserCreatedEventType)
+	if created == nil {
+		return nil
+	}
+
+	instance, errInstance := s.runner.Instance(ctx, SignUpSagaType, aggregateID)
+	if errInstance != nil {
+		return errInstance
+	}
+
+	decision := decideRecovery(instance, history)
+	if !decision.Run {
+		return nil
+	}
+
+	s.logger.Info(
+		"recovering user saga",
+		zap.String("aggregateId", aggregateID),
+		zap.String("reason", decision.Reason),
+	)
+	return s.Handle(ctx, *created)
 }
 
-func (s *saga) Handle(
+// syncProfileCompletion runs the profile completion saga again unless it
+// is done. Its step is idempotent, so points earned before the saga was
+// persisted are not awarded twice
+func (s *userSaga) syncProfileCompletion(
+	ctx context.Context,
+	aggregateID string,
+	history eventsource.History,
+) error {
+	completed := findProfileCompletion(history)
+	if completed == nil || hasEvent(history, user.UserDeletedEventType) {
+		return nil
+	}
+
+	instance, err := s.runner.Instance(ctx, ProfileCompletionSagaType, aggregateID)
+	if err != nil {
+		return err
+	}
+	if instance != nil && instance.Done() {
+		return nil
+	}
+
+	s.logger.Info(
+		"recovering profile completion saga",
+		zap.String("aggregateId", aggregateID),
+	)
+	return s.Handle(ctx, *completed)
+}
+
+// Recover implements the Saga interface; resumes the running sign up and
+// profile completion sagas, compensates the failed ones again, then starts
+// the sign up sagas of users whose UserCreated event was never handled
+func (s *userSaga) Recover(ctx context.Context) error {
+	errRecover := s.runner.Recover(
+		ctx,
+		s.resume,
+		SignUpSagaType,
+		ProfileCompletionSagaType,
+	)
+	if errRecover != nil {
+		return errRecover
+	}
+
+	return s.runner.Exclusive(ctx, "recovery:unstarted:"+SignUpSagaType, s.startUnstarted)
+}
+
+// startUnstarted syncs the users that have no sign up instance, so that
+// decideRecovery starts their saga. Users that cannot be synced are logged
+// so that they do not block the others
+func (s *userSaga) startUnstarted(ctx context.Context) error {
+	created, err := s.store.LoadByTypes(ctx, user.UserCreatedEventType)
+	if err != nil {
+		return errors.Wrap(err, "unable to load created users")
+	}
+
+	started := 0
+	for _, v := range created {
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "sign up recovery stopped after %v users", started)
+		}
+
+		instance, errInstance := s.runner.Instance(ctx, SignUpSagaType, v.AggregateID)
+		if errInstance != nil {
+			s.logger.Error(
+				"unable to load sign up saga instance",
+				zap.Error(errInstance),
+				zap.String("aggregateId", v.AggregateID),
+			)
+			continue
+		}
+		if instance != nil {
+			continue
+		}
+
+		history, errHistory := s.store.Load(ctx, v.AggregateID, 0)
+		if errHistory == nil {
+			errHistory = s.syncSignUp(ctx, v.AggregateID, history)
+		}
+		if errHistory != nil {
+			s.logger.Error(
+				"unable to start sign up saga",
+				zap.Error(errHistory),
+				zap.String("aggregateId", v.AggregateID),
+			)
+			continue
+		}
+		started++
+	}
+
+	s.logger.Info("sign up sagas started", zap.Int("count", started))
+	return nil
+}
+
+// resume syncs the user of a running instance, which runs its steps that
+// have not completed, and compensates a failed instance again
+func (s *userSaga) resume(ctx context.Context, instance saga.Instance) error {
+	if instance.Status != saga.StatusFailed {
+		return s.Sync(ctx, instance.CorrelationID)
+	}
+
+	// User sagas are correlated by user id
+	userID := instance.CorrelationID
+	history, err := s.store.Load(ctx, userID, 0)
+	if err != nil {
+		return errors.Wrapf(err, "unable to load history of user %v", userID)
+	}
+
+	var definition *saga.Definition
+	var errDefinition error
+	switch instance.Type {
+	case SignUpSagaType:
+		if created := findEvent(history, user.UserCreatedEventType); created != nil {
+			definition, errDefinition = s.signUpDefinition(*created)
+		}
+	case ProfileCompletionSagaType:
+		if completed := findProfileCompletion(history); completed != nil {
+			definition, errDefinition = s.profileCompletionDefinition(*completed)
+		}
+	}
+	if errDefinition != nil {
+		return errDefinition
+	}
+	if definition == nil {
+		return errors.Errorf("no event of user %v started saga %v", userID, instance.ID)
+	}
+
+	ctx = eventsource.WithCommandSource(ctx, eventsource.CommandSourceSaga)
+	_, errCompensate := s.runner.Compensate(ctx, *definition)
+	return errCompensate
+}
+
+func (s *userSaga) Handle(
 	ctx context.Context,
 	event eventsource.Event,
 ) error {
+	ctx = eventsource.WithCommandSource(ctx, eventsource.CommandSourceSaga)
 	switch event.EventType {
 	case user.UserCreatedEventType:
 		return s.handleUserCreatedEvent(ctx, event)
+
+	case user.ProfileUpdatedEventType:
+		return s.handleProfileUpdatedEvent(ctx, event)
 	}
 
 	return nil
 }
 
-func (s *saga) handleUserCreatedEvent(
+func (s *userSaga) handleUserCreatedEvent(
 	ctx context.Context,
 	event eventsource.Event,
 ) error {
-	rawApplier, err := user.GetApplier(event)
+	definition, err := s.signUpDefinition(event)
 	if err != nil {
 		return err
 	}
 
+	_, errRun := s.runner.Run(ctx, *definition)
+	return errRun
+}
+
+// signUpDefinition returns the sign up saga started by the UserCreated
+// event
+func (s *userSaga) signUpDefinition(
+	event eventsource.Event,
+) (*saga.Definition, error) {
+	rawApplier, err := user.GetApplier(event)
+	if err != nil {
+		return nil, err
+	}
+
 	applier, ok := rawApplier.(*user.Created)
 	if !ok {
-		return errors.New("invalid applier for event provided")
+		return nil, errors.New("invalid applier for event provided")
 	}
 
 	payload, errPayload := applier.GetDeserializedPayload()
 	if errPayload != nil {
-		return errPayload
+		return nil, errPayload
 	}
 
-	if payload.ReferredByCode == nil {
-		return s.handleSignUpWithoutReferral(ctx, event)
+	steps := s.signUpWithoutReferralSteps(event)
+	if payload.ReferredByCode != nil {
+		steps = s.signUpWithReferralSteps(event, payload)
 	}
 
-	referringUser, errReferringUser := s.repo.
-		UserByReferralCode(ctx, *payload.ReferredByCode)
-	if errReferringUser != nil {
-		return errors.Errorf(
-			"referring user not found for referral code: %v",
-			*payload.ReferredByCode,
-		)
+	return &saga.Definition{
+		Type:          SignUpSagaType,
+		CorrelationID: event.AggregateID,
+		AggregateID:   event.AggregateID,
+		Steps:         steps,
+	}, nil
+}
+
+// handleProfileUpdatedEvent awards the profile completion points when
+// the update completed the profile for the first time
+func (s *userSaga) handleProfileUpdatedEvent(
+	ctx context.Context,
+	event eventsource.Event,
+) error {
+	definition, err := s.profileCompletionDefinition(event)
+	if err != nil || definition == nil {
+		return err
 	}
 
-	errCompleteReferral := s.dispatcher.Dispatch(
-		ctx,
-		&loyalty.CompleteReferral{
-			CommandModel: eventsource.CommandModel{
-				ID: referringUser.UserID,
-			},
-			ReferredByCode:    *payload.ReferredByCode,
-			ReferredUserEmail: payload.Email,
-			ReferredUserID:    event.AggregateID,
+	_, errRun := s.runner.Run(ctx, *definition)
+	return errRun
+}
+
+// profileCompletionDefinition returns the profile completion saga started
+// by the ProfileUpdated event, or nil when the update did not complete the
+// profile
+func (s *userSaga) profileCompletionDefinition(
+	event eventsource.Event,
+) (*saga.Definition, error) {
+	updated := user.ProfileUpdated{
+		ApplierModel: *eventsource.NewApplierModel(event),
+	}
+	payload, err := updated.GetDeserializedPayload()
+	if err != nil {
+		return nil, err
+	}
+	if !payload.ProfileCompleted {
+		return nil, nil
+	}
+
+	return &saga.Definition{
+		Type:          ProfileCompletionSagaType,
+		CorrelationID: event.AggregateID,
+		AggregateID:   event.AggregateID,
+		Steps: []saga.Step{
+			s.earnPointsStep(
+				ProfileCompletionSagaType,
+				event,
+				"complete-profile",
+				loyalty.PointsActionCompleteProfile,
+				func(context.Context) (string, error) {
+					return event.AggregateID, nil
+				},
+			),
 		},
-	)
-	if errCompleteReferral != nil {
-		return errCompleteReferral
+	}, nil
+}
+
+func (s *userSaga) signUpWithoutReferralSteps(event eventsource.Event) []saga.Step {
+	return []saga.Step{
+		s.earnPointsStep(
+			SignUpSagaType,
+			event,
+			"sign-up-without-referral",
+			loyalty.PointsActionSignUpWithoutReferral,
+			func(context.Context) (string, error) {
+				return event.AggregateID, nil
+			},
+		),
 	}
+}
 
-	// Earn points for both users
-	errEarnPointsReferrer := s.handleReferUser(ctx, event, referringUser.UserID)
-	if errEarnPointsReferrer != nil {
-		return errEarnPointsReferrer
+func (s *userSaga) signUpWithReferralSteps(
+	event eventsource.Event,
+	payload *user.CreatedPayload,
+) []saga.Step {
+	referringUserID := func(ctx context.Context) (string, error) {
+		return s.referringUserID(ctx, *payload.ReferredByCode)
 	}
 
-	errEarnPointsReferee := s.handleSignUpWithReferral(ctx, event)
-	if errEarnPointsReferee != nil {
-		return errEarnPointsReferee
+	completeReferralID := sagaCommandID(event, "complete-referral")
+	return []saga.Step{
+		{
+			Name: "complete-referral",
+			Action: func(ctx context.Context) error {
+				referrerID, err := referringUserID(ctx)
+				if err != nil {
+					return err
+				}
+
+				_, errDispatch := s.dispatcher.Dispatch(
+					ctx,
+					&loyalty.CompleteReferral{
+						CommandModel: eventsource.CommandModel{
+							ID:        referrerID,
+							CommandID: completeReferralID,
+						},
+						ReferredByCode:    *payload.ReferredByCode,
+						ReferredUserEmail: payload.Email,
+						ReferredUserID:    event.AggregateID,
+					},
+				)
+				return errDispatch
+			},
+			// Cancels the referral the step completed. Flagged referrals
+			// were not completed and are left for review
+			Compensate: func(ctx context.Context) error {
+				referrerID, err := referringUserID(ctx)
+				if err != nil {
+					return err
+				}
+
+				referralID, errReferral := s.referralCompletedBy(
+					ctx,
+					referrerID,
+					completeReferralID,
+				)
+				if errReferral != nil || referralID == "" {
+					return errReferral
+				}
+
+				_, errDispatch := s.dispatcher.Dispatch(
+					ctx,
+					&loyalty.CancelReferral{
+						CommandModel: eventsource.CommandModel{
+							ID:        referrerID,
+							CommandID: sagaCommandID(event, "complete-referral:compensate"),
+						},
+						ReferralID: referralID,
+						Reason: fmt.Sprintf(
+							"compensating complete-referral of %v",
+							SignUpSagaType,
+						),
+					},
+				)
+				return errDispatch
+			},
+		},
+		// Earn points for both users
+		s.unlessReferralFlagged(event, referringUserID, s.earnPointsStep(
+			SignUpSagaType,
+			event,
+			"refer-user",
+			loyalty.PointsActionReferUser,
+			referringUserID,
+		)),
+		s.unlessReferralFlagged(event, referringUserID, s.earnPointsStep(
+			SignUpSagaType,
+			event,
+			"sign-up-with-referral",
+			loyalty.PointsActionSignUpWithReferral,
+			func(context.Context) (string, error) {
+				return event.AggregateID, nil
+			},
+		)),
 	}
+}
 
-	return nil
+// unlessReferralFlagged skips step when the referral policy flagged the
+// sign up of event instead of completing the referral. Flagged referrals
+// earn no points until they are reviewed
+func (s *userSaga) unlessReferralFlagged(
+	event eventsource.Event,
+	referringUserID func(context.Context) (string, error),
+	step saga.Step,
+) saga.Step {
+	action := step.Action
+	step.Action = func(ctx context.Context) error {
+		referrerID, err := referringUserID(ctx)
+		if err != nil {
+			return err
+		}
+
+		flagged, errFlagged := s.referralFlagged(ctx, referrerID, event.AggregateID)
+		if errFlagged != nil {
+			return errFlagged
+		}
+		if flagged {
+			s.logger.Info(
+				"referral flagged, skipping step",
+				zap.String("step", step.Name),
+				zap.String("userId", event.AggregateID),
+				zap.String("referrerId", referrerID),
+			)
+			return nil
+		}
+		return action(ctx)
+	}
+	return step
 }
 
-func (s *saga) handleSignUpWithoutReferral(
+// referralFlagged returns whether the referrer has a flagged referral of
+// the user with userID
+func (s *userSaga) referralFlagged(
 	ctx context.Context,
-	event eventsource.Event,
-) error {
-	signUpPoints, errSignUpPoints := s.pointsMapping.
-		Map(loyalty.PointsActionSignUpWithoutReferral)
-	if errSignUpPoints != nil {
-		return errSignUpPoints
+	referrerID string,
+	userID string,
+) (bool, error) {
+	history, err := s.store.Load(ctx, referrerID, 0)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to load history of user %v", referrerID)
 	}
 
-	return s.dispatcher.Dispatch(
-		ctx,
-		&loyalty.EarnPoints{
-			CommandModel: eventsource.CommandModel{
-				ID: event.AggregateID,
-			},
-			Points: *signUpPoints,
+	for _, v := range history {
+		if v.EventType != user.UserReferralFlaggedEventType {
+			continue
+		}
+		flagged := user.ReferralFlagged{
+			ApplierModel: *eventsource.NewApplierModel(v),
+		}
+		payload, errPayload := flagged.GetDeserializedPayload()
+		if errPayload != nil {
+			return false, errPayload
+		}
+		if payload.ReferredUserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// earnPointsStep awards the points mapped to action, once the award rules
+// are evaluated, to the user returned by userID. Compensating the step
+// revokes the points it earned, which may differ from the current rule if
+// the rule has changed since. sagaType names the saga of the step in the
+// revocation reason
+func (s *userSaga) earnPointsStep(
+	sagaType string,
+	event eventsource.Event,
+	name string,
+	action loyalty.PointsAction,
+	userID func(context.Context) (string, error),
+) saga.Step {
+	commandID := sagaCommandID(event, name)
+
+	return saga.Step{
+		Name: name,
+		Action: func(ctx context.Context) error {
+			id, err := userID(ctx)
+			if err != nil {
+				return err
+			}
+
+			facts, errFacts := s.awardFacts(ctx, id, event)
+			if errFacts != nil {
+				return errFacts
+			}
+
+			award, errAward := s.pointsMapping.Award(action, facts)
+			if errAward != nil {
+				return errAward
+			}
+			// An award rule capped the points, e.g. past a yearly limit
+			if award.Points == 0 {
+				s.logger.Info(
+					"no points awarded",
+					zap.String("userId", id),
+					zap.String("action", string(action)),
+					zap.Strings("awardRuleIds", award.MatchedRuleIDs),
+				)
+				return nil
+			}
+
+			_, errDispatch := s.dispatcher.Dispatch(ctx, &loyalty.EarnPoints{
+				CommandModel: eventsource.CommandModel{
+					ID:        id,
+					CommandID: commandID,
+				},
+				Points:       award.Points,
+				RuleAction:   award.Action,
+				RuleVersion:  award.RuleVersion,
+				AwardRuleIDs: award.MatchedRuleIDs,
+			})
+			return errDispatch
 		},
-	)
+		Compensate: func(ctx context.Context) error {
+			id, err := userID(ctx)
+			if err != nil {
+				return err
+			}
+
+			points, errPoints := s.pointsEarnedBy(ctx, id, commandID)
+			if errPoints != nil || points == 0 {
+				return errPoints
+			}
+
+			_, errDispatch := s.dispatcher.Dispatch(ctx, &loyalty.RevokePoints{
+				CommandModel: eventsource.CommandModel{
+					ID:        id,
+					CommandID: sagaCommandID(event, name+":compensate"),
+				},
+				Points: points,
+				Reason: fmt.Sprintf("compensating %v of %v", name, sagaType),
+			})
+			return errDispatch
+		},
+	}
 }
 
-func (s *saga) handleReferUser(
+// pointsEarnedBy returns the points the user earned through the command
+// with commandID
+func (s *userSaga) pointsEarnedBy(
 	ctx context.Context,
-	event eventsource.Event,
-	referringUserID string,
-) error {
-	referrerPoints, errReferrerPoints := s.pointsMapping.
-		Map(loyalty.PointsActionReferUser)
-	if errReferrerPoints != nil {
-		return errReferrerPoints
+	userID string,
+	commandID string,
+) (uint32, error) {
+	history, err := s.store.LoadByCommandID(ctx, userID, commandID)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to load events of command %v", commandID)
 	}
 
-	errEarnPointsReferrer := s.dispatcher.Dispatch(ctx, &loyalty.EarnPoints{
-		CommandModel: eventsource.CommandModel{
-			ID: referringUserID,
-		},
-		Points: *referrerPoints,
-	})
-	if errEarnPointsReferrer != nil {
-		return errEarnPointsReferrer
+	earned := findEvent(history, user.PointsEarnedEventType)
+	if earned == nil {
+		return 0, nil
 	}
 
-	return nil
+	pointsEarnedEvent := user.PointsEarned{
+		ApplierModel: *eventsource.NewApplierModel(*earned),
+	}
+	payload, errPayload := pointsEarnedEvent.GetDeserializedPayload()
+	if errPayload != nil {
+		return 0, errPayload
+	}
+	return payload.PointsEarned, nil
 }
 
-func (s *saga) handleSignUpWithReferral(
+// referralCompletedBy returns the id of the referral the command with
+// commandID completed, or an empty string if it completed none
+func (s *userSaga) referralCompletedBy(
 	ctx context.Context,
-	event eventsource.Event,
-) error {
-	refereePoints, errRefereePoints := s.pointsMapping.
-		Map(loyalty.PointsActionSignUpWithReferral)
-	if errRefereePoints != nil {
-		return errRefereePoints
+	userID string,
+	commandID string,
+) (string, error) {
+	history, err := s.store.LoadByCommandID(ctx, userID, commandID)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to load events of command %v", commandID)
 	}
 
-	errEarnPointsReferee := s.dispatcher.Dispatch(ctx, &loyalty.EarnPoints{
-		CommandModel: eventsource.CommandModel{
-			ID: event.AggregateID,
-		},
-		Points: *refereePoints,
-	})
-	if errEarnPointsReferee != nil {
-		return errEarnPointsReferee
+	if completed := findEvent(history, user.UserReferralCompletedEventType); completed != nil {
+		return user.ReferralTransitionID(*completed)
 	}
 
+	// Referrals without an open invite are created completed
+	created := findEvent(history, user.UserReferralCreatedEventType)
+	if created == nil {
+		return "", nil
+	}
+	referralCreated := user.ReferralCreated{
+		ApplierModel: *eventsource.NewApplierModel(*created),
+	}
+	payload, errPayload := referralCreated.GetDeserializedPayload()
+	if errPayload != nil {
+		return "", errPayload
+	}
+	return payload.ReferralID, nil
+}
+
+// findProfileCompletion returns the ProfileUpdated event of history that
+// completed the profile, if any
+func findProfileCompletion(history eventsource.History) *eventsource.Event {
+	for _, v := range history {
+		if v.EventType != user.ProfileUpdatedEventType {
+			continue
+		}
+
+		updated := user.ProfileUpdated{
+			ApplierModel: *eventsource.NewApplierModel(v),
+		}
+		payload, err := updated.GetDeserializedPayload()
+		if err == nil && payload.ProfileCompleted {
+			event := v
+			return &event
+		}
+	}
 	return nil
 }
+
+func (s *userSaga) referringUserID(
+	ctx context.Context,
+	referralCode string,
+) (string, error) {
+	referringUser, err := s.repo.UserByReferralCode(ctx, referralCode)
+	if status.Code(err) == codes.NotFound {
+		return "", saga.Permanent(errors.Errorf(
+			"referring user not found for referral code: %v",
+			referralCode,
+		))
+	}
+	if err != nil {
+		return "", errors.Wrapf(
+			err,
+			"unable to load referring user for referral code: %v",
+			referralCode,
+		)
+	}
+	return referringUser.UserID, nil
+}
+
+/* ----- helpers ----- */
+
+// sagaCommandID derives the idempotency key of a command dispatched in
+// response to event so that redelivered events do not repeat a step
+func sagaCommandID(event eventsource.Event, step string) string {
+	return fmt.Sprintf("saga:%v:%v:%v", event.AggregateID, event.Version, step)
+}
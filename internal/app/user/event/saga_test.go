@@ -0,0 +1,564 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource/eventsourcetest"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/saga/sagatest"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/* ----- tests ----- */
+func TestDecideRecovery(t *testing.T) {
+	created := newCreatedEvent(t, "user-1", nil)
+	earned := *eventsource.NewEvent("user-1", user.PointsEarnedEventType, 2, nil)
+	deleted := *eventsource.NewEvent("user-1", user.UserDeletedEventType, 2, nil)
+
+	tests := []struct {
+		name     string
+		instance *saga.Instance
+		history  eventsource.History
+		run      bool
+	}{
+		{
+			name:    "never started",
+			history: eventsource.History{created},
+			run:     true,
+		},
+		{
+			name:    "points earned before sagas were persisted",
+			history: eventsource.History{created, earned},
+		},
+		{
+			name:     "stuck",
+			instance: &saga.Instance{Status: saga.StatusRunning},
+			history:  eventsource.History{created},
+			run:      true,
+		},
+		{
+			name:     "stuck after points earned",
+			instance: &saga.Instance{Status: saga.StatusRunning},
+			history:  eventsource.History{created, earned},
+			run:      true,
+		},
+		{
+			name:     "completed",
+			instance: &saga.Instance{Status: saga.StatusCompleted},
+			history:  eventsource.History{created, earned},
+		},
+		{
+			name:     "compensated",
+			instance: &saga.Instance{Status: saga.StatusCompensated},
+			history:  eventsource.History{created},
+		},
+		{
+			name:     "failed",
+			instance: &saga.Instance{Status: saga.StatusFailed},
+			history:  eventsource.History{created},
+		},
+		{
+			name:     "deleted",
+			instance: &saga.Instance{Status: saga.StatusRunning},
+			history:  eventsource.History{created, deleted},
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			decision := decideRecovery(v.instance, v.history)
+			assert.Equal(t, v.run, decision.Run)
+			assert.NotEmpty(t, decision.Reason)
+		})
+	}
+}
+
+func TestSaga_SyncFinishesStuckSaga(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	referralCode := "ref-1"
+	created := newCreatedEvent(t, "user-1", &referralCode)
+	f := newSagaFixture(t, eventsource.History{created})
+
+	// The referrer's points were awarded before the restart
+	f.dispatcher.fail = errors.New("unavailable")
+	f.dispatcher.failAfter = 2
+	assert.NotNil(f.saga.Handle(ctx, created))
+
+	f.dispatcher.fail = nil
+	assert.Nil(f.saga.Sync(ctx, "user-1"))
+
+	assert.Equal([]string{
+		"CompleteReferral:referrer-1",
+		"EarnPoints:referrer-1",
+		"EarnPoints:user-1",
+	}, f.dispatcher.dispatched())
+
+	instance, _ := f.runner.Instance(ctx, SignUpSagaType, "user-1")
+	assert.Equal(saga.StatusCompleted, instance.Status)
+
+	// Syncing a completed saga does nothing
+	assert.Nil(f.saga.Sync(ctx, "user-1"))
+	assert.Len(f.dispatcher.dispatched(), 3)
+}
+
+func TestSaga_RecoverResumesInterruptedSagas(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	interrupted := newCreatedEvent(t, "user-1", nil)
+	completed := newCreatedEvent(t, "user-2", nil)
+	unstarted := newCreatedEvent(t, "user-3", nil)
+	f := newSagaFixture(t, eventsource.History{interrupted, completed, unstarted})
+	assert.Nil(f.saga.Handle(ctx, completed))
+
+	f.dispatcher.fail = errors.New("unavailable")
+	f.dispatcher.failAfter = 1
+	assert.NotNil(f.saga.Handle(ctx, interrupted))
+
+	// The running instance is resumed, then the user whose UserCreated
+	// event was never handled is started
+	f.dispatcher.fail = nil
+	assert.Nil(f.saga.Recover(ctx))
+	assert.Equal([]string{
+		"EarnPoints:user-2",
+		"EarnPoints:user-1",
+		"EarnPoints:user-3",
+	}, f.dispatcher.dispatched())
+
+	for _, v := range []string{"user-1", "user-3"} {
+		instance, _ := f.runner.Instance(ctx, SignUpSagaType, v)
+		if assert.NotNil(instance, v) {
+			assert.Equal(saga.StatusCompleted, instance.Status, v)
+		}
+	}
+}
+
+func TestSaga_UnknownReferralCodeCompensates(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	referralCode := "unknown"
+	created := newCreatedEvent(t, "user-1", &referralCode)
+	f := newSagaFixture(t, eventsource.History{created})
+
+	assert.NotNil(f.saga.Handle(ctx, created))
+
+	instance, _ := f.runner.Instance(ctx, SignUpSagaType, "user-1")
+	assert.Equal(saga.StatusCompensated, instance.Status)
+	assert.Empty(f.dispatcher.dispatched())
+}
+
+func TestSaga_FlaggedReferralEarnsNoReferralPoints(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	referralCode := "ref-1"
+	created := newCreatedEvent(t, "user-1", &referralCode)
+	flagged := user.NewReferralFlaggedApplier(
+		"referrer-1",
+		user.UserReferralFlaggedEventType,
+		2,
+	)
+	err := flagged.SetSerializedPayload(user.ReferralFlaggedPayload{
+		ReferredUserEmail: "user-1@example.com",
+		ReferredUserID:    "user-1",
+		Reason:            user.ReferralFlagBlockedDomain,
+		Command:           "CompleteReferral",
+	})
+	assert.Nil(err)
+
+	f := newSagaFixture(t, eventsource.History{created, flagged.EventModel()})
+	assert.Nil(f.saga.Handle(ctx, created))
+
+	assert.Equal([]string{"CompleteReferral:referrer-1"}, f.dispatcher.dispatched())
+	instance, _ := f.runner.Instance(ctx, SignUpSagaType, "user-1")
+	assert.Equal(saga.StatusCompleted, instance.Status)
+}
+
+func TestSaga_CompensationCancelsCompletedReferral(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	referralCode := "ref-1"
+	created := newCreatedEvent(t, "user-1", &referralCode)
+	completedEvent := newReferralCompletedEvent(t, "referrer-1", "referral-1", 3)
+	completedEvent.CommandID = sagaCommandID(created, "complete-referral")
+
+	f := newSagaFixture(t, eventsource.History{
+		created,
+		newCreatedEvent(t, "referrer-1", nil),
+		newReferralCreatedEvent(t, "referrer-1", "referral-1", 2),
+		completedEvent,
+	})
+	f.dispatcher.fail = saga.Permanent(errors.New("rejected"))
+	f.dispatcher.failOn = "EarnPoints"
+
+	assert.NotNil(f.saga.Handle(ctx, created))
+	assert.Equal([]string{
+		"CompleteReferral:referrer-1",
+		"CancelReferral:referrer-1",
+	}, f.dispatcher.dispatched())
+
+	instance, _ := f.runner.Instance(ctx, SignUpSagaType, "user-1")
+	assert.Equal(saga.StatusCompensated, instance.Status)
+	assert.Equal([]string{"complete-referral"}, instance.CompensatedSteps)
+}
+
+func TestSaga_AwardRulesChangeEarnedPoints(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	referralCode := "ref-1"
+	created := newCreatedEvent(t, "user-1", &referralCode)
+
+	// The referrer completed 10 referrals this year
+	history := eventsource.History{created, newCreatedEvent(t, "referrer-1", nil)}
+	referrerVersion := 1
+	for i := 0; i < 10; i++ {
+		history = append(history, newCompletedReferralEvents(
+			t,
+			"referrer-1",
+			fmt.Sprintf("referral-%v", i),
+			referrerVersion,
+			created.EventAt,
+		)...)
+		referrerVersion += 2
+	}
+
+	f := newSagaFixtureWithRules(t, history, []loyalty.AwardRule{
+		{
+			ID:     "double-new-users",
+			Action: loyalty.PointsActionSignUpWithReferral,
+			Conditions: []loyalty.AwardCondition{
+				{Fact: loyalty.FactUserCreatedAt, Operator: loyalty.OperatorInCurrentMonth},
+			},
+			Effect: loyalty.AwardEffect{Type: loyalty.EffectMultiply, Value: 2},
+		},
+		{
+			ID:     "cap-referrals",
+			Action: loyalty.PointsActionReferUser,
+			Conditions: []loyalty.AwardCondition{
+				{
+					Fact:     loyalty.FactUserReferralsCompletedThisYear,
+					Operator: loyalty.OperatorGt,
+					Value:    float64(10),
+				},
+			},
+			Effect: loyalty.AwardEffect{Type: loyalty.EffectSet, Value: 0},
+		},
+	})
+
+	// The referrer is under the cap
+	assert.Nil(f.saga.Handle(ctx, created))
+	assert.Equal([]string{
+		"CompleteReferral:referrer-1",
+		"EarnPoints:referrer-1",
+		"EarnPoints:user-1",
+	}, f.dispatcher.dispatched())
+
+	earned := f.dispatcher.earned
+	assert.Equal(uint32(200), earned[0].Points)
+	assert.Empty(earned[0].AwardRuleIDs)
+	assert.Equal(uint32(400), earned[1].Points)
+	assert.Equal([]string{"double-new-users"}, earned[1].AwardRuleIDs)
+	assert.Equal(1, earned[1].RuleVersion)
+
+	// Past the cap the referrer earns nothing
+	assert.Nil(f.store.Save(ctx, newCompletedReferralEvents(
+		t,
+		"referrer-1",
+		"referral-10",
+		referrerVersion,
+		created.EventAt,
+	)...))
+	second := newCreatedEvent(t, "user-2", &referralCode)
+	assert.Nil(f.saga.Handle(ctx, second))
+	assert.Equal([]string{
+		"CompleteReferral:referrer-1",
+		"EarnPoints:user-2",
+	}, f.dispatcher.dispatched()[3:])
+}
+
+func TestSaga_ProfileCompletionAwardedOnce(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	created := newCreatedEvent(t, "user-1", nil)
+	partial := newProfileUpdatedEvent(t, "user-1", 2, false)
+	completed := newProfileUpdatedEvent(t, "user-1", 3, true)
+	f := newSagaFixture(t, eventsource.History{created, partial, completed})
+
+	assert.Nil(f.saga.Handle(ctx, partial))
+	assert.Empty(f.dispatcher.dispatched())
+
+	// The award fails until the dispatcher recovers, then Sync retries it
+	f.dispatcher.fail = errors.New("unavailable")
+	assert.NotNil(f.saga.Handle(ctx, completed))
+	f.dispatcher.fail = nil
+
+	instance, _ := f.runner.Instance(ctx, SignUpSagaType, "user-1")
+	assert.Nil(instance)
+	assert.Nil(f.saga.Sync(ctx, "user-1"))
+
+	earned := f.dispatcher.earned
+	assert.Len(earned, 2)
+	assert.Equal(uint32(100), earned[0].Points)
+	assert.Equal(loyalty.PointsActionCompleteProfile, earned[1].RuleAction)
+	assert.Equal(uint32(50), earned[1].Points)
+
+	// Redelivered and synced again once done, nothing more is awarded
+	assert.Nil(f.saga.Handle(ctx, completed))
+	assert.Nil(f.saga.Sync(ctx, "user-1"))
+	assert.Len(f.dispatcher.earned, 2)
+
+	profile, _ := f.runner.Instance(ctx, ProfileCompletionSagaType, "user-1")
+	assert.Equal(saga.StatusCompleted, profile.Status)
+}
+
+func TestSaga_EarnPointsCompensationNamesSaga(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	completed := newProfileUpdatedEvent(t, "user-1", 2, true)
+	f := newSagaFixture(t, eventsource.History{
+		newCreatedEvent(t, "user-1", nil),
+		completed,
+	})
+
+	earned := user.NewPointsEarnedApplier("user-1", user.PointsEarnedEventType, 3)
+	assert.Nil(earned.SetSerializedPayload(user.PointsEarnedPayload{
+		PointsEarned: 50,
+	}))
+	earnedEvent := earned.EventModel()
+	earnedEvent.CommandID = sagaCommandID(completed, "complete-profile")
+	assert.Nil(f.store.Save(ctx, earnedEvent))
+
+	step := f.saga.(*userSaga).earnPointsStep(
+		ProfileCompletionSagaType,
+		completed,
+		"complete-profile",
+		loyalty.PointsActionCompleteProfile,
+		func(context.Context) (string, error) {
+			return "user-1", nil
+		},
+	)
+	assert.Nil(step.Compensate(ctx))
+
+	if assert.Len(f.dispatcher.revoked, 1) {
+		assert.Equal(uint32(50), f.dispatcher.revoked[0].Points)
+		assert.Equal(
+			"compensating complete-profile of ProfileCompletion",
+			f.dispatcher.revoked[0].Reason,
+		)
+	}
+}
+
+/* ----- helpers ----- */
+type sagaFixture struct {
+	saga       Saga
+	runner     saga.Runner
+	store      *eventsourcetest.MemoryStore
+	dispatcher *recordingDispatcher
+}
+
+func newSagaFixture(t *testing.T, history eventsource.History) *sagaFixture {
+	return newSagaFixtureWithRules(t, history, nil)
+}
+
+func newSagaFixtureWithRules(
+	t *testing.T,
+	history eventsource.History,
+	awardRules []loyalty.AwardRule,
+) *sagaFixture {
+	logger := zaptest.NewLogger(t)
+	rules, err := loyalty.NewPointsRulesService(
+		context.Background(),
+		loyalty.PointsRulesParams{
+			Store:      loyalty.NewMemoryPointsRulesStore(),
+			AwardRules: awardRules,
+			Logger:     logger,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(rules.Stop)
+
+	dispatcher := &recordingDispatcher{}
+	store := eventsourcetest.NewMemoryStore(history...)
+	runner := saga.NewRunner(saga.RunnerParams{
+		Store:  sagatest.NewMemoryStore(),
+		Logger: logger,
+	})
+
+	return &sagaFixture{
+		saga: NewSaga(SagaParams{
+			Dispatcher:    dispatcher,
+			Runner:        runner,
+			Store:         store,
+			Repo:          &referralReadRepo{},
+			PointsMapping: rules,
+			Logger:        logger,
+		}),
+		runner:     runner,
+		store:      store,
+		dispatcher: dispatcher,
+	}
+}
+
+func newCreatedEvent(
+	t *testing.T,
+	userID string,
+	referredByCode *string,
+) eventsource.Event {
+	applier := user.NewCreatedApplier(userID, user.UserCreatedEventType, 1)
+	err := applier.SetSerializedPayload(user.CreatedPayload{
+		Username:       userID,
+		Email:          userID + "@example.com",
+		ReferredByCode: referredByCode,
+		ReferralCode:   "code-" + userID,
+	})
+	assert.Nil(t, err)
+	return applier.EventModel()
+}
+
+func newReferralCompletedEvent(
+	t *testing.T,
+	userID string,
+	referralID string,
+	version int,
+) eventsource.Event {
+	return newReferralEvent(
+		t,
+		user.NewReferralCompletedApplier(
+			userID,
+			user.UserReferralCompletedEventType,
+			version,
+		),
+		user.ReferralCompletedPayload{ReferralID: referralID},
+	)
+}
+
+// newCompletedReferralEvents returns the events creating and completing a
+// referral of userID at eventAt, following the given version
+func newCompletedReferralEvents(
+	t *testing.T,
+	userID string,
+	referralID string,
+	version int,
+	eventAt time.Time,
+) eventsource.History {
+	history := eventsource.History{
+		newReferralCreatedEvent(t, userID, referralID, version+1),
+		newReferralCompletedEvent(t, userID, referralID, version+2),
+	}
+	for i := range history {
+		history[i].EventAt = eventAt
+	}
+	return history
+}
+
+func newProfileUpdatedEvent(
+	t *testing.T,
+	userID string,
+	version int,
+	completed bool,
+) eventsource.Event {
+	name := "Ada Lovelace"
+	applier := user.NewProfileUpdatedApplier(
+		userID,
+		user.ProfileUpdatedEventType,
+		version,
+	)
+	err := applier.SetSerializedPayload(user.ProfileUpdatedPayload{
+		Name:             &name,
+		ProfileCompleted: completed,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return applier.EventModel()
+}
+
+type recordingDispatcher struct {
+	eventsource.CommandDispatcher
+
+	mu        sync.Mutex
+	fail      error
+	failAfter int
+	failOn    string
+	commands  []string
+	earned    []loyalty.EarnPoints
+	revoked   []loyalty.RevokePoints
+}
+
+func (d *recordingDispatcher) Dispatch(
+	ctx context.Context,
+	cmd eventsource.Command,
+) (*eventsource.CommandResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	name := "unknown"
+	switch cmd.(type) {
+	case *loyalty.CancelReferral:
+		name = "CancelReferral"
+	case *loyalty.CompleteReferral:
+		name = "CompleteReferral"
+	case *loyalty.EarnPoints:
+		name = "EarnPoints"
+	case *loyalty.RevokePoints:
+		name = "RevokePoints"
+	case *loyalty.ExpireReferral:
+		name = "ExpireReferral"
+	case *loyalty.MarkReferralSent:
+		name = "MarkReferralSent"
+	}
+
+	if d.fail != nil && len(d.commands) >= d.failAfter &&
+		(d.failOn == "" || d.failOn == name) {
+		return nil, d.fail
+	}
+	if earn, ok := cmd.(*loyalty.EarnPoints); ok {
+		d.earned = append(d.earned, *earn)
+	}
+	if revoke, ok := cmd.(*loyalty.RevokePoints); ok {
+		d.revoked = append(d.revoked, *revoke)
+	}
+	d.commands = append(d.commands, name+":"+cmd.AggregateID())
+	return eventsource.NewCommandResult(cmd.AggregateID(), nil), nil
+}
+
+func (d *recordingDispatcher) dispatched() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string{}, d.commands...)
+}
+
+// referralReadRepo resolves the referral code ref-1 to referrer-1
+type referralReadRepo struct {
+	user.ReadRepo
+}
+
+func (r *referralReadRepo) UserByReferralCode(
+	ctx context.Context,
+	referralCode string,
+) (*user.DTO, error) {
+	if referralCode != "ref-1" {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	return &user.DTO{UserID: "referrer-1"}, nil
+}
@@ -20,6 +20,16 @@ func NewPointsEarnedApplier(
 
 type PointsEarnedPayload struct {
 	PointsEarned uint32 `json:"pointsEarned,omitempty"`
+
+	// RuleAction and RuleVersion identify the points rule that granted
+	// the points. They are empty for points granted before rules were
+	// versioned
+	RuleAction  string `json:"ruleAction,omitempty"`
+	RuleVersion int    `json:"ruleVersion,omitempty"`
+
+	// AwardRuleIDs are the award rules that changed the points of the
+	// points rule, in the order they were applied
+	AwardRuleIDs []string `json:"awardRuleIds,omitempty"`
 }
 
 // Apply implements the applier interface
@@ -0,0 +1,105 @@
+package user
+
+import (
+	"context"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+)
+
+// PointsRedeemed event is fired when a user spends points
+type PointsRedeemed struct {
+	eventsource.ApplierModel
+}
+
+func NewPointsRedeemedApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &PointsRedeemed{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+type PointsRedeemedPayload struct {
+	PointsRedeemed uint32 `json:"pointsRedeemed,omitempty"`
+
+	// Balance is the user's points after the redemption
+	Balance uint32 `json:"balance"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Apply implements the applier interface
+func (applier *PointsRedeemed) Apply(agg eventsource.Aggregate) error {
+	userAggregate, err := AssertUserAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+	if payload.PointsRedeemed > userAggregate.Points {
+		return errors.New("RedeemPoints event must not exceed the user's points")
+	}
+
+	userAggregate.Points -= payload.PointsRedeemed
+	userAggregate.Version = applier.Version
+	return nil
+}
+
+func (applier *PointsRedeemed) SetSerializedPayload(payload interface{}) error {
+	pointsRedeemedEvent, ok := payload.(PointsRedeemedPayload)
+	if !ok {
+		return applier.PayloadErr("user.PointsRedeemed.SetSerializedPayload", payload)
+	}
+	return applier.Serialize(pointsRedeemedEvent)
+}
+
+func (applier *PointsRedeemed) GetDeserializedPayload() (
+	*PointsRedeemedPayload,
+	error,
+) {
+	var payload PointsRedeemedPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsZero(payload.PointsRedeemed) {
+		return nil, applier.PayloadErr(
+			"user.PointsRedeemed.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
+
+// LoadPointsRedeemed returns the payload of the PointsRedeemed event of the
+// user at version, e.g. to report the balance after a redemption
+func LoadPointsRedeemed(
+	ctx context.Context,
+	store EventStore,
+	userID string,
+	version int,
+) (*PointsRedeemedPayload, error) {
+	history, err := store.Load(ctx, userID, version-1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load history of user %v", userID)
+	}
+	if len(history) == 0 ||
+		history[0].Version != version ||
+		history[0].EventType != PointsRedeemedEventType {
+		return nil, errors.Errorf(
+			"user %v has no PointsRedeemed event at version %v",
+			userID,
+			version,
+		)
+	}
+
+	applier := PointsRedeemed{
+		ApplierModel: *eventsource.NewApplierModel(history[0]),
+	}
+	return applier.GetDeserializedPayload()
+}
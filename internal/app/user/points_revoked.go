@@ -0,0 +1,73 @@
+package user
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+)
+
+// PointsRevoked event is fired when previously earned points are taken
+// back, e.g. when the action that earned them is compensated
+type PointsRevoked struct {
+	eventsource.ApplierModel
+}
+
+func NewPointsRevokedApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &PointsRevoked{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+type PointsRevokedPayload struct {
+	PointsRevoked uint32 `json:"pointsRevoked,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// Apply implements the applier interface
+func (applier *PointsRevoked) Apply(agg eventsource.Aggregate) error {
+	userAggregate, err := AssertUserAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+	if payload.PointsRevoked > userAggregate.Points {
+		return errors.New("RevokePoints event must not exceed the user's points")
+	}
+
+	userAggregate.Points -= payload.PointsRevoked
+	userAggregate.Version = applier.Version
+	return nil
+}
+
+func (applier *PointsRevoked) SetSerializedPayload(payload interface{}) error {
+	pointsRevokedEvent, ok := payload.(PointsRevokedPayload)
+	if !ok {
+		return applier.PayloadErr("user.PointsRevoked.SetSerializedPayload", payload)
+	}
+	return applier.Serialize(pointsRevokedEvent)
+}
+
+func (applier *PointsRevoked) GetDeserializedPayload() (
+	*PointsRevokedPayload,
+	error,
+) {
+	var payload PointsRevokedPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsZero(payload.PointsRevoked) {
+		return nil, applier.PayloadErr(
+			"user.PointsRevoked.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
@@ -0,0 +1,106 @@
+package user
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// Profile holds the personal details a user can fill in. Birthday is
+// formatted as 2006-01-02
+type Profile struct {
+	Name     string `json:"name" firestore:"name"`
+	Birthday string `json:"birthday" firestore:"birthday"`
+	Phone    string `json:"phone" firestore:"phone"`
+}
+
+// Complete returns whether every field of the profile is filled in
+func (p Profile) Complete() bool {
+	return p.Name != "" && p.Birthday != "" && p.Phone != ""
+}
+
+// Merge returns the profile with the fields set in payload changed
+func (p Profile) Merge(payload ProfileUpdatedPayload) Profile {
+	if payload.Name != nil {
+		p.Name = *payload.Name
+	}
+	if payload.Birthday != nil {
+		p.Birthday = *payload.Birthday
+	}
+	if payload.Phone != nil {
+		p.Phone = *payload.Phone
+	}
+	return p
+}
+
+// ProfileUpdated event is fired when a user changes their profile
+type ProfileUpdated struct {
+	eventsource.ApplierModel
+}
+
+func NewProfileUpdatedApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &ProfileUpdated{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+// ProfileUpdatedPayload holds the profile fields that changed
+type ProfileUpdatedPayload struct {
+	Name     *string `json:"name,omitempty"`
+	Birthday *string `json:"birthday,omitempty"`
+	Phone    *string `json:"phone,omitempty"`
+
+	// ProfileCompleted is set when the update completes the profile for
+	// the first time, which earns the profile completion points
+	ProfileCompleted bool `json:"profileCompleted,omitempty"`
+}
+
+// Apply implements the applier interface
+func (applier *ProfileUpdated) Apply(agg eventsource.Aggregate) error {
+	userAggregate, err := AssertUserAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	userAggregate.Profile = userAggregate.Profile.Merge(*payload)
+	if payload.ProfileCompleted {
+		completedAt := applier.EventAt
+		userAggregate.ProfileCompletedAt = &completedAt
+	}
+	userAggregate.UpdatedAt = applier.EventAt
+	userAggregate.Version = applier.Version
+	return nil
+}
+
+func (applier *ProfileUpdated) SetSerializedPayload(payload interface{}) error {
+	profileUpdatedEvent, ok := payload.(ProfileUpdatedPayload)
+	if !ok {
+		return applier.PayloadErr("user.ProfileUpdated.SetSerializedPayload", payload)
+	}
+	return applier.Serialize(profileUpdatedEvent)
+}
+
+func (applier *ProfileUpdated) GetDeserializedPayload() (
+	*ProfileUpdatedPayload,
+	error,
+) {
+	var payload ProfileUpdatedPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if payload.Name == nil && payload.Birthday == nil && payload.Phone == nil {
+		return nil, applier.PayloadErr(
+			"user.ProfileUpdated.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
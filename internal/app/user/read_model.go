@@ -19,6 +19,10 @@ type DTO struct {
 	ReferralCode   string    `json:"referralCode" firestore:"referralCode"`
 	CreatedAt      time.Time `json:"createdAt" firestore:"createdAt"`
 	UpdatedAt      time.Time `json:"updatedAt" firestore:"updatedAt"`
+	Profile        Profile   `json:"profile" firestore:"profile"`
+
+	// ProfileCompletedAt is when the profile was first completed
+	ProfileCompletedAt *time.Time `json:"profileCompletedAt" firestore:"profileCompletedAt"`
 }
 
 // EventVersion returns the last event version processed
@@ -58,3 +62,34 @@ func (r *readModel) Referrals(
 ) ([]Referral, error) {
 	return r.readRepo.Referrals(ctx, userID)
 }
+
+// RebuildStatus reports the progress of a read model rebuild
+type RebuildStatus struct {
+	Collection         string `json:"collection"`
+	PreviousCollection string `json:"previousCollection"`
+	EventsProcessed    int    `json:"eventsProcessed"`
+	TotalEvents        int    `json:"totalEvents"`
+	InProgress         bool   `json:"inProgress"`
+
+	// LastError is why the last rebuild failed, if it did
+	LastError *string `json:"lastError"`
+}
+
+// ReadModelRebuilder regenerates the user read model from the event store
+type ReadModelRebuilder interface {
+	// Rebuild starts projecting every event stream into a new collection
+	// in the background and switches the read model over to it once
+	// complete. Its progress is reported by Status
+	Rebuild(ctx context.Context) (*RebuildStatus, error)
+
+	// Rollback switches the read model back to the previous collection
+	Rollback(ctx context.Context) (*RebuildStatus, error)
+
+	// Status returns the active and previous collections of the read model
+	// and the progress of the last rebuild started by this instance
+	Status(ctx context.Context) (*RebuildStatus, error)
+
+	// Follow reloads the live projector's checkpoint whenever another
+	// instance switches the read model. It blocks until ctx is done
+	Follow(ctx context.Context) error
+}
@@ -2,16 +2,49 @@ package user
 
 import (
 	"context"
+	"time"
 )
 
 type ReadRepo interface {
 	CreateUser(ctx context.Context, user DTO) error
 	CreateReferral(ctx context.Context, userID string, referral Referral, version int) error
 	EarnPoints(ctx context.Context, userID string, points uint32, version int) error
-	UpdateReferralStatus(ctx context.Context, userID string, referralID string, status ReferralStatus, version int) error
+	RevokePoints(ctx context.Context, userID string, points uint32, version int) error
+	RedeemPoints(ctx context.Context, userID string, points uint32, version int) error
+	// UpdateReferralStatus records that the referral moved to status at updatedAt
+	UpdateReferralStatus(ctx context.Context, userID string, referralID string, status ReferralStatus, updatedAt time.Time, version int) error
+	// UpdateProfile replaces the profile of the user and, when completedAt
+	// is set, records when it was first completed
+	UpdateProfile(ctx context.Context, userID string, profile Profile, completedAt *time.Time, version int) error
 	DeleteUser(ctx context.Context, userID string) error
 	Users(context.Context) ([]DTO, error)
 	User(ctx context.Context, userID string) (*DTO, error)
 	Referrals(ctx context.Context, userID string) ([]Referral, error)
 	UserByReferralCode(ctx context.Context, referralCode string) (*DTO, error)
 }
+
+// SwitchableReadRepo is a ReadRepo that can be regenerated into a shadow
+// collection and switched over to it. The previous collection is kept so
+// that a switch can be rolled back
+type SwitchableReadRepo interface {
+	ReadRepo
+
+	// ActiveCollection returns the collection currently serving the read model
+	ActiveCollection(ctx context.Context) (string, error)
+
+	// PreviousCollection returns the collection that was active before the last switch
+	PreviousCollection(ctx context.Context) (string, error)
+
+	// Shadow returns a ReadRepo bound to the given collection
+	Shadow(collection string) ReadRepo
+
+	// SwitchCollection atomically makes collection the active collection
+	SwitchCollection(ctx context.Context, collection string) error
+
+	// WatchCollection calls onSwitch whenever the active collection is
+	// switched, including by another instance, until ctx is done
+	WatchCollection(
+		ctx context.Context,
+		onSwitch func(ctx context.Context, collection string),
+	) error
+}
@@ -0,0 +1,83 @@
+package user
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// ReferralCancelled event is fired when an open referral is withdrawn
+type ReferralCancelled struct {
+	eventsource.ApplierModel
+}
+
+type ReferralCancelledPayload struct {
+	ReferralID string `json:"referralId,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+func NewReferralCancelledApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &ReferralCancelled{
+		ApplierModel: *eventsource.NewApplierModel(*event),
+	}
+}
+
+// Apply implements the applier interface
+func (applier *ReferralCancelled) Apply(agg eventsource.Aggregate) error {
+	userAggregate, err := AssertUserAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	errStatus := userAggregate.applyReferralStatus(
+		payload.ReferralID,
+		ReferralStatusCancelled,
+		applier.EventAt,
+	)
+	if errStatus != nil {
+		return errStatus
+	}
+
+	userAggregate.Version = applier.Version
+	return nil
+}
+
+func (applier *ReferralCancelled) SetSerializedPayload(
+	payload interface{},
+) error {
+	referralCancelledPayload, ok := payload.(ReferralCancelledPayload)
+	if !ok {
+		return applier.PayloadErr(
+			"user.ReferralCancelled.SetSerializedPayload",
+			payload,
+		)
+	}
+	return applier.Serialize(referralCancelledPayload)
+}
+
+func (applier *ReferralCancelled) GetDeserializedPayload() (
+	*ReferralCancelledPayload,
+	error,
+) {
+	var payload ReferralCancelledPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsAnyStringEmpty(&payload.ReferralID) {
+		return nil, applier.PayloadErr(
+			"user.ReferralCancelled.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
@@ -4,13 +4,14 @@ import (
 	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
 )
 
-// ReferralCompleted event is fired when a new user referral is created
+// ReferralCompleted event is fired when the referred user signs up
 type ReferralCompleted struct {
 	eventsource.ApplierModel
 }
 
 type ReferralCompletedPayload struct {
-	ReferralID string `json:"referralId,omitempty"`
+	ReferralID     string `json:"referralId,omitempty"`
+	ReferredUserID string `json:"referredUserId,omitempty"`
 }
 
 func NewReferralCompletedApplier(
@@ -35,10 +36,16 @@ func (applier *ReferralCompleted) Apply(agg eventsource.Aggregate) error {
 		return errDeserialize
 	}
 
-	for _, v := range userAggregate.Referrals {
-		if v.ID == payload.ReferralID {
-			v.Status = ReferralStatusCreated
-		}
+	errStatus := userAggregate.applyReferralStatus(
+		payload.ReferralID,
+		ReferralStatusCompleted,
+		applier.EventAt,
+	)
+	if errStatus != nil {
+		return errStatus
+	}
+	if payload.ReferredUserID != "" {
+		userAggregate.Referral(payload.ReferralID).ReferredUserID = payload.ReferredUserID
 	}
 
 	userAggregate.Version = applier.Version
@@ -25,6 +25,9 @@ type ReferralCreatedPayload struct {
 	ReferralCode      string `json:"referralCode,omitempty"`
 	ReferralID        string `json:"referralId,omitempty"`
 	ReferralStatus    string `json:"referralStatus,omitempty"`
+
+	// ReferredUserID is set on referrals created completed
+	ReferredUserID string `json:"referredUserId,omitempty"`
 }
 
 // Apply implements the applier interface
@@ -48,7 +51,10 @@ func (applier *ReferralCreated) Apply(agg eventsource.Aggregate) error {
 		ID:                payload.ReferralID,
 		ReferralCode:      payload.ReferralCode,
 		ReferredUserEmail: payload.ReferredUserEmail,
+		ReferredUserID:    payload.ReferredUserID,
 		Status:            status,
+		CreatedAt:         applier.EventAt,
+		UpdatedAt:         applier.EventAt,
 	}
 
 	userAggregate.Version = applier.Version
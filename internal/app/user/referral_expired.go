@@ -0,0 +1,82 @@
+package user
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// ReferralExpired event is fired when a referral is not completed in time
+type ReferralExpired struct {
+	eventsource.ApplierModel
+}
+
+type ReferralExpiredPayload struct {
+	ReferralID string `json:"referralId,omitempty"`
+}
+
+func NewReferralExpiredApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &ReferralExpired{
+		ApplierModel: *eventsource.NewApplierModel(*event),
+	}
+}
+
+// Apply implements the applier interface
+func (applier *ReferralExpired) Apply(agg eventsource.Aggregate) error {
+	userAggregate, err := AssertUserAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	errStatus := userAggregate.applyReferralStatus(
+		payload.ReferralID,
+		ReferralStatusExpired,
+		applier.EventAt,
+	)
+	if errStatus != nil {
+		return errStatus
+	}
+
+	userAggregate.Version = applier.Version
+	return nil
+}
+
+func (applier *ReferralExpired) SetSerializedPayload(
+	payload interface{},
+) error {
+	referralExpiredPayload, ok := payload.(ReferralExpiredPayload)
+	if !ok {
+		return applier.PayloadErr(
+			"user.ReferralExpired.SetSerializedPayload",
+			payload,
+		)
+	}
+	return applier.Serialize(referralExpiredPayload)
+}
+
+func (applier *ReferralExpired) GetDeserializedPayload() (
+	*ReferralExpiredPayload,
+	error,
+) {
+	var payload ReferralExpiredPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsAnyStringEmpty(&payload.ReferralID) {
+		return nil, applier.PayloadErr(
+			"user.ReferralExpired.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
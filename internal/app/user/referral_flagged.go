@@ -0,0 +1,118 @@
+package user
+
+import (
+	"context"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+)
+
+// ReferralFlagged event is fired when a referral is rejected by the
+// ReferralPolicy. Flagged referrals are kept for review and do not count
+// towards the referral limits
+type ReferralFlagged struct {
+	eventsource.ApplierModel
+}
+
+type ReferralFlaggedPayload struct {
+	ReferredUserEmail string             `json:"referredUserEmail,omitempty"`
+	ReferredUserID    string             `json:"referredUserId,omitempty"`
+	Reason            ReferralFlagReason `json:"reason,omitempty"`
+
+	// Command is the name of the rejected command, e.g. CreateReferral
+	Command string `json:"command,omitempty"`
+}
+
+func NewReferralFlaggedApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &ReferralFlagged{
+		ApplierModel: *eventsource.NewApplierModel(*event),
+	}
+}
+
+// Apply implements the applier interface
+func (applier *ReferralFlagged) Apply(agg eventsource.Aggregate) error {
+	userAggregate, err := AssertUserAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	userAggregate.ReferralFlags = append(userAggregate.ReferralFlags, ReferralFlag{
+		ReferredUserEmail: payload.ReferredUserEmail,
+		ReferredUserID:    payload.ReferredUserID,
+		Reason:            payload.Reason,
+		FlaggedAt:         applier.EventAt,
+	})
+	userAggregate.Version = applier.Version
+	return nil
+}
+
+func (applier *ReferralFlagged) SetSerializedPayload(
+	payload interface{},
+) error {
+	referralFlaggedPayload, ok := payload.(ReferralFlaggedPayload)
+	if !ok {
+		return applier.PayloadErr(
+			"user.ReferralFlagged.SetSerializedPayload",
+			payload,
+		)
+	}
+	return applier.Serialize(referralFlaggedPayload)
+}
+
+func (applier *ReferralFlagged) GetDeserializedPayload() (
+	*ReferralFlaggedPayload,
+	error,
+) {
+	var payload ReferralFlaggedPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	reason := string(payload.Reason)
+	if eventsource.IsAnyStringEmpty(&payload.ReferredUserEmail, &reason) {
+		return nil, applier.PayloadErr(
+			"user.ReferralFlagged.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
+
+// LoadReferralFlagged returns the payload of the UserReferralFlagged event
+// of the user at version, e.g. to report why a referral was rejected
+func LoadReferralFlagged(
+	ctx context.Context,
+	store EventStore,
+	userID string,
+	version int,
+) (*ReferralFlaggedPayload, error) {
+	history, err := store.Load(ctx, userID, version-1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load history of user %v", userID)
+	}
+	if len(history) == 0 ||
+		history[0].Version != version ||
+		history[0].EventType != UserReferralFlaggedEventType {
+		return nil, errors.Errorf(
+			"user %v has no UserReferralFlagged event at version %v",
+			userID,
+			version,
+		)
+	}
+
+	applier := ReferralFlagged{
+		ApplierModel: *eventsource.NewApplierModel(history[0]),
+	}
+	return applier.GetDeserializedPayload()
+}
@@ -0,0 +1,122 @@
+package user
+
+import (
+	"strings"
+	"time"
+)
+
+// ReferralFlagReason is why the ReferralPolicy rejected a referral
+type ReferralFlagReason string
+
+const (
+	ReferralFlagSelfReferral         ReferralFlagReason = "SelfReferral"
+	ReferralFlagDuplicateEmail       ReferralFlagReason = "DuplicateEmail"
+	ReferralFlagBlockedDomain        ReferralFlagReason = "BlockedDomain"
+	ReferralFlagDailyLimitReached    ReferralFlagReason = "DailyLimitReached"
+	ReferralFlagLifetimeLimitReached ReferralFlagReason = "LifetimeLimitReached"
+)
+
+// ReferralFlag records a referral rejected by the ReferralPolicy
+type ReferralFlag struct {
+	ReferredUserEmail string             `json:"referredUserEmail"`
+	ReferredUserID    string             `json:"referredUserId"`
+	Reason            ReferralFlagReason `json:"reason"`
+	FlaggedAt         time.Time          `json:"flaggedAt"`
+}
+
+// ReferralPolicy holds the anti-abuse rules referrals must pass. Users
+// cannot refer themselves nor refer an email that has an open or
+// completed referral, whatever the policy
+type ReferralPolicy struct {
+	// DailyLimit caps the referrals a user makes in 24 hours. No cap when 0
+	DailyLimit int
+
+	// LifetimeLimit caps the referrals a user makes. No cap when 0
+	LifetimeLimit int
+
+	// BlockedDomains lists the email domains, and their subdomains, that
+	// cannot be referred
+	BlockedDomains []string
+}
+
+// ReferralAttempt describes a referral a user is about to make
+type ReferralAttempt struct {
+	ReferredUserEmail string
+
+	// ReferredUserID is the id of the user who signed up. Optional
+	ReferredUserID string
+
+	// NewReferral is set when the attempt adds a referral rather than
+	// completing an open one. Only new referrals count towards the limits
+	NewReferral bool
+
+	At time.Time
+}
+
+// Check returns why the policy rejects the attempt of u, or an empty
+// reason when the attempt is allowed
+func (p ReferralPolicy) Check(u *User, attempt ReferralAttempt) ReferralFlagReason {
+	email := normalizeEmail(attempt.ReferredUserEmail)
+	if email == normalizeEmail(u.Email) ||
+		(attempt.ReferredUserID != "" && attempt.ReferredUserID == u.ID) {
+		return ReferralFlagSelfReferral
+	}
+	if p.blocked(email) {
+		return ReferralFlagBlockedDomain
+	}
+	if !attempt.NewReferral {
+		return ""
+	}
+
+	lastDay := 0
+	for _, v := range u.Referrals {
+		if normalizeEmail(v.ReferredUserEmail) == email &&
+			(v.Status.Open() || v.Status == ReferralStatusCompleted) {
+			return ReferralFlagDuplicateEmail
+		}
+		if v.CreatedAt.After(attempt.At.Add(-24 * time.Hour)) {
+			lastDay++
+		}
+	}
+	if p.LifetimeLimit > 0 && len(u.Referrals) >= p.LifetimeLimit {
+		return ReferralFlagLifetimeLimitReached
+	}
+	if p.DailyLimit > 0 && lastDay >= p.DailyLimit {
+		return ReferralFlagDailyLimitReached
+	}
+	return ""
+}
+
+func (p ReferralPolicy) blocked(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, v := range p.BlockedDomains {
+		blocked := strings.ToLower(strings.TrimSpace(v))
+		if blocked == "" {
+			continue
+		}
+		if domain == blocked || strings.HasSuffix(domain, "."+blocked) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEmail lower cases email and drops the +tag of its local part
+// so that aliases of one mailbox compare equal
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return email
+	}
+
+	local := email[:at]
+	if plus := strings.Index(local, "+"); plus != -1 {
+		local = local[:plus]
+	}
+	return local + email[at:]
+}
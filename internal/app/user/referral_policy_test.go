@@ -0,0 +1,116 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferralPolicy_Check(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	referrer := &User{
+		ID:    "user-1",
+		Email: "Ada@example.com",
+		Referrals: []Referral{
+			{
+				ReferredUserEmail: "grace@example.com",
+				Status:            ReferralStatusSent,
+				CreatedAt:         now.Add(-time.Hour),
+			},
+			{
+				ReferredUserEmail: "alan@example.com",
+				Status:            ReferralStatusExpired,
+				CreatedAt:         now.Add(-48 * time.Hour),
+			},
+		},
+	}
+	policy := ReferralPolicy{
+		DailyLimit:     2,
+		LifetimeLimit:  3,
+		BlockedDomains: []string{"mailinator.com"},
+	}
+
+	tests := []struct {
+		name    string
+		policy  ReferralPolicy
+		attempt ReferralAttempt
+		want    ReferralFlagReason
+	}{
+		{
+			name:    "allowed",
+			policy:  policy,
+			attempt: ReferralAttempt{ReferredUserEmail: "linus@example.com"},
+			want:    "",
+		},
+		{
+			name:    "own email alias",
+			policy:  policy,
+			attempt: ReferralAttempt{ReferredUserEmail: "ada+promo@Example.com"},
+			want:    ReferralFlagSelfReferral,
+		},
+		{
+			name: "own user id",
+			attempt: ReferralAttempt{
+				ReferredUserEmail: "someone@example.com",
+				ReferredUserID:    "user-1",
+			},
+			want: ReferralFlagSelfReferral,
+		},
+		{
+			name:    "blocked subdomain",
+			policy:  policy,
+			attempt: ReferralAttempt{ReferredUserEmail: "bot@eu.mailinator.com"},
+			want:    ReferralFlagBlockedDomain,
+		},
+		{
+			name:    "open referral of the email",
+			attempt: ReferralAttempt{ReferredUserEmail: "grace@example.com"},
+			want:    ReferralFlagDuplicateEmail,
+		},
+		{
+			name:    "expired referral of the email",
+			attempt: ReferralAttempt{ReferredUserEmail: "alan@example.com"},
+			want:    "",
+		},
+		{
+			name:    "lifetime limit",
+			policy:  ReferralPolicy{LifetimeLimit: 2},
+			attempt: ReferralAttempt{ReferredUserEmail: "linus@example.com"},
+			want:    ReferralFlagLifetimeLimitReached,
+		},
+		{
+			name:    "daily limit",
+			policy:  ReferralPolicy{DailyLimit: 1},
+			attempt: ReferralAttempt{ReferredUserEmail: "linus@example.com"},
+			want:    ReferralFlagDailyLimitReached,
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			attempt := v.attempt
+			attempt.NewReferral = true
+			attempt.At = now
+			assert.Equal(t, v.want, v.policy.Check(referrer, attempt))
+		})
+	}
+}
+
+func TestReferralPolicy_CheckCompletingOpenReferral(t *testing.T) {
+	referrer := &User{
+		ID:    "user-1",
+		Email: "ada@example.com",
+		Referrals: []Referral{
+			{ReferredUserEmail: "grace@example.com", Status: ReferralStatusSent},
+		},
+	}
+	policy := ReferralPolicy{LifetimeLimit: 1}
+
+	// Completing the open referral neither duplicates it nor adds one
+	reason := policy.Check(referrer, ReferralAttempt{
+		ReferredUserEmail: "grace@example.com",
+		ReferredUserID:    "user-2",
+	})
+	assert.Equal(t, ReferralFlagReason(""), reason)
+}
@@ -0,0 +1,82 @@
+package user
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// ReferralSent event is fired when the invite of a referral is sent
+type ReferralSent struct {
+	eventsource.ApplierModel
+}
+
+type ReferralSentPayload struct {
+	ReferralID string `json:"referralId,omitempty"`
+}
+
+func NewReferralSentApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &ReferralSent{
+		ApplierModel: *eventsource.NewApplierModel(*event),
+	}
+}
+
+// Apply implements the applier interface
+func (applier *ReferralSent) Apply(agg eventsource.Aggregate) error {
+	userAggregate, err := AssertUserAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	errStatus := userAggregate.applyReferralStatus(
+		payload.ReferralID,
+		ReferralStatusSent,
+		applier.EventAt,
+	)
+	if errStatus != nil {
+		return errStatus
+	}
+
+	userAggregate.Version = applier.Version
+	return nil
+}
+
+func (applier *ReferralSent) SetSerializedPayload(
+	payload interface{},
+) error {
+	referralSentPayload, ok := payload.(ReferralSentPayload)
+	if !ok {
+		return applier.PayloadErr(
+			"user.ReferralSent.SetSerializedPayload",
+			payload,
+		)
+	}
+	return applier.Serialize(referralSentPayload)
+}
+
+func (applier *ReferralSent) GetDeserializedPayload() (
+	*ReferralSentPayload,
+	error,
+) {
+	var payload ReferralSentPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsAnyStringEmpty(&payload.ReferralID) {
+		return nil, applier.PayloadErr(
+			"user.ReferralSent.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
@@ -0,0 +1,123 @@
+package user
+
+import (
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+)
+
+// ErrIllegalReferralTransition is returned when a referral is moved to a
+// status its current status cannot transition to
+var ErrIllegalReferralTransition = errors.New("illegal referral status transition")
+
+// RuleReferralOpen is the validation rule rejecting commands that complete
+// a referral which expired or was cancelled
+const RuleReferralOpen = "referralOpen"
+
+// referralTransitions lists the statuses each referral status can move to.
+// Completed referrals are only cancelled when the sign up that completed
+// them is compensated. Expired and Cancelled referrals are final
+var referralTransitions = map[ReferralStatus][]ReferralStatus{
+	ReferralStatusCreated: {
+		ReferralStatusSent,
+		ReferralStatusCompleted,
+		ReferralStatusExpired,
+		ReferralStatusCancelled,
+	},
+	ReferralStatusSent: {
+		ReferralStatusCompleted,
+		ReferralStatusExpired,
+		ReferralStatusCancelled,
+	},
+	ReferralStatusCompleted: {
+		ReferralStatusCancelled,
+	},
+}
+
+// CanTransitionTo returns whether a referral in status s can move to next
+func (s ReferralStatus) CanTransitionTo(next ReferralStatus) bool {
+	for _, v := range referralTransitions[s] {
+		if v == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Open returns whether a referral in status s can still be completed
+func (s ReferralStatus) Open() bool {
+	return s.CanTransitionTo(ReferralStatusCompleted)
+}
+
+// TransitionReferral checks that the referral with id can move to next,
+// failing with ErrIllegalReferralTransition otherwise
+func (u *User) TransitionReferral(id string, next ReferralStatus) (*Referral, error) {
+	referral := u.Referral(id)
+	if referral == nil {
+		return nil, errors.Errorf("referral %v not found for user %v", id, u.ID)
+	}
+	if !referral.Status.CanTransitionTo(next) {
+		return nil, errors.Wrapf(
+			ErrIllegalReferralTransition,
+			"referral %v cannot move from %v to %v",
+			id,
+			referral.Status,
+			next,
+		)
+	}
+	return referral, nil
+}
+
+// applyReferralStatus moves the referral with id to status at updatedAt.
+// Appliers do not check transitions; events record transitions that were
+// accepted
+func (u *User) applyReferralStatus(
+	id string,
+	status ReferralStatus,
+	updatedAt time.Time,
+) error {
+	referral := u.Referral(id)
+	if referral == nil {
+		return errors.Errorf("referral %v not found", id)
+	}
+	referral.Status = status
+	referral.UpdatedAt = updatedAt
+	return nil
+}
+
+// ReferralTransitionID returns the id of the referral moved by a
+// UserReferralSent, Completed, Expired or Cancelled event
+func ReferralTransitionID(event eventsource.Event) (string, error) {
+	applier := eventsource.NewApplierModel(event)
+	switch event.EventType {
+	case UserReferralSentEventType:
+		payload, err := (&ReferralSent{ApplierModel: *applier}).GetDeserializedPayload()
+		if err != nil {
+			return "", err
+		}
+		return payload.ReferralID, nil
+
+	case UserReferralCompletedEventType:
+		payload, err := (&ReferralCompleted{ApplierModel: *applier}).GetDeserializedPayload()
+		if err != nil {
+			return "", err
+		}
+		return payload.ReferralID, nil
+
+	case UserReferralExpiredEventType:
+		payload, err := (&ReferralExpired{ApplierModel: *applier}).GetDeserializedPayload()
+		if err != nil {
+			return "", err
+		}
+		return payload.ReferralID, nil
+
+	case UserReferralCancelledEventType:
+		payload, err := (&ReferralCancelled{ApplierModel: *applier}).GetDeserializedPayload()
+		if err != nil {
+			return "", err
+		}
+		return payload.ReferralID, nil
+	}
+	return "", errors.Errorf("%v is not a referral transition event", event.EventType)
+}
@@ -0,0 +1,81 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferralStatus_CanTransitionTo(t *testing.T) {
+	statuses := []ReferralStatus{
+		ReferralStatusCreated,
+		ReferralStatusSent,
+		ReferralStatusCompleted,
+		ReferralStatusExpired,
+		ReferralStatusCancelled,
+	}
+	allowed := map[ReferralStatus][]ReferralStatus{
+		ReferralStatusCreated: {
+			ReferralStatusSent,
+			ReferralStatusCompleted,
+			ReferralStatusExpired,
+			ReferralStatusCancelled,
+		},
+		ReferralStatusSent: {
+			ReferralStatusCompleted,
+			ReferralStatusExpired,
+			ReferralStatusCancelled,
+		},
+		ReferralStatusCompleted: {
+			ReferralStatusCancelled,
+		},
+	}
+	open := map[ReferralStatus]bool{
+		ReferralStatusCreated: true,
+		ReferralStatusSent:    true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			expected := false
+			for _, v := range allowed[from] {
+				expected = expected || v == to
+			}
+			assert.Equal(
+				t,
+				expected,
+				from.CanTransitionTo(to),
+				"%v to %v",
+				from,
+				to,
+			)
+		}
+		assert.Equal(t, open[from], from.Open(), "%v open", from)
+	}
+}
+
+func TestUser_TransitionReferral(t *testing.T) {
+	assert := assert.New(t)
+	u := &User{
+		ID: "user-1",
+		Referrals: []Referral{
+			{ID: "sent", Status: ReferralStatusSent},
+			{ID: "expired", Status: ReferralStatusExpired},
+		},
+	}
+
+	referral, err := u.TransitionReferral("sent", ReferralStatusCompleted)
+	assert.Nil(err)
+	assert.Equal("sent", referral.ID)
+
+	_, errBack := u.TransitionReferral("sent", ReferralStatusCreated)
+	assert.Equal(ErrIllegalReferralTransition, errors.Cause(errBack))
+
+	_, errClosed := u.TransitionReferral("expired", ReferralStatusCancelled)
+	assert.Equal(ErrIllegalReferralTransition, errors.Cause(errClosed))
+
+	_, errMissing := u.TransitionReferral("unknown", ReferralStatusSent)
+	assert.NotNil(errMissing)
+	assert.NotEqual(ErrIllegalReferralTransition, errors.Cause(errMissing))
+}
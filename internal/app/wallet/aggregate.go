@@ -0,0 +1,95 @@
+package wallet
+
+import (
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidAggregateType = errors.New("aggregate is not of type wallet.Wallet")
+
+	WalletOpenedEventType               = "WalletOpened"
+	WalletPointsEarnedEventType         = "WalletPointsEarned"
+	WalletPointsRedeemedEventType       = "WalletPointsRedeemed"
+	WalletPointsAdjustedEventType       = "WalletPointsAdjusted"
+	WalletPointsExpiredEventType        = "WalletPointsExpired"
+	WalletPointsTransferredOutEventType = "WalletPointsTransferredOut"
+	WalletPointsTransferredInEventType  = "WalletPointsTransferredIn"
+	walletIDPrefix                      = "wallet-"
+)
+
+// ID returns the id of the wallet of the user. Wallets share the event
+// store with users, so their stream is kept apart by the prefix
+func ID(userID string) string {
+	return walletIDPrefix + userID
+}
+
+// Wallet is the points ledger of a user
+type Wallet struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	Balance   uint32    `json:"balance"`
+	Version   int       `json:"version"`
+	OpenedAt  time.Time `json:"openedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// NewWallet creates a new instance of the Wallet aggregate
+func NewWallet(id string) eventsource.Aggregate {
+	return &Wallet{
+		ID:        id,
+		OpenedAt:  time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Opened indicates whether the wallet has been opened
+func (w *Wallet) Opened() bool {
+	return w.UserID != ""
+}
+
+// EventVersion returns the current event version
+func (w *Wallet) EventVersion() int {
+	return w.Version
+}
+
+// Apply takes event history and applies them to an aggregate
+func (w *Wallet) Apply(history eventsource.History) error {
+	for _, h := range history {
+		a, err := GetApplier(h)
+		if err != nil {
+			return err
+		}
+
+		errApply := a.Apply(w)
+		if errApply != nil {
+			return errApply
+		}
+	}
+	return nil
+}
+
+// debit takes points from the balance of the wallet
+func (w *Wallet) debit(points uint32) error {
+	if points > w.Balance {
+		return errors.Errorf(
+			"wallet %v cannot be debited %v points, balance is %v",
+			w.ID,
+			points,
+			w.Balance,
+		)
+	}
+	w.Balance -= points
+	return nil
+}
+
+func AssertWalletAggregate(agg eventsource.Aggregate) (*Wallet, error) {
+	var w *Wallet
+	ok := false
+	if w, ok = agg.(*Wallet); !ok {
+		return nil, errInvalidAggregateType
+	}
+	return w, nil
+}
@@ -0,0 +1,38 @@
+package wallet
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+)
+
+func GetApplier(event eventsource.Event) (eventsource.Applier, error) {
+	model := eventsource.ApplierModel{
+		Event: event,
+	}
+
+	switch event.EventType {
+	case WalletOpenedEventType:
+		return &Opened{ApplierModel: model}, nil
+
+	case WalletPointsEarnedEventType:
+		return &PointsEarned{ApplierModel: model}, nil
+
+	case WalletPointsRedeemedEventType:
+		return &PointsRedeemed{ApplierModel: model}, nil
+
+	case WalletPointsAdjustedEventType:
+		return &PointsAdjusted{ApplierModel: model}, nil
+
+	case WalletPointsExpiredEventType:
+		return &PointsExpired{ApplierModel: model}, nil
+
+	case WalletPointsTransferredOutEventType:
+		return &PointsTransferredOut{ApplierModel: model}, nil
+
+	case WalletPointsTransferredInEventType:
+		return &PointsTransferredIn{ApplierModel: model}, nil
+
+	default:
+		return nil, errors.Errorf("unable to apply event %v", event.EventType)
+	}
+}
@@ -0,0 +1,381 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errWalletNotOpened  = errors.New("wallet not opened")
+	errZeroAdjustment   = errors.New("wallet adjustment must not be zero")
+	errTransferToSelf   = errors.New("cannot transfer points to the same wallet")
+	errWalletIDMismatch = errors.New("wallet id does not belong to user")
+)
+
+type handler struct {
+	eventBus eventsource.EventBus
+	repo     eventsource.EventRepo
+	logger   *zap.Logger
+}
+
+type CommandHandlerParams struct {
+	EventBus eventsource.EventBus
+	Repo     eventsource.EventRepo
+	Logger   *zap.Logger
+}
+
+// NewWalletCommandHandler creates the CommandHandler of the Wallet
+// aggregate. Commands address wallets by wallet.ID of the user
+func NewWalletCommandHandler(
+	params CommandHandlerParams,
+) eventsource.CommandHandler {
+	return &handler{
+		eventBus: params.EventBus,
+		repo:     params.Repo,
+		logger:   params.Logger,
+	}
+}
+
+// Handle implements the CommandHandler interface
+func (c *handler) Handle(
+	ctx context.Context,
+	cmd eventsource.Command,
+) (*eventsource.CommandResult, error) {
+	var err error
+	events := []eventsource.Event{}
+
+	switch v := cmd.(type) {
+	case *loyalty.OpenWallet:
+		events, err = c.handleOpenWallet(ctx, v)
+	case *loyalty.EarnWalletPoints:
+		events, err = c.handleEarnWalletPoints(ctx, v)
+	case *loyalty.RedeemWalletPoints:
+		events, err = c.handleRedeemWalletPoints(ctx, v)
+	case *loyalty.AdjustWalletPoints:
+		events, err = c.handleAdjustWalletPoints(ctx, v)
+	case *loyalty.ExpireWalletPoints:
+		events, err = c.handleExpireWalletPoints(ctx, v)
+	case *loyalty.TransferWalletPoints:
+		events, err = c.handleTransferWalletPoints(ctx, v)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	errPublish := c.eventBus.Publish(ctx, events)
+	if errPublish != nil {
+		return nil, errPublish
+	}
+
+	return eventsource.NewCommandResult(cmd.AggregateID(), events), nil
+}
+
+// CommandsHandled implements the CommandHandler interface
+func (c *handler) CommandsHandled() []eventsource.Command {
+	return []eventsource.Command{
+		&loyalty.AdjustWalletPoints{},
+		&loyalty.EarnWalletPoints{},
+		&loyalty.ExpireWalletPoints{},
+		&loyalty.OpenWallet{},
+		&loyalty.RedeemWalletPoints{},
+		&loyalty.TransferWalletPoints{},
+	}
+}
+
+func (c *handler) handleOpenWallet(
+	ctx context.Context,
+	command *loyalty.OpenWallet,
+) ([]eventsource.Event, error) {
+	if command.AggregateID() != wallet.ID(command.UserID) {
+		return nil, errWalletIDMismatch
+	}
+
+	aggregate, err := c.loadWalletAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+	// Opening is idempotent so that it can be triggered by redelivered
+	// events
+	if aggregate.Opened() {
+		return []eventsource.Event{}, nil
+	}
+
+	applier := wallet.NewOpenedApplier(
+		command.AggregateID(),
+		wallet.WalletOpenedEventType,
+		aggregate.Version+1,
+	)
+	errSetPayload := applier.SetSerializedPayload(wallet.OpenedPayload{
+		UserID: command.UserID,
+	})
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	return c.persist(ctx, applier.EventModel())
+}
+
+func (c *handler) handleEarnWalletPoints(
+	ctx context.Context,
+	command *loyalty.EarnWalletPoints,
+) ([]eventsource.Event, error) {
+	aggregate, err := c.loadOpenedWalletAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+
+	applier := wallet.NewPointsEarnedApplier(
+		command.AggregateID(),
+		wallet.WalletPointsEarnedEventType,
+		aggregate.Version+1,
+	)
+	errSetPayload := applier.SetSerializedPayload(wallet.PointsEarnedPayload{
+		Points: command.Points,
+		Reason: command.Reason,
+	})
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	return c.persist(ctx, applier.EventModel())
+}
+
+func (c *handler) handleRedeemWalletPoints(
+	ctx context.Context,
+	command *loyalty.RedeemWalletPoints,
+) ([]eventsource.Event, error) {
+	aggregate, err := c.loadOpenedWalletAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+	if command.Points > aggregate.Balance {
+		return nil, errors.Errorf(
+			"cannot redeem %v points, wallet %v has %v",
+			command.Points,
+			command.AggregateID(),
+			aggregate.Balance,
+		)
+	}
+
+	applier := wallet.NewPointsRedeemedApplier(
+		command.AggregateID(),
+		wallet.WalletPointsRedeemedEventType,
+		aggregate.Version+1,
+	)
+	errSetPayload := applier.SetSerializedPayload(wallet.PointsRedeemedPayload{
+		Points:  command.Points,
+		Reason:  command.Reason,
+		Balance: aggregate.Balance - command.Points,
+	})
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	return c.persist(ctx, applier.EventModel())
+}
+
+func (c *handler) handleAdjustWalletPoints(
+	ctx context.Context,
+	command *loyalty.AdjustWalletPoints,
+) ([]eventsource.Event, error) {
+	if command.Delta == 0 {
+		return nil, errZeroAdjustment
+	}
+
+	aggregate, err := c.loadOpenedWalletAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+	if command.Delta < 0 && -command.Delta > int64(aggregate.Balance) {
+		return nil, errors.Errorf(
+			"cannot adjust wallet %v by %v, balance is %v",
+			command.AggregateID(),
+			command.Delta,
+			aggregate.Balance,
+		)
+	}
+
+	applier := wallet.NewPointsAdjustedApplier(
+		command.AggregateID(),
+		wallet.WalletPointsAdjustedEventType,
+		aggregate.Version+1,
+	)
+	errSetPayload := applier.SetSerializedPayload(wallet.PointsAdjustedPayload{
+		Delta:  command.Delta,
+		Reason: command.Reason,
+	})
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	return c.persist(ctx, applier.EventModel())
+}
+
+// handleExpireWalletPoints expires up to the requested points; points
+// spent before they expire cannot lapse
+func (c *handler) handleExpireWalletPoints(
+	ctx context.Context,
+	command *loyalty.ExpireWalletPoints,
+) ([]eventsource.Event, error) {
+	aggregate, err := c.loadOpenedWalletAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+
+	points := command.Points
+	if points > aggregate.Balance {
+		points = aggregate.Balance
+	}
+	if points == 0 {
+		return []eventsource.Event{}, nil
+	}
+
+	applier := wallet.NewPointsExpiredApplier(
+		command.AggregateID(),
+		wallet.WalletPointsExpiredEventType,
+		aggregate.Version+1,
+	)
+	errSetPayload := applier.SetSerializedPayload(wallet.PointsExpiredPayload{
+		Points: points,
+	})
+	if errSetPayload != nil {
+		return nil, errSetPayload
+	}
+
+	return c.persist(ctx, applier.EventModel())
+}
+
+// handleTransferWalletPoints debits the sending wallet and credits the
+// receiving wallet. Both events are saved in one batch so that points are
+// never debited without being credited. The batch fails with a
+// concurrency conflict when either wallet changed since it was loaded,
+// e.g. by a command queued on the receiving wallet, so that the transfer
+// is retried against the latest balances
+func (c *handler) handleTransferWalletPoints(
+	ctx context.Context,
+	command *loyalty.TransferWalletPoints,
+) ([]eventsource.Event, error) {
+	toWalletID := wallet.ID(command.ToUserID)
+	if toWalletID == command.AggregateID() {
+		return nil, errTransferToSelf
+	}
+
+	from, err := c.loadOpenedWalletAggregate(ctx, command.AggregateID())
+	if err != nil {
+		return nil, err
+	}
+	if command.Points > from.Balance {
+		return nil, errors.Errorf(
+			"cannot transfer %v points, wallet %v has %v",
+			command.Points,
+			command.AggregateID(),
+			from.Balance,
+		)
+	}
+
+	to, errTo := c.loadOpenedWalletAggregate(ctx, toWalletID)
+	if errTo != nil {
+		return nil, errTo
+	}
+
+	payload := wallet.PointsTransferredPayload{
+		TransferID:   eventsource.NewUUID(),
+		Points:       command.Points,
+		FromWalletID: from.ID,
+		ToWalletID:   to.ID,
+	}
+
+	out := wallet.NewPointsTransferredOutApplier(
+		from.ID,
+		wallet.WalletPointsTransferredOutEventType,
+		from.Version+1,
+	)
+	errOut := out.SetSerializedPayload(payload)
+	if errOut != nil {
+		return nil, errOut
+	}
+
+	in := wallet.NewPointsTransferredInApplier(
+		to.ID,
+		wallet.WalletPointsTransferredInEventType,
+		to.Version+1,
+	)
+	errIn := in.SetSerializedPayload(payload)
+	if errIn != nil {
+		return nil, errIn
+	}
+
+	events := []eventsource.Event{out.EventModel(), in.EventModel()}
+	if commandID := eventsource.CommandIDFromContext(ctx); commandID != "" {
+		for i := range events {
+			events[i].CommandID = commandID
+		}
+	}
+
+	// The repository applies events of a single aggregate, so the batch
+	// spanning both wallets is saved directly. The store checks the
+	// versions of both wallets
+	errSave := c.repo.Save(ctx, events...)
+	if errSave != nil {
+		return nil, errSave
+	}
+	return events, nil
+}
+
+/* ----- helpers ----- */
+func (c *handler) loadWalletAggregate(
+	ctx context.Context,
+	aggregateID string,
+) (*wallet.Wallet, error) {
+	aggregate, err := c.repo.Load(ctx, aggregateID, 0)
+	if eventsource.IsNotFound(err) {
+		aggregate, err = wallet.NewWallet(aggregateID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet.AssertWalletAggregate(aggregate)
+}
+
+func (c *handler) loadOpenedWalletAggregate(
+	ctx context.Context,
+	aggregateID string,
+) (*wallet.Wallet, error) {
+	aggregate, err := c.loadWalletAggregate(ctx, aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	if !aggregate.Opened() {
+		return nil, errors.Wrap(errWalletNotOpened, aggregateID)
+	}
+	return aggregate, nil
+}
+
+func (c *handler) persist(
+	ctx context.Context,
+	events ...eventsource.Event,
+) ([]eventsource.Event, error) {
+	start := time.Now()
+	aggregateID, version, errApply := c.repo.Apply(ctx, events...)
+	if errApply != nil {
+		return nil, errApply
+	}
+
+	c.logger.Info(
+		"saved event(s)",
+		zap.Int("count", len(events)),
+		zap.String("aggregateId", *aggregateID),
+		zap.Int("currentVersion", *version),
+		zap.Duration("timeElapsed", time.Since(start)),
+	)
+
+	return events, nil
+}
@@ -0,0 +1,369 @@
+package command
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource/eventsourcetest"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestHandler_EarnAndRedeem(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newHandlerFixture(t)
+	f.open(t, "user-1")
+
+	f.handle(t, &loyalty.EarnWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Points:       100,
+	})
+	f.handle(t, &loyalty.RedeemWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Points:       40,
+	})
+	assert.Equal(uint32(60), f.balance(t, "user-1"))
+
+	_, err := f.handler.Handle(ctx, &loyalty.RedeemWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Points:       61,
+	})
+	assert.NotNil(err)
+	assert.Equal(uint32(60), f.balance(t, "user-1"))
+}
+
+func TestHandler_OpenIsIdempotent(t *testing.T) {
+	assert := assert.New(t)
+	f := newHandlerFixture(t)
+	f.open(t, "user-1")
+
+	result := f.handle(t, &loyalty.OpenWallet{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		UserID:       "user-1",
+	})
+	assert.Empty(result.EventTypes)
+
+	_, err := f.handler.Handle(context.Background(), &loyalty.OpenWallet{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-2")},
+		UserID:       "user-1",
+	})
+	assert.Equal(errWalletIDMismatch, err)
+}
+
+func TestHandler_RequiresOpenedWallet(t *testing.T) {
+	f := newHandlerFixture(t)
+
+	_, err := f.handler.Handle(context.Background(), &loyalty.EarnWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Points:       10,
+	})
+	assert.Equal(t, errWalletNotOpened, errors.Cause(err))
+}
+
+func TestHandler_AdjustAndExpire(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newHandlerFixture(t)
+	f.open(t, "user-1")
+
+	f.handle(t, &loyalty.AdjustWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Delta:        50,
+		Reason:       "goodwill",
+	})
+	f.handle(t, &loyalty.AdjustWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Delta:        -20,
+		Reason:       "correction",
+	})
+	assert.Equal(uint32(30), f.balance(t, "user-1"))
+
+	_, err := f.handler.Handle(ctx, &loyalty.AdjustWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Delta:        -31,
+		Reason:       "correction",
+	})
+	assert.NotNil(err)
+
+	// Expiring more than the balance only expires the balance
+	result := f.handle(t, &loyalty.ExpireWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Points:       100,
+	})
+	assert.Equal([]string{wallet.WalletPointsExpiredEventType}, result.EventTypes)
+	assert.Equal(uint32(0), f.balance(t, "user-1"))
+
+	result = f.handle(t, &loyalty.ExpireWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Points:       100,
+	})
+	assert.Empty(result.EventTypes)
+}
+
+func TestHandler_Transfer(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newHandlerFixture(t)
+	f.open(t, "user-1")
+	f.open(t, "user-2")
+
+	f.handle(t, &loyalty.EarnWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		Points:       100,
+	})
+	f.handle(t, &loyalty.TransferWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		ToUserID:     "user-2",
+		Points:       70,
+	})
+	assert.Equal(uint32(30), f.balance(t, "user-1"))
+	assert.Equal(uint32(70), f.balance(t, "user-2"))
+
+	_, err := f.handler.Handle(ctx, &loyalty.TransferWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		ToUserID:     "user-2",
+		Points:       31,
+	})
+	assert.NotNil(err)
+
+	_, err = f.handler.Handle(ctx, &loyalty.TransferWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		ToUserID:     "user-1",
+		Points:       1,
+	})
+	assert.Equal(errTransferToSelf, err)
+
+	_, err = f.handler.Handle(ctx, &loyalty.TransferWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		ToUserID:     "user-3",
+		Points:       1,
+	})
+	assert.Equal(errWalletNotOpened, errors.Cause(err))
+	assert.Equal(uint32(30), f.balance(t, "user-1"))
+}
+
+func TestHandler_Redeem(t *testing.T) {
+	tests := []struct {
+		name        string
+		points      uint32
+		wantBalance uint32
+		wantErr     bool
+	}{
+		{name: "insufficient balance", points: 101, wantBalance: 100, wantErr: true},
+		{name: "exact balance", points: 100, wantBalance: 0},
+		{name: "partial balance", points: 40, wantBalance: 60},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			assert := assert.New(t)
+			ctx := context.Background()
+			f := newHandlerFixture(t)
+			f.openWithPoints(t, "user-1", 100)
+
+			result, err := f.dispatcher.Dispatch(ctx, &loyalty.RedeemWalletPoints{
+				CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+				Points:       v.points,
+			})
+			assert.Equal(v.wantErr, err != nil)
+			assert.Equal(v.wantBalance, f.balance(t, "user-1"))
+			if err != nil {
+				return
+			}
+
+			redeemed, errRedeemed := wallet.LoadPointsRedeemed(
+				ctx,
+				f.store,
+				wallet.ID("user-1"),
+				result.Version,
+			)
+			assert.Nil(errRedeemed)
+			assert.Equal(v.wantBalance, redeemed.Balance)
+		})
+	}
+}
+
+func TestHandler_RedeemIdempotentRetry(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newHandlerFixture(t)
+	f.openWithPoints(t, "user-1", 100)
+
+	redeem := &loyalty.RedeemWalletPoints{
+		CommandModel: eventsource.CommandModel{
+			ID:        wallet.ID("user-1"),
+			CommandID: "redeem-1",
+		},
+		Points: 60,
+	}
+	first, err := f.dispatcher.Dispatch(ctx, redeem)
+	assert.Nil(err)
+
+	// The retry returns the first result instead of failing on the balance
+	retried, errRetried := f.dispatcher.Dispatch(ctx, redeem)
+	assert.Nil(errRetried)
+	assert.Equal(first.Version, retried.Version)
+	assert.Equal(uint32(40), f.balance(t, "user-1"))
+}
+
+func TestHandler_ConcurrentRedeemCannotOverspend(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newHandlerFixture(t)
+	f.openWithPoints(t, "user-1", 100)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.dispatcher.Dispatch(ctx, &loyalty.RedeemWalletPoints{
+				CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+				Points:       100,
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	assert.Equal(1, failed)
+	assert.Equal(uint32(0), f.balance(t, "user-1"))
+}
+
+func TestHandler_TransferRetriesConcurrentCredit(t *testing.T) {
+	assert := assert.New(t)
+	f := newHandlerFixture(t)
+	f.openWithPoints(t, "user-1", 100)
+	f.open(t, "user-2")
+
+	// Another command credits the receiving wallet after the transfer
+	// loaded it, so the first attempt conflicts
+	earned := wallet.NewPointsEarnedApplier(
+		wallet.ID("user-2"),
+		wallet.WalletPointsEarnedEventType,
+		2,
+	)
+	assert.Nil(earned.SetSerializedPayload(wallet.PointsEarnedPayload{Points: 5}))
+	f.racing.beforeSave = func() {
+		errEarn := f.store.Save(context.Background(), earned.EventModel())
+		assert.Nil(errEarn)
+	}
+
+	_, err := f.dispatcher.Dispatch(context.Background(), &loyalty.TransferWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID("user-1")},
+		ToUserID:     "user-2",
+		Points:       70,
+	})
+	assert.Nil(err)
+	assert.Equal(uint32(30), f.balance(t, "user-1"))
+	assert.Equal(uint32(75), f.balance(t, "user-2"))
+}
+
+/* ----- helpers ----- */
+type handlerFixture struct {
+	handler    eventsource.CommandHandler
+	dispatcher eventsource.CommandDispatcher
+	repo       eventsource.EventRepo
+	store      *eventsourcetest.MemoryStore
+	racing     *racingStore
+}
+
+func newHandlerFixture(t *testing.T) *handlerFixture {
+	logger := zaptest.NewLogger(t)
+	store := eventsourcetest.NewMemoryStore()
+	racing := &racingStore{MemoryStore: store}
+	repo := loyalty.NewRepository(loyalty.RepositoryParams{
+		Store:  racing,
+		Logger: logger,
+		NewAggregate: func(id string) eventsource.Aggregate {
+			return wallet.NewWallet(id)
+		},
+	})
+
+	handler := NewWalletCommandHandler(CommandHandlerParams{
+		EventBus: &eventsourcetest.NopEventBus{},
+		Repo:     repo,
+		Logger:   logger,
+	})
+	dispatcher := eventsource.NewDispatcher(logger)
+	dispatcher.Use(
+		eventsource.ConflictRetryMiddleware(3),
+		eventsource.IdempotencyMiddleware(store),
+	)
+	errRegister := dispatcher.RegisterHandler(handler)
+	if errRegister != nil {
+		t.Fatal(errRegister)
+	}
+
+	return &handlerFixture{
+		handler:    handler,
+		dispatcher: dispatcher,
+		repo:       repo,
+		store:      store,
+		racing:     racing,
+	}
+}
+
+func (f *handlerFixture) handle(
+	t *testing.T,
+	cmd eventsource.Command,
+) *eventsource.CommandResult {
+	result, err := f.handler.Handle(context.Background(), cmd)
+	assert.Nil(t, err)
+	return result
+}
+
+func (f *handlerFixture) open(t *testing.T, userID string) {
+	f.handle(t, &loyalty.OpenWallet{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID(userID)},
+		UserID:       userID,
+	})
+}
+
+func (f *handlerFixture) openWithPoints(t *testing.T, userID string, points uint32) {
+	f.open(t, userID)
+	f.handle(t, &loyalty.EarnWalletPoints{
+		CommandModel: eventsource.CommandModel{ID: wallet.ID(userID)},
+		Points:       points,
+	})
+}
+
+func (f *handlerFixture) balance(t *testing.T, userID string) uint32 {
+	aggregate, err := f.repo.Load(context.Background(), wallet.ID(userID), 0)
+	assert.Nil(t, err)
+
+	w, errWallet := wallet.AssertWalletAggregate(aggregate)
+	assert.Nil(t, errWallet)
+	return w.Balance
+}
+
+// racingStore runs beforeSave once before the next save, e.g. to save the
+// events of a concurrent command
+type racingStore struct {
+	*eventsourcetest.MemoryStore
+	beforeSave func()
+}
+
+func (r *racingStore) Save(ctx context.Context, events ...eventsource.Event) error {
+	if r.beforeSave != nil {
+		beforeSave := r.beforeSave
+		r.beforeSave = nil
+		beforeSave()
+	}
+	return r.MemoryStore.Save(ctx, events...)
+}
@@ -0,0 +1,151 @@
+package event
+
+import (
+	"context"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type walletEventHandler struct {
+	readRepo wallet.ReadRepo
+	logger   *zap.Logger
+}
+
+// ProjectionName identifies the checkpoint of the wallet read model projection
+var ProjectionName = "wallets"
+
+// NewEventHandler creates an instance of the wallet read model Projection
+func NewEventHandler(
+	logger *zap.Logger,
+	readRepo wallet.ReadRepo,
+) eventsource.Projection {
+	return &walletEventHandler{
+		readRepo: readRepo,
+		logger:   logger,
+	}
+}
+
+// Name implements the Projection interface
+func (h *walletEventHandler) Name() string {
+	return ProjectionName
+}
+
+// EventTypesHandled implements the Projection interface
+func (h *walletEventHandler) EventTypesHandled() []string {
+	return []string{
+		wallet.WalletOpenedEventType,
+		wallet.WalletPointsEarnedEventType,
+		wallet.WalletPointsRedeemedEventType,
+		wallet.WalletPointsAdjustedEventType,
+		wallet.WalletPointsExpiredEventType,
+		wallet.WalletPointsTransferredOutEventType,
+		wallet.WalletPointsTransferredInEventType,
+	}
+}
+
+// Project implements the Projection interface
+func (h *walletEventHandler) Project(
+	ctx context.Context,
+	event eventsource.Event,
+) error {
+	if event.EventType == wallet.WalletOpenedEventType {
+		return handleWalletOpened(ctx, event, h.readRepo)
+	}
+
+	delta, err := balanceDelta(event)
+	if err != nil {
+		return err
+	}
+	return h.readRepo.AdjustBalance(
+		ctx,
+		event.AggregateID,
+		delta,
+		event.Version,
+	)
+}
+
+/* ----- handlers ----- */
+func handleWalletOpened(
+	ctx context.Context,
+	event eventsource.Event,
+	readRepo wallet.ReadRepo,
+) error {
+	openedEvent := wallet.Opened{
+		ApplierModel: *eventsource.NewApplierModel(event),
+	}
+
+	payload, errPayload := openedEvent.GetDeserializedPayload()
+	if errPayload != nil {
+		return errPayload
+	}
+
+	return readRepo.OpenWallet(ctx, wallet.DTO{
+		WalletID:  event.AggregateID,
+		UserID:    payload.UserID,
+		OpenedAt:  event.EventAt,
+		UpdatedAt: event.EventAt,
+		AggregateBase: eventsource.AggregateBase{
+			Version: event.Version,
+		},
+	})
+}
+
+// balanceDelta returns the change of the wallet balance caused by event
+func balanceDelta(event eventsource.Event) (int64, error) {
+	model := *eventsource.NewApplierModel(event)
+
+	switch event.EventType {
+	case wallet.WalletPointsEarnedEventType:
+		applier := wallet.PointsEarned{ApplierModel: model}
+		payload, err := applier.GetDeserializedPayload()
+		if err != nil {
+			return 0, err
+		}
+		return int64(payload.Points), nil
+
+	case wallet.WalletPointsRedeemedEventType:
+		applier := wallet.PointsRedeemed{ApplierModel: model}
+		payload, err := applier.GetDeserializedPayload()
+		if err != nil {
+			return 0, err
+		}
+		return -int64(payload.Points), nil
+
+	case wallet.WalletPointsAdjustedEventType:
+		applier := wallet.PointsAdjusted{ApplierModel: model}
+		payload, err := applier.GetDeserializedPayload()
+		if err != nil {
+			return 0, err
+		}
+		return payload.Delta, nil
+
+	case wallet.WalletPointsExpiredEventType:
+		applier := wallet.PointsExpired{ApplierModel: model}
+		payload, err := applier.GetDeserializedPayload()
+		if err != nil {
+			return 0, err
+		}
+		return -int64(payload.Points), nil
+
+	case wallet.WalletPointsTransferredOutEventType:
+		applier := wallet.PointsTransferredOut{ApplierModel: model}
+		payload, err := applier.GetDeserializedPayload()
+		if err != nil {
+			return 0, err
+		}
+		return -int64(payload.Points), nil
+
+	case wallet.WalletPointsTransferredInEventType:
+		applier := wallet.PointsTransferredIn{ApplierModel: model}
+		payload, err := applier.GetDeserializedPayload()
+		if err != nil {
+			return 0, err
+		}
+		return int64(payload.Points), nil
+	}
+
+	return 0, errors.Errorf("event %v does not change a wallet balance", event.EventType)
+}
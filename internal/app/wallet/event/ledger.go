@@ -0,0 +1,151 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	"github.com/pkg/errors"
+)
+
+var revokedReason = "revoked"
+
+type ledger struct {
+	dispatcher eventsource.CommandDispatcher
+	store      user.EventStore
+}
+
+// NewLedger creates the EventHandler that posts the points users earn,
+// lose and redeem to their wallet. The user aggregate holds the balance
+// users spend; wallets mirror it so that it can be adjusted, expired and
+// transferred
+func NewLedger(
+	dispatcher eventsource.CommandDispatcher,
+	store user.EventStore,
+) eventsource.EventHandler {
+	return &ledger{
+		dispatcher: dispatcher,
+		store:      store,
+	}
+}
+
+func (l *ledger) EventTypesHandled() []string {
+	return []string{
+		user.PointsEarnedEventType,
+		user.PointsRevokedEventType,
+		user.PointsRedeemedEventType,
+	}
+}
+
+func (l *ledger) Handle(ctx context.Context, event eventsource.Event) error {
+	return l.post(ctx, event)
+}
+
+// Sync implements the EventHandler interface; posts the points events of
+// the user that were not posted yet
+func (l *ledger) Sync(ctx context.Context, aggregateID string) error {
+	history, err := l.store.Load(ctx, aggregateID, 0)
+	if err != nil {
+		return errors.Wrapf(err, "unable to load history of user %v", aggregateID)
+	}
+
+	for _, v := range history {
+		if !l.handles(v.EventType) {
+			continue
+		}
+		errPost := l.post(ctx, v)
+		if errPost != nil {
+			return errPost
+		}
+	}
+	return nil
+}
+
+/* ----- helpers ----- */
+
+// post dispatches the wallet command matching event. Commands are keyed by
+// the event so that redelivered events are posted once
+func (l *ledger) post(ctx context.Context, event eventsource.Event) error {
+	cmd, err := ledgerCommand(event)
+	if err != nil {
+		return err
+	}
+
+	ctx = eventsource.WithCommandSource(ctx, eventsource.CommandSourceSystem)
+	_, errOpen := l.dispatcher.Dispatch(ctx, &loyalty.OpenWallet{
+		CommandModel: eventsource.CommandModel{
+			ID: wallet.ID(event.AggregateID),
+		},
+		UserID: event.AggregateID,
+	})
+	if errOpen != nil {
+		return errOpen
+	}
+
+	_, errDispatch := l.dispatcher.Dispatch(ctx, cmd)
+	return errDispatch
+}
+
+func (l *ledger) handles(eventType string) bool {
+	for _, v := range l.EventTypesHandled() {
+		if v == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func ledgerCommand(event eventsource.Event) (eventsource.Command, error) {
+	model := eventsource.CommandModel{
+		ID:        wallet.ID(event.AggregateID),
+		CommandID: fmt.Sprintf("ledger:%v:%v", event.AggregateID, event.Version),
+	}
+	applierModel := *eventsource.NewApplierModel(event)
+
+	switch event.EventType {
+	case user.PointsEarnedEventType:
+		applier := user.PointsEarned{ApplierModel: applierModel}
+		payload, err := applier.GetDeserializedPayload()
+		if err != nil {
+			return nil, err
+		}
+		return &loyalty.EarnWalletPoints{
+			CommandModel: model,
+			Points:       payload.PointsEarned,
+			Reason:       payload.RuleAction,
+		}, nil
+
+	case user.PointsRevokedEventType:
+		applier := user.PointsRevoked{ApplierModel: applierModel}
+		payload, err := applier.GetDeserializedPayload()
+		if err != nil {
+			return nil, err
+		}
+		reason := payload.Reason
+		if reason == "" {
+			reason = revokedReason
+		}
+		return &loyalty.AdjustWalletPoints{
+			CommandModel: model,
+			Delta:        -int64(payload.PointsRevoked),
+			Reason:       reason,
+		}, nil
+
+	case user.PointsRedeemedEventType:
+		applier := user.PointsRedeemed{ApplierModel: applierModel}
+		payload, err := applier.GetDeserializedPayload()
+		if err != nil {
+			return nil, err
+		}
+		return &loyalty.RedeemWalletPoints{
+			CommandModel: model,
+			Points:       payload.PointsRedeemed,
+			Reason:       payload.Reason,
+		}, nil
+	}
+
+	return nil, errors.Errorf("event %v is not posted to wallets", event.EventType)
+}
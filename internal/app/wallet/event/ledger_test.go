@@ -0,0 +1,132 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource/eventsourcetest"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet/command"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestLedger_PostsPointsOnce(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	f := newLedgerFixture(t)
+
+	earned := f.userEvent(t, 2, user.PointsEarnedEventType, user.PointsEarnedPayload{
+		PointsEarned: 100,
+		RuleAction:   "SignUp",
+	})
+	revoked := f.userEvent(t, 3, user.PointsRevokedEventType, user.PointsRevokedPayload{
+		PointsRevoked: 30,
+	})
+
+	// Redelivered events are posted once
+	for _, v := range []eventsource.Event{earned, earned, revoked, revoked} {
+		assert.Nil(f.ledger.Handle(ctx, v))
+	}
+	assert.Equal(uint32(70), f.balance(t))
+
+	// Sync skips the events that were posted
+	assert.Nil(f.ledger.Sync(ctx, "user-1"))
+	assert.Equal(uint32(70), f.balance(t))
+}
+
+func TestLedger_SyncPostsMissedEvents(t *testing.T) {
+	assert := assert.New(t)
+	f := newLedgerFixture(t)
+
+	f.userEvent(t, 2, user.PointsEarnedEventType, user.PointsEarnedPayload{
+		PointsEarned: 100,
+	})
+	f.userEvent(t, 3, user.PointsRedeemedEventType, user.PointsRedeemedPayload{
+		PointsRedeemed: 40,
+		Balance:        60,
+	})
+
+	assert.Nil(f.ledger.Sync(context.Background(), "user-1"))
+	assert.Equal(uint32(60), f.balance(t))
+}
+
+/* ----- helpers ----- */
+type ledgerFixture struct {
+	ledger     eventsource.EventHandler
+	userStore  *eventsourcetest.MemoryStore
+	walletRepo eventsource.EventRepo
+}
+
+func newLedgerFixture(t *testing.T) *ledgerFixture {
+	logger := zaptest.NewLogger(t)
+	userStore := eventsourcetest.NewMemoryStore()
+	walletStore := eventsourcetest.NewMemoryStore()
+	walletRepo := loyalty.NewRepository(loyalty.RepositoryParams{
+		Store:  walletStore,
+		Logger: logger,
+		NewAggregate: func(id string) eventsource.Aggregate {
+			return wallet.NewWallet(id)
+		},
+	})
+
+	dispatcher := eventsource.NewDispatcher(logger)
+	dispatcher.Use(eventsource.IdempotencyMiddleware(walletStore))
+	errRegister := dispatcher.RegisterHandler(command.NewWalletCommandHandler(
+		command.CommandHandlerParams{
+			EventBus: &eventsourcetest.NopEventBus{},
+			Repo:     walletRepo,
+			Logger:   logger,
+		},
+	))
+	if errRegister != nil {
+		t.Fatal(errRegister)
+	}
+
+	return &ledgerFixture{
+		ledger:     NewLedger(dispatcher, userStore),
+		userStore:  userStore,
+		walletRepo: walletRepo,
+	}
+}
+
+// userEvent saves an event of user-1 and returns it
+func (f *ledgerFixture) userEvent(
+	t *testing.T,
+	version int,
+	eventType string,
+	payload interface{},
+) eventsource.Event {
+	applier, err := user.GetApplier(eventsource.Event{
+		AggregateID: "user-1",
+		EventType:   eventType,
+		Version:     version,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errPayload := applier.SetSerializedPayload(payload)
+	if errPayload != nil {
+		t.Fatal(errPayload)
+	}
+
+	event := applier.EventModel()
+	errSave := f.userStore.Save(context.Background(), event)
+	if errSave != nil {
+		t.Fatal(errSave)
+	}
+	return event
+}
+
+func (f *ledgerFixture) balance(t *testing.T) uint32 {
+	aggregate, err := f.walletRepo.Load(context.Background(), wallet.ID("user-1"), 0)
+	assert.Nil(t, err)
+
+	w, errWallet := wallet.AssertWalletAggregate(aggregate)
+	assert.Nil(t, errWallet)
+	return w.Balance
+}
@@ -0,0 +1,50 @@
+package event
+
+import (
+	"context"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/loyalty"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/wallet"
+)
+
+type opener struct {
+	dispatcher eventsource.CommandDispatcher
+}
+
+// NewOpener creates the EventHandler that opens a wallet for every user
+// that is created
+func NewOpener(dispatcher eventsource.CommandDispatcher) eventsource.EventHandler {
+	return &opener{
+		dispatcher: dispatcher,
+	}
+}
+
+func (o *opener) EventTypesHandled() []string {
+	return []string{
+		user.UserCreatedEventType,
+	}
+}
+
+func (o *opener) Handle(ctx context.Context, event eventsource.Event) error {
+	return o.open(ctx, event.AggregateID)
+}
+
+// Sync implements the EventHandler interface; opens the wallet of the
+// user if it was not opened yet
+func (o *opener) Sync(ctx context.Context, aggregateID string) error {
+	return o.open(ctx, aggregateID)
+}
+
+// open dispatches OpenWallet, which does nothing for opened wallets
+func (o *opener) open(ctx context.Context, userID string) error {
+	ctx = eventsource.WithCommandSource(ctx, eventsource.CommandSourceSystem)
+	_, err := o.dispatcher.Dispatch(ctx, &loyalty.OpenWallet{
+		CommandModel: eventsource.CommandModel{
+			ID: wallet.ID(userID),
+		},
+		UserID: userID,
+	})
+	return err
+}
@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+)
+
+// Opened event is fired when a wallet is opened for a user
+type Opened struct {
+	eventsource.ApplierModel
+}
+
+func NewOpenedApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &Opened{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+type OpenedPayload struct {
+	UserID string `json:"userId,omitempty"`
+}
+
+// Apply implements the applier interface
+func (applier *Opened) Apply(agg eventsource.Aggregate) error {
+	walletAggregate, err := AssertWalletAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	if walletAggregate.Opened() {
+		return errors.New("WalletOpened event must only be applied once")
+	}
+
+	walletAggregate.UserID = payload.UserID
+	walletAggregate.OpenedAt = applier.EventAt
+
+	walletAggregate.Version = applier.Version
+	walletAggregate.UpdatedAt = applier.EventAt
+	return nil
+}
+
+func (applier *Opened) SetSerializedPayload(payload interface{}) error {
+	openedEvent, ok := payload.(OpenedPayload)
+	if !ok {
+		return applier.PayloadErr("wallet.Opened.SetSerializedPayload", payload)
+	}
+	return applier.Serialize(openedEvent)
+}
+
+func (applier *Opened) GetDeserializedPayload() (
+	*OpenedPayload,
+	error,
+) {
+	var payload OpenedPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsStringEmpty(&payload.UserID) {
+		return nil, applier.PayloadErr(
+			"wallet.Opened.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
@@ -0,0 +1,79 @@
+package wallet
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// PointsAdjusted event is fired when the balance of a wallet is corrected
+// manually, e.g. by support
+type PointsAdjusted struct {
+	eventsource.ApplierModel
+}
+
+func NewPointsAdjustedApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &PointsAdjusted{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+type PointsAdjustedPayload struct {
+	// Delta is added to the balance; negative values take points away
+	Delta  int64  `json:"delta,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Apply implements the applier interface
+func (applier *PointsAdjusted) Apply(agg eventsource.Aggregate) error {
+	walletAggregate, err := AssertWalletAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	if payload.Delta > 0 {
+		walletAggregate.Balance += uint32(payload.Delta)
+	} else {
+		errDebit := walletAggregate.debit(uint32(-payload.Delta))
+		if errDebit != nil {
+			return errDebit
+		}
+	}
+
+	walletAggregate.Version = applier.Version
+	walletAggregate.UpdatedAt = applier.EventAt
+	return nil
+}
+
+func (applier *PointsAdjusted) SetSerializedPayload(payload interface{}) error {
+	pointsAdjustedEvent, ok := payload.(PointsAdjustedPayload)
+	if !ok {
+		return applier.PayloadErr("wallet.PointsAdjusted.SetSerializedPayload", payload)
+	}
+	return applier.Serialize(pointsAdjustedEvent)
+}
+
+func (applier *PointsAdjusted) GetDeserializedPayload() (
+	*PointsAdjustedPayload,
+	error,
+) {
+	var payload PointsAdjustedPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if payload.Delta == 0 || eventsource.IsStringEmpty(&payload.Reason) {
+		return nil, applier.PayloadErr(
+			"wallet.PointsAdjusted.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
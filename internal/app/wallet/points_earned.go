@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// PointsEarned event is fired when points are credited to a wallet
+type PointsEarned struct {
+	eventsource.ApplierModel
+}
+
+func NewPointsEarnedApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &PointsEarned{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+type PointsEarnedPayload struct {
+	Points uint32 `json:"points,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Apply implements the applier interface
+func (applier *PointsEarned) Apply(agg eventsource.Aggregate) error {
+	walletAggregate, err := AssertWalletAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	walletAggregate.Balance += payload.Points
+
+	walletAggregate.Version = applier.Version
+	walletAggregate.UpdatedAt = applier.EventAt
+	return nil
+}
+
+func (applier *PointsEarned) SetSerializedPayload(payload interface{}) error {
+	pointsEarnedEvent, ok := payload.(PointsEarnedPayload)
+	if !ok {
+		return applier.PayloadErr("wallet.PointsEarned.SetSerializedPayload", payload)
+	}
+	return applier.Serialize(pointsEarnedEvent)
+}
+
+func (applier *PointsEarned) GetDeserializedPayload() (
+	*PointsEarnedPayload,
+	error,
+) {
+	var payload PointsEarnedPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsZero(payload.Points) {
+		return nil, applier.PayloadErr(
+			"wallet.PointsEarned.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
@@ -0,0 +1,72 @@
+package wallet
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// PointsExpired event is fired when unused points lapse
+type PointsExpired struct {
+	eventsource.ApplierModel
+}
+
+func NewPointsExpiredApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &PointsExpired{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+type PointsExpiredPayload struct {
+	Points uint32 `json:"points,omitempty"`
+}
+
+// Apply implements the applier interface
+func (applier *PointsExpired) Apply(agg eventsource.Aggregate) error {
+	walletAggregate, err := AssertWalletAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	errDebit := walletAggregate.debit(payload.Points)
+	if errDebit != nil {
+		return errDebit
+	}
+
+	walletAggregate.Version = applier.Version
+	walletAggregate.UpdatedAt = applier.EventAt
+	return nil
+}
+
+func (applier *PointsExpired) SetSerializedPayload(payload interface{}) error {
+	pointsExpiredEvent, ok := payload.(PointsExpiredPayload)
+	if !ok {
+		return applier.PayloadErr("wallet.PointsExpired.SetSerializedPayload", payload)
+	}
+	return applier.Serialize(pointsExpiredEvent)
+}
+
+func (applier *PointsExpired) GetDeserializedPayload() (
+	*PointsExpiredPayload,
+	error,
+) {
+	var payload PointsExpiredPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsZero(payload.Points) {
+		return nil, applier.PayloadErr(
+			"wallet.PointsExpired.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
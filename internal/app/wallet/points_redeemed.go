@@ -0,0 +1,107 @@
+package wallet
+
+import (
+	"context"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+)
+
+// PointsRedeemed event is fired when a user spends points from their wallet
+type PointsRedeemed struct {
+	eventsource.ApplierModel
+}
+
+func NewPointsRedeemedApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &PointsRedeemed{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+type PointsRedeemedPayload struct {
+	Points uint32 `json:"points,omitempty"`
+	Reason string `json:"reason,omitempty"`
+
+	// Balance is the balance of the wallet after the redemption
+	Balance uint32 `json:"balance"`
+}
+
+// Apply implements the applier interface
+func (applier *PointsRedeemed) Apply(agg eventsource.Aggregate) error {
+	walletAggregate, err := AssertWalletAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	errDebit := walletAggregate.debit(payload.Points)
+	if errDebit != nil {
+		return errDebit
+	}
+
+	walletAggregate.Version = applier.Version
+	walletAggregate.UpdatedAt = applier.EventAt
+	return nil
+}
+
+func (applier *PointsRedeemed) SetSerializedPayload(payload interface{}) error {
+	pointsRedeemedEvent, ok := payload.(PointsRedeemedPayload)
+	if !ok {
+		return applier.PayloadErr("wallet.PointsRedeemed.SetSerializedPayload", payload)
+	}
+	return applier.Serialize(pointsRedeemedEvent)
+}
+
+func (applier *PointsRedeemed) GetDeserializedPayload() (
+	*PointsRedeemedPayload,
+	error,
+) {
+	var payload PointsRedeemedPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsZero(payload.Points) {
+		return nil, applier.PayloadErr(
+			"wallet.PointsRedeemed.GetDeserializedPayload",
+			payload,
+		)
+	}
+
+	return &payload, nil
+}
+
+// LoadPointsRedeemed returns the payload of the WalletPointsRedeemed event
+// of the wallet at version, e.g. to report the balance after a redemption
+func LoadPointsRedeemed(
+	ctx context.Context,
+	store EventStore,
+	walletID string,
+	version int,
+) (*PointsRedeemedPayload, error) {
+	history, err := store.Load(ctx, walletID, version-1)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load history of wallet %v", walletID)
+	}
+	if len(history) == 0 ||
+		history[0].Version != version ||
+		history[0].EventType != WalletPointsRedeemedEventType {
+		return nil, errors.Errorf(
+			"wallet %v has no WalletPointsRedeemed event at version %v",
+			walletID,
+			version,
+		)
+	}
+
+	applier := PointsRedeemed{
+		ApplierModel: *eventsource.NewApplierModel(history[0]),
+	}
+	return applier.GetDeserializedPayload()
+}
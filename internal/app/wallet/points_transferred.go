@@ -0,0 +1,150 @@
+package wallet
+
+import (
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// PointsTransferredOut event is fired on the sending wallet of a transfer
+type PointsTransferredOut struct {
+	eventsource.ApplierModel
+}
+
+func NewPointsTransferredOutApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &PointsTransferredOut{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+// PointsTransferredIn event is fired on the receiving wallet of a transfer
+type PointsTransferredIn struct {
+	eventsource.ApplierModel
+}
+
+func NewPointsTransferredInApplier(
+	id, eventType string,
+	version int,
+) eventsource.Applier {
+	event := eventsource.NewEvent(id, eventType, version, nil)
+	return &PointsTransferredIn{ApplierModel: *eventsource.NewApplierModel(*event)}
+}
+
+// PointsTransferredPayload is the payload of both sides of a transfer
+type PointsTransferredPayload struct {
+	TransferID   string `json:"transferId,omitempty"`
+	Points       uint32 `json:"points,omitempty"`
+	FromWalletID string `json:"fromWalletId,omitempty"`
+	ToWalletID   string `json:"toWalletId,omitempty"`
+}
+
+// Apply implements the applier interface
+func (applier *PointsTransferredOut) Apply(agg eventsource.Aggregate) error {
+	walletAggregate, err := AssertWalletAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	errDebit := walletAggregate.debit(payload.Points)
+	if errDebit != nil {
+		return errDebit
+	}
+
+	walletAggregate.Version = applier.Version
+	walletAggregate.UpdatedAt = applier.EventAt
+	return nil
+}
+
+func (applier *PointsTransferredOut) SetSerializedPayload(payload interface{}) error {
+	return setTransferredPayload(
+		&applier.ApplierModel,
+		"wallet.PointsTransferredOut.SetSerializedPayload",
+		payload,
+	)
+}
+
+func (applier *PointsTransferredOut) GetDeserializedPayload() (
+	*PointsTransferredPayload,
+	error,
+) {
+	return getTransferredPayload(
+		&applier.ApplierModel,
+		"wallet.PointsTransferredOut.GetDeserializedPayload",
+	)
+}
+
+// Apply implements the applier interface
+func (applier *PointsTransferredIn) Apply(agg eventsource.Aggregate) error {
+	walletAggregate, err := AssertWalletAggregate(agg)
+	if err != nil {
+		return err
+	}
+
+	payload, errDeserialize := applier.GetDeserializedPayload()
+	if errDeserialize != nil {
+		return errDeserialize
+	}
+
+	walletAggregate.Balance += payload.Points
+	walletAggregate.Version = applier.Version
+	walletAggregate.UpdatedAt = applier.EventAt
+	return nil
+}
+
+func (applier *PointsTransferredIn) SetSerializedPayload(payload interface{}) error {
+	return setTransferredPayload(
+		&applier.ApplierModel,
+		"wallet.PointsTransferredIn.SetSerializedPayload",
+		payload,
+	)
+}
+
+func (applier *PointsTransferredIn) GetDeserializedPayload() (
+	*PointsTransferredPayload,
+	error,
+) {
+	return getTransferredPayload(
+		&applier.ApplierModel,
+		"wallet.PointsTransferredIn.GetDeserializedPayload",
+	)
+}
+
+/* ----- helpers ----- */
+func setTransferredPayload(
+	applier *eventsource.ApplierModel,
+	operation eventsource.Operation,
+	payload interface{},
+) error {
+	pointsTransferredEvent, ok := payload.(PointsTransferredPayload)
+	if !ok {
+		return applier.PayloadErr(operation, payload)
+	}
+	return applier.Serialize(pointsTransferredEvent)
+}
+
+func getTransferredPayload(
+	applier *eventsource.ApplierModel,
+	operation eventsource.Operation,
+) (*PointsTransferredPayload, error) {
+	var payload PointsTransferredPayload
+	errPayload := applier.Deserialize(&payload)
+	if errPayload != nil {
+		return nil, errPayload
+	}
+
+	if eventsource.IsZero(payload.Points) ||
+		eventsource.IsAnyStringEmpty(
+			&payload.TransferID,
+			&payload.FromWalletID,
+			&payload.ToWalletID,
+		) {
+		return nil, applier.PayloadErr(operation, payload)
+	}
+
+	return &payload, nil
+}
@@ -0,0 +1,29 @@
+package wallet
+
+import (
+	"context"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+)
+
+// DTO is the read model of a wallet
+type DTO struct {
+	eventsource.AggregateBase
+	WalletID  string    `json:"walletId" firestore:"walletId"`
+	UserID    string    `json:"userId" firestore:"userId"`
+	Balance   uint32    `json:"balance" firestore:"balance"`
+	OpenedAt  time.Time `json:"openedAt" firestore:"openedAt"`
+	UpdatedAt time.Time `json:"updatedAt" firestore:"updatedAt"`
+}
+
+type ReadRepo interface {
+	OpenWallet(ctx context.Context, wallet DTO) error
+
+	// AdjustBalance adds delta to the balance of the wallet atomically
+	AdjustBalance(ctx context.Context, walletID string, delta int64, version int) error
+
+	// Wallet returns the wallet of the user. Missing wallets are reported
+	// with codes.NotFound
+	Wallet(ctx context.Context, userID string) (*DTO, error)
+}
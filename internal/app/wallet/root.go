@@ -0,0 +1,12 @@
+package wallet
+
+import "github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+
+type EventStore interface {
+	eventsource.EventStore
+}
+
+// Projector projects wallet events into the wallet read model
+type Projector interface {
+	eventsource.Projector
+}
@@ -0,0 +1,275 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Headers set on every webhook request
+const (
+	HeaderEvent     = "X-Loyalty-Event"
+	HeaderDelivery  = "X-Loyalty-Delivery"
+	HeaderSignature = "X-Loyalty-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// DeliveryConfig controls how webhook requests are retried
+type DeliveryConfig struct {
+	MaxRetry        uint64
+	InitialInterval time.Duration
+	MaxElapsedTime  time.Duration
+	RequestTimeout  time.Duration
+}
+
+var defaultDeliveryConfig = DeliveryConfig{
+	MaxRetry:        5,
+	InitialInterval: 500 * time.Millisecond,
+	MaxElapsedTime:  time.Minute,
+	RequestTimeout:  10 * time.Second,
+}
+
+// requestBody is the payload delivered to subscribers
+type requestBody struct {
+	DeliveryID  string          `json:"deliveryId"`
+	EventType   string          `json:"eventType"`
+	AggregateID string          `json:"aggregateId"`
+	Version     int             `json:"version"`
+	OccurredAt  time.Time       `json:"occurredAt"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// EventHandler is the eventsource.EventHandler delivering events to webhook
+// subscriptions
+type EventHandler interface {
+	eventsource.EventHandler
+
+	// Wait blocks until the in-flight deliveries have finished or ctx is
+	// done, e.g. so that shutdown does not cut deliveries off
+	Wait(ctx context.Context) error
+}
+
+type eventHandler struct {
+	store      Store
+	httpClient *http.Client
+	config     DeliveryConfig
+	logger     *zap.Logger
+
+	// deliveries tracks in-flight deliveries
+	deliveries sync.WaitGroup
+}
+
+// EventHandlerParams represent the params needed to instantiate a new webhook EventHandler
+type EventHandlerParams struct {
+	Store  Store
+	Logger *zap.Logger
+
+	// HTTPClient is used to deliver webhooks. Optional
+	HTTPClient *http.Client
+
+	// Config overrides the default delivery retry config. Optional
+	Config *DeliveryConfig
+}
+
+// NewEventHandler creates an EventHandler that delivers events to
+// webhook subscriptions
+func NewEventHandler(p EventHandlerParams) EventHandler {
+	deliveryConfig := defaultDeliveryConfig
+	if p.Config != nil {
+		deliveryConfig = *p.Config
+	}
+
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: deliveryConfig.RequestTimeout}
+	}
+
+	return &eventHandler{
+		store:      p.Store,
+		httpClient: httpClient,
+		config:     deliveryConfig,
+		logger:     p.Logger,
+	}
+}
+
+// EventTypesHandled implements the EventHandler interface
+func (h *eventHandler) EventTypesHandled() []string {
+	return EventTypes
+}
+
+// Sync implements the EventHandler interface; webhooks have no state to sync
+func (h *eventHandler) Sync(ctx context.Context, aggregateID string) error {
+	return nil
+}
+
+// Handle implements the EventHandler interface. Deliveries run in the
+// background with their own retries so that slow partners do not hold
+// up the event bus
+func (h *eventHandler) Handle(
+	ctx context.Context,
+	event eventsource.Event,
+) error {
+	subscriptions, err := h.store.SubscriptionsByEventType(ctx, event.EventType)
+	if err != nil {
+		return errors.Wrap(err, "unable to load webhook subscriptions")
+	}
+
+	deliveryCtx := eventsource.DetachContext(ctx)
+	for _, v := range subscriptions {
+		h.deliveries.Add(1)
+		go func(subscription Subscription) {
+			defer h.deliveries.Done()
+			h.deliver(deliveryCtx, subscription, event)
+		}(v)
+	}
+
+	return nil
+}
+
+// Wait implements the EventHandler interface
+func (h *eventHandler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.deliveries.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "webhook deliveries did not finish")
+	}
+}
+
+func (h *eventHandler) deliver(
+	ctx context.Context,
+	subscription Subscription,
+	event eventsource.Event,
+) {
+	delivery := Delivery{
+		ID:             eventsource.NewUUID(),
+		SubscriptionID: subscription.ID,
+		EventType:      event.EventType,
+		AggregateID:    event.AggregateID,
+		EventVersion:   event.Version,
+	}
+
+	body, err := newRequestBody(delivery.ID, event)
+	if err != nil {
+		delivery.Error = err.Error()
+		h.recordDelivery(ctx, delivery)
+		return
+	}
+
+	operation := func() error {
+		delivery.Attempts++
+		statusCode, errSend := h.send(ctx, subscription, delivery.ID, event.EventType, body)
+		delivery.StatusCode = statusCode
+		return errSend
+	}
+
+	errDeliver := backoff.Retry(operation, h.newBackOff(ctx))
+	if errDeliver != nil {
+		delivery.Error = errDeliver.Error()
+		h.logger.Error(
+			"webhook delivery failed",
+			zap.Error(errDeliver),
+			zap.String("subscriptionId", subscription.ID),
+			zap.String("eventType", event.EventType),
+			zap.Int("attempts", delivery.Attempts),
+		)
+	} else {
+		delivery.Succeeded = true
+	}
+
+	h.recordDelivery(ctx, delivery)
+}
+
+// send performs a single delivery attempt. Client errors other than
+// rate limiting are permanent and are not retried
+func (h *eventHandler) send(
+	ctx context.Context,
+	subscription Subscription,
+	deliveryID string,
+	eventType string,
+	body []byte,
+) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, backoff.Permanent(err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderSignature, signaturePrefix+Sign(subscription.Secret, body))
+
+	res, errDo := h.httpClient.Do(req)
+	if errDo != nil {
+		return 0, errDo
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res.StatusCode, nil
+	}
+
+	errStatus := errors.Errorf("webhook responded with status %d", res.StatusCode)
+	if res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests {
+		return res.StatusCode, errStatus
+	}
+	return res.StatusCode, backoff.Permanent(errStatus)
+}
+
+func (h *eventHandler) recordDelivery(ctx context.Context, delivery Delivery) {
+	delivery.DeliveredAt = time.Now()
+	err := h.store.RecordDelivery(ctx, delivery)
+	if err != nil {
+		h.logger.Error(
+			"unable to record webhook delivery",
+			zap.Error(err),
+			zap.String("subscriptionId", delivery.SubscriptionID),
+		)
+	}
+}
+
+func (h *eventHandler) newBackOff(ctx context.Context) backoff.BackOff {
+	backOff := backoff.NewExponentialBackOff()
+	backOff.InitialInterval = h.config.InitialInterval
+	backOff.MaxElapsedTime = h.config.MaxElapsedTime
+
+	return backoff.WithContext(
+		backoff.WithMaxRetries(backOff, h.config.MaxRetry),
+		ctx,
+	)
+}
+
+func newRequestBody(deliveryID string, event eventsource.Event) ([]byte, error) {
+	data := json.RawMessage("null")
+	if event.Payload != nil {
+		data = json.RawMessage(*event.Payload)
+	}
+
+	body, err := json.Marshal(requestBody{
+		DeliveryID:  deliveryID,
+		EventType:   event.EventType,
+		AggregateID: event.AggregateID,
+		Version:     event.Version,
+		OccurredAt:  event.EventAt,
+		Data:        data,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to serialize webhook payload")
+	}
+	return body, nil
+}
@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+/* ----- tests ----- */
+func TestEventHandler_DeliversSignedPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	var (
+		body      []byte
+		signature string
+	)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ = ioutil.ReadAll(r.Body)
+			signature = r.Header.Get(HeaderSignature)
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	store := newMemoryStore(Subscription{
+		ID:         "sub-1",
+		URL:        server.URL,
+		EventTypes: []string{user.PointsEarnedEventType},
+		Secret:     "secret",
+	})
+	handler := newTestEventHandler(t, store)
+
+	err := handler.Handle(context.Background(), newPointsEarnedEvent())
+	handler.deliveries.Wait()
+
+	assert.Nil(err)
+	assert.Equal(signaturePrefix+Sign("secret", body), signature)
+	assert.Len(store.deliveries, 1)
+	assert.True(store.deliveries[0].Succeeded)
+	assert.Equal(1, store.deliveries[0].Attempts)
+}
+
+func TestEventHandler_RetriesServerErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		},
+	))
+	defer server.Close()
+
+	store := newMemoryStore(Subscription{
+		ID:         "sub-1",
+		URL:        server.URL,
+		EventTypes: []string{user.PointsEarnedEventType},
+		Secret:     "secret",
+	})
+	handler := newTestEventHandler(t, store)
+
+	err := handler.Handle(context.Background(), newPointsEarnedEvent())
+	handler.deliveries.Wait()
+
+	assert.Nil(err)
+	assert.Len(store.deliveries, 1)
+	assert.True(store.deliveries[0].Succeeded)
+	assert.Equal(3, store.deliveries[0].Attempts)
+}
+
+func TestEventHandler_ClientErrorIsPermanent(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		},
+	))
+	defer server.Close()
+
+	store := newMemoryStore(Subscription{
+		ID:         "sub-1",
+		URL:        server.URL,
+		EventTypes: []string{user.PointsEarnedEventType},
+		Secret:     "secret",
+	})
+	handler := newTestEventHandler(t, store)
+
+	err := handler.Handle(context.Background(), newPointsEarnedEvent())
+	handler.deliveries.Wait()
+
+	assert.Nil(err)
+	assert.Len(store.deliveries, 1)
+	assert.False(store.deliveries[0].Succeeded)
+	assert.Equal(1, store.deliveries[0].Attempts)
+	assert.Equal(http.StatusBadRequest, store.deliveries[0].StatusCode)
+}
+
+func TestEventHandler_WaitForDeliveries(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	store := newMemoryStore(Subscription{
+		ID:         "sub-1",
+		URL:        server.URL,
+		EventTypes: []string{user.PointsEarnedEventType},
+		Secret:     "secret",
+	})
+	handler := newTestEventHandler(t, store)
+	assert.Nil(handler.Handle(context.Background(), newPointsEarnedEvent()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.NotNil(handler.Wait(ctx))
+
+	close(release)
+	assert.Nil(handler.Wait(context.Background()))
+	assert.Len(store.deliveries, 1)
+	assert.True(store.deliveries[0].Succeeded)
+}
+
+func TestService_CreateSubscriptionValidation(t *testing.T) {
+	assert := assert.New(t)
+
+	service := NewService(newMemoryStore(), zaptest.NewLogger(t))
+
+	_, errURL := service.CreateSubscription(
+		context.Background(),
+		"not-a-url",
+		[]string{user.PointsEarnedEventType},
+		nil,
+	)
+	assert.EqualError(errURL, errInvalidURL.Error())
+
+	_, errEventType := service.CreateSubscription(
+		context.Background(),
+		"https://example.com/hook",
+		[]string{user.UserDeletedEventType},
+		nil,
+	)
+	assert.Error(errEventType)
+
+	subscription, err := service.CreateSubscription(
+		context.Background(),
+		"https://example.com/hook",
+		[]string{user.PointsEarnedEventType},
+		nil,
+	)
+	assert.Nil(err)
+	assert.NotEmpty(subscription.Secret)
+}
+
+/* ----- store ----- */
+type memoryStore struct {
+	mu            sync.Mutex
+	subscriptions []Subscription
+	deliveries    []Delivery
+}
+
+func newMemoryStore(subscriptions ...Subscription) *memoryStore {
+	return &memoryStore{subscriptions: subscriptions}
+}
+
+func (m *memoryStore) CreateSubscription(ctx context.Context, subscription Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscriptions = append(m.subscriptions, subscription)
+	return nil
+}
+
+func (m *memoryStore) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	return nil
+}
+
+func (m *memoryStore) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	return m.subscriptions, nil
+}
+
+func (m *memoryStore) SubscriptionsByEventType(
+	ctx context.Context,
+	eventType string,
+) ([]Subscription, error) {
+	subscriptions := []Subscription{}
+	for _, v := range m.subscriptions {
+		if v.Handles(eventType) {
+			subscriptions = append(subscriptions, v)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (m *memoryStore) RecordDelivery(ctx context.Context, delivery Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries = append(m.deliveries, delivery)
+	return nil
+}
+
+func (m *memoryStore) Deliveries(ctx context.Context, subscriptionID string) ([]Delivery, error) {
+	return m.deliveries, nil
+}
+
+/* ----- helpers ----- */
+func newTestEventHandler(t *testing.T, store Store) *eventHandler {
+	return NewEventHandler(EventHandlerParams{
+		Store:  store,
+		Logger: zaptest.NewLogger(t),
+		Config: &DeliveryConfig{
+			MaxRetry:        3,
+			InitialInterval: time.Millisecond,
+			MaxElapsedTime:  time.Second,
+			RequestTimeout:  time.Second,
+		},
+	}).(*eventHandler)
+}
+
+func newPointsEarnedEvent() eventsource.Event {
+	return *eventsource.NewEvent(
+		"user-1",
+		user.PointsEarnedEventType,
+		2,
+		[]byte(`{"pointsEarned":100}`),
+	)
+}
@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/eventsource"
+	"github.com/dwaynelavon/es-loyalty-program/internal/app/user"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+var (
+	errInvalidURL             = errors.New("webhook url must be an absolute http or https url")
+	errMissingEventTypes      = errors.New("webhook subscription must include at least one event type")
+	errUnsupportedEventType   = errors.New("webhook event type not supported")
+	errSubscriptionIDRequired = errors.New("webhook subscription id must be defined")
+)
+
+// EventTypes lists the events partner systems can subscribe to
+var EventTypes = []string{
+	user.PointsEarnedEventType,
+	user.UserReferralCompletedEventType,
+}
+
+// Subscription registers a partner URL to receive signed event payloads
+type Subscription struct {
+	ID         string    `json:"id" firestore:"id"`
+	URL        string    `json:"url" firestore:"url"`
+	EventTypes []string  `json:"eventTypes" firestore:"eventTypes"`
+	Secret     string    `json:"-" firestore:"secret"`
+	CreatedAt  time.Time `json:"createdAt" firestore:"createdAt"`
+}
+
+// Handles indicates whether the subscription includes the event type
+func (s *Subscription) Handles(eventType string) bool {
+	for _, v := range s.EventTypes {
+		if v == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery records the outcome of delivering an event to a subscription
+type Delivery struct {
+	ID             string    `json:"id" firestore:"id"`
+	SubscriptionID string    `json:"subscriptionId" firestore:"subscriptionId"`
+	EventType      string    `json:"eventType" firestore:"eventType"`
+	AggregateID    string    `json:"aggregateId" firestore:"aggregateId"`
+	EventVersion   int       `json:"eventVersion" firestore:"eventVersion"`
+	Attempts       int       `json:"attempts" firestore:"attempts"`
+	StatusCode     int       `json:"statusCode" firestore:"statusCode"`
+	Succeeded      bool      `json:"succeeded" firestore:"succeeded"`
+	Error          string    `json:"error" firestore:"error"`
+	DeliveredAt    time.Time `json:"deliveredAt" firestore:"deliveredAt"`
+}
+
+// Store persists webhook subscriptions and their delivery log
+type Store interface {
+	CreateSubscription(ctx context.Context, subscription Subscription) error
+	DeleteSubscription(ctx context.Context, subscriptionID string) error
+	Subscriptions(ctx context.Context) ([]Subscription, error)
+	SubscriptionsByEventType(ctx context.Context, eventType string) ([]Subscription, error)
+	RecordDelivery(ctx context.Context, delivery Delivery) error
+	Deliveries(ctx context.Context, subscriptionID string) ([]Delivery, error)
+}
+
+// Service manages webhook subscriptions
+type Service interface {
+	// CreateSubscription registers a new subscription. A secret is
+	// generated when one is not provided
+	CreateSubscription(
+		ctx context.Context,
+		url string,
+		eventTypes []string,
+		secret *string,
+	) (*Subscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID string) error
+	Subscriptions(ctx context.Context) ([]Subscription, error)
+	Deliveries(ctx context.Context, subscriptionID string) ([]Delivery, error)
+}
+
+type service struct {
+	store  Store
+	logger *zap.Logger
+}
+
+// NewService creates a new instance of Service
+func NewService(store Store, logger *zap.Logger) Service {
+	return &service{
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (s *service) CreateSubscription(
+	ctx context.Context,
+	rawURL string,
+	eventTypes []string,
+	secret *string,
+) (*Subscription, error) {
+	errValidate := validateSubscription(rawURL, eventTypes)
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
+	subscriptionSecret := ""
+	if !eventsource.IsStringEmpty(secret) {
+		subscriptionSecret = *secret
+	} else {
+		generated, errSecret := generateSecret()
+		if errSecret != nil {
+			return nil, errSecret
+		}
+		subscriptionSecret = generated
+	}
+
+	subscription := Subscription{
+		ID:         eventsource.NewUUID(),
+		URL:        rawURL,
+		EventTypes: eventTypes,
+		Secret:     subscriptionSecret,
+		CreatedAt:  time.Now(),
+	}
+	errCreate := s.store.CreateSubscription(ctx, subscription)
+	if errCreate != nil {
+		return nil, errCreate
+	}
+
+	s.logger.Info(
+		"webhook subscription created",
+		zap.String("subscriptionId", subscription.ID),
+		zap.Strings("eventTypes", eventTypes),
+	)
+	return &subscription, nil
+}
+
+func (s *service) DeleteSubscription(
+	ctx context.Context,
+	subscriptionID string,
+) error {
+	if eventsource.IsStringEmpty(&subscriptionID) {
+		return errSubscriptionIDRequired
+	}
+	return s.store.DeleteSubscription(ctx, subscriptionID)
+}
+
+func (s *service) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	return s.store.Subscriptions(ctx)
+}
+
+func (s *service) Deliveries(
+	ctx context.Context,
+	subscriptionID string,
+) ([]Delivery, error) {
+	if eventsource.IsStringEmpty(&subscriptionID) {
+		return nil, errSubscriptionIDRequired
+	}
+	return s.store.Deliveries(ctx, subscriptionID)
+}
+
+/* ----- helpers ----- */
+
+// Sign returns the hex encoded HMAC-SHA256 signature of body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validateSubscription(rawURL string, eventTypes []string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || !parsed.IsAbs() ||
+		(parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errInvalidURL
+	}
+
+	if len(eventTypes) == 0 {
+		return errMissingEventTypes
+	}
+	for _, v := range eventTypes {
+		if !isSupportedEventType(v) {
+			return errors.Wrapf(errUnsupportedEventType, "%v", v)
+		}
+	}
+	return nil
+}
+
+func isSupportedEventType(eventType string) bool {
+	for _, v := range EventTypes {
+		if v == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to generate webhook secret")
+	}
+	return hex.EncodeToString(b), nil
+}